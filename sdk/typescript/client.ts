@@ -0,0 +1,1532 @@
+// Code generated by DX/src/cmd/openapi from the OpenAPI document. DO NOT EDIT.
+
+export interface AdminAssignErrandRequest {
+  user_id: string;
+}
+
+export interface AdminAssignRolesRequest {
+  roles: string[];
+}
+
+export interface AdminCategoryRequest {
+  name: string;
+}
+
+export interface AdminErrandRequest {
+  audio?: string[];
+  budget: number;
+  category: string;
+  description?: string;
+  dropoff_location?: ErrandLocation;
+  duration: ErrandDurationRequest;
+  images?: string[];
+  pickup_location: ErrandLocation;
+  restriction?: "qualification" | "verification" | "insurance";
+  user_id: string;
+}
+
+export interface AdminFeeRuleRequest {
+  category_id?: string;
+  flat?: number;
+  kind: "service-fee" | "commission";
+  minimum?: number;
+  percentage: number | null;
+}
+
+export interface AdminFeeRulesData {
+  defaults?: FeeRule[];
+  rules?: FeeRule[];
+}
+
+export interface AdminFeeWaiverRequest {
+  category_id?: string;
+  description?: string;
+  ends_at: string;
+  kind: "service-fee" | "commission";
+  starts_at: string;
+}
+
+export interface AdminPromoCreditRequest {
+  amount: number;
+  description?: string;
+  user_id: string;
+}
+
+export interface AdminPromoRequest {
+  category_ids?: string[];
+  code: string;
+  discount_type: "percentage" | "flat";
+  expires_at: string;
+  first_errand_only?: boolean;
+  max_discount?: number;
+  per_user_limit?: number;
+  usage_limit?: number;
+  value: number;
+}
+
+export interface AdminQuestionRequest {
+  question: string;
+}
+
+export interface AdminRefundRequest {
+  amount: number;
+  reason: string;
+  transaction_id: string;
+}
+
+export interface AdminResolveDiscrepancyRequest {
+  note: string;
+}
+
+export interface AdminUsersRequest {
+  users: string[];
+}
+
+export interface ApiKey {
+  created_at?: string;
+  hint?: string;
+  id?: string;
+  last_used_at?: string | null;
+  name?: string;
+  rate_limit?: number;
+  revoked_at?: string | null;
+  scopes?: string[];
+}
+
+export interface ApikeyCreated {
+  created_at?: string;
+  hint?: string;
+  id?: string;
+  key?: string;
+  last_used_at?: string | null;
+  name?: string;
+  rate_limit?: number;
+  revoked_at?: string | null;
+  scopes?: string[];
+}
+
+export interface ApikeyRequest {
+  name: string;
+  rate_limit?: number | null;
+  scopes: string[];
+}
+
+export interface AuthToken {
+  access_token?: string;
+  access_token_expires?: number;
+  refresh_token?: string;
+  refresh_token_expires?: number;
+}
+
+export interface AuthenticationCodeRequest {
+  code: string;
+}
+
+export interface BankAccountRequest {
+  bank_code: string;
+  name: string;
+  number: string;
+  transaction_pin: string;
+  type: string;
+}
+
+export interface Bid {
+  created_at?: string;
+  description?: string;
+  errand_id?: string;
+  haggles?: Haggle[];
+  id?: string;
+  runner?: string;
+  state?: string;
+  updated_at?: string;
+}
+
+export interface BidResponseRequest {
+  amount?: number;
+  response: "accept" | "reject";
+  runner_id: string;
+}
+
+export interface CancelErrandRequest {
+  reason?: string;
+}
+
+export interface Category {
+  created_at?: string;
+  created_by?: string;
+  id?: string;
+  identifier?: string;
+  image_url?: string;
+  modified_by?: EntityModifiedBy[];
+  name?: string;
+  type?: string;
+  updated_at?: string;
+}
+
+export interface ChangePasswordRequest {
+  current_password: string;
+  password: string;
+}
+
+export interface ChangePinRequest {
+  new_pin: string;
+  old_pin: string;
+}
+
+export interface CompleteErrandRequest {
+  source: "sender" | "runner";
+}
+
+export interface CompleteResetRequest {
+  password: string;
+  phone_number: string;
+  reset_token: string;
+}
+
+export interface ConfirmPhoneRequest {
+  code: string;
+  phone_number: string;
+}
+
+export interface CreateErrandRequest {
+  audio?: string[];
+  budget: number;
+  category: string;
+  description?: string;
+  dropoff_location?: ErrandLocation;
+  duration: ErrandDurationRequest;
+  images?: string[];
+  pickup_location: ErrandLocation;
+  promo_code?: string;
+  restriction?: "qualification" | "verification" | "insurance";
+  transaction_pin?: string;
+}
+
+export interface CreatePinRequest {
+  pin: string;
+}
+
+export interface EntityCreatedBy {
+  By?: string;
+  Id?: string;
+}
+
+export interface EntityModifiedBy {
+  Date?: string;
+  Id?: string;
+}
+
+export interface Errand {
+  amount?: number;
+  audio?: string[];
+  bids?: Bid[];
+  budget?: number;
+  cancellation_reason?: string;
+  category?: Category;
+  completed_at?: string | null;
+  created_at?: string;
+  created_by?: EntityCreatedBy;
+  description?: string;
+  dropoff_address?: ErrandAddress;
+  expiry_date?: string;
+  fees?: FeeBreakdown;
+  id?: string;
+  images?: string[];
+  pickup_address?: ErrandAddress;
+  restriction?: string;
+  runner_id?: string;
+  status?: string;
+  step?: number;
+  timeline?: Timeline;
+  total_bids?: number;
+  updated_at?: string;
+  user_id?: string;
+}
+
+export interface ErrandAddress {
+  lat?: number;
+  lng?: number;
+}
+
+export interface ErrandCategoryEarnings {
+  amount?: number;
+  category_id?: string;
+  errands?: number;
+  name?: string;
+}
+
+export interface ErrandDurationRequest {
+  period: "hours" | "days" | "weeks";
+  value: number;
+}
+
+export interface ErrandLocation {
+  lat: number | null;
+  lng: number | null;
+}
+
+export interface ErrandPendingEarnings {
+  awaiting_confirmation?: number;
+  errands?: number;
+  escrowed?: number;
+  total?: number;
+}
+
+export interface ErrandRequest {
+  audio?: string[];
+  budget: number;
+  category: string;
+  description?: string;
+  dropoff_location?: ErrandLocation;
+  duration: ErrandDurationRequest;
+  images?: string[];
+  pickup_location: ErrandLocation;
+  restriction?: "qualification" | "verification" | "insurance";
+}
+
+export interface Error {
+  code: string;
+  message: string;
+  success?: boolean;
+}
+
+export interface FeeBreakdown {
+  amount?: number;
+  budget?: number;
+  commission?: number;
+  commission_waived?: boolean;
+  discount?: number;
+  promo_code?: string;
+  promo_credit?: number;
+  runner_payout?: number;
+  service_fee?: number;
+  service_fee_waived?: boolean;
+  tip?: number;
+  total_charged?: number;
+}
+
+export interface FeeRule {
+  category_id?: string;
+  created_at?: string;
+  created_by?: string;
+  flat?: number;
+  id?: string;
+  kind?: string;
+  minimum?: number;
+  percentage?: number;
+  updated_at?: string;
+}
+
+export interface FeeWaiver {
+  category_id?: string;
+  created_at?: string;
+  created_by?: string;
+  description?: string;
+  ends_at?: string;
+  id?: string;
+  kind?: string;
+  starts_at?: string;
+}
+
+export interface Haggle {
+  amount?: number;
+  created_at?: string;
+  description?: string;
+  id?: string;
+  source?: string;
+}
+
+export interface HaggleRequest {
+  amount: number;
+  description?: string;
+  source: "sender" | "runner";
+}
+
+export interface InitData {
+  categories?: Category[];
+}
+
+export interface Notification {
+  created_at?: string;
+  id?: string;
+  item_id?: string;
+  link?: string;
+  message?: string;
+  title?: string;
+  type?: string;
+  user_id?: string;
+}
+
+export interface PaymentRequest {
+  amount: number;
+  reference?: string;
+  transaction_pin?: string;
+  type: "credit" | "debit";
+}
+
+export interface PhoneRequest {
+  phone_number: string;
+}
+
+export interface PinEvent {
+  created_at?: string;
+  id?: string;
+  reason?: string;
+  type?: string;
+}
+
+export interface PreviewPromoRequest {
+  amount: number;
+  category_id?: string;
+  code: string;
+}
+
+export interface Promo {
+  category_ids?: string[];
+  code?: string;
+  created_at?: string;
+  created_by?: string;
+  discount_type?: string;
+  expires_at?: string;
+  first_errand_only?: boolean;
+  id?: string;
+  is_active?: boolean;
+  max_discount?: number;
+  per_user_limit?: number;
+  updated_at?: string;
+  usage_limit?: number;
+  uses?: number;
+  value?: number;
+}
+
+export interface PromoPreviewData {
+  discount?: number;
+  total?: number;
+}
+
+export interface PromoReferral {
+  created_at?: string;
+  errand_id?: string;
+  id?: string;
+  referee_id?: string;
+  referrer_id?: string;
+  reward?: number;
+  rewarded_at?: string | null;
+  status?: string;
+}
+
+export interface PromoReferralSummary {
+  code?: string;
+  referrals?: PromoReferral[];
+  reward?: number;
+}
+
+export interface RateUserRequest {
+  errand_id: string;
+  rating: number;
+  runner_id: string;
+}
+
+export interface ReconciliationDiscrepancy {
+  created_at?: string;
+  gateway_amount?: number;
+  gateway_count?: number;
+  id?: string;
+  kind?: string;
+  ledger_amount?: number;
+  ledger_count?: number;
+  note?: string;
+  reference?: string;
+  report_id?: string;
+  resolved_at?: string | null;
+  resolved_by?: string;
+  state?: string;
+  transaction_ids?: string[];
+}
+
+export interface ReconciliationReport {
+  created_at?: string;
+  discrepancies?: number;
+  from?: string;
+  gateway_records?: number;
+  id?: string;
+  ledger_records?: number;
+  matched?: number;
+  to?: string;
+}
+
+export interface RecoveryCodesData {
+  recovery_codes?: string[];
+}
+
+export interface RefreshTokenRequest {
+  refresh_token: string;
+}
+
+export interface Refund {
+  amount?: number;
+  compensation?: number;
+  created_at?: string;
+  discount?: number;
+  errand_id?: string;
+  id?: string;
+  issued_by?: string;
+  kind?: string;
+  promo_credit?: number;
+  reason?: string;
+  runner_id?: string;
+  service_fee?: number;
+  transaction_ids?: string[];
+  user_id?: string;
+}
+
+export interface ResetAnswerRequest {
+  phone_number: string;
+  security_ticket: string;
+  ticket: string;
+}
+
+export interface ResetPinRequest {
+  new_pin: string;
+  password: string;
+}
+
+export interface ResetProgress {
+  reset_token?: string;
+  security_questions?: SecurityPrompt[];
+  ticket?: string;
+}
+
+export interface ResetRequest {
+  channel?: string;
+  phone_number: string;
+}
+
+export interface ResponseFieldError {
+  field?: string;
+  message?: string;
+}
+
+export interface Role {
+  built_in?: boolean;
+  created_at?: string;
+  description?: string;
+  name?: string;
+  permissions?: string[];
+  updated_at?: string;
+}
+
+export interface RoleDefinition {
+  description?: string;
+  permissions: string[];
+}
+
+export interface RoleRequest {
+  description?: string;
+  name: string;
+  permissions: string[];
+}
+
+export interface SecurityAnswerRequest {
+  answer: string;
+  question_id: string;
+}
+
+export interface SecurityAnswersRequest {
+  answers: SecurityAnswerRequest[];
+}
+
+export interface SecurityPrompt {
+  question?: string;
+  question_id?: string;
+}
+
+export interface SecurityQuestion {
+  active?: boolean;
+  id?: string;
+  question?: string;
+}
+
+export interface Session {
+  client?: string;
+  created_at?: string;
+  current?: boolean;
+  device_id?: string;
+  expires_at?: string;
+  id?: string;
+  ip?: string;
+  last_seen_at?: string;
+  two_factor?: boolean;
+  user_agent?: string;
+}
+
+export interface StartErrandRequest {
+  bid_id: string;
+  errand_id: string;
+}
+
+export interface TicketData {
+  ticket?: string;
+}
+
+export interface Timeline {
+  created_at?: string;
+  errand_id?: string;
+  id?: string;
+  updated_at?: string;
+  updates?: TimelineUpdate[];
+}
+
+export interface TimelineUpdate {
+  created_at?: string;
+  id?: string;
+  message?: string;
+  source?: string;
+  type?: string;
+}
+
+export interface TimelineUpdateRequest {
+  errand_id: string;
+  message: string;
+}
+
+export interface TipRequest {
+  amount: number;
+  transaction_pin?: string;
+}
+
+export interface TokenServiceJsonWebKey {
+  alg?: string;
+  crv?: string;
+  e?: string;
+  kid?: string;
+  kty?: string;
+  n?: string;
+  use?: string;
+  x?: string;
+}
+
+export interface TokenServiceJwks {
+  keys?: TokenServiceJsonWebKey[];
+}
+
+export interface TwoFactorLoginRequest {
+  challenge: string;
+  code: string;
+}
+
+export interface TwofactorEnrollment {
+  provisioning_uri?: string;
+  recovery_codes?: string[];
+  secret?: string;
+}
+
+export interface TwofactorStepUp {
+  challenge?: string;
+  two_factor_required?: boolean;
+}
+
+export interface User {
+  account_numbers?: UserAccount[];
+  category_interest?: string[];
+  client?: string;
+  created_at?: string;
+  created_by?: string;
+  creation_date?: string;
+  email?: string;
+  errands_cancelled?: number;
+  errands_completed?: number;
+  first_name?: string;
+  id?: string;
+  is_suspended?: boolean;
+  last_name?: string;
+  phone_number?: string;
+  profile_picture?: string;
+  rating?: number;
+  referral_code?: string;
+  refresh_token?: string;
+  roles?: string[];
+  token?: string;
+  token_expires_at?: string | null;
+  updated_at?: string;
+  verification?: number;
+}
+
+export interface UserAccount {
+  bank_code?: string;
+  country_code?: string;
+  name?: string;
+  number?: string;
+  type?: string;
+}
+
+export interface UserAccountDetails {
+  bank_code: string;
+  name: string;
+  number: string;
+  type: string;
+}
+
+export interface UserAdminUser {
+  account?: UserAccountDetails;
+  email?: string;
+  first_name: string;
+  interests?: string[];
+  last_name: string;
+  phone_number: string;
+}
+
+export interface UserAdminUserUpdate {
+  email?: string;
+  first_name: string;
+  last_name: string;
+  phone_number?: string;
+}
+
+export interface UserLogin {
+  device_id?: string;
+  password: string;
+  phone_number: string;
+}
+
+export interface UserSignUp {
+  client: "web" | "android" | "ios";
+  device_id?: string;
+  first_name: string;
+  interests?: string[];
+  last_name: string;
+  password: string;
+  phone_number: string;
+  referral_code?: string;
+  verification_ticket?: string;
+}
+
+export interface ValidationError {
+  code: string;
+  data?: {
+    errors?: ResponseFieldError[];
+  };
+  message: string;
+  success?: boolean;
+}
+
+export interface VerificationTicketData {
+  verification_ticket?: string;
+}
+
+export interface VerifyResetRequest {
+  code: string;
+  phone_number: string;
+}
+
+export interface VerifySecurityRequest {
+  answers: SecurityAnswerRequest[];
+  phone_number: string;
+}
+
+export interface Wallet {
+  balance?: number;
+  escrow?: number;
+  promo_balance?: number;
+  total_transactions?: number;
+  transactions?: WalletTransaction[];
+}
+
+export interface WalletEarnings {
+  commission?: number;
+  compensation?: number;
+  errands?: number;
+  gross?: number;
+  payouts?: number;
+  tips?: number;
+  total?: number;
+}
+
+export interface WalletEarningsDashboard {
+  available?: number;
+  from?: string;
+  lifetime?: WalletEarnings;
+  pending?: ErrandPendingEarnings;
+  period?: string;
+  series?: WalletEarningsPeriod[];
+  summary?: WalletEarnings;
+  to?: string;
+  top_categories?: ErrandCategoryEarnings[];
+}
+
+export interface WalletEarningsPeriod {
+  commission?: number;
+  compensation?: number;
+  errands?: number;
+  gross?: number;
+  payouts?: number;
+  period?: string;
+  tips?: number;
+  total?: number;
+}
+
+export interface WalletPage {
+  limit?: number;
+  page?: number;
+  total?: number;
+  transactions?: WalletTransaction[];
+}
+
+export interface WalletStatement {
+  closing_balance?: number;
+  from?: string;
+  generated_at?: string;
+  opening_balance?: number;
+  promo_credits?: number;
+  promo_debits?: number;
+  to?: string;
+  total_credits?: number;
+  total_debits?: number;
+  transactions?: WalletTransaction[];
+  user_id?: string;
+}
+
+export interface WalletTransaction {
+  amount?: number;
+  category?: string;
+  created_at?: string;
+  description?: string;
+  fee?: number;
+  gross?: number;
+  id?: string;
+  item_id?: string;
+  promotional?: boolean;
+  reference?: string;
+  refund_of?: string;
+  transaction_type?: number;
+  type?: string;
+  user_id?: string;
+}
+
+export interface WebhookAttempt {
+  at?: string;
+  duration_ms?: number;
+  error?: string;
+  response_body?: string;
+  response_code?: number;
+}
+
+export interface WebhookCreated {
+  active?: boolean;
+  created_at?: string;
+  disabled_at?: string | null;
+  events?: string[];
+  failures?: number;
+  id?: string;
+  secret?: string;
+  updated_at?: string;
+  url?: string;
+}
+
+export interface WebhookDelivery {
+  attempts?: WebhookAttempt[];
+  created_at?: string;
+  delivered_at?: string | null;
+  event?: string;
+  event_id?: string;
+  id?: string;
+  next_attempt_at?: string;
+  payload?: string;
+  redelivery_of?: string;
+  status?: string;
+  subscription_id?: string;
+}
+
+export interface WebhookRequest {
+  events: string[];
+  url: string;
+}
+
+export interface WebhookSubscription {
+  active?: boolean;
+  created_at?: string;
+  disabled_at?: string | null;
+  events?: string[];
+  failures?: number;
+  id?: string;
+  updated_at?: string;
+  url?: string;
+}
+
+export interface WebhookUpdate {
+  active?: boolean | null;
+  events?: string[];
+  url?: string | null;
+}
+
+export interface WithdrawalRequest {
+  amount: number;
+  transaction_pin: string;
+}
+
+export class ApiError extends Error {
+  constructor(readonly status: number, readonly code: string, readonly body: unknown) {
+    super(code);
+  }
+}
+
+export interface Envelope<T = never> {
+  success: boolean;
+  message: string;
+  data?: T;
+}
+
+export interface ClientOptions {
+  baseUrl: string;
+  // token is an access token or a partner API key, sent as a bearer token.
+  token?: string | (() => string | Promise<string>);
+  fetch?: typeof fetch;
+}
+
+type Call = {
+  query: Record<string, unknown>;
+  headers: Record<string, string | undefined>;
+  body: unknown;
+  auth: boolean;
+};
+
+export class Client {
+  constructor(private readonly options: ClientOptions) {}
+
+  private async request<T>(method: string, path: string, call: Call): Promise<T> {
+    const url = new URL(this.options.baseUrl.replace(/\/$/, "") + path);
+    for (const [name, value] of Object.entries(call.query)) {
+      if (value !== undefined && value !== null) url.searchParams.set(name, String(value));
+    }
+    const headers: Record<string, string> = {};
+    for (const [name, value] of Object.entries(call.headers)) {
+      if (value !== undefined) headers[name] = value;
+    }
+    if (call.auth && this.options.token) {
+      const token = typeof this.options.token === "function" ? await this.options.token() : this.options.token;
+      headers["Authorization"] = "Bearer " + token;
+    }
+    let body: BodyInit | undefined;
+    if (call.body instanceof FormData) {
+      body = call.body;
+    } else if (call.body !== undefined) {
+      headers["Content-Type"] = "application/json";
+      body = JSON.stringify(call.body);
+    }
+
+    const response = await (this.options.fetch ?? fetch)(url.toString(), { method, headers, body });
+    const type = response.headers.get("Content-Type") ?? "";
+    let value: any;
+    if (type.includes("json")) {
+      value = await response.json();
+    } else if (type.startsWith("text/")) {
+      value = await response.text();
+    } else {
+      value = await response.blob();
+    }
+    if (!response.ok) {
+      throw new ApiError(response.status, value?.code ?? "http_" + response.status, value);
+    }
+    return value as T;
+  }
+
+  /** Public keys that verify access tokens */
+  getSigningKeys(request: {} = {}): Promise<TokenServiceJwks> {
+    return this.request("GET", `/.well-known/jwks.json`, { query: {}, headers: {}, body: undefined, auth: false });
+  }
+
+  /** Every category */
+  adminGetCategories(request: { limit?: number } = {}): Promise<Envelope<Category[]>> {
+    return this.request("GET", `/v1/admin/category`, { query: { limit: request.limit }, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Create a category */
+  adminCreateCategory(request: { body: FormData }): Promise<Envelope> {
+    return this.request("POST", `/v1/admin/category`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Rename a category */
+  adminUpdateCategory(request: { id: string; body: AdminCategoryRequest }): Promise<Envelope> {
+    return this.request("PUT", `/v1/admin/category/${encodeURIComponent(request.id)}`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Delete a category */
+  adminDeleteCategory(request: { id: string }): Promise<Envelope> {
+    return this.request("DELETE", `/v1/admin/category/${encodeURIComponent(request.id)}`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Create an errand for a user */
+  adminCreateErrand(request: { body: AdminErrandRequest }): Promise<Envelope> {
+    return this.request("POST", `/v1/admin/errand`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** An errand */
+  adminGetErrand(request: { id: string }): Promise<Envelope<Errand>> {
+    return this.request("GET", `/v1/admin/errand/${encodeURIComponent(request.id)}`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Assign an errand to a runner */
+  adminAssignErrand(request: { id: string; body: AdminAssignErrandRequest }): Promise<Envelope> {
+    return this.request("PUT", `/v1/admin/errand/${encodeURIComponent(request.id)}/assign`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Every errand */
+  adminGetErrands(request: { type?: "open" | "cancelled" | "active" | "draft" } = {}): Promise<Envelope<Errand[]>> {
+    return this.request("GET", `/v1/admin/errands`, { query: { type: request.type }, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Fee rules and the defaults used without one */
+  adminGetFeeRules(request: {} = {}): Promise<Envelope<AdminFeeRulesData>> {
+    return this.request("GET", `/v1/admin/fees`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Create or replace the fee rule of a kind and category */
+  adminSaveFeeRule(request: { body: AdminFeeRuleRequest }): Promise<Envelope<FeeRule>> {
+    return this.request("PUT", `/v1/admin/fees`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Fees collected by the platform */
+  adminGetRevenue(request: {} = {}): Promise<Envelope<number>> {
+    return this.request("GET", `/v1/admin/fees/revenue`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Fee waivers */
+  adminGetFeeWaivers(request: {} = {}): Promise<Envelope<FeeWaiver[]>> {
+    return this.request("GET", `/v1/admin/fees/waivers`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Waive a fee for a period */
+  adminCreateFeeWaiver(request: { body: AdminFeeWaiverRequest }): Promise<Envelope<FeeWaiver>> {
+    return this.request("POST", `/v1/admin/fees/waivers`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Delete a fee waiver */
+  adminDeleteFeeWaiver(request: { id: string }): Promise<Envelope> {
+    return this.request("DELETE", `/v1/admin/fees/waivers/${encodeURIComponent(request.id)}`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Delete a fee rule */
+  adminDeleteFeeRule(request: { id: string }): Promise<Envelope> {
+    return this.request("DELETE", `/v1/admin/fees/${encodeURIComponent(request.id)}`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Permissions roles can grant */
+  adminGetPermissions(request: {} = {}): Promise<Envelope<string[]>> {
+    return this.request("GET", `/v1/admin/permissions`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Every promo code */
+  adminGetPromos(request: {} = {}): Promise<Envelope<Promo[]>> {
+    return this.request("GET", `/v1/admin/promos`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Create a promo code */
+  adminCreatePromo(request: { body: AdminPromoRequest }): Promise<Envelope<Promo>> {
+    return this.request("POST", `/v1/admin/promos`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Credit a user's wallet */
+  adminGrantPromoCredit(request: { body: AdminPromoCreditRequest }): Promise<Envelope> {
+    return this.request("POST", `/v1/admin/promos/credits`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Deactivate a promo code */
+  adminDeactivatePromo(request: { id: string }): Promise<Envelope> {
+    return this.request("DELETE", `/v1/admin/promos/${encodeURIComponent(request.id)}`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Reconcile the ledger, the previous day by default */
+  adminRunReconciliation(request: { from?: string; to?: string } = {}): Promise<Envelope<ReconciliationReport>> {
+    return this.request("POST", `/v1/admin/reconciliation`, { query: { from: request.from, to: request.to }, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Discrepancies found by reconciliation */
+  adminGetDiscrepancies(request: { state?: "open" | "resolved" } = {}): Promise<Envelope<ReconciliationDiscrepancy[]>> {
+    return this.request("GET", `/v1/admin/reconciliation/discrepancies`, { query: { state: request.state }, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Resolve a discrepancy */
+  adminResolveDiscrepancy(request: { id: string; body: AdminResolveDiscrepancyRequest }): Promise<Envelope> {
+    return this.request("PUT", `/v1/admin/reconciliation/discrepancies/${encodeURIComponent(request.id)}/resolve`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Reconciliation reports */
+  adminGetReconciliationReports(request: {} = {}): Promise<Envelope<ReconciliationReport[]>> {
+    return this.request("GET", `/v1/admin/reconciliation/reports`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Refunds of a user or errand */
+  adminGetRefunds(request: { user_id?: string; errand_id?: string } = {}): Promise<Envelope<Refund[]>> {
+    return this.request("GET", `/v1/admin/refunds`, { query: { user_id: request.user_id, errand_id: request.errand_id }, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Refund a transaction */
+  adminIssueRefund(request: { body: AdminRefundRequest }): Promise<Envelope<Refund>> {
+    return this.request("POST", `/v1/admin/refunds`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Every role */
+  adminGetRoles(request: {} = {}): Promise<Envelope<Role[]>> {
+    return this.request("GET", `/v1/admin/roles`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Create a role */
+  adminCreateRole(request: { body: RoleRequest }): Promise<Envelope<Role>> {
+    return this.request("POST", `/v1/admin/roles`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Change the permissions of a role */
+  adminUpdateRole(request: { name: string; body: RoleDefinition }): Promise<Envelope<Role>> {
+    return this.request("PUT", `/v1/admin/roles/${encodeURIComponent(request.name)}`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Delete a role */
+  adminDeleteRole(request: { name: string }): Promise<Envelope> {
+    return this.request("DELETE", `/v1/admin/roles/${encodeURIComponent(request.name)}`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Every security question */
+  adminGetSecurityQuestions(request: {} = {}): Promise<Envelope<SecurityQuestion[]>> {
+    return this.request("GET", `/v1/admin/security-questions`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Add a security question */
+  adminCreateSecurityQuestion(request: { body: AdminQuestionRequest }): Promise<Envelope<SecurityQuestion>> {
+    return this.request("POST", `/v1/admin/security-questions`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Deactivate a security question */
+  adminDeactivateSecurityQuestion(request: { id: string }): Promise<Envelope> {
+    return this.request("DELETE", `/v1/admin/security-questions/${encodeURIComponent(request.id)}`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Create a user */
+  adminCreateUser(request: { body: UserAdminUser }): Promise<Envelope> {
+    return this.request("POST", `/v1/admin/user`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Suspend users */
+  adminSuspendUsers(request: { body: AdminUsersRequest }): Promise<Envelope> {
+    return this.request("PUT", `/v1/admin/user`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Update a user */
+  adminUpdateUser(request: { body: UserAdminUserUpdate }): Promise<Envelope> {
+    return this.request("PATCH", `/v1/admin/user`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Delete users */
+  adminDeleteUsers(request: { body: AdminUsersRequest }): Promise<Envelope> {
+    return this.request("DELETE", `/v1/admin/user`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Restore a suspended or deleted user */
+  adminRestoreUser(request: { id: string }): Promise<Envelope> {
+    return this.request("PATCH", `/v1/admin/user/restore/${encodeURIComponent(request.id)}`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Suspend a user */
+  adminSuspendUser(request: { id: string }): Promise<Envelope> {
+    return this.request("PUT", `/v1/admin/user/suspend/${encodeURIComponent(request.id)}`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Unlock the transaction pin of a user */
+  adminUnlockUser(request: { id: string }): Promise<Envelope> {
+    return this.request("PUT", `/v1/admin/user/unlock/${encodeURIComponent(request.id)}`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** A user */
+  adminGetUser(request: { id: string }): Promise<Envelope<User>> {
+    return this.request("GET", `/v1/admin/user/${encodeURIComponent(request.id)}`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Delete a user */
+  adminDeleteUser(request: { id: string }): Promise<Envelope> {
+    return this.request("DELETE", `/v1/admin/user/${encodeURIComponent(request.id)}`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Replace the roles of a user */
+  adminAssignRoles(request: { id: string; body: AdminAssignRolesRequest }): Promise<Envelope> {
+    return this.request("PUT", `/v1/admin/user/${encodeURIComponent(request.id)}/roles`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Statement of a user's wallet */
+  adminGetUserStatement(request: { id: string; format?: "json" | "csv" | "pdf"; from?: string; to?: string }): Promise<Envelope<WalletStatement> | Blob | string> {
+    return this.request("GET", `/v1/admin/user/${encodeURIComponent(request.id)}/wallet/statement`, { query: { format: request.format, from: request.from, to: request.to }, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Page of a user's wallet transactions */
+  adminGetUserTransactions(request: { id: string; page?: number; limit?: number; type?: "credit" | "debit"; category?: string; errand_id?: string; from?: string; to?: string }): Promise<Envelope<WalletPage>> {
+    return this.request("GET", `/v1/admin/user/${encodeURIComponent(request.id)}/wallet/transactions`, { query: { page: request.page, limit: request.limit, type: request.type, category: request.category, errand_id: request.errand_id, from: request.from, to: request.to }, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Every user */
+  adminGetUsers(request: { type?: "suspended" | "deleted" } = {}): Promise<Envelope<User[]>> {
+    return this.request("GET", `/v1/admin/users`, { query: { type: request.type }, headers: {}, body: undefined, auth: true });
+  }
+
+  /** API reference rendered from this document */
+  getApiReference(request: {} = {}): Promise<string> {
+    return this.request("GET", `/v1/docs`, { query: {}, headers: {}, body: undefined, auth: false });
+  }
+
+  /** Categories errands can be created in */
+  getCategories(request: { limit?: number } = {}): Promise<Envelope<Category[]>> {
+    return this.request("GET", `/v1/errand/categories`, { query: { limit: request.limit }, headers: {}, body: undefined, auth: true });
+  }
+
+  /** The draft errand of the user, created when there is none */
+  getDraftErrand(request: {} = {}): Promise<Envelope<Errand>> {
+    return this.request("GET", `/v1/errand/draft`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Upload up to 3 images or audio files, returns their urls */
+  uploadErrandFiles(request: { body: FormData }): Promise<Envelope<string[]>> {
+    return this.request("POST", `/v1/errand/files`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Open errands runners can bid for */
+  getMarketErrands(request: {} = {}): Promise<Envelope<Errand[]>> {
+    return this.request("GET", `/v1/errand/market`, { query: {}, headers: {}, body: undefined, auth: false });
+  }
+
+  /** Start an errand as its runner */
+  startErrand(request: { body: StartErrandRequest }): Promise<Envelope> {
+    return this.request("POST", `/v1/errand/start`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Post an update to the timeline of an errand */
+  postErrandUpdate(request: { body: TimelineUpdateRequest }): Promise<Envelope> {
+    return this.request("POST", `/v1/errand/timeline`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Ask the runner for an update */
+  requestErrandUpdate(request: { id: string }): Promise<Envelope> {
+    return this.request("GET", `/v1/errand/timeline/request/${encodeURIComponent(request.id)}`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** An errand */
+  getErrand(request: { id: string }): Promise<Envelope<Errand>> {
+    return this.request("GET", `/v1/errand/${encodeURIComponent(request.id)}`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Publish a draft errand and pay for it */
+  createErrand(request: { id: string; idempotencyKey?: string; body: CreateErrandRequest }): Promise<Envelope<Errand>> {
+    return this.request("POST", `/v1/errand/${encodeURIComponent(request.id)}`, { query: {}, headers: { "Idempotency-Key": request.idempotencyKey }, body: request.body, auth: true });
+  }
+
+  /** Save the details of a draft errand */
+  updateErrand(request: { id: string; body: ErrandRequest }): Promise<Envelope<Errand>> {
+    return this.request("PATCH", `/v1/errand/${encodeURIComponent(request.id)}`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Bid for an errand */
+  bidForErrand(request: { id: string; body: HaggleRequest }): Promise<Envelope> {
+    return this.request("POST", `/v1/errand/${encodeURIComponent(request.id)}/bid`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Counter a bid */
+  updateBid(request: { id: string; bid_id: string; body: HaggleRequest }): Promise<Envelope> {
+    return this.request("PUT", `/v1/errand/${encodeURIComponent(request.id)}/bid/${encodeURIComponent(request.bid_id)}`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Accept or reject a bid */
+  respondToBid(request: { id: string; bid_id: string; body: BidResponseRequest }): Promise<Envelope> {
+    return this.request("PUT", `/v1/errand/${encodeURIComponent(request.id)}/bid/${encodeURIComponent(request.bid_id)}/respond`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Turn down an errand awarded to you */
+  rejectErrandContract(request: { id: string; bid_id: string }): Promise<Envelope> {
+    return this.request("DELETE", `/v1/errand/${encodeURIComponent(request.id)}/bid/${encodeURIComponent(request.bid_id)}/respond`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Cancel an errand */
+  cancelErrand(request: { id: string; body: CancelErrandRequest }): Promise<Envelope> {
+    return this.request("DELETE", `/v1/errand/${encodeURIComponent(request.id)}/cancel`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Mark an errand as completed */
+  completeErrand(request: { id: string; body: CompleteErrandRequest }): Promise<Envelope> {
+    return this.request("PATCH", `/v1/errand/${encodeURIComponent(request.id)}/complete`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Tip the runner of an errand */
+  tipRunner(request: { id: string; idempotencyKey?: string; body: TipRequest }): Promise<Envelope> {
+    return this.request("POST", `/v1/errand/${encodeURIComponent(request.id)}/tip`, { query: {}, headers: { "Idempotency-Key": request.idempotencyKey }, body: request.body, auth: true });
+  }
+
+  /** Status of each dependency, 503 when one is down */
+  checkHealth(request: {} = {}): Promise<Envelope<Record<string, string>>> {
+    return this.request("GET", `/v1/health`, { query: {}, headers: {}, body: undefined, auth: false });
+  }
+
+  /** Data the apps load on start */
+  getInitData(request: {} = {}): Promise<Envelope<InitData>> {
+    return this.request("GET", `/v1/init`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** This document */
+  getOpenApi(request: {} = {}): Promise<Record<string, unknown>> {
+    return this.request("GET", `/v1/openapi.json`, { query: {}, headers: {}, body: undefined, auth: false });
+  }
+
+  /** Events sent by Paystack */
+  receivePaystackEvent(request: {} = {}): Promise<Envelope> {
+    return this.request("POST", `/v1/paystack/webhook`, { query: {}, headers: {}, body: undefined, auth: false });
+  }
+
+  /** Questions the owner of a phone number has to answer */
+  getSecurityChallenge(request: { phone_number?: string } = {}): Promise<Envelope<SecurityPrompt[]>> {
+    return this.request("GET", `/v1/security-question`, { query: { phone_number: request.phone_number }, headers: {}, body: undefined, auth: false });
+  }
+
+  /** Set the answers to security questions */
+  saveSecurityAnswers(request: { body: SecurityAnswersRequest }): Promise<Envelope> {
+    return this.request("POST", `/v1/security-question`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Answer the questions for a ticket */
+  verifySecurityAnswers(request: { body: VerifySecurityRequest }): Promise<Envelope<TicketData>> {
+    return this.request("POST", `/v1/security-question/verify`, { query: {}, headers: {}, body: request.body, auth: false });
+  }
+
+  /** Questions a user can answer */
+  getSecurityQuestions(request: {} = {}): Promise<Envelope<SecurityQuestion[]>> {
+    return this.request("GET", `/v1/security-questions`, { query: {}, headers: {}, body: undefined, auth: false });
+  }
+
+  /** Credit or debit the wallet */
+  makePayment(request: { idempotencyKey?: string; body: PaymentRequest }): Promise<Envelope> {
+    return this.request("POST", `/v1/transact`, { query: {}, headers: { "Idempotency-Key": request.idempotencyKey }, body: request.body, auth: true });
+  }
+
+  /** Start setting up an authenticator app */
+  enrollTwoFactor(request: {} = {}): Promise<Envelope<TwofactorEnrollment>> {
+    return this.request("POST", `/v1/user/2fa`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Disable two-factor authentication */
+  disableTwoFactor(request: { body: AuthenticationCodeRequest }): Promise<Envelope> {
+    return this.request("DELETE", `/v1/user/2fa`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Enable two-factor authentication with a first code */
+  confirmTwoFactor(request: { body: AuthenticationCodeRequest }): Promise<Envelope> {
+    return this.request("POST", `/v1/user/2fa/confirm`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Replace every recovery code */
+  regenerateRecoveryCodes(request: { body: AuthenticationCodeRequest }): Promise<Envelope<RecoveryCodesData>> {
+    return this.request("POST", `/v1/user/2fa/recovery-codes`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Add the bank account withdrawals are paid to */
+  addBankAccount(request: { body: BankAccountRequest }): Promise<Envelope<UserAccount>> {
+    return this.request("POST", `/v1/user/account`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** API keys of the user */
+  getApiKeys(request: {} = {}): Promise<Envelope<ApiKey[]>> {
+    return this.request("GET", `/v1/user/api-keys`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Create an API key, the key is only returned here */
+  createApiKey(request: { body: ApikeyRequest }): Promise<Envelope<ApikeyCreated>> {
+    return this.request("POST", `/v1/user/api-keys`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Revoke an API key */
+  revokeApiKey(request: { id: string }): Promise<Envelope> {
+    return this.request("DELETE", `/v1/user/api-keys/${encodeURIComponent(request.id)}`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Errands of the user */
+  getMyErrands(request: {} = {}): Promise<Envelope<Errand[]>> {
+    return this.request("GET", `/v1/user/errands`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Notifications of the user */
+  getNotifications(request: {} = {}): Promise<Envelope<Notification[]>> {
+    return this.request("GET", `/v1/user/notifications`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Change the password */
+  changePassword(request: { body: ChangePasswordRequest }): Promise<Envelope> {
+    return this.request("PUT", `/v1/user/password`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Send a reset code */
+  requestPasswordReset(request: { body: ResetRequest }): Promise<Envelope> {
+    return this.request("POST", `/v1/user/password/reset`, { query: {}, headers: {}, body: request.body, auth: false });
+  }
+
+  /** Set the new password */
+  completePasswordReset(request: { body: CompleteResetRequest }): Promise<Envelope> {
+    return this.request("POST", `/v1/user/password/reset/complete`, { query: {}, headers: {}, body: request.body, auth: false });
+  }
+
+  /** Answer a security question of the reset */
+  answerPasswordResetQuestion(request: { body: ResetAnswerRequest }): Promise<Envelope<ResetProgress>> {
+    return this.request("POST", `/v1/user/password/reset/security`, { query: {}, headers: {}, body: request.body, auth: false });
+  }
+
+  /** Verify the reset code */
+  verifyPasswordReset(request: { body: VerifyResetRequest }): Promise<Envelope<ResetProgress>> {
+    return this.request("POST", `/v1/user/password/reset/verify`, { query: {}, headers: {}, body: request.body, auth: false });
+  }
+
+  /** Set the transaction pin */
+  createPin(request: { body: CreatePinRequest }): Promise<Envelope> {
+    return this.request("POST", `/v1/user/pin`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Change the transaction pin */
+  changePin(request: { body: ChangePinRequest }): Promise<Envelope> {
+    return this.request("PUT", `/v1/user/pin`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Changes and failed attempts of the transaction pin */
+  getPinEvents(request: {} = {}): Promise<Envelope<PinEvent[]>> {
+    return this.request("GET", `/v1/user/pin/events`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Reset the transaction pin with the password */
+  resetPin(request: { body: ResetPinRequest }): Promise<Envelope> {
+    return this.request("POST", `/v1/user/pin/reset`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** The signed in user */
+  getProfile(request: {} = {}): Promise<Envelope<User>> {
+    return this.request("GET", `/v1/user/profile`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Discount a promo code gives on an amount */
+  previewPromo(request: { body: PreviewPromoRequest }): Promise<Envelope<PromoPreviewData>> {
+    return this.request("POST", `/v1/user/promo/preview`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Rate the other user of an errand */
+  rateUser(request: { body: RateUserRequest }): Promise<Envelope> {
+    return this.request("POST", `/v1/user/rate`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Users the user referred */
+  getReferrals(request: {} = {}): Promise<Envelope<PromoReferralSummary>> {
+    return this.request("GET", `/v1/user/referrals`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Devices the user is signed in on */
+  getSessions(request: {} = {}): Promise<Envelope<Session[]>> {
+    return this.request("GET", `/v1/user/sessions`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Sign out everywhere */
+  revokeSessions(request: { except_current?: boolean } = {}): Promise<Envelope> {
+    return this.request("DELETE", `/v1/user/sessions`, { query: { except_current: request.except_current }, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Sign out a device */
+  revokeSession(request: { id: string }): Promise<Envelope> {
+    return this.request("DELETE", `/v1/user/sessions/${encodeURIComponent(request.id)}`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Sign in, a two-factor challenge is returned instead when it is enabled */
+  signIn(request: { body: UserLogin }): Promise<Envelope<User | TwofactorStepUp>> {
+    return this.request("POST", `/v1/user/sign-in`, { query: {}, headers: {}, body: request.body, auth: false });
+  }
+
+  /** Finish a sign in with an authentication or recovery code */
+  signInWithTwoFactor(request: { body: TwoFactorLoginRequest }): Promise<Envelope<User>> {
+    return this.request("POST", `/v1/user/sign-in/2fa`, { query: {}, headers: {}, body: request.body, auth: false });
+  }
+
+  /** End the session */
+  signOut(request: {} = {}): Promise<Envelope> {
+    return this.request("POST", `/v1/user/sign-out`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Create an account */
+  signUp(request: { body: UserSignUp }): Promise<Envelope<User>> {
+    return this.request("POST", `/v1/user/sign-up`, { query: {}, headers: {}, body: request.body, auth: false });
+  }
+
+  /** Suspend your own account */
+  suspendAccount(request: {} = {}): Promise<Envelope> {
+    return this.request("POST", `/v1/user/suspend`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Rotate the token pair */
+  refreshToken(request: { body: RefreshTokenRequest }): Promise<Envelope<AuthToken>> {
+    return this.request("POST", `/v1/user/token/refresh`, { query: {}, headers: {}, body: request.body, auth: false });
+  }
+
+  /** Send a verification code to a phone number */
+  verifyPhoneNumber(request: { body: PhoneRequest }): Promise<Envelope> {
+    return this.request("POST", `/v1/user/verify-phone`, { query: {}, headers: {}, body: request.body, auth: false });
+  }
+
+  /** Exchange the code for the ticket sign up needs */
+  confirmPhoneNumber(request: { body: ConfirmPhoneRequest }): Promise<Envelope<VerificationTicketData>> {
+    return this.request("POST", `/v1/user/verify-phone/confirm`, { query: {}, headers: {}, body: request.body, auth: false });
+  }
+
+  /** Wallet of the user with its latest transactions */
+  getWallet(request: {} = {}): Promise<Envelope<Wallet>> {
+    return this.request("GET", `/v1/user/wallet`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Earnings of a runner by period */
+  getEarnings(request: { period?: "day" | "week" | "month"; from?: string; to?: string } = {}): Promise<Envelope<WalletEarningsDashboard>> {
+    return this.request("GET", `/v1/user/wallet/earnings`, { query: { period: request.period, from: request.from, to: request.to }, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Statement of the wallet, as JSON or a CSV or PDF download */
+  getStatement(request: { format?: "json" | "csv" | "pdf"; from?: string; to?: string } = {}): Promise<Envelope<WalletStatement> | Blob | string> {
+    return this.request("GET", `/v1/user/wallet/statement`, { query: { format: request.format, from: request.from, to: request.to }, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Page of wallet transactions */
+  getTransactions(request: { page?: number; limit?: number; type?: "credit" | "debit"; category?: string; errand_id?: string; from?: string; to?: string } = {}): Promise<Envelope<WalletPage>> {
+    return this.request("GET", `/v1/user/wallet/transactions`, { query: { page: request.page, limit: request.limit, type: request.type, category: request.category, errand_id: request.errand_id, from: request.from, to: request.to }, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Withdraw to the bank account */
+  withdraw(request: { idempotencyKey?: string; body: WithdrawalRequest }): Promise<Envelope> {
+    return this.request("POST", `/v1/user/wallet/withdraw`, { query: {}, headers: { "Idempotency-Key": request.idempotencyKey }, body: request.body, auth: true });
+  }
+
+  /** Webhooks of the user */
+  getWebhooks(request: {} = {}): Promise<Envelope<WebhookSubscription[]>> {
+    return this.request("GET", `/v1/user/webhooks`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Create a webhook, the signing secret is only returned here */
+  createWebhook(request: { body: WebhookRequest }): Promise<Envelope<WebhookCreated>> {
+    return this.request("POST", `/v1/user/webhooks`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Change the url, events or state of a webhook */
+  updateWebhook(request: { id: string; body: WebhookUpdate }): Promise<Envelope<WebhookSubscription>> {
+    return this.request("PUT", `/v1/user/webhooks/${encodeURIComponent(request.id)}`, { query: {}, headers: {}, body: request.body, auth: true });
+  }
+
+  /** Delete a webhook */
+  deleteWebhook(request: { id: string }): Promise<Envelope> {
+    return this.request("DELETE", `/v1/user/webhooks/${encodeURIComponent(request.id)}`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Latest deliveries of a webhook */
+  getWebhookDeliveries(request: { id: string }): Promise<Envelope<WebhookDelivery[]>> {
+    return this.request("GET", `/v1/user/webhooks/${encodeURIComponent(request.id)}/deliveries`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Send the event of a delivery again */
+  redeliverWebhook(request: { id: string; delivery_id: string }): Promise<Envelope<WebhookDelivery>> {
+    return this.request("POST", `/v1/user/webhooks/${encodeURIComponent(request.id)}/deliveries/${encodeURIComponent(request.delivery_id)}/redeliver`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+
+  /** Another user */
+  getUser(request: { id: string }): Promise<Envelope<User>> {
+    return this.request("GET", `/v1/user/${encodeURIComponent(request.id)}`, { query: {}, headers: {}, body: undefined, auth: true });
+  }
+}
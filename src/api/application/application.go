@@ -8,6 +8,7 @@ import (
 	ginzap "github.com/gin-contrib/zap"
 	"github.com/gin-gonic/gin"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -17,6 +18,9 @@ var (
 
 func init() {
 	router = gin.New()
+	if err := trustProxies(router); err != nil {
+		panic(err)
+	}
 	zapLogger := logger.GetLogger()
 
 	config := cors.DefaultConfig()
@@ -29,10 +33,25 @@ func init() {
 	router.MaxMultipartMemory = 8 << 20
 }
 
+// trustProxies makes ClientIP, which the rate limits and sessions are keyed on, read X-Forwarded-For only
+// from the proxies in TRUSTED_PROXIES, any other peer is keyed on its own address. TRUSTED_PLATFORM names
+// the header a platform such as Cloudflare (CF-Connecting-IP) sets, it is only safe behind that platform.
+func trustProxies(engine *gin.Engine) error {
+	engine.TrustedPlatform = os.Getenv("TRUSTED_PLATFORM")
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return engine.SetTrustedProxies(proxies)
+}
+
 func StartApplication() {
 	initializeRepositories()
 	setUpRepositoriesAndManagers()
 	mapRoutes()
+	startJobs()
 	//Add custom recovery
 	router.Use(gin.CustomRecovery(middleWare.Recovery()))
 	port := os.Getenv("PORT")
@@ -0,0 +1,69 @@
+package application
+
+import (
+	"DX/src/api/middleware"
+	"DX/src/domain/entity/limiter"
+	limiterUseCase "DX/src/domain/usecase/limiter"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeLimiterUseCase records the key each request is counted under.
+type fakeLimiterUseCase struct {
+	limiterUseCase.UseCase
+	keys []string
+}
+
+func (f *fakeLimiterUseCase) Hit(_ limiter.Rule, key string) error {
+	f.keys = append(f.keys, key)
+	return nil
+}
+
+func TestRateLimitKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		proxies string
+		peer    string
+		want    string
+	}{
+		{name: "no trusted proxies", peer: "203.0.113.7:4000", want: "203.0.113.7"},
+		{name: "untrusted peer", proxies: "10.0.0.0/8", peer: "203.0.113.7:4000", want: "203.0.113.7"},
+		{name: "trusted proxy", proxies: "10.0.0.0/8", peer: "10.1.2.3:4000", want: "198.51.100.9"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("TRUSTED_PROXIES", test.proxies)
+			t.Setenv("TRUSTED_PLATFORM", "")
+			engine := gin.New()
+			if err := trustProxies(engine); err != nil {
+				t.Fatal(err)
+			}
+			limiterUseCase := &fakeLimiterUseCase{}
+			nMiddleware := middleware.NewErrandMiddleware(nil, nil, nil, limiterUseCase, nil, nil, nil)
+			engine.GET("/", nMiddleware.RateLimit(limiter.Rule{}), func(ctx *gin.Context) {
+				ctx.Status(http.StatusNoContent)
+			})
+
+			// Every request claims another address, only a trusted proxy is believed
+			for _, forwarded := range []string{"198.51.100.9", "192.0.2.1, 198.51.100.9"} {
+				request := httptest.NewRequest(http.MethodGet, "/", nil)
+				request.RemoteAddr = test.peer
+				request.Header.Set("X-Forwarded-For", forwarded)
+				request.Header.Set("X-Real-IP", "192.0.2.2")
+				engine.ServeHTTP(httptest.NewRecorder(), request)
+			}
+
+			if len(limiterUseCase.keys) != 2 {
+				t.Fatalf("%d requests limited, want 2", len(limiterUseCase.keys))
+			}
+			for _, key := range limiterUseCase.keys {
+				if key != test.want {
+					t.Fatalf("limited under %v, want %s", limiterUseCase.keys, test.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package application
+
+import (
+	"DX/src/domain/usecase/reconciliation"
+	"DX/src/utils/logger"
+	"fmt"
+	"time"
+)
+
+// reconciliationHour is the UTC hour the previous day's ledger is reconciled with the gateway.
+const reconciliationHour = 1
+
+// webhookInterval is how often the worker looks for webhook deliveries that are due.
+const webhookInterval = 10 * time.Second
+
+// payoutInterval is how often the payouts of completed errands whose runner was not paid are retried.
+const payoutInterval = 5 * time.Minute
+
+func startJobs() {
+	go runDaily(reconciliationHour, func() {
+		reconcilePreviousDay(reconciliationUseCase)
+	})
+	go runEvery(webhookInterval, webhookUseCase.DeliverDue)
+	go runEvery(payoutInterval, errandUseCase.PayPendingRunners)
+}
+
+func runEvery(interval time.Duration, job func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		job()
+	}
+}
+
+func runDaily(hour int, job func()) {
+	for {
+		now := time.Now().UTC()
+		next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, time.UTC)
+		if !next.After(now) {
+			next = next.Add(24 * time.Hour)
+		}
+		time.Sleep(next.Sub(now))
+		job()
+	}
+}
+
+func reconcilePreviousDay(useCase reconciliation.UseCase) {
+	end := time.Now().UTC().Truncate(24 * time.Hour)
+	report, err := useCase.Reconcile(end.Add(-24*time.Hour), end.Add(-time.Nanosecond))
+	if err != nil {
+		logger.Error("Reconciliation::", err)
+		return
+	}
+	logger.Info(fmt.Sprintf("Reconciliation:: %d matched, %d discrepancies", report.Matched, report.Discrepancies))
+}
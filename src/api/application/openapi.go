@@ -0,0 +1,22 @@
+package application
+
+import (
+	"DX/src/api/handler"
+	"DX/src/api/handler/admin"
+	"DX/src/pkg/openapi"
+)
+
+//go:generate go run DX/src/cmd/openapi -spec ../../../sdk/openapi.json -typescript ../../../sdk/typescript/client.ts
+
+// Spec describes every route mapRoutes registers, a test keeps the two in sync.
+func Spec() *openapi.Document {
+	var routes []openapi.Route
+	routes = append(routes, handler.Routes...)
+	routes = append(routes, admin.Routes...)
+	return openapi.New(openapi.Info{
+		Title: "DX API",
+		Description: "Errands marketplace API. Responses are wrapped in success, message and data fields, " +
+			"failed requests carry a stable code.",
+		Version: "1.0.0",
+	}, routes...)
+}
@@ -0,0 +1,179 @@
+package application
+
+import (
+	"DX/src/api/handler"
+	"DX/src/api/handler/admin"
+	"DX/src/api/middleware"
+	"DX/src/pkg/openapi"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestSpecMatchesRoutes registers the routes with handlers that are never called and compares them with
+// the spec, so a route added to one and not the other fails here.
+func TestSpecMatchesRoutes(t *testing.T) {
+	authenticationHandler = handler.NewAuthHandler(nil, nil)
+	securityHandler = handler.NewSecurityHandler(nil)
+	errandHandler = handler.NewErrandHandler(nil, nil)
+	initHandler = handler.NewInitHandler(nil)
+	walletHandler = handler.NewWalletHandler(nil, nil)
+	pinHandler = handler.NewPinHandler(nil)
+	promoHandler = handler.NewPromoHandler(nil)
+	sessionHandler = handler.NewSessionHandler(nil)
+	passwordResetHandler = handler.NewPasswordResetHandler(nil)
+	twoFactorHandler = handler.NewTwoFactorHandler(nil)
+	apiKeyHandler = handler.NewApiKeyHandler(nil)
+	webhookHandler = handler.NewWebhookHandler(nil)
+	categoryHandler = admin.NewAdminCategoryHandler(nil)
+	userAdminHandler = admin.NewAdminUserHandler(nil)
+	errandAdminHandler = admin.NewAdminErrandHandler(nil, nil)
+	feeAdminHandler = admin.NewAdminFeeHandler(nil)
+	walletAdminHandler = admin.NewAdminWalletHandler(nil, nil)
+	reconciliationHandler = admin.NewAdminReconciliationHandler(nil)
+	refundAdminHandler = admin.NewAdminRefundHandler(nil)
+	promoAdminHandler = admin.NewAdminPromoHandler(nil)
+	securityQuestionAdminHandler = admin.NewAdminSecurityQuestionHandler(nil)
+	roleAdminHandler = admin.NewAdminRoleHandler(nil)
+	healthHandler = handler.NewHealthHandler(nil)
+	jwksHandler = handler.NewJwksHandler(nil)
+	docsHandler = handler.NewDocsHandler(Spec())
+	middleWare = middleware.NewErrandMiddleware(nil, nil, nil, nil, nil, nil, nil)
+	mapRoutes()
+
+	registered := map[string]bool{}
+	for _, route := range router.Routes() {
+		registered[route.Method+" "+openapi.Path(route.Path)] = true
+	}
+	documented := map[string]bool{}
+	for _, operation := range Spec().Operations() {
+		documented[operation] = true
+	}
+
+	for _, operation := range difference(registered, documented) {
+		t.Errorf("%s is registered but missing from the spec", operation)
+	}
+	for _, operation := range difference(documented, registered) {
+		t.Errorf("%s is in the spec but not registered", operation)
+	}
+}
+
+// TestSdkIsGenerated fails when the committed spec or client are older than the routes, run go generate in
+// this package to write them again.
+func TestSdkIsGenerated(t *testing.T) {
+	document := Spec()
+	spec, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{
+		"../../../sdk/openapi.json":         append(spec, '\n'),
+		"../../../sdk/typescript/client.ts": openapi.TypeScript(document),
+	}
+	for path, want := range files {
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s is out of date, run go generate ./src/api/application", path)
+		}
+	}
+}
+
+// TestRequestsRejectMalformedBodies binds broken bodies to every documented request, none of them may panic
+// and the ones that are turned down must be turned down as bad requests.
+func TestRequestsRejectMalformedBodies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	bodies := []string{``, `{`, `[]`, `[{}]`, `"request"`, `1`, `true`, `{"a":}`, strings.Repeat(`[`, 10000)}
+	values := []string{`null`, `{}`, `[]`, `[null]`, `[{}]`, `""`, `"  "`, `"x"`, `-1`, `0`, `1.5`, `1e400`, `true`, strings.Repeat("a", 1<<16)}
+
+	var routes []openapi.Route
+	routes = append(routes, handler.Routes...)
+	routes = append(routes, admin.Routes...)
+	for _, route := range routes {
+		if route.Request == nil {
+			continue
+		}
+		request := reflect.TypeOf(route.Request)
+		t.Run(route.Id, func(t *testing.T) {
+			for _, body := range bodies {
+				if bind(t, request, body) {
+					t.Fatalf("%q was accepted", body)
+				}
+			}
+			for _, field := range jsonFields(request) {
+				for _, value := range values {
+					if value[0] == 'a' {
+						value = strconv.Quote(value)
+					}
+					bind(t, request, fmt.Sprintf(`{%q:%s}`, field, value))
+				}
+			}
+		})
+	}
+}
+
+// bind binds the body to a new request of the type and checks how it was turned down.
+func bind(t *testing.T, request reflect.Type, body string) bool {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	defer func() {
+		if err := recover(); err != nil {
+			t.Fatalf("%.100s panicked: %v", body, err)
+		}
+	}()
+	if handler.Bind(ctx, reflect.New(request).Interface()) {
+		return true
+	}
+	var resp struct {
+		Code string `json:"code"`
+	}
+	_ = json.Unmarshal(recorder.Body.Bytes(), &resp)
+	if recorder.Code != http.StatusBadRequest || (resp.Code != "validation_failed" && resp.Code != "invalid_request") {
+		t.Fatalf("%.100s got %d %q, want a bad request", body, recorder.Code, resp.Code)
+	}
+	return false
+}
+
+// jsonFields are the names of the fields decoded from the top level of the body.
+func jsonFields(request reflect.Type) []string {
+	var fields []string
+	for index := 0; index < request.NumField(); index++ {
+		field := request.Field(index)
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		switch {
+		case name == "-" || !field.IsExported():
+		case field.Anonymous && name == "":
+			fields = append(fields, jsonFields(field.Type)...)
+		case name == "":
+			fields = append(fields, field.Name)
+		default:
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+func difference(from, without map[string]bool) []string {
+	var missing []string
+	for value := range from {
+		if !without[value] {
+			missing = append(missing, value)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
@@ -4,32 +4,68 @@ import (
 	"DX/src/api/handler"
 	"DX/src/api/handler/admin"
 	"DX/src/api/middleware"
+	apiKeyRepository "DX/src/domain/entity/apikey"
 	"DX/src/domain/entity/auth"
 	"DX/src/domain/entity/category"
 	errandRepository "DX/src/domain/entity/errand"
+	feeRepository "DX/src/domain/entity/fee"
 	fileRepository "DX/src/domain/entity/file"
+	"DX/src/domain/entity/idempotency"
+	"DX/src/domain/entity/limiter"
 	"DX/src/domain/entity/notification"
+	otpRepository "DX/src/domain/entity/otp"
+	pinRepository "DX/src/domain/entity/pin"
+	promoRepository "DX/src/domain/entity/promo"
+	reconciliationRepository "DX/src/domain/entity/reconciliation"
+	refundRepository "DX/src/domain/entity/refund"
+	resetRepository "DX/src/domain/entity/reset"
+	"DX/src/domain/entity/role"
 	secRepository "DX/src/domain/entity/security"
+	sessionRepository "DX/src/domain/entity/session"
+	twoFactorRepository "DX/src/domain/entity/twofactor"
 	"DX/src/domain/entity/user"
 	"DX/src/domain/entity/wallet"
+	webhookRepository "DX/src/domain/entity/webhook"
 	adminUseCase "DX/src/domain/usecase/admin"
+	apiKeyUseCase "DX/src/domain/usecase/apikey"
 	"DX/src/domain/usecase/authentication"
 	"DX/src/domain/usecase/errand"
+	"DX/src/domain/usecase/fee"
 	"DX/src/domain/usecase/file"
 	"DX/src/domain/usecase/init_data"
+	limiter2 "DX/src/domain/usecase/limiter"
+	"DX/src/domain/usecase/otp"
+	"DX/src/domain/usecase/pin"
+	"DX/src/domain/usecase/promo"
+	reconciliation2 "DX/src/domain/usecase/reconciliation"
+	"DX/src/domain/usecase/refund"
+	"DX/src/domain/usecase/reset"
+	roleUseCase "DX/src/domain/usecase/role"
 	"DX/src/domain/usecase/security"
+	"DX/src/domain/usecase/session"
+	"DX/src/domain/usecase/twofactor"
 	wallet2 "DX/src/domain/usecase/wallet"
+	webhook2 "DX/src/domain/usecase/webhook"
+	"DX/src/pkg/email_service"
 	"DX/src/pkg/error_service"
+	"DX/src/pkg/gateway_service"
 	"DX/src/pkg/password_service"
+	"DX/src/pkg/redis_service"
+	"DX/src/pkg/sms_service"
+	"DX/src/pkg/statement_service"
 	"DX/src/pkg/token_service"
+	"DX/src/pkg/totp_service"
+	"DX/src/pkg/webhook_service"
 	"DX/src/utils/logger"
 	"cloud.google.com/go/storage"
 	"context"
+	"fmt"
 	ginzap "github.com/gin-contrib/zap"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"google.golang.org/api/option"
+	"os"
 	"time"
 )
 
@@ -41,15 +77,37 @@ const (
 )
 
 var (
-	authenticationHandler handler.Authentication
-	securityHandler       handler.Security
-	errandHandler         handler.Errand
-	initHandler           handler.Init
-	walletHandler         handler.Wallet
-	categoryHandler       admin.Category
-	userAdminHandler      admin.User
-	errandAdminHandler    admin.Errand
-	middleWare            middleware.Middleware
+	authenticationHandler        handler.Authentication
+	securityHandler              handler.Security
+	errandHandler                handler.Errand
+	initHandler                  handler.Init
+	walletHandler                handler.Wallet
+	pinHandler                   handler.Pin
+	promoHandler                 handler.Promo
+	sessionHandler               handler.Session
+	passwordResetHandler         handler.PasswordReset
+	twoFactorHandler             handler.TwoFactor
+	apiKeyHandler                handler.ApiKey
+	webhookHandler               handler.Webhook
+	categoryHandler              admin.Category
+	userAdminHandler             admin.User
+	errandAdminHandler           admin.Errand
+	feeAdminHandler              admin.Fee
+	walletAdminHandler           admin.Wallet
+	reconciliationHandler        admin.Reconciliation
+	refundAdminHandler           admin.Refund
+	promoAdminHandler            admin.Promo
+	securityQuestionAdminHandler admin.SecurityQuestion
+	roleAdminHandler             admin.Role
+	healthHandler                handler.Health
+	jwksHandler                  handler.Jwks
+	docsHandler                  handler.Docs
+	middleWare                   middleware.Middleware
+
+	errandUseCase         errand.UseCase
+	reconciliationUseCase reconciliation2.UseCase
+	webhookUseCase        webhook2.UseCase
+	redisService          redis_service.Service
 )
 
 func GetDatabase() *mongo.Database {
@@ -82,8 +140,14 @@ func InitializeUserCollection(database *mongo.Database) *mongo.Collection {
 		},
 		Options: options.Index(),
 	}
+	referralIndexModel := mongo.IndexModel{
+		Keys: bson.D{
+			{"referral_code", 1},
+		},
+		Options: options.Index().SetUnique(true).SetSparse(true),
+	}
 	collection := database.Collection("users")
-	_, indexError := collection.Indexes().CreateMany(mongoContext, []mongo.IndexModel{userIndexModel, emailIndexModel})
+	_, indexError := collection.Indexes().CreateMany(mongoContext, []mongo.IndexModel{userIndexModel, emailIndexModel, referralIndexModel})
 	if indexError != nil {
 		panic(indexError)
 	}
@@ -97,7 +161,7 @@ func InitializeTokenCollection(database *mongo.Database) *mongo.Collection {
 
 	tokenExpIndex := mongo.IndexModel{
 		Keys: bson.D{
-			{"access_token_expires", 1},
+			{"refresh_token_expires", 1},
 		},
 		Options: options.Index().SetExpireAfterSeconds(0),
 	}
@@ -106,9 +170,27 @@ func InitializeTokenCollection(database *mongo.Database) *mongo.Collection {
 			{"access_uuid", 1},
 		},
 	}
+	refreshUuidIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{"refresh_uuid", 1},
+		},
+	}
+	familyIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{"family", 1},
+		},
+	}
+	deviceIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{"user_id", 1},
+			{"device_id", 1},
+		},
+	}
 
 	collection := database.Collection("token")
-	_, indexError := collection.Indexes().CreateMany(mongoContext, []mongo.IndexModel{tokenExpIndex, uuidIndex})
+	// Tokens used to expire with the access token, rotated pairs now live until the refresh token expires
+	_, _ = collection.Indexes().DropOne(mongoContext, "access_token_expires_1")
+	_, indexError := collection.Indexes().CreateMany(mongoContext, []mongo.IndexModel{tokenExpIndex, uuidIndex, refreshUuidIndex, familyIndex, deviceIndex})
 	if indexError != nil {
 		panic(indexError)
 	}
@@ -137,6 +219,26 @@ func InitializeSecurityCollection(database *mongo.Database) *mongo.Collection {
 	return collection
 }
 
+func InitializeSecurityCatalogueCollection(database *mongo.Database) *mongo.Collection {
+	mongoContext, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	activeIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{"active", 1},
+			{"created_at", 1},
+		},
+	}
+
+	collection := database.Collection("security-question-catalogue")
+	_, indexError := collection.Indexes().CreateMany(mongoContext, []mongo.IndexModel{activeIndex})
+	if indexError != nil {
+		panic(indexError)
+	}
+
+	return collection
+}
+
 func InitializeErrandCollection(database *mongo.Database) *mongo.Collection {
 	mongoContext, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -205,7 +307,9 @@ func InitializeNotificationCollection(database *mongo.Database) *mongo.Collectio
 	return collection
 }
 
-func InitializeTransactionCollection(database *mongo.Database) *mongo.Collection {
+// InitializeTransactionCollections returns the ledger and the lock documents that serialise transfers
+// for a wallet. Transfers run in Mongo transactions, so Mongo must run as a replica set.
+func InitializeTransactionCollections(database *mongo.Database) (*mongo.Collection, *mongo.Collection) {
 	mongoContext, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -216,9 +320,49 @@ func InitializeTransactionCollection(database *mongo.Database) *mongo.Collection
 		},
 		Options: options.Index(),
 	}
+	historyIndices := mongo.IndexModel{
+		Keys: bson.D{
+			{"user_id", 1},
+			{"created_at", -1},
+		},
+		Options: options.Index(),
+	}
+	keyIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{"key", 1},
+		},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"key": bson.M{"$exists": true}}),
+	}
 
 	collection := database.Collection("transactions")
-	_, indexError := collection.Indexes().CreateMany(mongoContext, []mongo.IndexModel{indices})
+	_, indexError := collection.Indexes().CreateMany(mongoContext, []mongo.IndexModel{indices, historyIndices, keyIndex})
+	if indexError != nil {
+		panic(indexError)
+	}
+
+	return collection, database.Collection("wallet-locks")
+}
+
+func InitializeIdempotencyCollection(database *mongo.Database) *mongo.Collection {
+	mongoContext, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	keyIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{"user_id", 1},
+			{"key", 1},
+		},
+		Options: options.Index().SetUnique(true),
+	}
+	expiryIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{"expires_at", 1},
+		},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}
+
+	collection := database.Collection("idempotency-keys")
+	_, indexError := collection.Indexes().CreateMany(mongoContext, []mongo.IndexModel{keyIndex, expiryIndex})
 	if indexError != nil {
 		panic(indexError)
 	}
@@ -226,21 +370,579 @@ func InitializeTransactionCollection(database *mongo.Database) *mongo.Collection
 	return collection
 }
 
+func InitializePinCollections(database *mongo.Database) (*mongo.Collection, *mongo.Collection) {
+	mongoContext, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	userIdIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{"user_id", 1},
+		},
+		Options: options.Index().SetUnique(true),
+	}
+	eventIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{"user_id", 1},
+			{"created_at", -1},
+		},
+		Options: options.Index(),
+	}
+
+	collection := database.Collection("transaction-pins")
+	if _, indexError := collection.Indexes().CreateMany(mongoContext, []mongo.IndexModel{userIdIndex}); indexError != nil {
+		panic(indexError)
+	}
+	eventCollection := database.Collection("transaction-pin-events")
+	if _, indexError := eventCollection.Indexes().CreateMany(mongoContext, []mongo.IndexModel{eventIndex}); indexError != nil {
+		panic(indexError)
+	}
+
+	return collection, eventCollection
+}
+
+func InitializeFeeCollections(database *mongo.Database) (*mongo.Collection, *mongo.Collection) {
+	mongoContext, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ruleIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{"fee_kind", 1},
+			{"category_id", 1},
+		},
+		Options: options.Index().SetUnique(true),
+	}
+	waiverIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{"fee_kind", 1},
+			{"starts_at", 1},
+			{"ends_at", 1},
+		},
+		Options: options.Index(),
+	}
+
+	ruleCollection := database.Collection("fee-rules")
+	if _, indexError := ruleCollection.Indexes().CreateMany(mongoContext, []mongo.IndexModel{ruleIndex}); indexError != nil {
+		panic(indexError)
+	}
+	waiverCollection := database.Collection("fee-waivers")
+	if _, indexError := waiverCollection.Indexes().CreateMany(mongoContext, []mongo.IndexModel{waiverIndex}); indexError != nil {
+		panic(indexError)
+	}
+
+	return ruleCollection, waiverCollection
+}
+
+func InitializeReconciliationCollections(database *mongo.Database) (*mongo.Collection, *mongo.Collection) {
+	mongoContext, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	reportIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{"created_at", -1},
+		},
+		Options: options.Index(),
+	}
+	discrepancyIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{"reference", 1},
+			{"discrepancy_kind", 1},
+		},
+		Options: options.Index().SetUnique(true),
+	}
+	statusIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{"status", 1},
+			{"created_at", -1},
+		},
+		Options: options.Index(),
+	}
+
+	reportCollection := database.Collection("reconciliation-reports")
+	if _, indexError := reportCollection.Indexes().CreateMany(mongoContext, []mongo.IndexModel{reportIndex}); indexError != nil {
+		panic(indexError)
+	}
+	discrepancyCollection := database.Collection("reconciliation-discrepancies")
+	if _, indexError := discrepancyCollection.Indexes().CreateMany(mongoContext, []mongo.IndexModel{discrepancyIndex, statusIndex}); indexError != nil {
+		panic(indexError)
+	}
+
+	return reportCollection, discrepancyCollection
+}
+
+func InitializeSessionCollection(database *mongo.Database) *mongo.Collection {
+	mongoContext, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	indices := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{"user_id", 1},
+				{"device_id", 1},
+			},
+			Options: options.Index(),
+		},
+		{
+			Keys: bson.D{
+				{"expires_at", 1},
+			},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	collection := database.Collection("sessions")
+	if _, indexError := collection.Indexes().CreateMany(mongoContext, indices); indexError != nil {
+		panic(indexError)
+	}
+
+	return collection
+}
+
+func InitializeOtpCollection(database *mongo.Database) *mongo.Collection {
+	mongoContext, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	indices := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{"phone", 1},
+				{"purpose", 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{"expires_at", 1},
+			},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	collection := database.Collection("otps")
+	if _, indexError := collection.Indexes().CreateMany(mongoContext, indices); indexError != nil {
+		panic(indexError)
+	}
+
+	return collection
+}
+
+func InitializePasswordResetCollection(database *mongo.Database) *mongo.Collection {
+	mongoContext, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	indices := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{"phone", 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{"expires_at", 1},
+			},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	collection := database.Collection("password-resets")
+	if _, indexError := collection.Indexes().CreateMany(mongoContext, indices); indexError != nil {
+		panic(indexError)
+	}
+
+	return collection
+}
+
+func InitializeTwoFactorCollections(database *mongo.Database) (*mongo.Collection, *mongo.Collection) {
+	mongoContext, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	twoFactorCollection := database.Collection("two-factor")
+	if _, indexError := twoFactorCollection.Indexes().CreateOne(mongoContext, mongo.IndexModel{
+		Keys: bson.D{
+			{"user_id", 1},
+		},
+		Options: options.Index().SetUnique(true),
+	}); indexError != nil {
+		panic(indexError)
+	}
+
+	indices := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{"hash", 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{"expires_at", 1},
+			},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	challengeCollection := database.Collection("two-factor-challenges")
+	if _, indexError := challengeCollection.Indexes().CreateMany(mongoContext, indices); indexError != nil {
+		panic(indexError)
+	}
+
+	return twoFactorCollection, challengeCollection
+}
+
+func InitializeRoleCollection(database *mongo.Database) *mongo.Collection {
+	mongoContext, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	index := mongo.IndexModel{
+		Keys: bson.D{
+			{"name", 1},
+		},
+		Options: options.Index().SetUnique(true),
+	}
+
+	collection := database.Collection("roles")
+	if _, indexError := collection.Indexes().CreateOne(mongoContext, index); indexError != nil {
+		panic(indexError)
+	}
+
+	return collection
+}
+
+func InitializeApiKeyCollection(database *mongo.Database) *mongo.Collection {
+	mongoContext, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	indices := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{"hash", 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{"user_id", 1},
+				{"created_at", -1},
+			},
+			Options: options.Index(),
+		},
+	}
+
+	collection := database.Collection("api-keys")
+	if _, indexError := collection.Indexes().CreateMany(mongoContext, indices); indexError != nil {
+		panic(indexError)
+	}
+
+	return collection
+}
+
+func InitializeWebhookCollections(database *mongo.Database) (*mongo.Collection, *mongo.Collection) {
+	mongoContext, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	subscriptionIndices := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{"user_id", 1},
+				{"events", 1},
+				{"active", 1},
+			},
+			Options: options.Index(),
+		},
+	}
+	subscriptionCollection := database.Collection("webhooks")
+	if _, indexError := subscriptionCollection.Indexes().CreateMany(mongoContext, subscriptionIndices); indexError != nil {
+		panic(indexError)
+	}
+
+	deliveryIndices := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{"status", 1},
+				{"next_attempt_at", 1},
+			},
+			Options: options.Index(),
+		},
+		{
+			Keys: bson.D{
+				{"user_id", 1},
+				{"subscription_id", 1},
+				{"created_at", -1},
+			},
+			Options: options.Index(),
+		},
+		{
+			Keys: bson.D{
+				{"created_at", 1},
+			},
+			Options: options.Index().SetExpireAfterSeconds(int32((30 * 24 * time.Hour).Seconds())),
+		},
+	}
+	deliveryCollection := database.Collection("webhook-deliveries")
+	if _, indexError := deliveryCollection.Indexes().CreateMany(mongoContext, deliveryIndices); indexError != nil {
+		panic(indexError)
+	}
+
+	return subscriptionCollection, deliveryCollection
+}
+
+func InitializeRefundCollection(database *mongo.Database) *mongo.Collection {
+	mongoContext, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	indices := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{"user_id", 1},
+				{"created_at", -1},
+			},
+			Options: options.Index(),
+		},
+		{
+			Keys: bson.D{
+				{"errand_id", 1},
+			},
+			Options: options.Index(),
+		},
+	}
+
+	collection := database.Collection("refunds")
+	if _, indexError := collection.Indexes().CreateMany(mongoContext, indices); indexError != nil {
+		panic(indexError)
+	}
+
+	return collection
+}
+
+func InitializePromoCollections(database *mongo.Database) (*mongo.Collection, *mongo.Collection, *mongo.Collection) {
+	mongoContext, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	promoIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{"code", 1},
+		},
+		Options: options.Index().SetUnique(true),
+	}
+	redemptionIndices := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{"promo_id", 1},
+				{"user_id", 1},
+			},
+			Options: options.Index(),
+		},
+		{
+			Keys: bson.D{
+				{"promo_id", 1},
+				{"user_id", 1},
+				{"use", 1},
+			},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"use": bson.M{"$exists": true}}),
+		},
+		{
+			Keys: bson.D{
+				{"errand_id", 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	referralIndices := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{"referee_id", 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{"referrer_id", 1},
+			},
+			Options: options.Index(),
+		},
+	}
+
+	promoCollection := database.Collection("promos")
+	if _, indexError := promoCollection.Indexes().CreateMany(mongoContext, []mongo.IndexModel{promoIndex}); indexError != nil {
+		panic(indexError)
+	}
+	redemptionCollection := database.Collection("promo-redemptions")
+	if _, indexError := redemptionCollection.Indexes().CreateMany(mongoContext, redemptionIndices); indexError != nil {
+		panic(indexError)
+	}
+	referralCollection := database.Collection("referrals")
+	if _, indexError := referralCollection.Indexes().CreateMany(mongoContext, referralIndices); indexError != nil {
+		panic(indexError)
+	}
+
+	return promoCollection, redemptionCollection, referralCollection
+}
+
+// InitializeTokenStore builds the token repository for the backend selected with TOKEN_STORE, and
+// registers a health check for it.
+func InitializeTokenStore(database *mongo.Database, errorService error_service.Service, checks map[string]handler.HealthCheck) auth.Repository {
+	store, err := auth.GetStore(os.Getenv("TOKEN_STORE"))
+	if err != nil {
+		panic(err)
+	}
+
+	switch store {
+	case auth.RedisStore:
+		return auth.NewRedisRepository(InitializeRedis(checks).Client(), errorService)
+	case auth.InMemoryStore:
+		logger.Info("tokens are stored in memory and will be lost on restart")
+		return auth.NewInMemoryRepository()
+	default:
+		return auth.NewMongoCacheRepository(InitializeTokenCollection(database), errorService)
+	}
+}
+
+// InitializeLimiterStore builds the rate limit repository for the backend selected with
+// RATE_LIMIT_STORE, the in-memory store only limits a single instance.
+func InitializeLimiterStore(database *mongo.Database, checks map[string]handler.HealthCheck) limiter.Repository {
+	store, err := auth.GetStore(os.Getenv("RATE_LIMIT_STORE"))
+	if err != nil {
+		panic(err)
+	}
+
+	switch store {
+	case auth.RedisStore:
+		return limiter.NewRedisRepository(InitializeRedis(checks).Client())
+	case auth.InMemoryStore:
+		logger.Info("rate limits are kept in memory and only apply to this instance")
+		return limiter.NewInMemoryRepository()
+	default:
+		return limiter.NewMongoRepository(InitializeLimiterCollection(database))
+	}
+}
+
+// InitializeRedis connects to Redis once for every store that uses it, and registers a health check for it.
+func InitializeRedis(checks map[string]handler.HealthCheck) redis_service.Service {
+	if redisService != nil {
+		return redisService
+	}
+
+	config, err := redis_service.ConfigFromEnv()
+	if err != nil {
+		panic(err)
+	}
+	service, err := redis_service.New(config)
+	if err != nil {
+		panic(err)
+	}
+	checks["redis"] = service.Health
+	redisService = service
+	return redisService
+}
+
+// InitializeSms sends messages with Termii. Without TERMII_API_KEY messages are only logged, which is
+// allowed in development alone.
+func InitializeSms() sms_service.Service {
+	if apiKey := os.Getenv("TERMII_API_KEY"); apiKey != "" {
+		return sms_service.NewTermii(apiKey, os.Getenv("TERMII_SENDER_ID"))
+	}
+	requireDevelopment("TERMII_API_KEY")
+	logger.Info("sms are logged instead of sent")
+	return sms_service.NewFake()
+}
+
+// InitializeEmail sends emails through the SMTP server in SMTP_HOST. Without one emails are only logged,
+// which is allowed in development alone.
+func InitializeEmail() email_service.Service {
+	config, err := email_service.ConfigFromEnv()
+	if err != nil {
+		panic(err)
+	}
+	if config.Configured() {
+		return email_service.NewSmtp(config)
+	}
+	requireDevelopment("SMTP_HOST")
+	logger.Info("emails are logged instead of sent")
+	return email_service.NewFake()
+}
+
+// InitializeGateway reads settlements from Paystack. Without PAYSTACK_SECRET_KEY reconciliation runs against
+// an empty fake gateway, which is allowed in development alone.
+func InitializeGateway() gateway_service.Service {
+	if secretKey := os.Getenv("PAYSTACK_SECRET_KEY"); secretKey != "" {
+		return gateway_service.NewPaystack(secretKey)
+	}
+	requireDevelopment("PAYSTACK_SECRET_KEY")
+	logger.Info("reconciliation uses a fake payment gateway")
+	return gateway_service.NewFake()
+}
+
+// requireDevelopment stops the application when a provider is missing outside development, where the fake
+// providers would drop messages and payments or log secrets.
+func requireDevelopment(name string) {
+	if os.Getenv("APP_ENV") != "development" {
+		panic(fmt.Sprintf("%s is required, set APP_ENV=development to use a fake instead", name))
+	}
+}
+
+func InitializeLimiterCollection(database *mongo.Database) *mongo.Collection {
+	mongoContext, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	indices := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{"expires_at", 1},
+			},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	collection := database.Collection("rate-limits")
+	if _, indexError := collection.Indexes().CreateMany(mongoContext, indices); indexError != nil {
+		panic(indexError)
+	}
+
+	return collection
+}
+
 func setUpRepositoriesAndManagers() {
 	//Service
-	tokenService := token_service.New()
+	keys, err := token_service.LoadKeys()
+	if err != nil {
+		panic(err)
+	}
+	tokenService := token_service.New(keys)
 	errorService := error_service.New()
 	passwordService := password_service.New()
+	statementService := statement_service.New()
+	gatewayService := InitializeGateway()
+	smsService := InitializeSms()
+	emailService := InitializeEmail()
+	totpIssuer := os.Getenv("TOTP_ISSUER")
+	if totpIssuer == "" {
+		totpIssuer = "Errand App"
+	}
+	totpService := totp_service.New(totpIssuer)
+	webhookService := webhook_service.NewHttp(10 * time.Second)
 
 	//DB and collection
 	db := GetDatabase()
 	userCollection := InitializeUserCollection(db)
-	tokenCollection := InitializeTokenCollection(db)
 	secCollection := InitializeSecurityCollection(db)
+	secCatalogueCollection := InitializeSecurityCatalogueCollection(db)
 	errandCollection := InitializeErrandCollection(db)
 	categoryCollection := InitializeCategoryCollection(db)
 	notificationCollection := InitializeNotificationCollection(db)
-	transactionCollection := InitializeTransactionCollection(db)
+	transactionCollection, walletLockCollection := InitializeTransactionCollections(db)
+	idempotencyCollection := InitializeIdempotencyCollection(db)
+	pinCollection, pinEventCollection := InitializePinCollections(db)
+	feeRuleCollection, feeWaiverCollection := InitializeFeeCollections(db)
+	reportCollection, discrepancyCollection := InitializeReconciliationCollections(db)
+	refundCollection := InitializeRefundCollection(db)
+	sessionCollection := InitializeSessionCollection(db)
+	otpCollection := InitializeOtpCollection(db)
+	resetCollection := InitializePasswordResetCollection(db)
+	twoFactorCollection, twoFactorChallengeCollection := InitializeTwoFactorCollections(db)
+	roleCollection := InitializeRoleCollection(db)
+	apiKeyCollection := InitializeApiKeyCollection(db)
+	webhookCollection, webhookDeliveryCollection := InitializeWebhookCollections(db)
+	promoCollection, redemptionCollection, referralCollection := InitializePromoCollections(db)
 
 	//Clients
 	strClient, err := storage.NewClient(context.Background(), option.WithCredentialsFile(""))
@@ -249,31 +951,73 @@ func setUpRepositoriesAndManagers() {
 	}
 
 	// Repositories
-	authRepo := auth.NewMongoCacheRepository(tokenCollection)
-	secRepo := secRepository.NewDatabaseRepository(secCollection)
+	healthChecks := map[string]handler.HealthCheck{
+		"mongo": func(ctx context.Context) error {
+			return db.Client().Ping(ctx, nil)
+		},
+	}
+	authRepo := InitializeTokenStore(db, errorService, healthChecks)
+	limiterRepo := InitializeLimiterStore(db, healthChecks)
+	secRepo := secRepository.NewDatabaseRepository(secCollection, secCatalogueCollection)
 	userRepo := user.NewDatabaseRepository(userCollection)
 	errandRepo := errandRepository.NewRepository(errandCollection)
 	fileRepo := fileRepository.NewRepository(strClient)
 	categoryRepo := category.NewRepository(categoryCollection)
 	notificationRepo := notification.NewInAppNotificationRepository(notificationCollection)
-	walletRepo := wallet.NewWalletRepository(transactionCollection)
+	walletRepo := wallet.NewWalletRepository(transactionCollection, walletLockCollection)
+	idempotencyRepo := idempotency.NewRepository(idempotencyCollection)
+	pinRepo := pinRepository.NewRepository(pinCollection, pinEventCollection)
+	feeRepo := feeRepository.NewRepository(feeRuleCollection, feeWaiverCollection)
+	reconciliationRepo := reconciliationRepository.NewRepository(reportCollection, discrepancyCollection)
+	refundRepo := refundRepository.NewRepository(refundCollection)
+	sessionRepo := sessionRepository.NewRepository(sessionCollection)
+	otpRepo := otpRepository.NewRepository(otpCollection)
+	resetRepo := resetRepository.NewRepository(resetCollection)
+	twoFactorRepo := twoFactorRepository.NewRepository(twoFactorCollection, twoFactorChallengeCollection)
+	roleRepo := role.NewRepository(roleCollection)
+	apiKeyRepo := apiKeyRepository.NewRepository(apiKeyCollection)
+	webhookRepo := webhookRepository.NewRepository(webhookCollection, webhookDeliveryCollection)
+	promoRepo := promoRepository.NewRepository(promoCollection, redemptionCollection, referralCollection)
 
 	// Managers
 	authManager := auth.NewManager(tokenService, authRepo)
 
 	// UseCases
-	authUseCase := authentication.NewUseCase(userRepo, errorService, passwordService, authManager, notificationRepo)
-	secUseCase := security.NewUseCase(authManager, secRepo, errorService, userRepo)
-	errandUseCase := errand.NewUseCase(authManager, errandRepo, userRepo, errorService, notificationRepo, categoryRepo, errandRepo, walletRepo)
-	fileUseCase := file.NewUseCase(authManager, fileRepo, errandRepo, errorService)
-	adminUserUseCase := adminUseCase.NewUserUseCase(authManager, userRepo, errorService)
-	adminCategoryUseCase := adminUseCase.NewCategoryUseCase(fileRepo, authManager, userRepo, categoryRepo, errorService)
-	adminErrandUseCase := adminUseCase.NewErrandUseCase(authManager, errandRepo, errorService, userRepo, notificationRepo, categoryRepo)
+	limiterUseCase := limiter2.NewUseCase(limiterRepo)
+	pinUseCase := pin.NewUseCase(pinRepo, userRepo, notificationRepo, passwordService, limiterUseCase, errorService)
+	feeUseCase := fee.NewUseCase(feeRepo, errorService)
+	promoUseCase := promo.NewUseCase(promoRepo, userRepo, errandRepo, walletRepo, notificationRepo, errorService)
+	refundUseCase := refund.NewUseCase(refundRepo, walletRepo, notificationRepo, promoUseCase, errorService)
+	sessionUseCase := session.NewUseCase(authManager, sessionRepo, errorService)
+	otpUseCase := otp.NewUseCase(otpRepo, smsService, emailService, errorService)
+	rbacUseCase := roleUseCase.NewUseCase(roleRepo, userRepo, errorService)
+	if phone := os.Getenv("SUPER_ADMIN_PHONE"); phone != "" {
+		if err := rbacUseCase.Bootstrap(phone); err != nil {
+			logger.Error("unable to make "+phone+" a super-admin", err)
+		}
+	}
+	keyUseCase := apiKeyUseCase.NewUseCase(apiKeyRepo, errorService)
+	webhookUseCase = webhook2.NewUseCase(webhookRepo, webhookService, notificationRepo, errorService)
+	twoFactorUseCase := twofactor.NewUseCase(twoFactorRepo, userRepo, totpService, sessionUseCase, errorService)
+	authUseCase := authentication.NewUseCase(userRepo, errorService, passwordService, authManager, notificationRepo, pinUseCase, promoUseCase, sessionUseCase, otpUseCase, limiterUseCase, twoFactorUseCase)
+	secUseCase := security.NewUseCase(secRepo, errorService, userRepo, passwordService)
+	resetUseCase := reset.NewUseCase(resetRepo, userRepo, notificationRepo, passwordService, otpUseCase, secUseCase, sessionUseCase, errorService)
+	errandUseCase = errand.NewUseCase(errandRepo, userRepo, errorService, notificationRepo, categoryRepo, errandRepo, walletRepo, pinUseCase, feeUseCase, refundUseCase, promoUseCase, webhookUseCase)
+	fileUseCase := file.NewUseCase(fileRepo, errandRepo, errorService)
+	adminUserUseCase := adminUseCase.NewUserUseCase(userRepo, errorService, limiterUseCase)
+	adminCategoryUseCase := adminUseCase.NewCategoryUseCase(fileRepo, userRepo, categoryRepo, errorService)
+	adminErrandUseCase := adminUseCase.NewErrandUseCase(errandRepo, errorService, userRepo, notificationRepo, categoryRepo)
+	adminFeeUseCase := adminUseCase.NewFeeUseCase(feeRepo, walletRepo, errorService)
+	adminRoleUseCase := adminUseCase.NewRoleUseCase(roleRepo, userRepo, errorService)
+	adminSecurityQuestionUseCase := adminUseCase.NewSecurityQuestionUseCase(secRepo, errorService)
+	adminPromoUseCase := adminUseCase.NewPromoUseCase(promoRepo, userRepo, walletRepo, notificationRepo, errorService)
 	initUseCase := init_data.NewUseCase(categoryRepo)
-	walletUseCase := wallet2.NewUseCase(walletRepo, errorService, authManager)
+	walletUseCase := wallet2.NewUseCase(walletRepo, errorService, pinUseCase, errandRepo)
+	reconciliationUseCase = reconciliation2.NewUseCase(reconciliationRepo, walletRepo, gatewayService, errorService)
+	adminReconciliationUseCase := adminUseCase.NewReconciliationUseCase(reconciliationRepo, reconciliationUseCase, errorService)
 
 	// Middlewares
-	middleWare = middleware.NewErrandMiddleware(userRepo, tokenService, authManager)
+	middleWare = middleware.NewErrandMiddleware(userRepo, authManager, idempotencyRepo, limiterUseCase, sessionUseCase, rbacUseCase, keyUseCase)
 
 	// Handlers
 	authenticationHandler = handler.NewAuthHandler(authUseCase, errandUseCase)
@@ -283,7 +1027,24 @@ func setUpRepositoriesAndManagers() {
 	userAdminHandler = admin.NewAdminUserHandler(adminUserUseCase)
 	categoryHandler = admin.NewAdminCategoryHandler(adminCategoryUseCase)
 	errandAdminHandler = admin.NewAdminErrandHandler(adminErrandUseCase, errandUseCase)
-	walletHandler = handler.NewWalletHandler(walletUseCase)
+	feeAdminHandler = admin.NewAdminFeeHandler(adminFeeUseCase)
+	walletAdminHandler = admin.NewAdminWalletHandler(walletUseCase, statementService)
+	reconciliationHandler = admin.NewAdminReconciliationHandler(adminReconciliationUseCase)
+	refundAdminHandler = admin.NewAdminRefundHandler(refundUseCase)
+	promoAdminHandler = admin.NewAdminPromoHandler(adminPromoUseCase)
+	securityQuestionAdminHandler = admin.NewAdminSecurityQuestionHandler(adminSecurityQuestionUseCase)
+	roleAdminHandler = admin.NewAdminRoleHandler(adminRoleUseCase)
+	promoHandler = handler.NewPromoHandler(promoUseCase)
+	walletHandler = handler.NewWalletHandler(walletUseCase, statementService)
+	pinHandler = handler.NewPinHandler(pinUseCase)
+	sessionHandler = handler.NewSessionHandler(sessionUseCase)
+	passwordResetHandler = handler.NewPasswordResetHandler(resetUseCase)
+	twoFactorHandler = handler.NewTwoFactorHandler(twoFactorUseCase)
+	apiKeyHandler = handler.NewApiKeyHandler(keyUseCase)
+	webhookHandler = handler.NewWebhookHandler(webhookUseCase)
+	healthHandler = handler.NewHealthHandler(healthChecks)
+	jwksHandler = handler.NewJwksHandler(tokenService)
+	docsHandler = handler.NewDocsHandler(Spec())
 
 	zapLogger := logger.GetLogger()
 
@@ -293,83 +1054,175 @@ func setUpRepositoriesAndManagers() {
 }
 
 func mapRoutes() {
+	router.GET("/.well-known/jwks.json", jwksHandler.GetKeys)
+
 	v1Group := router.Group("/v1")
 	{
+		v1Group.GET("/health", healthHandler.Check)
+		v1Group.GET("/openapi.json", docsHandler.GetSpec)
+		v1Group.GET("/docs", docsHandler.GetViewer)
 		v1Group.GET("/init", middleWare.Authorization(), middleWare.Suspension(), initHandler.GetInitData)
 
 		v1Group.POST("/security-question", middleWare.Authorization(), middleWare.Suspension(), securityHandler.SaveSecurityQuestion)
-		v1Group.GET("/security-question", securityHandler.GetSecurityQuestion)
-		v1Group.POST("/security-question/verify", securityHandler.VerifySecurityQuestion)
+		v1Group.GET("/security-questions", securityHandler.GetSecurityQuestions)
+		v1Group.GET("/security-question", middleWare.RateLimit(limiter.SecurityQuestionIp), securityHandler.GetSecurityQuestion)
+		v1Group.POST("/security-question/verify", middleWare.RateLimit(limiter.SecurityQuestionIp), securityHandler.VerifySecurityQuestion)
 		v1Group.POST("/paystack/webhook", walletHandler.PaystackWebhook)
-		v1Group.POST("/transact", walletHandler.MakePayment)
+		v1Group.POST("/transact", middleWare.Authorization(), middleWare.Idempotency(), walletHandler.MakePayment)
 		v1Group.GET("/errand/market", errandHandler.FetchAllErrands)
 
 		authenticationGroup := v1Group.Group("/user")
 		{
 			authenticationGroup.POST("/sign-in", authenticationHandler.Login)
-			authenticationGroup.POST("/verify-phone", authenticationHandler.VerifyPhoneNumber)
+			authenticationGroup.POST("/sign-in/2fa", middleWare.RateLimit(limiter.TwoFactorIp), authenticationHandler.LoginWithTwoFactor)
+			authenticationGroup.POST("/verify-phone", middleWare.RateLimit(limiter.OtpIp), authenticationHandler.VerifyPhoneNumber)
+			authenticationGroup.POST("/verify-phone/confirm", middleWare.RateLimit(limiter.OtpIp), authenticationHandler.ConfirmPhoneNumber)
 			authenticationGroup.POST("/sign-up", authenticationHandler.CreateAccount)
 			authenticationGroup.POST("/sign-out", middleWare.Authorization(), authenticationHandler.LogOut)
+			authenticationGroup.POST("/token/refresh", authenticationHandler.RefreshToken)
 			authenticationGroup.POST("/suspend", middleWare.Authorization(), authenticationHandler.SuspendUser)
-			authenticationGroup.POST("/password", authenticationHandler.UpdatePassword)
+			authenticationGroup.POST("/password/reset", middleWare.RateLimit(limiter.PasswordResetIp), passwordResetHandler.RequestReset)
+			authenticationGroup.POST("/password/reset/verify", middleWare.RateLimit(limiter.PasswordResetIp), passwordResetHandler.VerifyResetCode)
+			authenticationGroup.POST("/password/reset/security", middleWare.RateLimit(limiter.PasswordResetIp), passwordResetHandler.AnswerSecurityQuestion)
+			authenticationGroup.POST("/password/reset/complete", middleWare.RateLimit(limiter.PasswordResetIp), passwordResetHandler.CompleteReset)
+			authenticationGroup.PUT("/password", middleWare.Authorization(), middleWare.Suspension(), authenticationHandler.ChangePassword)
+			authenticationGroup.POST("/2fa", middleWare.Authorization(), middleWare.Suspension(), twoFactorHandler.Enroll)
+			authenticationGroup.POST("/2fa/confirm", middleWare.Authorization(), middleWare.Suspension(), twoFactorHandler.Confirm)
+			authenticationGroup.DELETE("/2fa", middleWare.Authorization(), middleWare.Suspension(), twoFactorHandler.Disable)
+			authenticationGroup.POST("/2fa/recovery-codes", middleWare.Authorization(), middleWare.Suspension(), twoFactorHandler.RegenerateRecoveryCodes)
+			authenticationGroup.GET("/api-keys", middleWare.Authorization(), middleWare.Suspension(), apiKeyHandler.GetKeys)
+			authenticationGroup.POST("/api-keys", middleWare.Authorization(), middleWare.Suspension(), apiKeyHandler.CreateKey)
+			authenticationGroup.DELETE("/api-keys/:id", middleWare.Authorization(), middleWare.Suspension(), apiKeyHandler.RevokeKey)
+			webhookGroup := authenticationGroup.Group("/webhooks", middleWare.Authorization(apiKeyRepository.WebhooksManage), middleWare.Suspension())
+			{
+				webhookGroup.GET("", webhookHandler.GetWebhooks)
+				webhookGroup.POST("", webhookHandler.CreateWebhook)
+				webhookGroup.PUT("/:id", webhookHandler.UpdateWebhook)
+				webhookGroup.DELETE("/:id", webhookHandler.DeleteWebhook)
+				webhookGroup.GET("/:id/deliveries", webhookHandler.GetDeliveries)
+				webhookGroup.POST("/:id/deliveries/:delivery_id/redeliver", webhookHandler.Redeliver)
+			}
+			authenticationGroup.GET("/sessions", middleWare.Authorization(), middleWare.Suspension(), sessionHandler.GetSessions)
+			authenticationGroup.DELETE("/sessions", middleWare.Authorization(), sessionHandler.RevokeSessions)
+			authenticationGroup.DELETE("/sessions/:id", middleWare.Authorization(), sessionHandler.RevokeSession)
 			authenticationGroup.GET("/profile", middleWare.Authorization(), middleWare.Suspension(), authenticationHandler.Profile)
-			authenticationGroup.GET("/errands", middleWare.Authorization(), middleWare.Suspension(), authenticationHandler.MyErrands)
+			authenticationGroup.GET("/errands", middleWare.Authorization(apiKeyRepository.ErrandsRead), middleWare.Suspension(), authenticationHandler.MyErrands)
 			authenticationGroup.GET("/notifications", middleWare.Authorization(), middleWare.Suspension(), authenticationHandler.MyNotifications)
-			authenticationGroup.GET("/wallet", middleWare.Authorization(), middleWare.Suspension(), walletHandler.GetWallet)
+			authenticationGroup.GET("/wallet", middleWare.Authorization(apiKeyRepository.WalletRead), middleWare.Suspension(), walletHandler.GetWallet)
+			authenticationGroup.GET("/wallet/transactions", middleWare.Authorization(apiKeyRepository.WalletRead), middleWare.Suspension(), walletHandler.GetTransactions)
+			authenticationGroup.GET("/wallet/earnings", middleWare.Authorization(apiKeyRepository.WalletRead), middleWare.Suspension(), walletHandler.GetEarnings)
+			authenticationGroup.GET("/wallet/statement", middleWare.Authorization(apiKeyRepository.WalletRead), middleWare.Suspension(), walletHandler.GetStatement)
+			authenticationGroup.POST("/wallet/withdraw", middleWare.Authorization(), middleWare.Suspension(), middleWare.Idempotency(), walletHandler.MakeWithdrawal)
+			authenticationGroup.POST("/promo/preview", middleWare.Authorization(), middleWare.Suspension(), promoHandler.PreviewPromo)
+			authenticationGroup.GET("/referrals", middleWare.Authorization(), middleWare.Suspension(), promoHandler.GetReferrals)
+			authenticationGroup.POST("/account", middleWare.Authorization(), middleWare.Suspension(), authenticationHandler.AddBankAccount)
+			authenticationGroup.POST("/pin", middleWare.Authorization(), middleWare.Suspension(), pinHandler.CreatePin)
+			authenticationGroup.PUT("/pin", middleWare.Authorization(), middleWare.Suspension(), pinHandler.ChangePin)
+			authenticationGroup.POST("/pin/reset", middleWare.Authorization(), middleWare.Suspension(), pinHandler.ResetPin)
+			authenticationGroup.GET("/pin/events", middleWare.Authorization(), middleWare.Suspension(), pinHandler.GetPinEvents)
 			authenticationGroup.GET("/:id", middleWare.Authorization(), middleWare.Suspension(), authenticationHandler.GetUser)
 			authenticationGroup.POST("/rate", middleWare.Authorization(), middleWare.Suspension(), authenticationHandler.RateUser)
 		}
-		errandGroup := v1Group.Group("/errand", middleWare.Authorization(), middleWare.Suspension())
+		errandGroup := v1Group.Group("/errand")
 		{
-			errandGroup.POST("/files", errandHandler.UploadErrandFiles)
-			errandGroup.GET("/draft", errandHandler.GetDraftErrand)
-			errandGroup.PATCH("/:id", errandHandler.UpdateErrand)
-			errandGroup.POST("/:id", errandHandler.CreateErrand)
-			errandGroup.DELETE("/:id/cancel", errandHandler.CancelErrand)
-			errandGroup.PATCH("/:id/complete", errandHandler.CompleteErrand)
-			errandGroup.GET("/:id", errandHandler.GetErrand)
-			errandGroup.GET("/categories", categoryHandler.GetAllCategories)
-			errandGroup.POST("/start", errandHandler.StartErrand)
-
-			bidGroup := errandGroup.Group("/:id")
+			errandGroup.POST("/files", middleWare.Authorization(apiKeyRepository.ErrandsCreate), middleWare.Suspension(), errandHandler.UploadErrandFiles)
+			errandGroup.GET("/draft", middleWare.Authorization(apiKeyRepository.ErrandsCreate), middleWare.Suspension(), errandHandler.GetDraftErrand)
+			errandGroup.PATCH("/:id", middleWare.Authorization(apiKeyRepository.ErrandsCreate), middleWare.Suspension(), errandHandler.UpdateErrand)
+			errandGroup.POST("/:id", middleWare.Authorization(apiKeyRepository.ErrandsCreate), middleWare.Suspension(), middleWare.Idempotency(), errandHandler.CreateErrand)
+			errandGroup.DELETE("/:id/cancel", middleWare.Authorization(), middleWare.Suspension(), errandHandler.CancelErrand)
+			errandGroup.PATCH("/:id/complete", middleWare.Authorization(), middleWare.Suspension(), errandHandler.CompleteErrand)
+			errandGroup.POST("/:id/tip", middleWare.Authorization(), middleWare.Suspension(), middleWare.Idempotency(), errandHandler.TipRunner)
+			errandGroup.GET("/:id", middleWare.Authorization(apiKeyRepository.ErrandsRead), middleWare.Suspension(), errandHandler.GetErrand)
+			errandGroup.GET("/categories", middleWare.Authorization(apiKeyRepository.ErrandsRead), middleWare.Suspension(), categoryHandler.GetAllCategories)
+			errandGroup.POST("/start", middleWare.Authorization(), middleWare.Suspension(), errandHandler.StartErrand)
+
+			bidGroup := errandGroup.Group("/:id", middleWare.Authorization(), middleWare.Suspension())
 			{
 				bidGroup.POST("/bid", errandHandler.BidForErrand)
 				bidGroup.PUT("/bid/:bid_id", errandHandler.UpdateBidForErrand)
 				bidGroup.PUT("/bid/:bid_id/respond", errandHandler.RespondToBid)
 				bidGroup.DELETE("/bid/:bid_id/respond", errandHandler.RejectErrandContract)
 			}
-			timelineGroup := errandGroup.Group("/timeline")
+			timelineGroup := errandGroup.Group("/timeline", middleWare.Authorization(), middleWare.Suspension())
 			{
 				timelineGroup.POST("", errandHandler.PostUpdate)
 				timelineGroup.GET("/request/:id", errandHandler.RequestForUpdate)
 			}
 		}
-		adminGroup := v1Group.Group("/admin", middleWare.Authorization(), middleWare.Suspension(), middleWare.Admin())
+		adminGroup := v1Group.Group("/admin", middleWare.Authorization(), middleWare.Suspension(), middleWare.RequirePermission(role.AdminAccess), middleWare.TwoFactor())
 		{
-			adminGroup.GET("/users", userAdminHandler.GetAllUsers)
-			adminGroup.GET("/errands", errandAdminHandler.GetAllErrands)
+			adminGroup.GET("/users", middleWare.RequirePermission(role.UsersRead), userAdminHandler.GetAllUsers)
+			adminGroup.GET("/errands", middleWare.RequirePermission(role.ErrandsRead), errandAdminHandler.GetAllErrands)
+			adminGroup.GET("/permissions", middleWare.RequirePermission(role.RolesWrite), roleAdminHandler.GetPermissions)
 			userGroup := adminGroup.Group("/user")
 			{
-				userGroup.GET("/:id", userAdminHandler.GetUser)
-				userGroup.POST("", userAdminHandler.CreateUser)
-				userGroup.PATCH("", userAdminHandler.UpdateUser)
-				userGroup.PATCH("/restore/:id", userAdminHandler.RestoreUser)
-				userGroup.PUT("/suspend/:id", userAdminHandler.SuspendUser)
-				userGroup.DELETE("/:id", userAdminHandler.DeleteUser)
-				userGroup.DELETE("", userAdminHandler.DeleteUsers)
-				userGroup.PUT("", userAdminHandler.SuspendUsers)
+				userGroup.GET("/:id", middleWare.RequirePermission(role.UsersRead), userAdminHandler.GetUser)
+				userGroup.POST("", middleWare.RequirePermission(role.UsersWrite), userAdminHandler.CreateUser)
+				userGroup.PATCH("", middleWare.RequirePermission(role.UsersWrite), userAdminHandler.UpdateUser)
+				userGroup.PATCH("/restore/:id", middleWare.RequirePermission(role.UsersWrite), userAdminHandler.RestoreUser)
+				userGroup.PUT("/unlock/:id", middleWare.RequirePermission(role.UsersSuspend), userAdminHandler.UnlockUser)
+				userGroup.PUT("/suspend/:id", middleWare.RequirePermission(role.UsersSuspend), userAdminHandler.SuspendUser)
+				userGroup.PUT("/:id/roles", middleWare.RequirePermission(role.RolesWrite), roleAdminHandler.AssignRoles)
+				userGroup.DELETE("/:id", middleWare.RequirePermission(role.UsersDelete), userAdminHandler.DeleteUser)
+				userGroup.DELETE("", middleWare.RequirePermission(role.UsersDelete), userAdminHandler.DeleteUsers)
+				userGroup.PUT("", middleWare.RequirePermission(role.UsersSuspend), userAdminHandler.SuspendUsers)
+				userGroup.GET("/:id/wallet/transactions", middleWare.RequirePermission(role.WalletsRead), walletAdminHandler.GetUserTransactions)
+				userGroup.GET("/:id/wallet/statement", middleWare.RequirePermission(role.WalletsRead), walletAdminHandler.GetUserStatement)
+			}
+			roleGroup := adminGroup.Group("/roles", middleWare.RequirePermission(role.RolesWrite))
+			{
+				roleGroup.GET("", roleAdminHandler.GetRoles)
+				roleGroup.POST("", roleAdminHandler.CreateRole)
+				roleGroup.PUT("/:name", roleAdminHandler.UpdateRole)
+				roleGroup.DELETE("/:name", roleAdminHandler.DeleteRole)
 			}
 			categoryGroup := adminGroup.Group("/category")
 			{
-				categoryGroup.POST("", categoryHandler.CreateCategory)
-				categoryGroup.PUT("/:id", categoryHandler.UpdateCategory)
+				categoryGroup.POST("", middleWare.RequirePermission(role.CategoriesWrite), categoryHandler.CreateCategory)
+				categoryGroup.PUT("/:id", middleWare.RequirePermission(role.CategoriesWrite), categoryHandler.UpdateCategory)
 				categoryGroup.GET("", categoryHandler.GetAllCategories)
-				categoryGroup.DELETE("/:id", categoryHandler.DeleteCategory)
+				categoryGroup.DELETE("/:id", middleWare.RequirePermission(role.CategoriesWrite), categoryHandler.DeleteCategory)
 			}
 			errandAdminGroup := adminGroup.Group("/errand")
 			{
-				errandAdminGroup.POST("", errandAdminHandler.CreatErrand)
-				errandAdminGroup.GET("/:id", errandAdminHandler.GetErrand)
+				errandAdminGroup.POST("", middleWare.RequirePermission(role.ErrandsWrite), errandAdminHandler.CreatErrand)
+				errandAdminGroup.GET("/:id", middleWare.RequirePermission(role.ErrandsRead), errandAdminHandler.GetErrand)
+				errandAdminGroup.PUT("/:id/assign", middleWare.RequirePermission(role.ErrandsAssign), errandAdminHandler.AssignErrandToRunner)
+			}
+			feeGroup := adminGroup.Group("/fees")
+			{
+				feeGroup.GET("", middleWare.RequirePermission(role.FeesRead), feeAdminHandler.GetFeeRules)
+				feeGroup.PUT("", middleWare.RequirePermission(role.FeesWrite), feeAdminHandler.SaveFeeRule)
+				feeGroup.DELETE("/:id", middleWare.RequirePermission(role.FeesWrite), feeAdminHandler.DeleteFeeRule)
+				feeGroup.GET("/waivers", middleWare.RequirePermission(role.FeesRead), feeAdminHandler.GetFeeWaivers)
+				feeGroup.POST("/waivers", middleWare.RequirePermission(role.FeesWrite), feeAdminHandler.CreateFeeWaiver)
+				feeGroup.DELETE("/waivers/:id", middleWare.RequirePermission(role.FeesWrite), feeAdminHandler.DeleteFeeWaiver)
+				feeGroup.GET("/revenue", middleWare.RequirePermission(role.FeesRead), feeAdminHandler.GetRevenue)
+			}
+			promoGroup := adminGroup.Group("/promos", middleWare.RequirePermission(role.PromosWrite))
+			{
+				promoGroup.GET("", promoAdminHandler.GetPromos)
+				promoGroup.POST("", promoAdminHandler.CreatePromo)
+				promoGroup.DELETE("/:id", promoAdminHandler.DeactivatePromo)
+				promoGroup.POST("/credits", promoAdminHandler.GrantPromoCredit)
+			}
+			refundGroup := adminGroup.Group("/refunds")
+			{
+				refundGroup.GET("", middleWare.RequirePermission(role.RefundsRead), refundAdminHandler.GetRefunds)
+				refundGroup.POST("", middleWare.RequirePermission(role.RefundsWrite), refundAdminHandler.IssueRefund)
+			}
+			securityQuestionGroup := adminGroup.Group("/security-questions", middleWare.RequirePermission(role.SecurityQuestionsWrite))
+			{
+				securityQuestionGroup.GET("", securityQuestionAdminHandler.GetQuestions)
+				securityQuestionGroup.POST("", securityQuestionAdminHandler.CreateQuestion)
+				securityQuestionGroup.DELETE("/:id", securityQuestionAdminHandler.DeactivateQuestion)
+			}
+			reconciliationGroup := adminGroup.Group("/reconciliation")
+			{
+				reconciliationGroup.POST("", middleWare.RequirePermission(role.ReconciliationWrite), reconciliationHandler.RunReconciliation)
+				reconciliationGroup.GET("/reports", middleWare.RequirePermission(role.ReconciliationRead), reconciliationHandler.GetReconciliationReports)
+				reconciliationGroup.GET("/discrepancies", middleWare.RequirePermission(role.ReconciliationRead), reconciliationHandler.GetDiscrepancies)
+				reconciliationGroup.PUT("/discrepancies/:id/resolve", middleWare.RequirePermission(role.ReconciliationWrite), reconciliationHandler.ResolveDiscrepancy)
 			}
 		}
 	}
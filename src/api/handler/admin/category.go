@@ -7,12 +7,10 @@ import (
 	adminUseCase "DX/src/domain/usecase/admin"
 	"DX/src/pkg/response"
 	"DX/src/utils"
-	"DX/src/utils/logger"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"strconv"
-	"strings"
 )
 
 type Category interface {
@@ -27,6 +25,10 @@ type categoryImpl struct {
 	adminUseCase.CategoryUseCase
 }
 
+type categoryRequest struct {
+	Name string `json:"name" binding:"required,notblank,max=100"`
+}
+
 func NewAdminCategoryHandler(useCase adminUseCase.CategoryUseCase) Category {
 	return &categoryImpl{
 		CategoryUseCase: useCase,
@@ -34,12 +36,15 @@ func NewAdminCategoryHandler(useCase adminUseCase.CategoryUseCase) Category {
 }
 
 func (a *categoryImpl) CreateCategory(ctx *gin.Context) {
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
+	principal, ok := handler.Principal(ctx)
+	if !ok {
+		return
+	}
 	var files []*file.File
 
 	form, err := ctx.MultipartForm()
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		handler.Error(ctx, handler.ErrInvalidForm.WithCause(err))
 		return
 	}
 	name := ctx.PostForm("name")
@@ -69,9 +74,9 @@ func (a *categoryImpl) CreateCategory(ctx *gin.Context) {
 		files = file.NewListRequest(name, icon)
 	}
 
-	err = a.CategoryUseCase.CreateNewCategory(token, name, categoryType, files)
+	err = a.CategoryUseCase.CreateNewCategory(principal, name, categoryType, files)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		handler.Error(ctx, err)
 		return
 	}
 
@@ -79,27 +84,20 @@ func (a *categoryImpl) CreateCategory(ctx *gin.Context) {
 }
 
 func (a *categoryImpl) UpdateCategory(ctx *gin.Context) {
-	var payload handler.Payload
-	var name string
-	var ok bool
-
-	err := ctx.ShouldBind(&payload)
-	if err != nil {
-		logger.Error("UpdateCategory::", err)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("invalid request data"))
+	var request categoryRequest
+	if !handler.Bind(ctx, &request) {
 		return
 	}
 
-	if name, ok = payload["name"].(string); !ok {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("category name is required"))
+	categoryId := ctx.Param("id")
+	principal, ok := handler.Principal(ctx)
+	if !ok {
 		return
 	}
-	categoryId := ctx.Param("id")
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
 
-	err = a.CategoryUseCase.UpdateCategory(token, categoryId, name)
+	err := a.CategoryUseCase.UpdateCategory(principal, categoryId, request.Name)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		handler.Error(ctx, err)
 		return
 	}
 
@@ -118,7 +116,7 @@ func (a *categoryImpl) GetAllCategories(ctx *gin.Context) {
 	}
 
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		handler.Error(ctx, err)
 		return
 	}
 
@@ -126,12 +124,11 @@ func (a *categoryImpl) GetAllCategories(ctx *gin.Context) {
 }
 
 func (a *categoryImpl) DeleteCategory(ctx *gin.Context) {
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
 	categoryId := ctx.Param("id")
 
-	err := a.CategoryUseCase.DeleteCategory(token, categoryId)
+	err := a.CategoryUseCase.DeleteCategory(categoryId)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		handler.Error(ctx, err)
 		return
 	}
 
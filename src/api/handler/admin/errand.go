@@ -8,7 +8,6 @@ import (
 	"DX/src/pkg/response"
 	"github.com/gin-gonic/gin"
 	"net/http"
-	"strings"
 )
 
 type Errand interface {
@@ -24,6 +23,15 @@ type errandImpl struct {
 	errand.UseCase
 }
 
+type adminErrandRequest struct {
+	errandEntity.Request
+	UserId string `json:"user_id" binding:"required,notblank"`
+}
+
+type assignErrandRequest struct {
+	UserId string `json:"user_id" binding:"required,notblank"`
+}
+
 func NewAdminErrandHandler(adminUseCase admin.ErrandUseCase, useCase errand.UseCase) Errand {
 	return &errandImpl{
 		ErrandUseCase: adminUseCase,
@@ -32,29 +40,24 @@ func NewAdminErrandHandler(adminUseCase admin.ErrandUseCase, useCase errand.UseC
 }
 
 func (e *errandImpl) CreatErrand(ctx *gin.Context) {
-	var payload handler.Payload
-	var userId string
-	var ok bool
-	err := ctx.ShouldBind(&payload)
-	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+	var request adminErrandRequest
+	if !handler.Bind(ctx, &request) {
 		return
 	}
 
-	nErrand, err := errandEntity.ValidateErrandFromMap(payload, true)
+	nErrand, err := errandEntity.FromRequest(request.Request, true)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		handler.Error(ctx, err)
 		return
 	}
-	if userId, ok = payload["user_id"].(string); !ok {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("user id is required"))
+
+	principal, ok := handler.Principal(ctx)
+	if !ok {
 		return
 	}
-
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
-	err = e.ErrandUseCase.CreateErrand(token, userId, nErrand)
+	err = e.ErrandUseCase.CreateErrand(principal, request.UserId, nErrand)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		handler.Error(ctx, err)
 		return
 	}
 
@@ -66,18 +69,35 @@ func (e *errandImpl) AssignErrandToSender(ctx *gin.Context) {
 	panic("implement me")
 }
 
+// AssignErrandToRunner hands an errand the admin created to one of their offline runners.
 func (e *errandImpl) AssignErrandToRunner(ctx *gin.Context) {
-	//TODO implement me
-	panic("implement me")
+	var request assignErrandRequest
+	if !handler.Bind(ctx, &request) {
+		return
+	}
+	principal, ok := handler.Principal(ctx)
+	if !ok {
+		return
+	}
+
+	if err := e.ErrandUseCase.AssignErrand(principal, ctx.Param("id"), request.UserId); err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("errand assigned", nil))
 }
 
 func (e *errandImpl) GetErrand(ctx *gin.Context) {
 	errandId := ctx.Param("id")
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
+	principal, ok := handler.Principal(ctx)
+	if !ok {
+		return
+	}
 
-	nErrand, err := e.UseCase.GetErrand(token, errandId)
+	nErrand, err := e.UseCase.GetErrand(principal, errandId)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		handler.Error(ctx, err)
 		return
 	}
 
@@ -88,7 +108,7 @@ func (e *errandImpl) GetAllErrands(ctx *gin.Context) {
 	filter := ctx.Query("type")
 	errands, err := e.ErrandUseCase.GetAllErrands(filter)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		handler.Error(ctx, err)
 		return
 	}
 
@@ -0,0 +1,158 @@
+package admin
+
+import (
+	"DX/src/api/handler"
+	"DX/src/domain/entity/fee"
+	adminUseCase "DX/src/domain/usecase/admin"
+	"DX/src/pkg/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+type Fee interface {
+	GetFeeRules(*gin.Context)
+	SaveFeeRule(*gin.Context)
+	DeleteFeeRule(*gin.Context)
+	GetFeeWaivers(*gin.Context)
+	CreateFeeWaiver(*gin.Context)
+	DeleteFeeWaiver(*gin.Context)
+	GetRevenue(*gin.Context)
+}
+
+type feeImpl struct {
+	adminUseCase.FeeUseCase
+}
+
+type feeRuleRequest struct {
+	Kind       string   `json:"kind" binding:"required,oneof=service-fee commission"`
+	CategoryId string   `json:"category_id" binding:"omitempty,objectid"`
+	Percentage *float64 `json:"percentage" binding:"required,gte=0,lte=100"`
+	Flat       float64  `json:"flat" binding:"gte=0"`
+	Minimum    float64  `json:"minimum" binding:"gte=0"`
+}
+
+type feeWaiverRequest struct {
+	Kind        string `json:"kind" binding:"required,oneof=service-fee commission"`
+	CategoryId  string `json:"category_id" binding:"omitempty,objectid"`
+	Description string `json:"description" binding:"max=500"`
+	StartsAt    string `json:"starts_at" binding:"required,datetime=2006-01-02T15:04:05Z07:00"`
+	EndsAt      string `json:"ends_at" binding:"required,datetime=2006-01-02T15:04:05Z07:00"`
+}
+
+func NewAdminFeeHandler(useCase adminUseCase.FeeUseCase) Fee {
+	return &feeImpl{
+		FeeUseCase: useCase,
+	}
+}
+
+func (f *feeImpl) GetFeeRules(ctx *gin.Context) {
+	rules, err := f.FeeUseCase.GetRules()
+	if err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("fee rules fetched", gin.H{
+		"rules":    rules,
+		"defaults": []fee.Rule{fee.DefaultRule(fee.ServiceFee), fee.DefaultRule(fee.Commission)},
+	}))
+}
+
+func (f *feeImpl) SaveFeeRule(ctx *gin.Context) {
+	var request feeRuleRequest
+	if !handler.Bind(ctx, &request) {
+		return
+	}
+	kind, err := fee.GetKind(request.Kind)
+	if err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	rule, err := fee.NewRule(kind, request.CategoryId, *request.Percentage, int64(request.Flat), int64(request.Minimum))
+	if err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	principal, ok := handler.Principal(ctx)
+	if !ok {
+		return
+	}
+	if err = f.FeeUseCase.SaveRule(principal, rule); err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("fee rule saved", rule))
+}
+
+func (f *feeImpl) DeleteFeeRule(ctx *gin.Context) {
+	if err := f.FeeUseCase.DeleteRule(ctx.Param("id")); err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("fee rule deleted", nil))
+}
+
+func (f *feeImpl) GetFeeWaivers(ctx *gin.Context) {
+	waivers, err := f.FeeUseCase.GetWaivers()
+	if err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("fee waivers fetched", waivers))
+}
+
+func (f *feeImpl) CreateFeeWaiver(ctx *gin.Context) {
+	var request feeWaiverRequest
+	if !handler.Bind(ctx, &request) {
+		return
+	}
+	kind, err := fee.GetKind(request.Kind)
+	if err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+	start, _ := time.Parse(time.RFC3339, request.StartsAt)
+	end, _ := time.Parse(time.RFC3339, request.EndsAt)
+
+	waiver, err := fee.NewWaiver(kind, request.CategoryId, request.Description, start, end)
+	if err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	principal, ok := handler.Principal(ctx)
+	if !ok {
+		return
+	}
+	if err = f.FeeUseCase.CreateWaiver(principal, waiver); err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response.NewCreatedResponse("fee waiver created", waiver))
+}
+
+func (f *feeImpl) DeleteFeeWaiver(ctx *gin.Context) {
+	if err := f.FeeUseCase.DeleteWaiver(ctx.Param("id")); err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("fee waiver deleted", nil))
+}
+
+func (f *feeImpl) GetRevenue(ctx *gin.Context) {
+	revenue, err := f.FeeUseCase.GetRevenue()
+	if err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("platform revenue fetched", revenue))
+}
@@ -0,0 +1,151 @@
+package admin
+
+import (
+	"DX/src/api/handler"
+	"DX/src/domain/entity/category"
+	errandEntity "DX/src/domain/entity/errand"
+	"DX/src/domain/entity/fee"
+	"DX/src/domain/entity/promo"
+	"DX/src/domain/entity/reconciliation"
+	"DX/src/domain/entity/refund"
+	"DX/src/domain/entity/role"
+	"DX/src/domain/entity/security"
+	"DX/src/domain/entity/user"
+	"DX/src/domain/entity/wallet"
+	"DX/src/pkg/openapi"
+	"net/http"
+)
+
+type feeRulesData struct {
+	Rules    []fee.Rule `json:"rules"`
+	Defaults []fee.Rule `json:"defaults"`
+}
+
+// Routes describes the admin routes, every one of them needs admin access and a passed two-factor check.
+var Routes = []openapi.Route{
+	{Method: http.MethodGet, Path: "/v1/admin/users", Id: "adminGetUsers", Summary: "Every user",
+		Permissions: permissions(role.UsersRead), Query: []openapi.Parameter{openapi.Query("type", "", "suspended", "deleted")},
+		Data: []user.User{}},
+	{Method: http.MethodGet, Path: "/v1/admin/errands", Id: "adminGetErrands", Summary: "Every errand",
+		Permissions: permissions(role.ErrandsRead), Query: []openapi.Parameter{openapi.Query("type", "", "open", "cancelled", "active", "draft")},
+		Data: []errandEntity.Errand{}},
+	{Method: http.MethodGet, Path: "/v1/admin/permissions", Id: "adminGetPermissions", Summary: "Permissions roles can grant",
+		Permissions: permissions(role.RolesWrite), Data: []role.Permission{}},
+
+	{Method: http.MethodGet, Path: "/v1/admin/user/:id", Id: "adminGetUser", Summary: "A user",
+		Permissions: permissions(role.UsersRead), Data: user.User{}},
+	{Method: http.MethodPost, Path: "/v1/admin/user", Id: "adminCreateUser", Summary: "Create a user", Status: http.StatusCreated,
+		Permissions: permissions(role.UsersWrite), Request: user.AdminUser{}},
+	{Method: http.MethodPatch, Path: "/v1/admin/user", Id: "adminUpdateUser", Summary: "Update a user",
+		Permissions: permissions(role.UsersWrite), Request: user.AdminUserUpdate{}},
+	{Method: http.MethodPatch, Path: "/v1/admin/user/restore/:id", Id: "adminRestoreUser", Summary: "Restore a suspended or deleted user",
+		Permissions: permissions(role.UsersWrite)},
+	{Method: http.MethodPut, Path: "/v1/admin/user/unlock/:id", Id: "adminUnlockUser", Summary: "Unlock the transaction pin of a user",
+		Permissions: permissions(role.UsersSuspend)},
+	{Method: http.MethodPut, Path: "/v1/admin/user/suspend/:id", Id: "adminSuspendUser", Summary: "Suspend a user",
+		Permissions: permissions(role.UsersSuspend)},
+	{Method: http.MethodPut, Path: "/v1/admin/user/:id/roles", Id: "adminAssignRoles", Summary: "Replace the roles of a user",
+		Permissions: permissions(role.RolesWrite), Request: assignRolesRequest{}},
+	{Method: http.MethodDelete, Path: "/v1/admin/user/:id", Id: "adminDeleteUser", Summary: "Delete a user",
+		Permissions: permissions(role.UsersDelete)},
+	{Method: http.MethodDelete, Path: "/v1/admin/user", Id: "adminDeleteUsers", Summary: "Delete users",
+		Permissions: permissions(role.UsersDelete), Request: usersRequest{}},
+	{Method: http.MethodPut, Path: "/v1/admin/user", Id: "adminSuspendUsers", Summary: "Suspend users",
+		Permissions: permissions(role.UsersSuspend), Request: usersRequest{}},
+	{Method: http.MethodGet, Path: "/v1/admin/user/:id/wallet/transactions", Id: "adminGetUserTransactions", Summary: "Page of a user's wallet transactions",
+		Permissions: permissions(role.WalletsRead), Query: handler.TransactionQuery, Data: wallet.Page{}},
+	{Method: http.MethodGet, Path: "/v1/admin/user/:id/wallet/statement", Id: "adminGetUserStatement", Summary: "Statement of a user's wallet",
+		Permissions: permissions(role.WalletsRead), Query: handler.StatementQuery, Data: wallet.Statement{}, Downloads: handler.StatementDownloads},
+
+	{Method: http.MethodGet, Path: "/v1/admin/roles", Id: "adminGetRoles", Summary: "Every role",
+		Permissions: permissions(role.RolesWrite), Data: []role.Role{}},
+	{Method: http.MethodPost, Path: "/v1/admin/roles", Id: "adminCreateRole", Summary: "Create a role", Status: http.StatusCreated,
+		Permissions: permissions(role.RolesWrite), Request: role.Request{}, Data: role.Role{}},
+	{Method: http.MethodPut, Path: "/v1/admin/roles/:name", Id: "adminUpdateRole", Summary: "Change the permissions of a role",
+		Permissions: permissions(role.RolesWrite), Request: role.Definition{}, Data: role.Role{}},
+	{Method: http.MethodDelete, Path: "/v1/admin/roles/:name", Id: "adminDeleteRole", Summary: "Delete a role",
+		Permissions: permissions(role.RolesWrite)},
+
+	{Method: http.MethodPost, Path: "/v1/admin/category", Id: "adminCreateCategory", Summary: "Create a category", Status: http.StatusCreated,
+		Permissions: permissions(role.CategoriesWrite), Form: []openapi.FormField{
+			{Name: "name"},
+			{Name: "type", Description: "task or service"},
+			{Name: "icon", Description: "one image", Files: true},
+		}},
+	{Method: http.MethodPut, Path: "/v1/admin/category/:id", Id: "adminUpdateCategory", Summary: "Rename a category",
+		Permissions: permissions(role.CategoriesWrite), Request: categoryRequest{}},
+	{Method: http.MethodGet, Path: "/v1/admin/category", Id: "adminGetCategories", Summary: "Every category",
+		Permissions: permissions(), Query: []openapi.Parameter{openapi.QueryOf("limit", "", "integer")}, Data: []category.Category{}},
+	{Method: http.MethodDelete, Path: "/v1/admin/category/:id", Id: "adminDeleteCategory", Summary: "Delete a category",
+		Permissions: permissions(role.CategoriesWrite)},
+
+	{Method: http.MethodPost, Path: "/v1/admin/errand", Id: "adminCreateErrand", Summary: "Create an errand for a user", Status: http.StatusCreated,
+		Permissions: permissions(role.ErrandsWrite), Request: adminErrandRequest{}},
+	{Method: http.MethodGet, Path: "/v1/admin/errand/:id", Id: "adminGetErrand", Summary: "An errand",
+		Permissions: permissions(role.ErrandsRead), Data: errandEntity.Errand{}},
+	{Method: http.MethodPut, Path: "/v1/admin/errand/:id/assign", Id: "adminAssignErrand", Summary: "Assign an errand to a runner",
+		Permissions: permissions(role.ErrandsAssign), Request: assignErrandRequest{}},
+
+	{Method: http.MethodGet, Path: "/v1/admin/fees", Id: "adminGetFeeRules", Summary: "Fee rules and the defaults used without one",
+		Permissions: permissions(role.FeesRead), Data: feeRulesData{}},
+	{Method: http.MethodPut, Path: "/v1/admin/fees", Id: "adminSaveFeeRule", Summary: "Create or replace the fee rule of a kind and category",
+		Permissions: permissions(role.FeesWrite), Request: feeRuleRequest{}, Data: fee.Rule{}},
+	{Method: http.MethodDelete, Path: "/v1/admin/fees/:id", Id: "adminDeleteFeeRule", Summary: "Delete a fee rule",
+		Permissions: permissions(role.FeesWrite)},
+	{Method: http.MethodGet, Path: "/v1/admin/fees/waivers", Id: "adminGetFeeWaivers", Summary: "Fee waivers",
+		Permissions: permissions(role.FeesRead), Data: []fee.Waiver{}},
+	{Method: http.MethodPost, Path: "/v1/admin/fees/waivers", Id: "adminCreateFeeWaiver", Summary: "Waive a fee for a period", Status: http.StatusCreated,
+		Permissions: permissions(role.FeesWrite), Request: feeWaiverRequest{}, Data: fee.Waiver{}},
+	{Method: http.MethodDelete, Path: "/v1/admin/fees/waivers/:id", Id: "adminDeleteFeeWaiver", Summary: "Delete a fee waiver",
+		Permissions: permissions(role.FeesWrite)},
+	{Method: http.MethodGet, Path: "/v1/admin/fees/revenue", Id: "adminGetRevenue", Summary: "Fees collected by the platform",
+		Permissions: permissions(role.FeesRead), Data: int64(0)},
+
+	{Method: http.MethodGet, Path: "/v1/admin/promos", Id: "adminGetPromos", Summary: "Every promo code",
+		Permissions: permissions(role.PromosWrite), Data: []promo.Promo{}},
+	{Method: http.MethodPost, Path: "/v1/admin/promos", Id: "adminCreatePromo", Summary: "Create a promo code", Status: http.StatusCreated,
+		Permissions: permissions(role.PromosWrite), Request: promoRequest{}, Data: promo.Promo{}},
+	{Method: http.MethodDelete, Path: "/v1/admin/promos/:id", Id: "adminDeactivatePromo", Summary: "Deactivate a promo code",
+		Permissions: permissions(role.PromosWrite)},
+	{Method: http.MethodPost, Path: "/v1/admin/promos/credits", Id: "adminGrantPromoCredit", Summary: "Credit a user's wallet",
+		Permissions: permissions(role.PromosWrite), Request: promoCreditRequest{}},
+
+	{Method: http.MethodGet, Path: "/v1/admin/refunds", Id: "adminGetRefunds", Summary: "Refunds of a user or errand",
+		Permissions: permissions(role.RefundsRead), Query: []openapi.Parameter{openapi.Query("user_id", ""), openapi.Query("errand_id", "")},
+		Data: []refund.Refund{}},
+	{Method: http.MethodPost, Path: "/v1/admin/refunds", Id: "adminIssueRefund", Summary: "Refund a transaction", Status: http.StatusCreated,
+		Permissions: permissions(role.RefundsWrite), Request: refundRequest{}, Data: refund.Refund{}},
+
+	{Method: http.MethodGet, Path: "/v1/admin/security-questions", Id: "adminGetSecurityQuestions", Summary: "Every security question",
+		Permissions: permissions(role.SecurityQuestionsWrite), Data: []security.Question{}},
+	{Method: http.MethodPost, Path: "/v1/admin/security-questions", Id: "adminCreateSecurityQuestion", Summary: "Add a security question",
+		Permissions: permissions(role.SecurityQuestionsWrite), Request: questionRequest{}, Data: security.Question{}},
+	{Method: http.MethodDelete, Path: "/v1/admin/security-questions/:id", Id: "adminDeactivateSecurityQuestion", Summary: "Deactivate a security question",
+		Permissions: permissions(role.SecurityQuestionsWrite)},
+
+	{Method: http.MethodPost, Path: "/v1/admin/reconciliation", Id: "adminRunReconciliation", Summary: "Reconcile the ledger, the previous day by default",
+		Permissions: permissions(role.ReconciliationWrite), Query: handler.DateRangeQuery, Data: reconciliation.Report{}},
+	{Method: http.MethodGet, Path: "/v1/admin/reconciliation/reports", Id: "adminGetReconciliationReports", Summary: "Reconciliation reports",
+		Permissions: permissions(role.ReconciliationRead), Data: []reconciliation.Report{}},
+	{Method: http.MethodGet, Path: "/v1/admin/reconciliation/discrepancies", Id: "adminGetDiscrepancies", Summary: "Discrepancies found by reconciliation",
+		Permissions: permissions(role.ReconciliationRead), Query: []openapi.Parameter{openapi.Query("state", "defaults to open", "open", "resolved")},
+		Data: []reconciliation.Discrepancy{}},
+	{Method: http.MethodPut, Path: "/v1/admin/reconciliation/discrepancies/:id/resolve", Id: "adminResolveDiscrepancy", Summary: "Resolve a discrepancy",
+		Permissions: permissions(role.ReconciliationWrite), Request: resolveDiscrepancyRequest{}},
+}
+
+func init() {
+	for index := range Routes {
+		Routes[index].Auth = openapi.User
+		Routes[index].Tag = "admin"
+	}
+}
+
+// permissions are the admin access permission and the permissions of the route.
+func permissions(values ...role.Permission) []string {
+	names := []string{string(role.AdminAccess)}
+	for _, value := range values {
+		names = append(names, string(value))
+	}
+	return names
+}
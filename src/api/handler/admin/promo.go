@@ -0,0 +1,119 @@
+package admin
+
+import (
+	"DX/src/api/handler"
+	"DX/src/domain/entity/promo"
+	adminUseCase "DX/src/domain/usecase/admin"
+	"DX/src/pkg/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type Promo interface {
+	CreatePromo(*gin.Context)
+	GetPromos(*gin.Context)
+	DeactivatePromo(*gin.Context)
+	GrantPromoCredit(*gin.Context)
+}
+
+type promoImpl struct {
+	adminUseCase.PromoUseCase
+}
+
+type promoRequest struct {
+	Code            string   `json:"code" binding:"required,notblank,max=32"`
+	DiscountType    string   `json:"discount_type" binding:"required,oneof=percentage flat"`
+	Value           float64  `json:"value" binding:"required,gt=0"`
+	MaxDiscount     float64  `json:"max_discount" binding:"gte=0"`
+	FirstErrandOnly bool     `json:"first_errand_only"`
+	CategoryIds     []string `json:"category_ids" binding:"dive,objectid"`
+	PerUserLimit    float64  `json:"per_user_limit" binding:"gte=0"`
+	UsageLimit      float64  `json:"usage_limit" binding:"gte=0"`
+	ExpiresAt       string   `json:"expires_at" binding:"required,datetime=2006-01-02T15:04:05Z07:00"`
+}
+
+type promoCreditRequest struct {
+	UserId      string  `json:"user_id" binding:"required,notblank"`
+	Amount      float64 `json:"amount" binding:"required,gt=0"`
+	Description string  `json:"description" binding:"max=200"`
+}
+
+func NewAdminPromoHandler(useCase adminUseCase.PromoUseCase) Promo {
+	return &promoImpl{
+		PromoUseCase: useCase,
+	}
+}
+
+func (p *promoImpl) CreatePromo(ctx *gin.Context) {
+	var request promoRequest
+	if !handler.Bind(ctx, &request) {
+		return
+	}
+	discountType, err := promo.GetDiscountType(request.DiscountType)
+	if err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+	expiry, _ := time.Parse(time.RFC3339, request.ExpiresAt)
+
+	nPromo, err := promo.NewPromo(request.Code, discountType, int64(request.Value), int64(request.MaxDiscount), request.FirstErrandOnly,
+		request.CategoryIds, int64(request.PerUserLimit), int64(request.UsageLimit), expiry)
+	if err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	principal, ok := handler.Principal(ctx)
+	if !ok {
+		return
+	}
+	if err = p.PromoUseCase.CreatePromo(principal, nPromo); err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response.NewCreatedResponse("promo created", nPromo))
+}
+
+func (p *promoImpl) GetPromos(ctx *gin.Context) {
+	promos, err := p.PromoUseCase.GetPromos()
+	if err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("promos fetched", promos))
+}
+
+func (p *promoImpl) DeactivatePromo(ctx *gin.Context) {
+	if err := p.PromoUseCase.DeactivatePromo(ctx.Param("id")); err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("promo deactivated", nil))
+}
+
+func (p *promoImpl) GrantPromoCredit(ctx *gin.Context) {
+	var request promoCreditRequest
+	if !handler.Bind(ctx, &request) {
+		return
+	}
+	description := strings.TrimSpace(request.Description)
+	if description == "" {
+		description = "Promotional credit"
+	}
+
+	principal, ok := handler.Principal(ctx)
+	if !ok {
+		return
+	}
+	if err := p.PromoUseCase.GrantCredit(principal, request.UserId, int64(request.Amount), description); err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("promo credit granted", nil))
+}
@@ -0,0 +1,91 @@
+package admin
+
+import (
+	"DX/src/api/handler"
+	adminUseCase "DX/src/domain/usecase/admin"
+	"DX/src/pkg/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type Reconciliation interface {
+	RunReconciliation(*gin.Context)
+	GetReconciliationReports(*gin.Context)
+	GetDiscrepancies(*gin.Context)
+	ResolveDiscrepancy(*gin.Context)
+}
+
+type reconciliationImpl struct {
+	adminUseCase.ReconciliationUseCase
+}
+
+type resolveDiscrepancyRequest struct {
+	Note string `json:"note" binding:"required,notblank,max=1000"`
+}
+
+func NewAdminReconciliationHandler(useCase adminUseCase.ReconciliationUseCase) Reconciliation {
+	return &reconciliationImpl{
+		ReconciliationUseCase: useCase,
+	}
+}
+
+func (r *reconciliationImpl) RunReconciliation(ctx *gin.Context) {
+	from, to, err := handler.DateRange(ctx)
+	if err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+	if from.IsZero() && to.IsZero() {
+		to = time.Now().UTC().Truncate(24 * time.Hour)
+		from = to.Add(-24 * time.Hour)
+		to = to.Add(-time.Nanosecond)
+	}
+
+	report, err := r.ReconciliationUseCase.Run(from, to)
+	if err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("reconciliation completed", report))
+}
+
+func (r *reconciliationImpl) GetReconciliationReports(ctx *gin.Context) {
+	reports, err := r.ReconciliationUseCase.GetReports()
+	if err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("reconciliation reports fetched", reports))
+}
+
+func (r *reconciliationImpl) GetDiscrepancies(ctx *gin.Context) {
+	discrepancies, err := r.ReconciliationUseCase.GetDiscrepancies(ctx.DefaultQuery("state", "open"))
+	if err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("discrepancies fetched", discrepancies))
+}
+
+func (r *reconciliationImpl) ResolveDiscrepancy(ctx *gin.Context) {
+	var request resolveDiscrepancyRequest
+	if !handler.Bind(ctx, &request) {
+		return
+	}
+
+	principal, ok := handler.Principal(ctx)
+	if !ok {
+		return
+	}
+	if err := r.ReconciliationUseCase.ResolveDiscrepancy(principal, ctx.Param("id"), strings.TrimSpace(request.Note)); err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("discrepancy resolved", nil))
+}
@@ -0,0 +1,60 @@
+package admin
+
+import (
+	"DX/src/api/handler"
+	refundUseCase "DX/src/domain/usecase/refund"
+	"DX/src/pkg/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+type Refund interface {
+	IssueRefund(*gin.Context)
+	GetRefunds(*gin.Context)
+}
+
+type refundImpl struct {
+	refundUseCase.UseCase
+}
+
+type refundRequest struct {
+	TransactionId string  `json:"transaction_id" binding:"required,notblank"`
+	Amount        float64 `json:"amount" binding:"required,gt=0"`
+	Reason        string  `json:"reason" binding:"required,notblank,max=500"`
+}
+
+func NewAdminRefundHandler(useCase refundUseCase.UseCase) Refund {
+	return &refundImpl{
+		UseCase: useCase,
+	}
+}
+
+func (r *refundImpl) IssueRefund(ctx *gin.Context) {
+	var request refundRequest
+	if !handler.Bind(ctx, &request) {
+		return
+	}
+
+	principal, ok := handler.Principal(ctx)
+	if !ok {
+		return
+	}
+	nRefund, err := r.UseCase.IssueManualRefund(principal, request.TransactionId, int64(request.Amount), strings.TrimSpace(request.Reason))
+	if err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response.NewCreatedResponse("refund issued", nRefund))
+}
+
+func (r *refundImpl) GetRefunds(ctx *gin.Context) {
+	refunds, err := r.UseCase.GetRefunds(ctx.Query("user_id"), ctx.Query("errand_id"))
+	if err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("refunds fetched", refunds))
+}
@@ -0,0 +1,109 @@
+package admin
+
+import (
+	"DX/src/api/handler"
+	"DX/src/domain/entity/role"
+	adminUseCase "DX/src/domain/usecase/admin"
+	"DX/src/pkg/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type Role interface {
+	GetPermissions(*gin.Context)
+	GetRoles(*gin.Context)
+	CreateRole(*gin.Context)
+	UpdateRole(*gin.Context)
+	DeleteRole(*gin.Context)
+	AssignRoles(*gin.Context)
+}
+
+type roleImpl struct {
+	adminUseCase.RoleUseCase
+}
+
+type assignRolesRequest struct {
+	Roles []string `json:"roles" binding:"required,max=20,dive,notblank"`
+}
+
+func NewAdminRoleHandler(useCase adminUseCase.RoleUseCase) Role {
+	return &roleImpl{
+		RoleUseCase: useCase,
+	}
+}
+
+func (r *roleImpl) GetPermissions(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, response.NewOkResponse("permissions fetched", role.Permissions))
+}
+
+func (r *roleImpl) GetRoles(ctx *gin.Context) {
+	roles, err := r.RoleUseCase.GetRoles()
+	if err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("roles fetched", roles))
+}
+
+func (r *roleImpl) CreateRole(ctx *gin.Context) {
+	var request role.Request
+	if !handler.Bind(ctx, &request) {
+		return
+	}
+	principal, ok := handler.Principal(ctx)
+	if !ok {
+		return
+	}
+
+	nRole, err := r.RoleUseCase.CreateRole(principal, request)
+	if err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response.NewCreatedResponse("role created", nRole))
+}
+
+func (r *roleImpl) UpdateRole(ctx *gin.Context) {
+	var request role.Definition
+	if !handler.Bind(ctx, &request) {
+		return
+	}
+
+	nRole, err := r.RoleUseCase.UpdateRole(ctx.Param("name"), request)
+	if err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("role updated", nRole))
+}
+
+func (r *roleImpl) DeleteRole(ctx *gin.Context) {
+	if err := r.RoleUseCase.DeleteRole(ctx.Param("name")); err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("role deleted", nil))
+}
+
+// AssignRoles replaces the roles of the user with the ones in the request.
+func (r *roleImpl) AssignRoles(ctx *gin.Context) {
+	var request assignRolesRequest
+	if !handler.Bind(ctx, &request) {
+		return
+	}
+	principal, ok := handler.Principal(ctx)
+	if !ok {
+		return
+	}
+
+	if err := r.RoleUseCase.AssignRoles(principal, ctx.Param("id"), request.Roles); err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("roles assigned", nil))
+}
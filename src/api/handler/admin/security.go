@@ -0,0 +1,67 @@
+package admin
+
+import (
+	"DX/src/api/handler"
+	adminUseCase "DX/src/domain/usecase/admin"
+	"DX/src/pkg/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type SecurityQuestion interface {
+	GetQuestions(*gin.Context)
+	CreateQuestion(*gin.Context)
+	DeactivateQuestion(*gin.Context)
+}
+
+type securityQuestionImpl struct {
+	adminUseCase.SecurityQuestionUseCase
+}
+
+type questionRequest struct {
+	Question string `json:"question" binding:"required,notblank,max=200"`
+}
+
+func NewAdminSecurityQuestionHandler(useCase adminUseCase.SecurityQuestionUseCase) SecurityQuestion {
+	return &securityQuestionImpl{
+		SecurityQuestionUseCase: useCase,
+	}
+}
+
+func (s *securityQuestionImpl) GetQuestions(ctx *gin.Context) {
+	questions, err := s.SecurityQuestionUseCase.GetQuestions()
+	if err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("security questions fetched", questions))
+}
+
+func (s *securityQuestionImpl) CreateQuestion(ctx *gin.Context) {
+	var request questionRequest
+	if !handler.Bind(ctx, &request) {
+		return
+	}
+	principal, ok := handler.Principal(ctx)
+	if !ok {
+		return
+	}
+
+	question, err := s.SecurityQuestionUseCase.CreateQuestion(principal, request.Question)
+	if err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("security question created", question))
+}
+
+func (s *securityQuestionImpl) DeactivateQuestion(ctx *gin.Context) {
+	if err := s.SecurityQuestionUseCase.DeactivateQuestion(ctx.Param("id")); err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("security question deactivated", nil))
+}
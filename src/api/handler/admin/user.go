@@ -8,7 +8,6 @@ import (
 	"DX/src/utils/logger"
 	"github.com/gin-gonic/gin"
 	"net/http"
-	"strings"
 )
 
 type User interface {
@@ -18,6 +17,7 @@ type User interface {
 	SuspendUser(*gin.Context)
 	SuspendUsers(*gin.Context)
 	RestoreUser(*gin.Context)
+	UnlockUser(*gin.Context)
 	DeleteUser(*gin.Context)
 	DeleteUsers(*gin.Context)
 	CreateUser(*gin.Context)
@@ -34,27 +34,25 @@ func NewAdminUserHandler(UseCase adminUseCase.UserUseCase) User {
 	}
 }
 
+type usersRequest struct {
+	Users []string `json:"users" binding:"required,min=1,max=100,dive,notblank"`
+}
+
 func (i *impl) CreateUser(ctx *gin.Context) {
-	var payload handler.Payload
-	err := ctx.ShouldBind(&payload)
-	if err != nil {
-		logger.Error("CreateUser::", err)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("invalid request data"))
+	var request user.AdminUser
+	if !handler.Bind(ctx, &request) {
 		return
 	}
+	nUser := user.CreateForAdmin(request)
 
-	nUser, err := user.CreateForAdmin(payload)
-	if err != nil {
-		logger.Error("CreateUser::", err)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+	principal, ok := handler.Principal(ctx)
+	if !ok {
 		return
 	}
-
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
-	err = i.UserUseCase.CreateNewUser(token, nUser)
+	err := i.UserUseCase.CreateNewUser(principal, nUser)
 	if err != nil {
 		logger.Error("CreateUser::", err)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		handler.Error(ctx, err)
 		return
 	}
 
@@ -64,11 +62,14 @@ func (i *impl) CreateUser(ctx *gin.Context) {
 func (i *impl) SuspendUser(ctx *gin.Context) {
 	userId := ctx.Param("id")
 
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
-	err := i.UserUseCase.SuspendUser(token, userId)
+	principal, ok := handler.Principal(ctx)
+	if !ok {
+		return
+	}
+	err := i.UserUseCase.SuspendUser(principal, userId)
 	if err != nil {
 		logger.Error("SuspendUser::", err)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		handler.Error(ctx, err)
 		return
 	}
 
@@ -76,29 +77,19 @@ func (i *impl) SuspendUser(ctx *gin.Context) {
 }
 
 func (i *impl) SuspendUsers(ctx *gin.Context) {
-	var payload handler.Payload
-	var users []string
-	err := ctx.ShouldBind(&payload)
-	if err != nil {
-		logger.Error("SuspendUsers::", err)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("invalid request data"))
+	var request usersRequest
+	if !handler.Bind(ctx, &request) {
 		return
 	}
 
-	if tUsers, ok := payload["users"].([]interface{}); !ok {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("users list is required"))
+	principal, ok := handler.Principal(ctx)
+	if !ok {
 		return
-	} else {
-		for _, cUser := range tUsers {
-			users = append(users, cUser.(string))
-		}
 	}
-
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
-	err = i.UserUseCase.SuspendUsers(token, users)
+	err := i.UserUseCase.SuspendUsers(principal, request.Users)
 	if err != nil {
 		logger.Error("SuspendUsers::", err)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		handler.Error(ctx, err)
 		return
 	}
 
@@ -108,39 +99,48 @@ func (i *impl) SuspendUsers(ctx *gin.Context) {
 func (i *impl) RestoreUser(ctx *gin.Context) {
 	userId := ctx.Param("id")
 
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
-	err := i.UserUseCase.RestoreUser(token, userId)
+	principal, ok := handler.Principal(ctx)
+	if !ok {
+		return
+	}
+	err := i.UserUseCase.RestoreUser(principal, userId)
 	if err != nil {
 		logger.Error("RestoreUser::", err)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		handler.Error(ctx, err)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, response.NewOkResponse("user successfully updated", nil))
 }
 
-func (i *impl) UpdateUser(ctx *gin.Context) {
-	var payload handler.Payload
-	err := ctx.ShouldBind(&payload)
+func (i *impl) UnlockUser(ctx *gin.Context) {
+	err := i.UserUseCase.UnlockUser(ctx.Param("id"))
 	if err != nil {
-		logger.Error("UpdateUser::", err)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("invalid request data"))
+		logger.Error("UnlockUser::", err)
+		handler.Error(ctx, err)
 		return
 	}
 
-	userId := ctx.Param("id")
-	nUser, err := user.UpdateForAdmin(payload)
-	if err != nil {
-		logger.Error("UpdateUser::", err)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+	ctx.JSON(http.StatusOK, response.NewOkResponse("user successfully unlocked", nil))
+}
+
+func (i *impl) UpdateUser(ctx *gin.Context) {
+	var request user.AdminUserUpdate
+	if !handler.Bind(ctx, &request) {
 		return
 	}
 
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
-	err = i.UserUseCase.UpdateUser(token, userId, nUser)
+	userId := ctx.Param("id")
+	nUser := user.UpdateForAdmin(request)
+
+	principal, ok := handler.Principal(ctx)
+	if !ok {
+		return
+	}
+	err := i.UserUseCase.UpdateUser(principal, userId, nUser)
 	if err != nil {
 		logger.Error("UpdateUser::", err)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		handler.Error(ctx, err)
 		return
 	}
 
@@ -153,7 +153,7 @@ func (i *impl) GetUser(ctx *gin.Context) {
 	nUser, err := i.UserUseCase.GetUser(userId)
 	if err != nil {
 		logger.Error("GetUser::", err)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		handler.Error(ctx, err)
 		return
 	}
 
@@ -164,7 +164,7 @@ func (i *impl) GetAllUsers(ctx *gin.Context) {
 	filter := ctx.Query("type")
 	users, err := i.UserUseCase.GetAllUsers(filter)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		handler.Error(ctx, err)
 		return
 	}
 
@@ -172,12 +172,15 @@ func (i *impl) GetAllUsers(ctx *gin.Context) {
 }
 
 func (i *impl) DeleteUser(ctx *gin.Context) {
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
+	principal, ok := handler.Principal(ctx)
+	if !ok {
+		return
+	}
 	userId := ctx.Param("id")
 
-	err := i.UserUseCase.DeleteUser(token, userId)
+	err := i.UserUseCase.DeleteUser(principal, userId)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		handler.Error(ctx, err)
 		return
 	}
 
@@ -185,29 +188,19 @@ func (i *impl) DeleteUser(ctx *gin.Context) {
 }
 
 func (i *impl) DeleteUsers(ctx *gin.Context) {
-	var payload handler.Payload
-	var users []string
-	err := ctx.ShouldBind(&payload)
-	if err != nil {
-		logger.Error("DeleteUsers::", err)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("invalid request data"))
+	var request usersRequest
+	if !handler.Bind(ctx, &request) {
 		return
 	}
 
-	if tUsers, ok := payload["users"].([]interface{}); !ok {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("users list is required"))
+	principal, ok := handler.Principal(ctx)
+	if !ok {
 		return
-	} else {
-		for _, cUser := range tUsers {
-			users = append(users, cUser.(string))
-		}
 	}
-
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
-	err = i.UserUseCase.DeleteUsers(token, users)
+	err := i.UserUseCase.DeleteUsers(principal, request.Users)
 	if err != nil {
 		logger.Error("DeleteUsers::", err)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		handler.Error(ctx, err)
 		return
 	}
 
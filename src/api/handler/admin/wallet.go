@@ -0,0 +1,59 @@
+package admin
+
+import (
+	"DX/src/api/handler"
+	"DX/src/domain/usecase/wallet"
+	"DX/src/pkg/response"
+	"DX/src/pkg/statement_service"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type Wallet interface {
+	GetUserTransactions(*gin.Context)
+	GetUserStatement(*gin.Context)
+}
+
+type walletImpl struct {
+	wallet.UseCase
+	StatementService statement_service.Service
+}
+
+func NewAdminWalletHandler(useCase wallet.UseCase, statementService statement_service.Service) Wallet {
+	return &walletImpl{
+		UseCase:          useCase,
+		StatementService: statementService,
+	}
+}
+
+func (w *walletImpl) GetUserTransactions(ctx *gin.Context) {
+	filter, err := handler.TransactionFilter(ctx)
+	if err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	page, err := w.UseCase.GetUserTransactions(ctx.Param("id"), filter)
+	if err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("transactions fetched", page))
+}
+
+func (w *walletImpl) GetUserStatement(ctx *gin.Context) {
+	from, to, err := handler.DateRange(ctx)
+	if err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	statement, err := w.UseCase.GetUserStatement(ctx.Param("id"), from, to)
+	if err != nil {
+		handler.Error(ctx, err)
+		return
+	}
+
+	handler.WriteStatement(ctx, w.StatementService, statement)
+}
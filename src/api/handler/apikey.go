@@ -0,0 +1,72 @@
+package handler
+
+import (
+	apiKeyEntity "DX/src/domain/entity/apikey"
+	"DX/src/domain/usecase/apikey"
+	"DX/src/pkg/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type ApiKey interface {
+	CreateKey(*gin.Context)
+	GetKeys(*gin.Context)
+	RevokeKey(*gin.Context)
+}
+
+type apiKeyImpl struct {
+	apikey.UseCase
+}
+
+func NewApiKeyHandler(useCase apikey.UseCase) ApiKey {
+	return &apiKeyImpl{
+		UseCase: useCase,
+	}
+}
+
+// CreateKey issues a key with a name, scopes and optionally a rate_limit in requests a minute. The key is
+// only in this response.
+func (a *apiKeyImpl) CreateKey(ctx *gin.Context) {
+	var request apiKeyEntity.Request
+	if !Bind(ctx, &request) {
+		return
+	}
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+
+	created, err := a.UseCase.CreateKey(principal, request)
+	if err != nil {
+		Error(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, response.NewCreatedResponse("api key created, store it now as it will not be shown again", created))
+}
+
+func (a *apiKeyImpl) GetKeys(ctx *gin.Context) {
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+
+	apiKeys, err := a.UseCase.GetKeys(principal)
+	if err != nil {
+		Error(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response.NewOkResponse("api keys fetched", apiKeys))
+}
+
+func (a *apiKeyImpl) RevokeKey(ctx *gin.Context) {
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+
+	if err := a.UseCase.RevokeKey(principal, ctx.Param("id")); err != nil {
+		Error(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response.NewOkResponse("api key revoked", nil))
+}
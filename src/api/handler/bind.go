@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"DX/src/pkg/response"
+	"DX/src/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/go-playground/validator/v10/non-standard/validators"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"reflect"
+	"strings"
+)
+
+func init() {
+	engine, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	// report fields by the names clients send them with
+	engine.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	_ = engine.RegisterValidation("notblank", validators.NotBlank)
+	_ = engine.RegisterValidation("phone", func(fl validator.FieldLevel) bool {
+		return utils.IsValidPhoneNumber(fl.Field().String())
+	})
+	_ = engine.RegisterValidation("objectid", func(fl validator.FieldLevel) bool {
+		return primitive.IsValidObjectID(fl.Field().String())
+	})
+}
+
+// Bind decodes the JSON body into the request and validates it, responding with every invalid field
+// when it fails.
+func Bind(ctx *gin.Context, request interface{}) bool {
+	err := ctx.ShouldBindJSON(request)
+	if err == nil {
+		return true
+	}
+	resp := bindError(err)
+	ctx.AbortWithStatusJSON(resp.Code, resp)
+	return false
+}
+
+func bindError(err error) *response.BaseResponse {
+	var validationErrors validator.ValidationErrors
+	var typeError *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &validationErrors):
+		fields := make([]response.FieldError, 0, len(validationErrors))
+		for _, fieldError := range validationErrors {
+			fields = append(fields, response.FieldError{Field: fieldName(fieldError), Message: fieldMessage(fieldError)})
+		}
+		return response.NewValidationError(fields)
+	case errors.As(err, &typeError) && typeError.Field == "":
+		return response.NewBadRequestError("request body must be an object")
+	case errors.As(err, &typeError):
+		return response.NewValidationError([]response.FieldError{
+			{Field: typeError.Field, Message: "must be " + jsonType(typeError.Type)},
+		})
+	default:
+		return response.NewBadRequestError("invalid request data")
+	}
+}
+
+// fieldName is the path of the field in the body. It leaves out the request struct and embedded structs,
+// whose fields are decoded from the same level, which are the only segments without a json name.
+func fieldName(fieldError validator.FieldError) string {
+	names := strings.Split(fieldError.Namespace(), ".")
+	fields := strings.Split(fieldError.StructNamespace(), ".")
+	path := make([]string, 0, len(names))
+	for index := 1; index < len(names); index++ {
+		if index < len(names)-1 && index < len(fields) && names[index] == fields[index] {
+			continue
+		}
+		path = append(path, names[index])
+	}
+	return strings.Join(path, ".")
+}
+
+func fieldMessage(fieldError validator.FieldError) string {
+	param := fieldError.Param()
+	switch fieldError.Tag() {
+	case "required", "notblank", "required_with", "required_without", "required_if":
+		return "is required"
+	case "min":
+		if fieldError.Kind() == reflect.String {
+			return fmt.Sprintf("must be at least %s characters", param)
+		}
+		if fieldError.Kind() == reflect.Slice || fieldError.Kind() == reflect.Map {
+			return fmt.Sprintf("must have at least %s items", param)
+		}
+		return "must be at least " + param
+	case "max":
+		if fieldError.Kind() == reflect.String {
+			return fmt.Sprintf("must be at most %s characters", param)
+		}
+		if fieldError.Kind() == reflect.Slice || fieldError.Kind() == reflect.Map {
+			return fmt.Sprintf("must have at most %s items", param)
+		}
+		return "must be at most " + param
+	case "len":
+		if fieldError.Kind() == reflect.String {
+			return fmt.Sprintf("must be %s characters", param)
+		}
+		return fmt.Sprintf("must have %s items", param)
+	case "gt":
+		return "must be greater than " + param
+	case "gte":
+		return "must be at least " + param
+	case "lt":
+		return "must be less than " + param
+	case "lte":
+		return "must be at most " + param
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(param, " ", ", ")
+	case "numeric":
+		return "must contain only digits"
+	case "email":
+		return "must be a valid email address"
+	case "phone":
+		return "must be a valid phone number"
+	case "objectid":
+		return "must be a valid id"
+	case "url", "https_url":
+		return "must be a valid url"
+	case "latitude":
+		return "must be a valid latitude"
+	case "longitude":
+		return "must be a valid longitude"
+	case "unique":
+		return "must not contain duplicates"
+	case "datetime":
+		return "must be a date formatted as " + param
+	}
+	return "is invalid"
+}
+
+func jsonType(kind reflect.Type) string {
+	switch kind.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "a whole number"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "a list"
+	}
+	return "an object"
+}
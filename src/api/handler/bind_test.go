@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"DX/src/pkg/response"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type bindStop struct {
+	Street    string  `json:"street" binding:"required"`
+	Latitude  float64 `json:"latitude" binding:"omitempty,latitude"`
+	Reference string  `json:"-"`
+}
+
+type bindContact struct {
+	Name string `json:"name" binding:"required,notblank,max=5"`
+}
+
+type bindRequest struct {
+	bindContact
+	Pickup bindStop   `json:"pickup"`
+	Stops  []bindStop `json:"stops" binding:"max=2,dive"`
+	Tags   []string   `json:"tags" binding:"dive,notblank"`
+	Amount int64      `json:"amount" binding:"gte=0"`
+	Rating *int       `json:"rating" binding:"omitempty,min=1,max=5"`
+}
+
+// bindBody runs Bind on the body the way a handler does and returns the response it wrote.
+func bindBody(t *testing.T, body string, request interface{}) (bool, *httptest.ResponseRecorder) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	return Bind(ctx, request), recorder
+}
+
+func TestBind(t *testing.T) {
+	valid := `{"name":"Ada","pickup":{"street":"1 Main St"}}`
+	tests := []struct {
+		name    string
+		body    string
+		code    string
+		message string
+		fields  map[string]string
+	}{
+		{name: "valid", body: valid},
+		{
+			name:   "embedded",
+			body:   `{"name":"  ","pickup":{"street":"1 Main St"}}`,
+			code:   "validation_failed",
+			fields: map[string]string{"name": "is required"},
+		},
+		{
+			name:   "nested",
+			body:   `{"name":"Ada","pickup":{"latitude":91}}`,
+			code:   "validation_failed",
+			fields: map[string]string{"pickup.street": "is required", "pickup.latitude": "must be a valid latitude"},
+		},
+		{
+			name:   "dive into structs",
+			body:   `{"name":"Ada","pickup":{"street":"1 Main St"},"stops":[{"street":"2 Main St"},{}]}`,
+			code:   "validation_failed",
+			fields: map[string]string{"stops[1].street": "is required"},
+		},
+		{
+			name:   "dive into values",
+			body:   `{"name":"Ada","pickup":{"street":"1 Main St"},"tags":["fragile",""]}`,
+			code:   "validation_failed",
+			fields: map[string]string{"tags[1]": "is required"},
+		},
+		{
+			name: "several fields",
+			body: `{"name":"Adaline","pickup":{"street":"1 Main St"},"stops":[{"street":"a"},{"street":"b"},{"street":"c"}],"amount":-1,"rating":9}`,
+			code: "validation_failed",
+			fields: map[string]string{
+				"name":   "must be at most 5 characters",
+				"stops":  "must have at most 2 items",
+				"amount": "must be at least 0",
+				"rating": "must be at most 5",
+			},
+		},
+		{
+			name:   "wrong type",
+			body:   `{"name":"Ada","pickup":{"street":"1 Main St"},"amount":"500"}`,
+			code:   "validation_failed",
+			fields: map[string]string{"amount": "must be a whole number"},
+		},
+		{
+			name:   "wrong type in nested object",
+			body:   `{"name":"Ada","pickup":{"street":"1 Main St","latitude":"north"}}`,
+			code:   "validation_failed",
+			fields: map[string]string{"pickup.latitude": "must be a number"},
+		},
+		{
+			name:   "object instead of list",
+			body:   `{"name":"Ada","pickup":{"street":"1 Main St"},"stops":{}}`,
+			code:   "validation_failed",
+			fields: map[string]string{"stops": "must be a list"},
+		},
+		{name: "list body", body: `[]`, code: "invalid_request", message: "request body must be an object"},
+		{name: "string body", body: `"name"`, code: "invalid_request", message: "request body must be an object"},
+		{name: "syntax error", body: `{"name":`, code: "invalid_request", message: "invalid request data"},
+		{name: "empty body", body: ``, code: "invalid_request", message: "invalid request data"},
+		{
+			name:   "number overflow",
+			body:   `{"name":"Ada","pickup":{"street":"1 Main St"},"amount":1e400}`,
+			code:   "validation_failed",
+			fields: map[string]string{"amount": "must be a whole number"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ok, recorder := bindBody(t, test.body, &bindRequest{})
+			if ok != (test.code == "") {
+				t.Fatalf("Bind() = %v, response %s", ok, recorder.Body)
+			}
+			if ok {
+				return
+			}
+			var resp struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+				Data    struct {
+					Errors []struct {
+						Field   string `json:"field"`
+						Message string `json:"message"`
+					} `json:"errors"`
+				} `json:"data"`
+			}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if recorder.Code != http.StatusBadRequest || resp.Code != test.code {
+				t.Fatalf("response = %d %q, want %d %q", recorder.Code, resp.Code, http.StatusBadRequest, test.code)
+			}
+			if test.message != "" && resp.Message != test.message {
+				t.Fatalf("message = %q, want %q", resp.Message, test.message)
+			}
+			fields := map[string]string{}
+			for _, fieldError := range resp.Data.Errors {
+				fields[fieldError.Field] = fieldError.Message
+			}
+			if len(fields) != len(test.fields) {
+				t.Fatalf("errors = %v, want %v", fields, test.fields)
+			}
+			for field, message := range test.fields {
+				if fields[field] != message {
+					t.Fatalf("errors = %v, want %v", fields, test.fields)
+				}
+			}
+		})
+	}
+}
+
+func TestFieldMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		request interface{}
+		message string
+	}{
+		{name: "required", request: &struct {
+			Value string `json:"value" binding:"required"`
+		}{}, message: "is required"},
+		{name: "short string", request: &struct {
+			Value string `json:"value" binding:"min=3"`
+		}{Value: "a"}, message: "must be at least 3 characters"},
+		{name: "short list", request: &struct {
+			Value []string `json:"value" binding:"min=1"`
+		}{}, message: "must have at least 1 items"},
+		{name: "small number", request: &struct {
+			Value int `json:"value" binding:"min=10"`
+		}{Value: 1}, message: "must be at least 10"},
+		{name: "long string", request: &struct {
+			Value string `json:"value" binding:"max=2"`
+		}{Value: "abc"}, message: "must be at most 2 characters"},
+		{name: "length", request: &struct {
+			Value string `json:"value" binding:"len=4"`
+		}{Value: "abc"}, message: "must be 4 characters"},
+		{name: "greater than", request: &struct {
+			Value float64 `json:"value" binding:"gt=0"`
+		}{}, message: "must be greater than 0"},
+		{name: "one of", request: &struct {
+			Value string `json:"value" binding:"oneof=percentage flat"`
+		}{Value: "free"}, message: "must be one of percentage, flat"},
+		{name: "numeric", request: &struct {
+			Value string `json:"value" binding:"numeric"`
+		}{Value: "12a"}, message: "must contain only digits"},
+		{name: "email", request: &struct {
+			Value string `json:"value" binding:"email"`
+		}{Value: "ada"}, message: "must be a valid email address"},
+		{name: "phone", request: &struct {
+			Value string `json:"value" binding:"phone"`
+		}{Value: "call me"}, message: "must be a valid phone number"},
+		{name: "object id", request: &struct {
+			Value string `json:"value" binding:"objectid"`
+		}{Value: "errand-1"}, message: "must be a valid id"},
+		{name: "url", request: &struct {
+			Value string `json:"value" binding:"url"`
+		}{Value: "partner"}, message: "must be a valid url"},
+		{name: "unique", request: &struct {
+			Value []string `json:"value" binding:"unique"`
+		}{Value: []string{"a", "a"}}, message: "must not contain duplicates"},
+		{name: "datetime", request: &struct {
+			Value string `json:"value" binding:"datetime=2006-01-02"`
+		}{Value: "tomorrow"}, message: "must be a date formatted as 2006-01-02"},
+		{name: "other tag", request: &struct {
+			Value string `json:"value" binding:"alpha"`
+		}{Value: "a1"}, message: "is invalid"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := binding.Validator.ValidateStruct(test.request)
+			if err == nil {
+				t.Fatal("request is valid")
+			}
+			resp := bindError(err)
+			fields := resp.Data.(map[string][]response.FieldError)["errors"]
+			if len(fields) != 1 || fields[0].Message != test.message {
+				t.Fatalf("errors = %v, want %q", fields, test.message)
+			}
+		})
+	}
+}
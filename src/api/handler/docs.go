@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"DX/src/pkg/openapi"
+	_ "embed"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+//go:embed docs.html
+var viewer []byte
+
+type Docs interface {
+	GetSpec(*gin.Context)
+	GetViewer(*gin.Context)
+}
+
+type docsImpl struct {
+	spec []byte
+}
+
+func NewDocsHandler(document *openapi.Document) Docs {
+	spec, err := json.Marshal(document)
+	if err != nil {
+		panic(err)
+	}
+	return &docsImpl{
+		spec: spec,
+	}
+}
+
+func (d *docsImpl) GetSpec(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.Data(http.StatusOK, "application/json; charset=utf-8", d.spec)
+}
+
+// GetViewer serves a page that renders the spec, it loads nothing but the spec so it works offline.
+func (d *docsImpl) GetViewer(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", viewer)
+}
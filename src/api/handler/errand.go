@@ -32,6 +32,7 @@ type Errand interface {
 	PostUpdate(*gin.Context)
 	StartErrand(*gin.Context)
 	RejectErrandContract(*gin.Context)
+	TipRunner(*gin.Context)
 }
 
 type errand struct {
@@ -39,6 +40,41 @@ type errand struct {
 	FileUseCase file.UseCase
 }
 
+type createErrandRequest struct {
+	errandEntity.Request
+	TransactionPin string `json:"transaction_pin"`
+	PromoCode      string `json:"promo_code" binding:"max=32"`
+}
+
+type cancelErrandRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+type completeErrandRequest struct {
+	Source string `json:"source" binding:"required,oneof=sender runner"`
+}
+
+type startErrandRequest struct {
+	ErrandId string `json:"errand_id" binding:"required,objectid"`
+	BidId    string `json:"bid_id" binding:"required,objectid"`
+}
+
+type bidResponseRequest struct {
+	Response string  `json:"response" binding:"required,oneof=accept reject"`
+	Amount   float64 `json:"amount" binding:"required_if=Response accept,gte=0"`
+	RunnerId string  `json:"runner_id" binding:"required,notblank"`
+}
+
+type timelineUpdateRequest struct {
+	ErrandId string `json:"errand_id" binding:"required,objectid"`
+	Message  string `json:"message" binding:"required,notblank,max=1000"`
+}
+
+type tipRequest struct {
+	Amount         float64 `json:"amount" binding:"required,gt=0"`
+	TransactionPin string  `json:"transaction_pin"`
+}
+
 func NewErrandHandler(useCase errandUseCase.UseCase, fileUseCase file.UseCase) Errand {
 	return &errand{
 		UseCase:     useCase,
@@ -47,11 +83,14 @@ func NewErrandHandler(useCase errandUseCase.UseCase, fileUseCase file.UseCase) E
 }
 
 func (e *errand) GetDraftErrand(ctx *gin.Context) {
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
 
-	nErrand, err := e.UseCase.CreateDraftErrand(token)
+	nErrand, err := e.UseCase.CreateDraftErrand(principal)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	}
 
@@ -59,11 +98,14 @@ func (e *errand) GetDraftErrand(ctx *gin.Context) {
 }
 
 func (e *errand) UploadErrandFiles(ctx *gin.Context) {
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
 
 	form, err := ctx.MultipartForm()
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, ErrInvalidForm.WithCause(err))
 		return
 	}
 
@@ -88,10 +130,10 @@ func (e *errand) UploadErrandFiles(ctx *gin.Context) {
 
 	listReq := fileUtil.NewListRequest(reqType, files)
 
-	resp, err := e.FileUseCase.UploadFiles(token, errandId, listReq)
+	resp, err := e.FileUseCase.UploadFiles(principal, errandId, listReq)
 
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	}
 
@@ -99,24 +141,25 @@ func (e *errand) UploadErrandFiles(ctx *gin.Context) {
 }
 
 func (e *errand) UpdateErrand(ctx *gin.Context) {
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
 
-	var payload Payload
-	err := ctx.ShouldBind(&payload)
-	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+	var request errandEntity.Request
+	if !Bind(ctx, &request) {
 		return
 	}
 
-	nErrand, err := errandEntity.ValidateErrandFromMap(payload, false)
+	nErrand, err := errandEntity.FromRequest(request, false)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	}
 
-	err = e.UseCase.UpdateErrand(token, nErrand)
+	err = e.UseCase.UpdateErrand(principal, nErrand)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	}
 
@@ -124,21 +167,21 @@ func (e *errand) UpdateErrand(ctx *gin.Context) {
 }
 
 func (e *errand) CancelErrand(ctx *gin.Context) {
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
 	errandId := ctx.Param("id")
 
-	var payload Payload
-	err := ctx.ShouldBind(&payload)
-	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+	var request cancelErrandRequest
+	if !Bind(ctx, &request) {
 		return
 	}
 
-	reason := payload["reason"].(string)
-	err = e.UseCase.CancelErrand(token, errandId, reason)
+	err := e.UseCase.CancelErrand(principal, errandId, request.Reason)
 
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	}
 
@@ -146,30 +189,20 @@ func (e *errand) CancelErrand(ctx *gin.Context) {
 }
 
 func (e *errand) CompleteErrand(ctx *gin.Context) {
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
-	errandId := ctx.Param("id")
-	var source string
-	var ok bool
-
-	var payload Payload
-	err := ctx.ShouldBind(&payload)
-	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+	principal, ok := Principal(ctx)
+	if !ok {
 		return
 	}
-	if source, ok = payload["source"].(string); !ok {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("source is required"))
+	errandId := ctx.Param("id")
+
+	var request completeErrandRequest
+	if !Bind(ctx, &request) {
 		return
-	} else {
-		if source != "sender" && source != "runner" {
-			ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("invalid source type"))
-			return
-		}
 	}
 
-	err = e.UseCase.CompleteErrand(token, errandId, source)
+	err := e.UseCase.CompleteErrand(principal, errandId, request.Source)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	}
 
@@ -177,18 +210,19 @@ func (e *errand) CompleteErrand(ctx *gin.Context) {
 }
 
 func (e *errand) CreateErrand(ctx *gin.Context) {
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
 
-	var payload Payload
-	err := ctx.ShouldBind(&payload)
-	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+	var request createErrandRequest
+	if !Bind(ctx, &request) {
 		return
 	}
 
-	nErrand, err := errandEntity.ValidateErrandFromMap(payload, false)
+	nErrand, err := errandEntity.FromRequest(request.Request, false)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	}
 	errandId := ctx.Param("id")
@@ -197,9 +231,9 @@ func (e *errand) CreateErrand(ctx *gin.Context) {
 		return
 	}
 
-	err = e.UseCase.CreateErrand(token, errandId, nErrand)
+	err = e.UseCase.CreateErrand(principal, errandId, nErrand, request.TransactionPin, strings.TrimSpace(request.PromoCode))
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	}
 
@@ -207,31 +241,27 @@ func (e *errand) CreateErrand(ctx *gin.Context) {
 }
 
 func (e *errand) BidForErrand(ctx *gin.Context) {
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
-
-	var payload Payload
-	err := ctx.ShouldBind(&payload)
-	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+	principal, ok := Principal(ctx)
+	if !ok {
 		return
 	}
-	errandId := ctx.Param("id")
 
-	nBid, err := bid.FromPayload(payload, errandId)
-	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+	var request haggle.Request
+	if !Bind(ctx, &request) {
 		return
 	}
+	errandId := ctx.Param("id")
 
-	nHaggle, err := haggle.FromPayload(payload)
+	nBid := bid.NewOpenBid(errandId, request.Description)
+	nHaggle, err := haggle.FromRequest(request)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	}
 
-	err = e.UseCase.BidForErrand(token, nBid, nHaggle)
+	err = e.UseCase.BidForErrand(principal, nBid, nHaggle)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	}
 
@@ -239,26 +269,27 @@ func (e *errand) BidForErrand(ctx *gin.Context) {
 }
 
 func (e *errand) UpdateBidForErrand(ctx *gin.Context) {
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
 
-	var payload Payload
-	err := ctx.ShouldBind(&payload)
-	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+	var request haggle.Request
+	if !Bind(ctx, &request) {
 		return
 	}
 	errandId := ctx.Param("id")
 	bidId := ctx.Param("bid_id")
 
-	nHaggle, err := haggle.FromPayload(payload)
+	nHaggle, err := haggle.FromRequest(request)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	}
 
-	err = e.UseCase.UpdateErrandBid(token, errandId, bidId, nHaggle)
+	err = e.UseCase.UpdateErrandBid(principal, errandId, bidId, nHaggle)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	}
 
@@ -266,28 +297,19 @@ func (e *errand) UpdateBidForErrand(ctx *gin.Context) {
 }
 
 func (e *errand) StartErrand(ctx *gin.Context) {
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
-
-	var payload Payload
-	var errandId, bidId string
-	var ok bool
-	err := ctx.ShouldBind(&payload)
-	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
-		return
-	}
-	if errandId, ok = payload["errand_id"].(string); !ok {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("errand id is required"))
+	principal, ok := Principal(ctx)
+	if !ok {
 		return
 	}
-	if bidId, ok = payload["bid_id"].(string); !ok {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("bid id is required"))
+
+	var request startErrandRequest
+	if !Bind(ctx, &request) {
 		return
 	}
 
-	err = e.UseCase.AcceptContract(token, errandId, bidId)
+	err := e.UseCase.AcceptContract(principal, request.ErrandId, request.BidId)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	}
 
@@ -295,14 +317,17 @@ func (e *errand) StartErrand(ctx *gin.Context) {
 }
 
 func (e *errand) RejectErrandContract(ctx *gin.Context) {
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
 
 	errandId := ctx.Param("id")
 	bidId := ctx.Param("bid_id")
 
-	err := e.UseCase.RejectContract(token, errandId, bidId)
+	err := e.UseCase.RejectContract(principal, errandId, bidId)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	}
 
@@ -310,53 +335,30 @@ func (e *errand) RejectErrandContract(ctx *gin.Context) {
 }
 
 func (e *errand) RespondToBid(ctx *gin.Context) {
-	var amount float64
-	var ok bool
-	var responseType, runnerId string
-	var payload Payload
-	err := ctx.ShouldBind(&payload)
-	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+	var request bidResponseRequest
+	if !Bind(ctx, &request) {
 		return
 	}
-	if amount, ok = payload["amount"].(float64); !ok {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("amount is required"))
-		return
-	} else {
-		if amount < 0.0 {
-			ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("amount is invalid"))
-			return
-		}
-	}
-	if responseType, ok = payload["response"].(string); !ok {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("response type is required"))
-		return
-	} else {
-		if responseType != "accept" && responseType != "reject" {
-			ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("invalid response type specified"))
-			return
-		}
-	}
-	if runnerId, ok = payload["runner_id"].(string); !ok {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("runner id is required"))
+
+	principal, ok := Principal(ctx)
+	if !ok {
 		return
 	}
-
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
 	errandId := ctx.Param("id")
 	bidId := ctx.Param("bid_id")
 
-	if responseType == "accept" {
-		err = e.UseCase.AcceptBid(token, errandId, bidId, runnerId, amount)
-	} else if responseType == "reject" {
-		err = e.UseCase.RejectBid(token, errandId, bidId)
+	var err error
+	if request.Response == "accept" {
+		err = e.UseCase.AcceptBid(principal, errandId, bidId, request.RunnerId, request.Amount)
+	} else {
+		err = e.UseCase.RejectBid(principal, errandId, bidId)
 	}
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	}
 
-	if responseType == "accept" {
+	if request.Response == "accept" {
 		ctx.JSON(http.StatusOK, response.NewOkResponse("bid accepted successfully", nil))
 	} else {
 		ctx.JSON(http.StatusOK, response.NewOkResponse("bid rejected successfully", nil))
@@ -366,10 +368,13 @@ func (e *errand) RespondToBid(ctx *gin.Context) {
 func (e *errand) RequestForUpdate(ctx *gin.Context) {
 	errandId := ctx.Param("id")
 
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
-	err := e.UseCase.RequestErrandTimelineUpdate(token, errandId)
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+	err := e.UseCase.RequestErrandTimelineUpdate(principal, errandId)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	}
 
@@ -377,28 +382,18 @@ func (e *errand) RequestForUpdate(ctx *gin.Context) {
 }
 
 func (e *errand) PostUpdate(ctx *gin.Context) {
-	var payload Payload
-	var errandId, message string
-	var ok bool
-
-	err := ctx.ShouldBind(&payload)
-	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
-		return
-	}
-	if errandId, ok = payload["errand_id"].(string); !ok {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("errand id is required"))
+	var request timelineUpdateRequest
+	if !Bind(ctx, &request) {
 		return
 	}
-	if message, ok = payload["message"].(string); !ok {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("update message is required"))
+	principal, ok := Principal(ctx)
+	if !ok {
 		return
 	}
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
 
-	err = e.UseCase.UpdateErrandTimeline(token, errandId, message)
+	err := e.UseCase.UpdateErrandTimeline(principal, request.ErrandId, request.Message)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	}
 
@@ -407,11 +402,14 @@ func (e *errand) PostUpdate(ctx *gin.Context) {
 
 func (e *errand) GetErrand(ctx *gin.Context) {
 	errandId := ctx.Param("id")
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
 
-	nErrand, err := e.UseCase.GetErrand(token, errandId)
+	nErrand, err := e.UseCase.GetErrand(principal, errandId)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	}
 
@@ -419,13 +417,34 @@ func (e *errand) GetErrand(ctx *gin.Context) {
 }
 
 func (e *errand) FetchAllErrands(ctx *gin.Context) {
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
 
-	errands, err := e.GetAllErrands(token)
+	errands, err := e.GetAllErrands(principal)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, response.NewOkResponse("errands fetched successfully", errands))
 }
+
+func (e *errand) TipRunner(ctx *gin.Context) {
+	var request tipRequest
+	if !Bind(ctx, &request) {
+		return
+	}
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+
+	if err := e.UseCase.TipRunner(principal, ctx.Param("id"), int64(request.Amount), request.TransactionPin); err != nil {
+		Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("runner tipped successfully", nil))
+}
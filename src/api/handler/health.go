@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"DX/src/pkg/response"
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+type HealthCheck func(context.Context) error
+
+type Health interface {
+	Check(*gin.Context)
+}
+
+type healthImpl struct {
+	Checks map[string]HealthCheck
+}
+
+func NewHealthHandler(checks map[string]HealthCheck) Health {
+	return &healthImpl{
+		Checks: checks,
+	}
+}
+
+func (h *healthImpl) Check(ctx *gin.Context) {
+	status := http.StatusOK
+	results := map[string]string{}
+	for name, check := range h.Checks {
+		checkContext, cancel := context.WithTimeout(ctx.Request.Context(), 3*time.Second)
+		if err := check(checkContext); err != nil {
+			results[name] = err.Error()
+			status = http.StatusServiceUnavailable
+		} else {
+			results[name] = "ok"
+		}
+		cancel()
+	}
+
+	if status != http.StatusOK {
+		ctx.JSON(status, &response.BaseResponse{Success: false, Message: "service unhealthy", Data: results})
+		return
+	}
+	ctx.JSON(status, response.NewOkResponse("service healthy", results))
+}
@@ -25,7 +25,7 @@ func NewInitHandler(UseCase init_data.UseCase) Init {
 func (i *impl) GetInitData(ctx *gin.Context) {
 	data, err := i.UseCase.GetInitData()
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	}
 
@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"DX/src/pkg/token_service"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type Jwks interface {
+	GetKeys(*gin.Context)
+}
+
+type jwksImpl struct {
+	token_service.Service
+}
+
+func NewJwksHandler(service token_service.Service) Jwks {
+	return &jwksImpl{
+		Service: service,
+	}
+}
+
+// GetKeys serves the public signing keys as a plain JWK set, so other services can verify our tokens.
+func (j *jwksImpl) GetKeys(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, j.Service.Jwks())
+}
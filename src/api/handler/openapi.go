@@ -0,0 +1,244 @@
+package handler
+
+import (
+	apiKeyEntity "DX/src/domain/entity/apikey"
+	"DX/src/domain/entity/auth"
+	"DX/src/domain/entity/category"
+	errandEntity "DX/src/domain/entity/errand"
+	"DX/src/domain/entity/haggle"
+	initData "DX/src/domain/entity/init_data"
+	"DX/src/domain/entity/notification"
+	pinEntity "DX/src/domain/entity/pin"
+	"DX/src/domain/entity/reset"
+	entSecurity "DX/src/domain/entity/security"
+	"DX/src/domain/entity/session"
+	"DX/src/domain/entity/twofactor"
+	"DX/src/domain/entity/user"
+	walletEntity "DX/src/domain/entity/wallet"
+	webhookEntity "DX/src/domain/entity/webhook"
+	promoUseCase "DX/src/domain/usecase/promo"
+	walletUseCase "DX/src/domain/usecase/wallet"
+	"DX/src/pkg/openapi"
+	"DX/src/pkg/token_service"
+	"net/http"
+)
+
+// Query parameters read by TransactionFilter, DateRange and WriteStatement.
+var (
+	DateRangeQuery = []openapi.Parameter{
+		openapi.Query("from", "start date, 2006-01-02 or RFC3339"),
+		openapi.Query("to", "end date, 2006-01-02 or RFC3339, a bare date includes the whole day"),
+	}
+	TransactionQuery = append([]openapi.Parameter{
+		openapi.QueryOf("page", "page number, from 1", "integer"),
+		openapi.QueryOf("limit", "transactions per page", "integer"),
+		openapi.Query("type", "", walletEntity.Credit.String(), walletEntity.Debit.String()),
+		openapi.Query("category", "transaction category, like top-up or errand-payment"),
+		openapi.Query("errand_id", ""),
+	}, DateRangeQuery...)
+	StatementQuery = append([]openapi.Parameter{
+		openapi.Query("format", "defaults to json", "json", "csv", "pdf"),
+	}, DateRangeQuery...)
+	StatementDownloads = []string{"text/csv", "application/pdf"}
+)
+
+type ticketData struct {
+	Ticket string `json:"ticket"`
+}
+
+type verificationTicketData struct {
+	VerificationTicket string `json:"verification_ticket"`
+}
+
+type recoveryCodesData struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type promoPreviewData struct {
+	Discount int64 `json:"discount"`
+	Total    int64 `json:"total"`
+}
+
+// Routes describes the routes served by this package's handlers, the routes test checks it against the router.
+var Routes = []openapi.Route{
+	{Method: http.MethodGet, Path: "/.well-known/jwks.json", Id: "getSigningKeys", Tag: "auth",
+		Summary: "Public keys that verify access tokens", Raw: true, Data: token_service.Jwks{}},
+	{Method: http.MethodGet, Path: "/v1/openapi.json", Id: "getOpenApi", Tag: "docs",
+		Summary: "This document", Raw: true, Data: map[string]interface{}{}},
+	{Method: http.MethodGet, Path: "/v1/docs", Id: "getApiReference", Tag: "docs",
+		Summary: "API reference rendered from this document", Raw: true, ContentType: "text/html"},
+	{Method: http.MethodGet, Path: "/v1/health", Id: "checkHealth", Tag: "health",
+		Summary: "Status of each dependency, 503 when one is down", Data: map[string]string{}},
+	{Method: http.MethodGet, Path: "/v1/init", Id: "getInitData", Tag: "init", Auth: openapi.User,
+		Summary: "Data the apps load on start", Data: initData.Data{}},
+
+	{Method: http.MethodPost, Path: "/v1/security-question", Id: "saveSecurityAnswers", Tag: "security questions", Auth: openapi.User,
+		Summary: "Set the answers to security questions", Request: securityAnswersRequest{}},
+	{Method: http.MethodGet, Path: "/v1/security-questions", Id: "getSecurityQuestions", Tag: "security questions",
+		Summary: "Questions a user can answer", Data: []entSecurity.Question{}},
+	{Method: http.MethodGet, Path: "/v1/security-question", Id: "getSecurityChallenge", Tag: "security questions", RateLimited: true,
+		Summary: "Questions the owner of a phone number has to answer",
+		Query:   []openapi.Parameter{openapi.Query("phone_number", "")}, Data: []entSecurity.Prompt{}},
+	{Method: http.MethodPost, Path: "/v1/security-question/verify", Id: "verifySecurityAnswers", Tag: "security questions", RateLimited: true,
+		Summary: "Answer the questions for a ticket", Request: verifySecurityRequest{}, Data: ticketData{}},
+
+	{Method: http.MethodPost, Path: "/v1/paystack/webhook", Id: "receivePaystackEvent", Tag: "wallet",
+		Summary: "Events sent by Paystack"},
+	{Method: http.MethodPost, Path: "/v1/transact", Id: "makePayment", Tag: "wallet", Auth: openapi.User, Idempotent: true,
+		Summary: "Credit or debit the wallet", Request: paymentRequest{}},
+	{Method: http.MethodGet, Path: "/v1/errand/market", Id: "getMarketErrands", Tag: "errands",
+		Summary: "Open errands runners can bid for", Data: []errandEntity.Errand{}},
+
+	{Method: http.MethodPost, Path: "/v1/user/sign-in", Id: "signIn", Tag: "auth",
+		Summary: "Sign in, a two-factor challenge is returned instead when it is enabled",
+		Request: user.Login{}, Data: openapi.OneOf(user.User{}, twofactor.StepUp{})},
+	{Method: http.MethodPost, Path: "/v1/user/sign-in/2fa", Id: "signInWithTwoFactor", Tag: "auth", RateLimited: true,
+		Summary: "Finish a sign in with an authentication or recovery code", Request: twoFactorLoginRequest{}, Data: user.User{}},
+	{Method: http.MethodPost, Path: "/v1/user/verify-phone", Id: "verifyPhoneNumber", Tag: "auth", RateLimited: true,
+		Summary: "Send a verification code to a phone number", Request: phoneRequest{}},
+	{Method: http.MethodPost, Path: "/v1/user/verify-phone/confirm", Id: "confirmPhoneNumber", Tag: "auth", RateLimited: true,
+		Summary: "Exchange the code for the ticket sign up needs", Request: confirmPhoneRequest{}, Data: verificationTicketData{}},
+	{Method: http.MethodPost, Path: "/v1/user/sign-up", Id: "signUp", Tag: "auth", Status: http.StatusCreated,
+		Summary: "Create an account", Request: user.SignUp{}, Data: user.User{}},
+	{Method: http.MethodPost, Path: "/v1/user/sign-out", Id: "signOut", Tag: "auth", Auth: openapi.User,
+		Summary: "End the session"},
+	{Method: http.MethodPost, Path: "/v1/user/token/refresh", Id: "refreshToken", Tag: "auth",
+		Summary: "Rotate the token pair", Request: refreshTokenRequest{}, Data: auth.Token{}},
+	{Method: http.MethodPost, Path: "/v1/user/suspend", Id: "suspendAccount", Tag: "account", Auth: openapi.User,
+		Summary: "Suspend your own account"},
+	{Method: http.MethodPost, Path: "/v1/user/password/reset", Id: "requestPasswordReset", Tag: "password reset", RateLimited: true,
+		Summary: "Send a reset code", Request: resetRequest{}},
+	{Method: http.MethodPost, Path: "/v1/user/password/reset/verify", Id: "verifyPasswordReset", Tag: "password reset", RateLimited: true,
+		Summary: "Verify the reset code", Request: verifyResetRequest{}, Data: reset.Progress{}},
+	{Method: http.MethodPost, Path: "/v1/user/password/reset/security", Id: "answerPasswordResetQuestion", Tag: "password reset", RateLimited: true,
+		Summary: "Answer a security question of the reset", Request: resetAnswerRequest{}, Data: reset.Progress{}},
+	{Method: http.MethodPost, Path: "/v1/user/password/reset/complete", Id: "completePasswordReset", Tag: "password reset", RateLimited: true,
+		Summary: "Set the new password", Request: completeResetRequest{}},
+	{Method: http.MethodPut, Path: "/v1/user/password", Id: "changePassword", Tag: "account", Auth: openapi.User,
+		Summary: "Change the password", Request: changePasswordRequest{}},
+
+	{Method: http.MethodPost, Path: "/v1/user/2fa", Id: "enrollTwoFactor", Tag: "two-factor", Auth: openapi.User,
+		Summary: "Start setting up an authenticator app", Data: twofactor.Enrollment{}},
+	{Method: http.MethodPost, Path: "/v1/user/2fa/confirm", Id: "confirmTwoFactor", Tag: "two-factor", Auth: openapi.User,
+		Summary: "Enable two-factor authentication with a first code", Request: authenticationCodeRequest{}},
+	{Method: http.MethodDelete, Path: "/v1/user/2fa", Id: "disableTwoFactor", Tag: "two-factor", Auth: openapi.User,
+		Summary: "Disable two-factor authentication", Request: authenticationCodeRequest{}},
+	{Method: http.MethodPost, Path: "/v1/user/2fa/recovery-codes", Id: "regenerateRecoveryCodes", Tag: "two-factor", Auth: openapi.User,
+		Summary: "Replace every recovery code", Request: authenticationCodeRequest{}, Data: recoveryCodesData{}},
+
+	{Method: http.MethodGet, Path: "/v1/user/api-keys", Id: "getApiKeys", Tag: "api keys", Auth: openapi.User,
+		Summary: "API keys of the user", Data: []apiKeyEntity.ApiKey{}},
+	{Method: http.MethodPost, Path: "/v1/user/api-keys", Id: "createApiKey", Tag: "api keys", Auth: openapi.User, Status: http.StatusCreated,
+		Summary: "Create an API key, the key is only returned here", Request: apiKeyEntity.Request{}, Data: apiKeyEntity.Created{}},
+	{Method: http.MethodDelete, Path: "/v1/user/api-keys/:id", Id: "revokeApiKey", Tag: "api keys", Auth: openapi.User,
+		Summary: "Revoke an API key"},
+
+	{Method: http.MethodGet, Path: "/v1/user/webhooks", Id: "getWebhooks", Tag: "webhooks", Auth: openapi.User, Scopes: webhookScopes,
+		Summary: "Webhooks of the user", Data: []webhookEntity.Subscription{}},
+	{Method: http.MethodPost, Path: "/v1/user/webhooks", Id: "createWebhook", Tag: "webhooks", Auth: openapi.User, Scopes: webhookScopes,
+		Status: http.StatusCreated, Summary: "Create a webhook, the signing secret is only returned here",
+		Request: webhookEntity.Request{}, Data: webhookEntity.Created{}},
+	{Method: http.MethodPut, Path: "/v1/user/webhooks/:id", Id: "updateWebhook", Tag: "webhooks", Auth: openapi.User, Scopes: webhookScopes,
+		Summary: "Change the url, events or state of a webhook", Request: webhookEntity.Update{}, Data: webhookEntity.Subscription{}},
+	{Method: http.MethodDelete, Path: "/v1/user/webhooks/:id", Id: "deleteWebhook", Tag: "webhooks", Auth: openapi.User, Scopes: webhookScopes,
+		Summary: "Delete a webhook"},
+	{Method: http.MethodGet, Path: "/v1/user/webhooks/:id/deliveries", Id: "getWebhookDeliveries", Tag: "webhooks", Auth: openapi.User,
+		Scopes: webhookScopes, Summary: "Latest deliveries of a webhook", Data: []webhookEntity.Delivery{}},
+	{Method: http.MethodPost, Path: "/v1/user/webhooks/:id/deliveries/:delivery_id/redeliver", Id: "redeliverWebhook", Tag: "webhooks",
+		Auth: openapi.User, Scopes: webhookScopes, Status: http.StatusCreated,
+		Summary: "Send the event of a delivery again", Data: webhookEntity.Delivery{}},
+
+	{Method: http.MethodGet, Path: "/v1/user/sessions", Id: "getSessions", Tag: "sessions", Auth: openapi.User,
+		Summary: "Devices the user is signed in on", Data: []session.Session{}},
+	{Method: http.MethodDelete, Path: "/v1/user/sessions", Id: "revokeSessions", Tag: "sessions", Auth: openapi.User,
+		Summary: "Sign out everywhere", Query: []openapi.Parameter{openapi.QueryOf("except_current", "keep the session of the request", "boolean")}},
+	{Method: http.MethodDelete, Path: "/v1/user/sessions/:id", Id: "revokeSession", Tag: "sessions", Auth: openapi.User,
+		Summary: "Sign out a device"},
+
+	{Method: http.MethodGet, Path: "/v1/user/profile", Id: "getProfile", Tag: "account", Auth: openapi.User,
+		Summary: "The signed in user", Data: user.User{}},
+	{Method: http.MethodGet, Path: "/v1/user/errands", Id: "getMyErrands", Tag: "errands", Auth: openapi.User, Scopes: errandsReadScopes,
+		Summary: "Errands of the user", Data: []errandEntity.Errand{}},
+	{Method: http.MethodGet, Path: "/v1/user/notifications", Id: "getNotifications", Tag: "account", Auth: openapi.User,
+		Summary: "Notifications of the user", Data: []notification.Notification{}},
+
+	{Method: http.MethodGet, Path: "/v1/user/wallet", Id: "getWallet", Tag: "wallet", Auth: openapi.User, Scopes: walletReadScopes,
+		Summary: "Wallet of the user with its latest transactions", Data: walletEntity.Wallet{}},
+	{Method: http.MethodGet, Path: "/v1/user/wallet/transactions", Id: "getTransactions", Tag: "wallet", Auth: openapi.User,
+		Scopes: walletReadScopes, Summary: "Page of wallet transactions", Query: TransactionQuery, Data: walletEntity.Page{}},
+	{Method: http.MethodGet, Path: "/v1/user/wallet/earnings", Id: "getEarnings", Tag: "wallet", Auth: openapi.User, Scopes: walletReadScopes,
+		Summary: "Earnings of a runner by period",
+		Query: append([]openapi.Parameter{openapi.Query("period", "defaults to day", walletEntity.Day.Id(), walletEntity.Week.Id(), walletEntity.Month.Id())},
+			DateRangeQuery...), Data: walletUseCase.EarningsDashboard{}},
+	{Method: http.MethodGet, Path: "/v1/user/wallet/statement", Id: "getStatement", Tag: "wallet", Auth: openapi.User, Scopes: walletReadScopes,
+		Summary: "Statement of the wallet, as JSON or a CSV or PDF download", Query: StatementQuery,
+		Data: walletEntity.Statement{}, Downloads: StatementDownloads},
+	{Method: http.MethodPost, Path: "/v1/user/wallet/withdraw", Id: "withdraw", Tag: "wallet", Auth: openapi.User, Idempotent: true,
+		Summary: "Withdraw to the bank account", Request: withdrawalRequest{}},
+
+	{Method: http.MethodPost, Path: "/v1/user/promo/preview", Id: "previewPromo", Tag: "promos", Auth: openapi.User,
+		Summary: "Discount a promo code gives on an amount", Request: previewPromoRequest{}, Data: promoPreviewData{}},
+	{Method: http.MethodGet, Path: "/v1/user/referrals", Id: "getReferrals", Tag: "promos", Auth: openapi.User,
+		Summary: "Users the user referred", Data: promoUseCase.ReferralSummary{}},
+	{Method: http.MethodPost, Path: "/v1/user/account", Id: "addBankAccount", Tag: "account", Auth: openapi.User, Status: http.StatusCreated,
+		Summary: "Add the bank account withdrawals are paid to", Request: bankAccountRequest{}, Data: user.Account{}},
+
+	{Method: http.MethodPost, Path: "/v1/user/pin", Id: "createPin", Tag: "transaction pin", Auth: openapi.User, Status: http.StatusCreated,
+		Summary: "Set the transaction pin", Request: createPinRequest{}},
+	{Method: http.MethodPut, Path: "/v1/user/pin", Id: "changePin", Tag: "transaction pin", Auth: openapi.User,
+		Summary: "Change the transaction pin", Request: changePinRequest{}},
+	{Method: http.MethodPost, Path: "/v1/user/pin/reset", Id: "resetPin", Tag: "transaction pin", Auth: openapi.User,
+		Summary: "Reset the transaction pin with the password", Request: resetPinRequest{}},
+	{Method: http.MethodGet, Path: "/v1/user/pin/events", Id: "getPinEvents", Tag: "transaction pin", Auth: openapi.User,
+		Summary: "Changes and failed attempts of the transaction pin", Data: []pinEntity.Event{}},
+	{Method: http.MethodGet, Path: "/v1/user/:id", Id: "getUser", Tag: "account", Auth: openapi.User,
+		Summary: "Another user", Data: user.User{}},
+	{Method: http.MethodPost, Path: "/v1/user/rate", Id: "rateUser", Tag: "errands", Auth: openapi.User,
+		Summary: "Rate the other user of an errand", Request: rateUserRequest{}},
+
+	{Method: http.MethodPost, Path: "/v1/errand/files", Id: "uploadErrandFiles", Tag: "errands", Auth: openapi.User,
+		Scopes: errandsCreateScopes, Status: http.StatusCreated, Summary: "Upload up to 3 images or audio files, returns their urls",
+		Form: []openapi.FormField{
+			{Name: "type", Description: "what the files are for, errand or profile"},
+			{Name: "errand_id"},
+			{Name: "files", Files: true},
+		}, Data: []string{}},
+	{Method: http.MethodGet, Path: "/v1/errand/draft", Id: "getDraftErrand", Tag: "errands", Auth: openapi.User, Scopes: errandsCreateScopes,
+		Status: http.StatusCreated, Summary: "The draft errand of the user, created when there is none", Data: errandEntity.Errand{}},
+	{Method: http.MethodPatch, Path: "/v1/errand/:id", Id: "updateErrand", Tag: "errands", Auth: openapi.User, Scopes: errandsCreateScopes,
+		Summary: "Save the details of a draft errand", Request: errandEntity.Request{}, Data: errandEntity.Errand{}},
+	{Method: http.MethodPost, Path: "/v1/errand/:id", Id: "createErrand", Tag: "errands", Auth: openapi.User, Scopes: errandsCreateScopes,
+		Idempotent: true, Summary: "Publish a draft errand and pay for it", Request: createErrandRequest{}, Data: errandEntity.Errand{}},
+	{Method: http.MethodDelete, Path: "/v1/errand/:id/cancel", Id: "cancelErrand", Tag: "errands", Auth: openapi.User,
+		Summary: "Cancel an errand", Request: cancelErrandRequest{}},
+	{Method: http.MethodPatch, Path: "/v1/errand/:id/complete", Id: "completeErrand", Tag: "errands", Auth: openapi.User,
+		Summary: "Mark an errand as completed", Request: completeErrandRequest{}},
+	{Method: http.MethodPost, Path: "/v1/errand/:id/tip", Id: "tipRunner", Tag: "errands", Auth: openapi.User, Idempotent: true,
+		Summary: "Tip the runner of an errand", Request: tipRequest{}},
+	{Method: http.MethodGet, Path: "/v1/errand/:id", Id: "getErrand", Tag: "errands", Auth: openapi.User, Scopes: errandsReadScopes,
+		Summary: "An errand", Data: errandEntity.Errand{}},
+	{Method: http.MethodGet, Path: "/v1/errand/categories", Id: "getCategories", Tag: "errands", Auth: openapi.User, Scopes: errandsReadScopes,
+		Summary: "Categories errands can be created in", Query: []openapi.Parameter{openapi.QueryOf("limit", "", "integer")},
+		Data: []category.Category{}},
+	{Method: http.MethodPost, Path: "/v1/errand/start", Id: "startErrand", Tag: "errands", Auth: openapi.User,
+		Summary: "Start an errand as its runner", Request: startErrandRequest{}},
+	{Method: http.MethodPost, Path: "/v1/errand/:id/bid", Id: "bidForErrand", Tag: "bids", Auth: openapi.User,
+		Summary: "Bid for an errand", Request: haggle.Request{}},
+	{Method: http.MethodPut, Path: "/v1/errand/:id/bid/:bid_id", Id: "updateBid", Tag: "bids", Auth: openapi.User,
+		Summary: "Counter a bid", Request: haggle.Request{}},
+	{Method: http.MethodPut, Path: "/v1/errand/:id/bid/:bid_id/respond", Id: "respondToBid", Tag: "bids", Auth: openapi.User,
+		Summary: "Accept or reject a bid", Request: bidResponseRequest{}},
+	{Method: http.MethodDelete, Path: "/v1/errand/:id/bid/:bid_id/respond", Id: "rejectErrandContract", Tag: "bids", Auth: openapi.User,
+		Summary: "Turn down an errand awarded to you"},
+	{Method: http.MethodPost, Path: "/v1/errand/timeline", Id: "postErrandUpdate", Tag: "errands", Auth: openapi.User,
+		Summary: "Post an update to the timeline of an errand", Request: timelineUpdateRequest{}},
+	{Method: http.MethodGet, Path: "/v1/errand/timeline/request/:id", Id: "requestErrandUpdate", Tag: "errands", Auth: openapi.User,
+		Summary: "Ask the runner for an update"},
+}
+
+var (
+	webhookScopes       = []string{string(apiKeyEntity.WebhooksManage)}
+	errandsReadScopes   = []string{string(apiKeyEntity.ErrandsRead)}
+	errandsCreateScopes = []string{string(apiKeyEntity.ErrandsCreate)}
+	walletReadScopes    = []string{string(apiKeyEntity.WalletRead)}
+)
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"DX/src/domain/usecase/pin"
+	"DX/src/pkg/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type Pin interface {
+	CreatePin(*gin.Context)
+	ChangePin(*gin.Context)
+	ResetPin(*gin.Context)
+	GetPinEvents(*gin.Context)
+}
+
+type pinImpl struct {
+	pin.UseCase
+}
+
+type createPinRequest struct {
+	Pin string `json:"pin" binding:"required"`
+}
+
+type changePinRequest struct {
+	OldPin string `json:"old_pin" binding:"required"`
+	NewPin string `json:"new_pin" binding:"required"`
+}
+
+type resetPinRequest struct {
+	Password string `json:"password" binding:"required"`
+	NewPin   string `json:"new_pin" binding:"required"`
+}
+
+func NewPinHandler(useCase pin.UseCase) Pin {
+	return &pinImpl{
+		UseCase: useCase,
+	}
+}
+
+func (p *pinImpl) CreatePin(ctx *gin.Context) {
+	var request createPinRequest
+	if !Bind(ctx, &request) {
+		return
+	}
+
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+	if err := p.UseCase.CreatePin(principal, request.Pin); err != nil {
+		Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response.NewCreatedResponse("transaction pin created", nil))
+}
+
+func (p *pinImpl) ChangePin(ctx *gin.Context) {
+	var request changePinRequest
+	if !Bind(ctx, &request) {
+		return
+	}
+
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+	if err := p.UseCase.ChangePin(principal, request.OldPin, request.NewPin); err != nil {
+		Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("transaction pin changed", nil))
+}
+
+func (p *pinImpl) ResetPin(ctx *gin.Context) {
+	var request resetPinRequest
+	if !Bind(ctx, &request) {
+		return
+	}
+
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+	if err := p.UseCase.ResetPin(principal, request.Password, request.NewPin); err != nil {
+		Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("transaction pin reset", nil))
+}
+
+func (p *pinImpl) GetPinEvents(ctx *gin.Context) {
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+
+	events, err := p.UseCase.GetEvents(principal)
+	if err != nil {
+		Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("transaction pin events fetched", events))
+}
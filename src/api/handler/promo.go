@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"DX/src/domain/usecase/promo"
+	"DX/src/pkg/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type Promo interface {
+	PreviewPromo(*gin.Context)
+	GetReferrals(*gin.Context)
+}
+
+type promoImpl struct {
+	promo.UseCase
+}
+
+type previewPromoRequest struct {
+	Code       string  `json:"code" binding:"required,notblank,max=32"`
+	Amount     float64 `json:"amount" binding:"required,gt=0"`
+	CategoryId string  `json:"category_id" binding:"omitempty,objectid"`
+}
+
+func NewPromoHandler(useCase promo.UseCase) Promo {
+	return &promoImpl{
+		UseCase: useCase,
+	}
+}
+
+func (p *promoImpl) PreviewPromo(ctx *gin.Context) {
+	var request previewPromoRequest
+	if !Bind(ctx, &request) {
+		return
+	}
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+
+	discount, err := p.UseCase.Preview(principal, request.Code, request.CategoryId, int64(request.Amount))
+	if err != nil {
+		Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("promo code applied", map[string]int64{
+		"discount": discount,
+		"total":    int64(request.Amount) - discount,
+	}))
+}
+
+func (p *promoImpl) GetReferrals(ctx *gin.Context) {
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+	summary, err := p.UseCase.GetReferrals(principal)
+	if err != nil {
+		Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("referrals fetched", summary))
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"DX/src/domain/entity/reset"
+	resetUseCase "DX/src/domain/usecase/reset"
+	"DX/src/pkg/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+type PasswordReset interface {
+	RequestReset(*gin.Context)
+	VerifyResetCode(*gin.Context)
+	AnswerSecurityQuestion(*gin.Context)
+	CompleteReset(*gin.Context)
+}
+
+type passwordResetImpl struct {
+	resetUseCase.UseCase
+}
+
+type resetRequest struct {
+	PhoneNumber string `json:"phone_number" binding:"required,notblank"`
+	Channel     string `json:"channel"`
+}
+
+type verifyResetRequest struct {
+	PhoneNumber string `json:"phone_number" binding:"required,notblank"`
+	Code        string `json:"code" binding:"required,notblank"`
+}
+
+type resetAnswerRequest struct {
+	PhoneNumber    string `json:"phone_number" binding:"required,notblank"`
+	Ticket         string `json:"ticket" binding:"required,notblank"`
+	SecurityTicket string `json:"security_ticket" binding:"required,notblank"`
+}
+
+type completeResetRequest struct {
+	PhoneNumber string `json:"phone_number" binding:"required,notblank"`
+	ResetToken  string `json:"reset_token" binding:"required,notblank"`
+	Password    string `json:"password" binding:"required,notblank"`
+}
+
+func NewPasswordResetHandler(useCase resetUseCase.UseCase) PasswordReset {
+	return &passwordResetImpl{
+		UseCase: useCase,
+	}
+}
+
+func (p *passwordResetImpl) RequestReset(ctx *gin.Context) {
+	var request resetRequest
+	if !Bind(ctx, &request) {
+		return
+	}
+	phone, value := request.PhoneNumber, request.Channel
+	channel, err := reset.GetChannel(value)
+	if err != nil {
+		Error(ctx, err)
+		return
+	}
+
+	if err = p.UseCase.Request(phone, channel); err != nil {
+		Error(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response.NewOkResponse("if an account exists for this phone number, a reset code has been sent", nil))
+}
+
+func (p *passwordResetImpl) VerifyResetCode(ctx *gin.Context) {
+	var request verifyResetRequest
+	if !Bind(ctx, &request) {
+		return
+	}
+
+	progress, err := p.UseCase.VerifyCode(request.PhoneNumber, strings.TrimSpace(request.Code))
+	if err != nil {
+		Error(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response.NewOkResponse("reset code verified", progress))
+}
+
+func (p *passwordResetImpl) AnswerSecurityQuestion(ctx *gin.Context) {
+	var request resetAnswerRequest
+	if !Bind(ctx, &request) {
+		return
+	}
+
+	progress, err := p.UseCase.AnswerQuestion(request.PhoneNumber, request.Ticket, request.SecurityTicket)
+	if err != nil {
+		Error(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response.NewOkResponse("security question answered", progress))
+}
+
+func (p *passwordResetImpl) CompleteReset(ctx *gin.Context) {
+	var request completeResetRequest
+	if !Bind(ctx, &request) {
+		return
+	}
+
+	if err := p.UseCase.Complete(request.PhoneNumber, request.ResetToken, strings.TrimSpace(request.Password)); err != nil {
+		Error(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response.NewOkResponse("password successfully reset", nil))
+}
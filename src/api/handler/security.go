@@ -12,6 +12,7 @@ import (
 )
 
 type Security interface {
+	GetSecurityQuestions(ctx *gin.Context)
 	SaveSecurityQuestion(ctx *gin.Context)
 	GetSecurityQuestion(ctx *gin.Context)
 	VerifySecurityQuestion(ctx *gin.Context)
@@ -21,36 +22,55 @@ type securityHandler struct {
 	security.UseCase
 }
 
+type securityAnswersRequest struct {
+	Answers []entSecurity.AnswerRequest `json:"answers" binding:"required,min=1,max=10,dive"`
+}
+
+type verifySecurityRequest struct {
+	PhoneNumber string                      `json:"phone_number" binding:"required,notblank"`
+	Answers     []entSecurity.AnswerRequest `json:"answers" binding:"required,min=1,max=10,dive"`
+}
+
 func NewSecurityHandler(useCase security.UseCase) Security {
 	return &securityHandler{
 		UseCase: useCase,
 	}
 }
 
-func (s *securityHandler) SaveSecurityQuestion(ctx *gin.Context) {
-	var payload Payload
-	err := ctx.ShouldBind(&payload)
+func (s *securityHandler) GetSecurityQuestions(ctx *gin.Context) {
+	questions, err := s.UseCase.GetQuestions()
 	if err != nil {
-		logger.Error("SaveSecurityQuestion::", err)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("invalid request data"))
+		Error(ctx, err)
 		return
 	}
-	sec, err := entSecurity.Create(payload)
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("security questions fetched", questions))
+}
+
+func (s *securityHandler) SaveSecurityQuestion(ctx *gin.Context) {
+	var request securityAnswersRequest
+	if !Bind(ctx, &request) {
+		return
+	}
+	answers, err := entSecurity.GetAnswers(request.Answers)
 	if err != nil {
 		logger.Error("SaveSecurityQuestion::", err)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	}
 
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
-	err = s.UseCase.SaveSecurity(token, sec)
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+	err = s.UseCase.SaveSecurity(principal, answers)
 	if err != nil {
 		logger.Error("SaveSecurityQuestion::", err)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, response.NewOkResponse("security question saved", nil))
+	ctx.JSON(http.StatusOK, response.NewOkResponse("security questions saved", nil))
 }
 
 func (s *securityHandler) GetSecurityQuestion(ctx *gin.Context) {
@@ -60,42 +80,36 @@ func (s *securityHandler) GetSecurityQuestion(ctx *gin.Context) {
 		return
 	}
 
-	ques, err := s.UseCase.GetSecurityWithPhone(phone)
+	prompts, err := s.UseCase.GetChallenge(phone)
 
 	if err != nil {
 		logger.Error("GetSecurityQuestion::", errors.New(err.Error()))
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, response.NewOkResponse("security question fetched", entSecurity.Security{
-		Question: ques,
-	}))
+	ctx.JSON(http.StatusOK, response.NewOkResponse("security questions fetched", prompts))
 }
 
 func (s *securityHandler) VerifySecurityQuestion(ctx *gin.Context) {
-	var payload Payload
-
-	err := ctx.ShouldBind(&payload)
-	if err != nil {
-		logger.Error("GetSecurityQuestion::", err)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("invalid request data"))
+	var request verifySecurityRequest
+	if !Bind(ctx, &request) {
 		return
 	}
-
-	phone, answer, err := entSecurity.GetAnswer(payload)
+	phone := request.PhoneNumber
+	answers, err := entSecurity.GetAnswers(request.Answers)
 	if err != nil {
 		logger.Error("VerifySecurityQuestion::", err)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	}
-	err = s.UseCase.Validate(phone, answer)
+	ticket, err := s.UseCase.Verify(phone, answers)
 
 	if err != nil {
 		logger.Error("VerifySecurityQuestion::", err)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, response.NewOkResponse("answer is correct", nil))
+	ctx.JSON(http.StatusOK, response.NewOkResponse("answers are correct", map[string]string{"ticket": ticket}))
 }
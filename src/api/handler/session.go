@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"DX/src/domain/usecase/session"
+	"DX/src/pkg/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type Session interface {
+	GetSessions(*gin.Context)
+	RevokeSession(*gin.Context)
+	RevokeSessions(*gin.Context)
+}
+
+type sessionImpl struct {
+	session.UseCase
+}
+
+func NewSessionHandler(useCase session.UseCase) Session {
+	return &sessionImpl{
+		UseCase: useCase,
+	}
+}
+
+func (s *sessionImpl) GetSessions(ctx *gin.Context) {
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+
+	sessions, err := s.UseCase.GetSessions(principal)
+	if err != nil {
+		Error(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response.NewOkResponse("sessions fetched", sessions))
+}
+
+func (s *sessionImpl) RevokeSession(ctx *gin.Context) {
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+
+	if err := s.UseCase.Revoke(principal, ctx.Param("id")); err != nil {
+		Error(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response.NewOkResponse("session revoked", nil))
+}
+
+// RevokeSessions logs the user out everywhere, pass except_current=true to stay signed in on this device.
+func (s *sessionImpl) RevokeSessions(ctx *gin.Context) {
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+	exceptCurrent := ctx.Query("except_current") == "true"
+
+	if err := s.UseCase.RevokeAll(principal, exceptCurrent); err != nil {
+		Error(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response.NewOkResponse("sessions revoked", nil))
+}
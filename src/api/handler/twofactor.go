@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"DX/src/domain/usecase/twofactor"
+	"DX/src/pkg/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+type TwoFactor interface {
+	Enroll(*gin.Context)
+	Confirm(*gin.Context)
+	Disable(*gin.Context)
+	RegenerateRecoveryCodes(*gin.Context)
+}
+
+type twoFactorImpl struct {
+	twofactor.UseCase
+}
+
+func NewTwoFactorHandler(useCase twofactor.UseCase) TwoFactor {
+	return &twoFactorImpl{
+		UseCase: useCase,
+	}
+}
+
+// Enroll starts setting up an authenticator app, it only takes effect once a code is confirmed.
+func (t *twoFactorImpl) Enroll(ctx *gin.Context) {
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+
+	enrollment, err := t.UseCase.Enroll(principal)
+	if err != nil {
+		Error(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response.NewOkResponse("scan the code with your authenticator app and confirm it", enrollment))
+}
+
+func (t *twoFactorImpl) Confirm(ctx *gin.Context) {
+	code, ok := authenticationCode(ctx)
+	if !ok {
+		return
+	}
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+
+	if err := t.UseCase.Confirm(principal, code); err != nil {
+		Error(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response.NewOkResponse("two-factor authentication enabled", nil))
+}
+
+func (t *twoFactorImpl) Disable(ctx *gin.Context) {
+	code, ok := authenticationCode(ctx)
+	if !ok {
+		return
+	}
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+
+	if err := t.UseCase.Disable(principal, code); err != nil {
+		Error(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response.NewOkResponse("two-factor authentication disabled", nil))
+}
+
+// RegenerateRecoveryCodes replaces every recovery code of the user, the old ones stop working.
+func (t *twoFactorImpl) RegenerateRecoveryCodes(ctx *gin.Context) {
+	code, ok := authenticationCode(ctx)
+	if !ok {
+		return
+	}
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+
+	codes, err := t.UseCase.RegenerateRecoveryCodes(principal, code)
+	if err != nil {
+		Error(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response.NewOkResponse("recovery codes generated", map[string][]string{"recovery_codes": codes}))
+}
+
+type authenticationCodeRequest struct {
+	Code string `json:"code" binding:"required,notblank"`
+}
+
+func authenticationCode(ctx *gin.Context) (string, bool) {
+	var request authenticationCodeRequest
+	if !Bind(ctx, &request) {
+		return "", false
+	}
+	return strings.TrimSpace(request.Code), true
+}
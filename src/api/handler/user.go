@@ -1,12 +1,11 @@
 package handler
 
 import (
+	"DX/src/domain/entity/twofactor"
 	"DX/src/domain/entity/user"
 	"DX/src/domain/usecase/authentication"
 	errands "DX/src/domain/usecase/errand"
 	"DX/src/pkg/response"
-	"DX/src/utils/logger"
-	"errors"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"strings"
@@ -14,18 +13,20 @@ import (
 
 type Authentication interface {
 	VerifyPhoneNumber(*gin.Context)
+	ConfirmPhoneNumber(*gin.Context)
 	Login(*gin.Context)
+	LoginWithTwoFactor(*gin.Context)
 	LogOut(*gin.Context)
 	CreateAccount(*gin.Context)
-	ForgotPassword(*gin.Context)
 	RefreshToken(*gin.Context)
 	SuspendUser(*gin.Context)
 	Profile(*gin.Context)
 	MyErrands(*gin.Context)
 	MyNotifications(*gin.Context)
-	UpdatePassword(*gin.Context)
+	ChangePassword(*gin.Context)
 	GetUser(*gin.Context)
 	RateUser(*gin.Context)
+	AddBankAccount(*gin.Context)
 }
 
 type authImpl struct {
@@ -40,55 +41,81 @@ func NewAuthHandler(useCase authentication.UseCase, ErrandUseCase errands.UseCas
 	}
 }
 
-func (i *authImpl) VerifyPhoneNumber(ctx *gin.Context) {
-	var payload Payload
+type phoneRequest struct {
+	PhoneNumber string `json:"phone_number" binding:"required,notblank"`
+}
 
-	err := ctx.ShouldBind(&payload)
-	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("invalid request data"))
-		return
-	}
+type confirmPhoneRequest struct {
+	PhoneNumber string `json:"phone_number" binding:"required,notblank"`
+	Code        string `json:"code" binding:"required,notblank"`
+}
 
-	phone, ok := payload["phone_number"].(string)
-	if !ok {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("phone number is required"))
+type twoFactorLoginRequest struct {
+	Challenge string `json:"challenge" binding:"required,notblank"`
+	Code      string `json:"code" binding:"required,notblank"`
+}
+
+type changePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	Password        string `json:"password" binding:"required,notblank"`
+}
+
+type rateUserRequest struct {
+	RunnerId string  `json:"runner_id" binding:"required,notblank"`
+	ErrandId string  `json:"errand_id" binding:"required,objectid"`
+	Rating   float64 `json:"rating" binding:"required,gte=1,lte=5"`
+}
+
+type bankAccountRequest struct {
+	Name           string `json:"name" binding:"required,notblank,max=100"`
+	Number         string `json:"number" binding:"required,numeric,max=20"`
+	Type           string `json:"type" binding:"required,notblank,max=50"`
+	BankCode       string `json:"bank_code" binding:"required,notblank,max=20"`
+	TransactionPin string `json:"transaction_pin" binding:"required"`
+}
+
+type refreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required,notblank"`
+}
+
+func (i *authImpl) VerifyPhoneNumber(ctx *gin.Context) {
+	var request phoneRequest
+	if !Bind(ctx, &request) {
 		return
 	}
+	phone := request.PhoneNumber
 
-	if resp := i.ValidatePhoneNumber(phone); resp != nil {
-		ctx.AbortWithStatusJSON(resp.Code, resp)
+	if err := i.ValidatePhoneNumber(phone); err != nil {
+		Error(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, response.NewOkResponse("phone number is valid", nil))
+	ctx.JSON(http.StatusOK, response.NewOkResponse("verification code sent", nil))
 }
 
-func (i *authImpl) CreateAccount(ctx *gin.Context) {
-	var payload Payload
-	err := ctx.ShouldBind(&payload)
-	if err != nil {
-		logger.Error("CreateAccount::", err)
-		ctx.JSON(http.StatusBadRequest, response.NewBadRequestError("invalid request data"))
+func (i *authImpl) ConfirmPhoneNumber(ctx *gin.Context) {
+	var request confirmPhoneRequest
+	if !Bind(ctx, &request) {
 		return
 	}
-	newUser, err := user.Create(payload)
+
+	ticket, err := i.UseCase.ConfirmPhoneNumber(strings.TrimSpace(request.PhoneNumber), strings.TrimSpace(request.Code))
 	if err != nil {
-		logger.Error("CreateAccount::", err)
-		ctx.JSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	}
+	ctx.JSON(http.StatusOK, response.NewOkResponse("phone number verified", map[string]string{"verification_ticket": ticket}))
+}
 
-	resp := newUser.IsValidForInitialCreation()
-	if resp != nil {
-		logger.Error("CreateAccount::", errors.New(resp.Message))
-		ctx.JSON(http.StatusBadRequest, response.NewBadRequestError(resp.Message))
+func (i *authImpl) CreateAccount(ctx *gin.Context) {
+	var request user.SignUp
+	if !Bind(ctx, &request) {
 		return
 	}
+	newUser := user.Create(request)
 
-	resp = i.UseCase.CreateUser(newUser)
-	if resp != nil {
-		logger.Error("CreateAccount::", errors.New(resp.Message))
-		ctx.JSON(http.StatusBadRequest, response.NewBadRequestError(resp.Message))
+	if err := i.UseCase.CreateUser(newUser, SessionInfo(ctx)); err != nil {
+		Error(ctx, err)
 		return
 	}
 
@@ -97,39 +124,56 @@ func (i *authImpl) CreateAccount(ctx *gin.Context) {
 }
 
 func (i *authImpl) Login(ctx *gin.Context) {
-	var payload Payload
-	err := ctx.ShouldBind(&payload)
-	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("invalid request data"))
+	var request user.Login
+	if !Bind(ctx, &request) {
 		return
 	}
+	newUser := user.CreateForLogin(request)
 
-	newUser, err := user.CreateForLogin(payload)
-	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
-		return
-	}
-
-	resp := i.UseCase.LoginUser(newUser)
-	if resp != nil {
-		ctx.AbortWithStatusJSON(resp.Code, resp)
+	if err := i.UseCase.LoginUser(newUser, SessionInfo(ctx)); err != nil {
+		Error(ctx, err)
 		return
 	}
 	if newUser.IsSuspended {
 		ctx.AbortWithStatusJSON(http.StatusUnauthorized, response.NewBadRequestError("user is suspended"))
 		return
 	}
+	if newUser.TwoFactorChallenge != "" {
+		ctx.JSON(http.StatusOK, response.NewOkResponse("two-factor authentication required", twofactor.StepUp{
+			TwoFactorRequired: true,
+			Challenge:         newUser.TwoFactorChallenge,
+		}))
+		return
+	}
 
 	newUser.UpdateVerification()
 	ctx.JSON(http.StatusOK, response.NewOkResponse("user login successful", newUser))
 }
 
-func (i *authImpl) LogOut(ctx *gin.Context) {
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
-	resp := i.UseCase.LogOut(token)
+// LoginWithTwoFactor finishes a sign in with the challenge it returned and an authenticator or recovery code.
+func (i *authImpl) LoginWithTwoFactor(ctx *gin.Context) {
+	var request twoFactorLoginRequest
+	if !Bind(ctx, &request) {
+		return
+	}
+
+	nUser, err := i.UseCase.LoginWithTwoFactor(strings.TrimSpace(request.Challenge), strings.TrimSpace(request.Code), SessionInfo(ctx))
+	if err != nil {
+		Error(ctx, err)
+		return
+	}
 
-	if resp != nil {
-		ctx.AbortWithStatusJSON(resp.Code, resp)
+	nUser.UpdateVerification()
+	ctx.JSON(http.StatusOK, response.NewOkResponse("user login successful", nUser))
+}
+
+func (i *authImpl) LogOut(ctx *gin.Context) {
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+	if err := i.UseCase.LogOut(principal); err != nil {
+		Error(ctx, err)
 		return
 	}
 
@@ -137,11 +181,12 @@ func (i *authImpl) LogOut(ctx *gin.Context) {
 }
 
 func (i *authImpl) SuspendUser(ctx *gin.Context) {
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
-	resp := i.UseCase.SuspendUser(token)
-
-	if resp != nil {
-		ctx.AbortWithStatusJSON(resp.Code, resp)
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+	if err := i.UseCase.SuspendUser(principal); err != nil {
+		Error(ctx, err)
 		return
 	}
 
@@ -149,11 +194,14 @@ func (i *authImpl) SuspendUser(ctx *gin.Context) {
 }
 
 func (i *authImpl) Profile(ctx *gin.Context) {
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
-	nUser, err := i.UseCase.GetUserProfile(token)
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+	nUser, err := i.UseCase.GetUserProfile(principal)
 
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	}
 
@@ -163,79 +211,57 @@ func (i *authImpl) Profile(ctx *gin.Context) {
 func (i *authImpl) GetUser(ctx *gin.Context) {
 	userId := ctx.Param("id")
 	if nUser, err := i.UseCase.GetUser(userId); err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	} else {
 		ctx.JSON(http.StatusOK, response.NewOkResponse("user data fetched successfully", nUser))
 	}
 }
 
-func (i *authImpl) UpdatePassword(ctx *gin.Context) {
-	var payload Payload
-	var password, phone string
-	var ok bool
-
-	err := ctx.ShouldBind(&payload)
-	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+func (i *authImpl) ChangePassword(ctx *gin.Context) {
+	var request changePasswordRequest
+	if !Bind(ctx, &request) {
 		return
 	}
-	if password, ok = payload["password"].(string); !ok {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("password is required"))
-		return
-	}
-	if phone, ok = payload["phone_number"].(string); !ok {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("phone number is required"))
+	principal, ok := Principal(ctx)
+	if !ok {
 		return
 	}
 
-	err = i.UseCase.UpdateUserPassword(phone, password)
-	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+	if err := i.UseCase.ChangePassword(principal, request.CurrentPassword, strings.TrimSpace(request.Password)); err != nil {
+		Error(ctx, err)
 		return
 	}
-	ctx.JSON(http.StatusOK, response.NewOkResponse("password successfully updated", nil))
+	ctx.JSON(http.StatusOK, response.NewOkResponse("password successfully changed", nil))
 }
 
 func (i *authImpl) MyErrands(ctx *gin.Context) {
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
 
-	nErrands, err := i.ErrandUseCase.GetErrandsFor(token)
+	nErrands, err := i.ErrandUseCase.GetErrandsFor(principal)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, response.NewOkResponse("errands fetched", nErrands))
 }
 
 func (i *authImpl) RateUser(ctx *gin.Context) {
-	var payload Payload
-	var errandId, runnerId string
-	var rating float64
-	var ok bool
-
-	err := ctx.ShouldBind(&payload)
-	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
-		return
-	}
-	if runnerId, ok = payload["runner_id"].(string); !ok {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("runner id is required"))
+	var request rateUserRequest
+	if !Bind(ctx, &request) {
 		return
 	}
-	if errandId, ok = payload["errand_id"].(string); !ok {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("errand id is required"))
-		return
-	}
-	if rating, ok = payload["rating"].(float64); !ok {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("rating is required"))
+
+	principal, ok := Principal(ctx)
+	if !ok {
 		return
 	}
 
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
-
-	if err = i.ErrandUseCase.RateUser(token, runnerId, errandId, int64(rating)); err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+	if err := i.ErrandUseCase.RateUser(principal, request.RunnerId, request.ErrandId, int64(request.Rating)); err != nil {
+		Error(ctx, err)
 		return
 	}
 
@@ -243,21 +269,55 @@ func (i *authImpl) RateUser(ctx *gin.Context) {
 }
 
 func (i *authImpl) MyNotifications(ctx *gin.Context) {
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
 
-	notifications, err := i.UseCase.GetNotifications(token)
+	notifications, err := i.UseCase.GetNotifications(principal)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, response.NewOkResponse("notifications fetched successfully", notifications))
 }
 
-func (i *authImpl) ForgotPassword(ctx *gin.Context) {
+func (i *authImpl) AddBankAccount(ctx *gin.Context) {
+	var request bankAccountRequest
+	if !Bind(ctx, &request) {
+		return
+	}
+	account := user.NewAccount(user.AccountDetails{
+		Name:     request.Name,
+		Number:   request.Number,
+		Type:     request.Type,
+		BankCode: request.BankCode,
+	})
+
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+	if err := i.UseCase.AddBankAccount(principal, account, request.TransactionPin); err != nil {
+		Error(ctx, err)
+		return
+	}
 
+	ctx.JSON(http.StatusCreated, response.NewCreatedResponse("bank account added successfully", account))
 }
 
 func (i *authImpl) RefreshToken(ctx *gin.Context) {
+	var request refreshTokenRequest
+	if !Bind(ctx, &request) {
+		return
+	}
+
+	token, err := i.UseCase.RefreshToken(strings.TrimSpace(request.RefreshToken), SessionInfo(ctx))
+	if err != nil {
+		Error(ctx, err)
+		return
+	}
 
+	ctx.JSON(http.StatusOK, response.NewOkResponse("token refreshed", token))
 }
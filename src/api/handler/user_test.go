@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"DX/src/domain/entity/limiter"
+	"DX/src/domain/entity/otp"
+	"DX/src/domain/entity/session"
+	"DX/src/domain/entity/user"
+	"DX/src/domain/usecase/authentication"
+	"DX/src/pkg/error_service"
+	"DX/src/pkg/response"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeAuthenticationUseCase struct {
+	authentication.UseCase
+	err error
+}
+
+func (f fakeAuthenticationUseCase) CreateUser(*user.User, session.Info) error {
+	return f.err
+}
+
+func TestCreateAccountErrorStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{name: "created", status: http.StatusCreated},
+		{name: "phone in use", err: error_service.ErrDuplicatePhoneNumber, status: http.StatusConflict, code: "phone_number_in_use"},
+		{name: "invalid ticket", err: otp.ErrInvalidTicket, status: http.StatusBadRequest, code: "invalid_verification_ticket"},
+		{name: "rate limited", err: limiter.NewLockedError(time.Minute), status: http.StatusTooManyRequests, code: "rate_limited"},
+		{name: "unexpected", err: errors.New("dial tcp 10.0.0.5:27017: connection refused"), status: http.StatusInternalServerError, code: "internal_error"},
+	}
+
+	body := `{"first_name":"Ada","last_name":"Obi","phone_number":"+2348000000000","password":"secret","client":"web","verification_ticket":"ticket"}`
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+			ctx.Request.Header.Set("Content-Type", "application/json")
+
+			NewAuthHandler(fakeAuthenticationUseCase{err: test.err}, nil).CreateAccount(ctx)
+
+			if recorder.Code != test.status {
+				t.Fatalf("status = %d, want %d", recorder.Code, test.status)
+			}
+			var resp response.BaseResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.ErrorCode != test.code {
+				t.Fatalf("code = %q, want %q", resp.ErrorCode, test.code)
+			}
+			if strings.Contains(resp.Message, "10.0.0.5") {
+				t.Fatalf("message %q leaks the cause", resp.Message)
+			}
+		})
+	}
+}
@@ -1,3 +1,126 @@
 package handler
 
-type Payload = map[string]interface{}
+import (
+	"DX/src/api/middleware"
+	"DX/src/domain/entity/auth"
+	"DX/src/domain/entity/session"
+	"DX/src/domain/entity/wallet"
+	"DX/src/pkg/apperror"
+	"DX/src/pkg/response"
+	"DX/src/pkg/statement_service"
+	"DX/src/utils/logger"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const queryDateLayout = "2006-01-02"
+
+var ErrInvalidTransactionType = apperror.New(apperror.Invalid, "invalid_transaction_type", "invalid transaction type")
+var ErrInvalidFromDate = apperror.New(apperror.Invalid, "invalid_from_date", "invalid from date")
+var ErrInvalidToDate = apperror.New(apperror.Invalid, "invalid_to_date", "invalid to date")
+var ErrInvalidForm = apperror.New(apperror.Invalid, "invalid_form", "invalid multipart form")
+
+// TransactionFilter builds a wallet filter from the page, limit, type, category, errand_id, from and to query params.
+func TransactionFilter(ctx *gin.Context) (wallet.Filter, error) {
+	page, _ := strconv.ParseInt(ctx.DefaultQuery("page", "1"), 10, 64)
+	limit, _ := strconv.ParseInt(ctx.DefaultQuery("limit", strconv.FormatInt(wallet.DefaultPageLimit, 10)), 10, 64)
+	filter := wallet.NewFilter("", page, limit)
+
+	if transactionType := ctx.Query("type"); transactionType != "" {
+		if transactionType != wallet.Credit.String() && transactionType != wallet.Debit.String() {
+			return filter, ErrInvalidTransactionType
+		}
+		filter.Type = transactionType
+	}
+	filter.Category = ctx.Query("category")
+	filter.ItemId = ctx.Query("errand_id")
+
+	from, to, err := DateRange(ctx)
+	if err != nil {
+		return filter, err
+	}
+	filter.From, filter.To = from, to
+
+	return filter, nil
+}
+
+// DateRange reads the from and to query params as dates or RFC3339 timestamps, a bare to date covers the whole day.
+func DateRange(ctx *gin.Context) (from, to time.Time, err error) {
+	if value := ctx.Query("from"); value != "" {
+		if from, err = parseQueryDate(value, false); err != nil {
+			return from, to, ErrInvalidFromDate
+		}
+	}
+	if value := ctx.Query("to"); value != "" {
+		if to, err = parseQueryDate(value, true); err != nil {
+			return from, to, ErrInvalidToDate
+		}
+	}
+	return from, to, nil
+}
+
+func parseQueryDate(value string, endOfDay bool) (time.Time, error) {
+	if date, err := time.Parse(queryDateLayout, value); err == nil {
+		if endOfDay {
+			return date.Add(24*time.Hour - time.Nanosecond), nil
+		}
+		return date, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// Principal returns who the request was authenticated as, aborting with 401 when the route is not behind Authorization.
+func Principal(ctx *gin.Context) (*auth.Principal, bool) {
+	principal, ok := middleware.GetPrincipal(ctx)
+	if !ok {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, response.NewUnAuthorizedError())
+	}
+	return principal, ok
+}
+
+// Error aborts the request with the status and code of the error.
+func Error(ctx *gin.Context, err error) {
+	middleware.AbortWithError(ctx, err)
+}
+
+// SessionInfo describes the device making the request.
+func SessionInfo(ctx *gin.Context) session.Info {
+	return session.Info{
+		Ip:        ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+	}
+}
+
+// WriteStatement renders the statement in the requested format as a downloadable file.
+func WriteStatement(ctx *gin.Context, service statement_service.Service, statement *wallet.Statement) {
+	var data []byte
+	var contentType, extension string
+	var err error
+
+	switch ctx.DefaultQuery("format", "json") {
+	case "json":
+		ctx.JSON(http.StatusOK, response.NewOkResponse("statement generated", statement))
+		return
+	case "csv":
+		data, err = service.CSV(statement)
+		contentType, extension = "text/csv", "csv"
+	case "pdf":
+		data, err = service.PDF(statement)
+		contentType, extension = "application/pdf", "pdf"
+	default:
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("format must be one of json, csv or pdf"))
+		return
+	}
+	if err != nil {
+		logger.Error("WriteStatement::", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, response.NewInternalServerError("unable to generate statement"))
+		return
+	}
+
+	filename := fmt.Sprintf("statement-%s-%s.%s", statement.From.Format(queryDateLayout), statement.To.Format(queryDateLayout), extension)
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.Data(http.StatusOK, contentType, data)
+}
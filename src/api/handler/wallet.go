@@ -1,11 +1,12 @@
 package handler
 
 import (
+	walletEntity "DX/src/domain/entity/wallet"
 	"DX/src/domain/usecase/wallet"
 	"DX/src/pkg/response"
+	"DX/src/pkg/statement_service"
 	"github.com/gin-gonic/gin"
 	"net/http"
-	"strings"
 )
 
 type Wallet interface {
@@ -14,46 +15,53 @@ type Wallet interface {
 	GetBalance(*gin.Context)
 	GetWallet(*gin.Context)
 	MakeWithdrawal(*gin.Context)
+	GetTransactions(*gin.Context)
+	GetStatement(*gin.Context)
+	GetEarnings(*gin.Context)
 }
 
 type walletImpl struct {
 	wallet.UseCase
+	StatementService statement_service.Service
 }
 
-func NewWalletHandler(useCase wallet.UseCase) Wallet {
+type paymentRequest struct {
+	Amount         float64 `json:"amount" binding:"required,gt=0"`
+	Type           string  `json:"type" binding:"required,oneof=credit debit"`
+	TransactionPin string  `json:"transaction_pin"`
+	Reference      string  `json:"reference" binding:"max=100"`
+}
+
+type withdrawalRequest struct {
+	Amount         float64 `json:"amount" binding:"required,gt=0"`
+	TransactionPin string  `json:"transaction_pin" binding:"required"`
+}
+
+func NewWalletHandler(useCase wallet.UseCase, statementService statement_service.Service) Wallet {
 	return &walletImpl{
-		UseCase: useCase,
+		UseCase:          useCase,
+		StatementService: statementService,
 	}
 }
 
 func (w *walletImpl) MakePayment(ctx *gin.Context) {
-	var amount float64
-	var paymentType string
-	var ok bool
-
-	var payload Payload
-	err := ctx.ShouldBind(&payload)
-	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
-		return
-	}
-	if amount, ok = payload["amount"].(float64); !ok {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("amount is required"))
+	var request paymentRequest
+	if !Bind(ctx, &request) {
 		return
 	}
-	if paymentType, ok = payload["type"].(string); !ok {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("type is required"))
+	principal, ok := Principal(ctx)
+	if !ok {
 		return
 	}
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
 
-	if paymentType == "credit" {
-		err = w.UseCase.AddMoney(token, int64(amount))
+	var err error
+	if request.Type == "credit" {
+		err = w.UseCase.AddMoney(principal, int64(request.Amount), request.Reference)
 	} else {
-		err = w.UseCase.RemoveMoney(token, int64(amount))
+		err = w.UseCase.RemoveMoney(principal, int64(request.Amount), request.TransactionPin)
 	}
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	}
 
@@ -63,7 +71,7 @@ func (w *walletImpl) MakePayment(ctx *gin.Context) {
 func (w *walletImpl) GetBalance(ctx *gin.Context) {
 	balance, err := w.UseCase.GetBalance()
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	}
 
@@ -71,10 +79,13 @@ func (w *walletImpl) GetBalance(ctx *gin.Context) {
 }
 
 func (w *walletImpl) GetWallet(ctx *gin.Context) {
-	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
-	nWallet, err := w.GetWalletFor(token)
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+	nWallet, err := w.GetWalletFor(principal)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
+		Error(ctx, err)
 		return
 	}
 
@@ -82,8 +93,86 @@ func (w *walletImpl) GetWallet(ctx *gin.Context) {
 }
 
 func (w *walletImpl) MakeWithdrawal(ctx *gin.Context) {
-	//TODO implement me
-	panic("implement me")
+	var request withdrawalRequest
+	if !Bind(ctx, &request) {
+		return
+	}
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+
+	if err := w.UseCase.Withdraw(principal, int64(request.Amount), request.TransactionPin); err != nil {
+		Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("withdrawal successful", nil))
+}
+
+func (w *walletImpl) GetTransactions(ctx *gin.Context) {
+	filter, err := TransactionFilter(ctx)
+	if err != nil {
+		Error(ctx, err)
+		return
+	}
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+
+	page, err := w.UseCase.GetTransactions(principal, filter)
+	if err != nil {
+		Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("transactions fetched", page))
+}
+
+func (w *walletImpl) GetStatement(ctx *gin.Context) {
+	from, to, err := DateRange(ctx)
+	if err != nil {
+		Error(ctx, err)
+		return
+	}
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+
+	statement, err := w.UseCase.GetStatement(principal, from, to)
+	if err != nil {
+		Error(ctx, err)
+		return
+	}
+
+	WriteStatement(ctx, w.StatementService, statement)
+}
+
+func (w *walletImpl) GetEarnings(ctx *gin.Context) {
+	period, err := walletEntity.GetPeriod(ctx.Query("period"))
+	if err != nil {
+		Error(ctx, err)
+		return
+	}
+	from, to, err := DateRange(ctx)
+	if err != nil {
+		Error(ctx, err)
+		return
+	}
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+
+	earnings, err := w.UseCase.GetEarnings(principal, period, from, to)
+	if err != nil {
+		Error(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.NewOkResponse("earnings fetched", earnings))
 }
 
 func (w *walletImpl) PaystackWebhook(ctx *gin.Context) {
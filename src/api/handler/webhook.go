@@ -0,0 +1,121 @@
+package handler
+
+import (
+	webhookEntity "DX/src/domain/entity/webhook"
+	"DX/src/domain/usecase/webhook"
+	"DX/src/pkg/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type Webhook interface {
+	CreateWebhook(*gin.Context)
+	GetWebhooks(*gin.Context)
+	UpdateWebhook(*gin.Context)
+	DeleteWebhook(*gin.Context)
+	GetDeliveries(*gin.Context)
+	Redeliver(*gin.Context)
+}
+
+type webhookImpl struct {
+	webhook.UseCase
+}
+
+func NewWebhookHandler(useCase webhook.UseCase) Webhook {
+	return &webhookImpl{
+		UseCase: useCase,
+	}
+}
+
+// CreateWebhook subscribes a url to events. The secret signing the payloads is only in this response.
+func (w *webhookImpl) CreateWebhook(ctx *gin.Context) {
+	var request webhookEntity.Request
+	if !Bind(ctx, &request) {
+		return
+	}
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+
+	created, err := w.UseCase.CreateSubscription(principal, request)
+	if err != nil {
+		Error(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, response.NewCreatedResponse("webhook created, store the secret now as it will not be shown again", created))
+}
+
+func (w *webhookImpl) GetWebhooks(ctx *gin.Context) {
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+
+	subscriptions, err := w.UseCase.GetSubscriptions(principal)
+	if err != nil {
+		Error(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response.NewOkResponse("webhooks fetched", subscriptions))
+}
+
+// UpdateWebhook changes the url or events, active=true enables a webhook that was disabled after failures.
+func (w *webhookImpl) UpdateWebhook(ctx *gin.Context) {
+	var request webhookEntity.Update
+	if !Bind(ctx, &request) {
+		return
+	}
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+
+	subscription, err := w.UseCase.UpdateSubscription(principal, ctx.Param("id"), request)
+	if err != nil {
+		Error(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response.NewOkResponse("webhook updated", subscription))
+}
+
+func (w *webhookImpl) DeleteWebhook(ctx *gin.Context) {
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+
+	if err := w.UseCase.DeleteSubscription(principal, ctx.Param("id")); err != nil {
+		Error(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response.NewOkResponse("webhook deleted", nil))
+}
+
+func (w *webhookImpl) GetDeliveries(ctx *gin.Context) {
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+
+	deliveries, err := w.UseCase.GetDeliveries(principal, ctx.Param("id"))
+	if err != nil {
+		Error(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response.NewOkResponse("webhook deliveries fetched", deliveries))
+}
+
+func (w *webhookImpl) Redeliver(ctx *gin.Context) {
+	principal, ok := Principal(ctx)
+	if !ok {
+		return
+	}
+
+	delivery, err := w.UseCase.Redeliver(principal, ctx.Param("id"), ctx.Param("delivery_id"))
+	if err != nil {
+		Error(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, response.NewCreatedResponse("webhook redelivery queued", delivery))
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"DX/src/domain/entity/limiter"
+	"DX/src/pkg/apperror"
+	"DX/src/pkg/response"
+	"DX/src/utils/logger"
+	"errors"
+	"github.com/gin-gonic/gin"
+)
+
+// AbortWithError responds with the status, code and public message of the error. Errors that are not the
+// client's fault are logged with their cause.
+func AbortWithError(ctx *gin.Context, err error) {
+	var limitError *limiter.Error
+	if errors.As(err, &limitError) {
+		abortLimited(ctx, limitError)
+		return
+	}
+
+	appErr := apperror.From(err)
+	if appErr.Status() >= 500 {
+		logger.Error(ctx.Request.Method+" "+ctx.FullPath(), err)
+	}
+	resp := response.NewErrorResponse(appErr)
+	ctx.AbortWithStatusJSON(resp.Code, resp)
+}
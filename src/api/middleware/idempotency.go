@@ -0,0 +1,177 @@
+package middleware
+
+import (
+	"DX/src/domain/entity/idempotency"
+	"DX/src/pkg/response"
+	"DX/src/utils/logger"
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const maxIdempotencyKeyLength = 255
+
+type responseRecorder struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(data string) (int, error) {
+	r.body.WriteString(data)
+	return r.ResponseWriter.WriteString(data)
+}
+
+func (m *middleWare) Idempotency() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := strings.TrimSpace(ctx.GetHeader(idempotency.HeaderKey))
+		if key == "" {
+			ctx.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("idempotency key is too long"))
+			return
+		}
+
+		principal, ok := GetPrincipal(ctx)
+		if !ok {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response.NewUnAuthorizedError())
+			return
+		}
+		userId := principal.UserId
+
+		requestBody, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError("invalid request data"))
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewBuffer(requestBody))
+		fingerprint := idempotency.Fingerprint(ctx.Request.Method, ctx.Request.URL.Path, requestBody)
+
+		record := idempotency.New(userId, key, fingerprint)
+		if err = m.IdempotencyRepo.Create(record); err != nil {
+			if !mongo.IsDuplicateKeyError(err) {
+				logger.Error("Idempotency::", err)
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, response.NewInternalServerError("unable to process request"))
+				return
+			}
+			if m.replay(ctx, userId, key, fingerprint) {
+				return
+			}
+			// The previous record has expired but hasn't been cleared by the TTL index yet, or its request
+			// stopped renewing its lease.
+			if err = m.IdempotencyRepo.DeleteStale(userId, key); err == nil {
+				err = m.IdempotencyRepo.Create(record)
+			}
+			if err != nil {
+				logger.Error("Idempotency::", err)
+				ctx.AbortWithStatusJSON(http.StatusConflict, response.NewConflictError("request with this idempotency key is in progress"))
+				return
+			}
+		}
+
+		stopRenewal := m.renewLease(record)
+
+		// A panic in the handler releases the key before Recovery answers, a retry would otherwise be refused
+		// as in progress until the lease ends.
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				stopRenewal()
+				if err := m.IdempotencyRepo.Delete(record); err != nil {
+					logger.Error("Idempotency::", err)
+				}
+				panic(recovered)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: ctx.Writer, body: &bytes.Buffer{}}
+		ctx.Writer = recorder
+		ctx.Next()
+		stopRenewal()
+
+		// Server errors are not stored so the client can safely retry with the same key.
+		if recorder.Status() >= http.StatusInternalServerError {
+			if err = m.IdempotencyRepo.Delete(record); err != nil {
+				logger.Error("Idempotency::", err)
+			}
+			return
+		}
+		record.Complete(recorder.Status(), recorder.Header().Get("Content-Type"), recorder.body.Bytes())
+		if err = m.IdempotencyRepo.Complete(record); err != nil {
+			logger.Error("Idempotency::", err)
+		}
+	}
+}
+
+// leaseRenewal is how often a running request renews the lease on its key.
+var leaseRenewal = idempotency.LeaseRenewal
+
+// renewLease keeps the key held by record while its request runs, so a retry is refused as in progress
+// however long the request takes. The returned func stops the renewal and waits for it.
+func (m *middleWare) renewLease(record *idempotency.Record) func() {
+	stop, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(leaseRenewal)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if err := m.IdempotencyRepo.Renew(record); err != nil {
+					logger.Error("Idempotency::", err)
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(stop)
+			<-stopped
+		})
+	}
+}
+
+// replay writes the stored response for a retried request and reports whether the request was handled.
+// Expired and abandoned records are left for the caller to take over.
+func (m *middleWare) replay(ctx *gin.Context, userId, key, fingerprint string) bool {
+	record, err := m.IdempotencyRepo.Get(userId, key)
+	if err == mongo.ErrNoDocuments {
+		return false
+	}
+	if err != nil {
+		logger.Error("Idempotency::", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, response.NewInternalServerError("unable to process request"))
+		return true
+	}
+
+	if !record.Matches(fingerprint) {
+		ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, response.NewUnprocessableEntityError("idempotency key has already been used for a different request"))
+		return true
+	}
+	if record.IsAbandoned() {
+		return false
+	}
+	if !record.IsCompleted() {
+		ctx.AbortWithStatusJSON(http.StatusConflict, response.NewConflictError("request with this idempotency key is in progress"))
+		return true
+	}
+
+	ctx.Header("Idempotent-Replayed", "true")
+	ctx.Data(record.StatusCode, record.ContentType, record.Body)
+	ctx.Abort()
+	return true
+}
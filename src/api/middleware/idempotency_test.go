@@ -0,0 +1,318 @@
+package middleware
+
+import (
+	"DX/src/domain/entity/auth"
+	"DX/src/domain/entity/idempotency"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeIdempotencyRepository keeps records the way the collection does, one per user and key.
+type fakeIdempotencyRepository struct {
+	mutex   sync.Mutex
+	records map[string]idempotency.Record
+}
+
+func newFakeIdempotencyRepository() *fakeIdempotencyRepository {
+	return &fakeIdempotencyRepository{records: map[string]idempotency.Record{}}
+}
+
+func (f *fakeIdempotencyRepository) Get(userId, key string) (*idempotency.Record, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	record, ok := f.records[userId+key]
+	if !ok || !record.ExpiresAt.After(time.Now()) {
+		return nil, mongo.ErrNoDocuments
+	}
+	return &record, nil
+}
+
+func (f *fakeIdempotencyRepository) Create(record *idempotency.Record) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if _, ok := f.records[record.UserId+record.Key]; ok {
+		return mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000}}}
+	}
+	f.records[record.UserId+record.Key] = *record
+	return nil
+}
+
+func (f *fakeIdempotencyRepository) Complete(record *idempotency.Record) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if stored, ok := f.records[record.UserId+record.Key]; ok && stored.Id == record.Id {
+		f.records[record.UserId+record.Key] = *record
+	}
+	return nil
+}
+
+func (f *fakeIdempotencyRepository) Renew(record *idempotency.Record) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if stored, ok := f.records[record.UserId+record.Key]; ok && stored.Id == record.Id && stored.State == idempotency.Processing {
+		stored.RenewedAt = time.Now()
+		f.records[record.UserId+record.Key] = stored
+	}
+	return nil
+}
+
+func (f *fakeIdempotencyRepository) renewedAt(userId, key string) time.Time {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.records[userId+key].RenewedAt
+}
+
+func (f *fakeIdempotencyRepository) Delete(record *idempotency.Record) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if stored, ok := f.records[record.UserId+record.Key]; ok && stored.Id == record.Id {
+		delete(f.records, record.UserId+record.Key)
+	}
+	return nil
+}
+
+func (f *fakeIdempotencyRepository) DeleteStale(userId, key string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if stored, ok := f.records[userId+key]; ok && (!stored.ExpiresAt.After(time.Now()) || stored.IsAbandoned()) {
+		delete(f.records, userId+key)
+	}
+	return nil
+}
+
+func (f *fakeIdempotencyRepository) size() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return len(f.records)
+}
+
+func newIdempotencyRouter(repo idempotency.Repository, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	m := &middleWare{IdempotencyRepo: repo}
+	router := gin.New()
+	router.Use(gin.CustomRecovery(m.Recovery()))
+	router.POST("/v1/transact", func(ctx *gin.Context) {
+		ctx.Set(principalKey, &auth.Principal{UserId: "user-1"})
+	}, m.Idempotency(), handler)
+	return router
+}
+
+func post(router *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, "/v1/transact", strings.NewReader(body))
+	if key != "" {
+		request.Header.Set(idempotency.HeaderKey, key)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	var calls int32
+	router := newIdempotencyRouter(newFakeIdempotencyRepository(), func(ctx *gin.Context) {
+		body, _ := io.ReadAll(ctx.Request.Body)
+		ctx.JSON(http.StatusCreated, gin.H{"call": atomic.AddInt32(&calls, 1), "body": string(body)})
+	})
+
+	first := post(router, "key-1", `{"amount":500}`)
+	second := post(router, "key-1", `{"amount":500}`)
+	if calls != 1 {
+		t.Fatalf("handler ran %d times, want 1", calls)
+	}
+	if second.Code != first.Code || second.Body.String() != first.Body.String() {
+		t.Fatalf("replay = %d %s, want %d %s", second.Code, second.Body, first.Code, first.Body)
+	}
+	if second.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatal("replay is not marked as replayed")
+	}
+	if first.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatal("first response is marked as replayed")
+	}
+
+	post(router, "", `{"amount":500}`)
+	post(router, "key-2", `{"amount":500}`)
+	if calls != 3 {
+		t.Fatalf("handler ran %d times, want 3", calls)
+	}
+}
+
+func TestIdempotencyFingerprintMismatch(t *testing.T) {
+	var calls int32
+	router := newIdempotencyRouter(newFakeIdempotencyRepository(), func(ctx *gin.Context) {
+		atomic.AddInt32(&calls, 1)
+		ctx.JSON(http.StatusCreated, gin.H{})
+	})
+
+	post(router, "key-1", `{"amount":500}`)
+	if recorder := post(router, "key-1", `{"amount":5000}`); recorder.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusUnprocessableEntity)
+	}
+	if calls != 1 {
+		t.Fatalf("handler ran %d times, want 1", calls)
+	}
+}
+
+func TestIdempotencyConcurrentRequests(t *testing.T) {
+	entered, release := make(chan struct{}), make(chan struct{})
+	var calls int32
+	router := newIdempotencyRouter(newFakeIdempotencyRepository(), func(ctx *gin.Context) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(entered)
+			<-release
+		}
+		ctx.JSON(http.StatusCreated, gin.H{})
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- post(router, "key-1", `{"amount":500}`)
+	}()
+	<-entered
+
+	var wait sync.WaitGroup
+	for n := 0; n < 8; n++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			if recorder := post(router, "key-1", `{"amount":500}`); recorder.Code != http.StatusConflict {
+				t.Errorf("status = %d while the first request runs, want %d", recorder.Code, http.StatusConflict)
+			}
+		}()
+	}
+	wait.Wait()
+	close(release)
+
+	if recorder := <-done; recorder.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusCreated)
+	}
+	if recorder := post(router, "key-1", `{"amount":500}`); recorder.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("status = %d after the first request finished, want a replay", recorder.Code)
+	}
+	if calls != 1 {
+		t.Fatalf("handler ran %d times, want 1", calls)
+	}
+}
+
+func TestIdempotencyReleasesKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler func(*gin.Context)
+		status  int
+	}{
+		{
+			name: "panic",
+			handler: func(*gin.Context) {
+				panic(errors.New("nil map"))
+			},
+			status: http.StatusInternalServerError,
+		},
+		{
+			name: "server error",
+			handler: func(ctx *gin.Context) {
+				ctx.JSON(http.StatusServiceUnavailable, gin.H{})
+			},
+			status: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repo := newFakeIdempotencyRepository()
+			var calls int32
+			router := newIdempotencyRouter(repo, func(ctx *gin.Context) {
+				if atomic.AddInt32(&calls, 1) == 1 {
+					test.handler(ctx)
+					return
+				}
+				ctx.JSON(http.StatusCreated, gin.H{})
+			})
+
+			if recorder := post(router, "key-1", `{"amount":500}`); recorder.Code != test.status {
+				t.Fatalf("status = %d, want %d", recorder.Code, test.status)
+			}
+			if repo.size() != 0 {
+				t.Fatal("key is still held")
+			}
+			if recorder := post(router, "key-1", `{"amount":500}`); recorder.Code != http.StatusCreated {
+				t.Fatalf("retry status = %d, want %d", recorder.Code, http.StatusCreated)
+			}
+		})
+	}
+}
+
+func TestIdempotencyTakesOverAbandonedKey(t *testing.T) {
+	repo := newFakeIdempotencyRepository()
+	fingerprint := idempotency.Fingerprint(http.MethodPost, "/v1/transact", []byte(`{"amount":500}`))
+	abandoned := idempotency.New("user-1", "key-1", fingerprint)
+	abandoned.RenewedAt = time.Now().Add(-idempotency.Lease - time.Second)
+	if err := repo.Create(abandoned); err != nil {
+		t.Fatal(err)
+	}
+	// A request that started long ago but still renews its lease keeps its key
+	held := idempotency.New("user-1", "key-2", fingerprint)
+	held.CreatedAt = time.Now().Add(-time.Hour)
+	if err := repo.Create(held); err != nil {
+		t.Fatal(err)
+	}
+	router := newIdempotencyRouter(repo, func(ctx *gin.Context) {
+		ctx.JSON(http.StatusCreated, gin.H{})
+	})
+
+	if recorder := post(router, "key-1", `{"amount":500}`); recorder.Code != http.StatusCreated {
+		t.Fatalf("abandoned key status = %d, want %d", recorder.Code, http.StatusCreated)
+	}
+	if recorder := post(router, "key-2", `{"amount":500}`); recorder.Code != http.StatusConflict {
+		t.Fatalf("held key status = %d, want %d", recorder.Code, http.StatusConflict)
+	}
+}
+
+func TestIdempotencyRenewsLease(t *testing.T) {
+	renewal := leaseRenewal
+	leaseRenewal = 5 * time.Millisecond
+	t.Cleanup(func() {
+		leaseRenewal = renewal
+	})
+
+	repo := newFakeIdempotencyRepository()
+	entered, release := make(chan struct{}), make(chan struct{})
+	router := newIdempotencyRouter(repo, func(ctx *gin.Context) {
+		close(entered)
+		<-release
+		ctx.JSON(http.StatusCreated, gin.H{})
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- post(router, "key-1", `{"amount":500}`)
+	}()
+	<-entered
+
+	started := repo.renewedAt("user-1", "key-1")
+	deadline := time.Now().Add(5 * time.Second)
+	for !repo.renewedAt("user-1", "key-1").After(started) {
+		if time.Now().After(deadline) {
+			t.Fatal("the lease of a running request was not renewed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+
+	if recorder := <-done; recorder.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusCreated)
+	}
+	// The renewal stops with the request
+	finished := repo.renewedAt("user-1", "key-1")
+	time.Sleep(20 * time.Millisecond)
+	if renewed := repo.renewedAt("user-1", "key-1"); !renewed.Equal(finished) {
+		t.Fatal("the lease was renewed after the request finished")
+	}
+}
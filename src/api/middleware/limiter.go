@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"DX/src/domain/entity/limiter"
+	"DX/src/pkg/response"
+	"github.com/gin-gonic/gin"
+	"strconv"
+)
+
+// RateLimit counts the requests of each IP address against the rule and rejects them once it is over
+// the limit.
+func (m *middleWare) RateLimit(rule limiter.Rule) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if err := m.LimiterUseCase.Hit(rule, ctx.ClientIP()); err != nil {
+			AbortWithError(ctx, err)
+			return
+		}
+		ctx.Next()
+	}
+}
+
+func abortLimited(ctx *gin.Context, limitError *limiter.Error) {
+	resp := response.NewTooManyRequestsError(limitError.Message, limitError.RetryAfter)
+	ctx.Header("Retry-After", strconv.FormatInt(int64(limitError.RetryAfter.Seconds())+1, 10))
+	ctx.AbortWithStatusJSON(resp.Code, resp)
+}
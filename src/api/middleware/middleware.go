@@ -1,154 +1,131 @@
 package middleware
 
 import (
+	"DX/src/domain/entity/apikey"
 	"DX/src/domain/entity/auth"
+	"DX/src/domain/entity/idempotency"
+	"DX/src/domain/entity/limiter"
+	"DX/src/domain/entity/role"
 	"DX/src/domain/entity/user"
+	apiKeyUseCase "DX/src/domain/usecase/apikey"
+	limiterUseCase "DX/src/domain/usecase/limiter"
+	roleUseCase "DX/src/domain/usecase/role"
+	sessionUseCase "DX/src/domain/usecase/session"
 	"DX/src/pkg/response"
-	"DX/src/pkg/token_service"
 	"DX/src/utils/logger"
 	"github.com/gin-gonic/gin"
 	"net/http"
-	"strings"
 )
 
 type Middleware interface {
 	Suspension() gin.HandlerFunc
-	Authorization() gin.HandlerFunc
-	Admin() gin.HandlerFunc
-	SuperAdmin() gin.HandlerFunc
+	Authorization(...apikey.Scope) gin.HandlerFunc
+	RequirePermission(...role.Permission) gin.HandlerFunc
+	TwoFactor() gin.HandlerFunc
+	Idempotency() gin.HandlerFunc
+	RateLimit(limiter.Rule) gin.HandlerFunc
 	CORS() gin.HandlerFunc
 	Recovery() gin.RecoveryFunc
 }
 
 type middleWare struct {
 	user.Repository
-	token_service.Service
 	auth.Manager
+	IdempotencyRepo idempotency.Repository
+	LimiterUseCase  limiterUseCase.UseCase
+	SessionUseCase  sessionUseCase.UseCase
+	RoleUseCase     roleUseCase.UseCase
+	ApiKeyUseCase   apiKeyUseCase.UseCase
 }
 
-func NewErrandMiddleware(repo user.Repository, service token_service.Service, manager auth.Manager) Middleware {
+func NewErrandMiddleware(repo user.Repository, manager auth.Manager, idempotencyRepo idempotency.Repository,
+	limiterUseCase limiterUseCase.UseCase, sessionUseCase sessionUseCase.UseCase,
+	roleUseCase roleUseCase.UseCase, apiKeyUseCase apiKeyUseCase.UseCase) Middleware {
 	return &middleWare{
-		Repository: repo,
-		Service:    service,
-		Manager:    manager,
+		Repository:      repo,
+		Manager:         manager,
+		IdempotencyRepo: idempotencyRepo,
+		LimiterUseCase:  limiterUseCase,
+		SessionUseCase:  sessionUseCase,
+		RoleUseCase:     roleUseCase,
+		ApiKeyUseCase:   apiKeyUseCase,
 	}
 }
 
+// Suspension refuses requests from suspended or deleted accounts, it runs after Authorization.
 func (m *middleWare) Suspension() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		authHeader := ctx.GetHeader("Authorization")
-		if strings.TrimSpace(authHeader) == "" {
+		principal, ok := GetPrincipal(ctx)
+		if !ok || principal.Suspended {
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response.NewUnAuthorizedError())
 			return
 		}
-		token := strings.Split(authHeader, " ")[1]
-		userId, resp := m.Manager.Get(token)
-		if resp != nil {
-			ctx.JSON(resp.Code, resp)
-			ctx.Abort()
-			return
-		}
-
-		nUser, err := m.Repository.GetWithId(*userId)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, response.NewUnAuthorizedError())
-			ctx.Abort()
-			return
-		}
-
-		if nUser.IsSuspended || nUser.IsDeleted {
-			ctx.JSON(http.StatusUnauthorized, response.NewUnAuthorizedError())
-			ctx.Abort()
-			return
-		}
 
 		ctx.Next()
 	}
 }
 
-func (m *middleWare) Authorization() gin.HandlerFunc {
+// Authorization resolves the access token and its user once and puts the principal on the context for
+// the middlewares and handlers after it. API keys are only accepted when the route names the scopes
+// they need.
+func (m *middleWare) Authorization(scopes ...apikey.Scope) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		tokenPayload := ctx.GetHeader("Authorization")
-		if strings.TrimSpace(tokenPayload) == "" {
-			ctx.JSON(http.StatusUnauthorized, response.NewUnAuthorizedError())
-			ctx.Abort()
-			return
-		}
-		bearerToken := strings.Split(tokenPayload, " ")
-		if len(bearerToken) != 2 {
-			ctx.JSON(http.StatusUnauthorized, response.NewUnAuthorizedError())
-			ctx.Abort()
-			return
-		}
-		userToken := bearerToken[1]
-		if strings.TrimSpace(userToken) == "" {
-			ctx.JSON(http.StatusUnauthorized, response.NewUnAuthorizedError())
-			ctx.Abort()
+		value, ok := bearerToken(ctx)
+		if !ok {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response.NewUnAuthorizedError())
 			return
 		}
-		if err := m.Service.CheckTokenValidity(userToken, true); err != nil {
-			ctx.JSON(http.StatusUnauthorized, response.NewUnAuthorizedError())
-			ctx.Abort()
+		if apikey.IsKey(value) {
+			m.authorizeKey(ctx, value, scopes)
 			return
 		}
-		ctx.Next()
-	}
-}
 
-func (m *middleWare) Admin() gin.HandlerFunc {
-	return func(ctx *gin.Context) {
-		authHeader := ctx.GetHeader("Authorization")
-		if strings.TrimSpace(authHeader) == "" {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response.NewUnAuthorizedError())
-			return
-		}
-		token := strings.Split(authHeader, " ")[1]
-		userId, resp := m.Manager.Get(token)
+		token, resp := m.Manager.GetToken(value)
 		if resp != nil {
-			ctx.AbortWithStatusJSON(resp.Code, resp)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response.NewUnAuthorizedError())
 			return
 		}
 
-		nUser, err := m.Repository.GetWithId(*userId)
+		nUser, err := m.Repository.GetWithId(token.UserId)
 		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
-			return
-		}
-
-		if !nUser.IsAdmin() {
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response.NewUnAuthorizedError())
 			return
 		}
+
+		ctx.Set(principalKey, auth.NewPrincipal(token, nUser.RoleNames(), nUser.IsSuspended || nUser.IsDeleted))
 		ctx.Next()
 	}
 }
 
-func (m *middleWare) SuperAdmin() gin.HandlerFunc {
-	return func(ctx *gin.Context) {
-		authHeader := ctx.GetHeader("Authorization")
-		if strings.TrimSpace(authHeader) == "" {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response.NewUnAuthorizedError())
-			return
-		}
-		token := strings.Split(authHeader, " ")[1]
-		userId, resp := m.Manager.Get(token)
-		if resp != nil {
-			ctx.AbortWithStatusJSON(resp.Code, resp)
-			return
-		}
-
-		nUser, err := m.Repository.GetWithId(*userId)
-		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusBadRequest, response.NewBadRequestError(err.Error()))
-			return
-		}
+// authorizeKey lets a partner system in with an API key holding the scopes, within the rate limit of the key.
+func (m *middleWare) authorizeKey(ctx *gin.Context, value string, scopes []apikey.Scope) {
+	if len(scopes) == 0 {
+		AbortWithError(ctx, apikey.ErrNotAccepted)
+		return
+	}
+	apiKey, err := m.ApiKeyUseCase.Authenticate(value)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, response.NewUnAuthorizedError())
+		return
+	}
+	if !apiKey.Allows(scopes...) {
+		AbortWithError(ctx, apikey.ErrMissingScope)
+		return
+	}
+	if err = m.LimiterUseCase.Hit(apiKey.Rule(), apiKey.Id.Hex()); err != nil {
+		AbortWithError(ctx, err)
+		return
+	}
 
-		if !nUser.IsAdmin() {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response.NewUnAuthorizedError())
-			return
-		}
-		ctx.Next()
+	nUser, err := m.Repository.GetWithId(apiKey.UserId)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, response.NewUnAuthorizedError())
+		return
 	}
+
+	principal := auth.NewKeyPrincipal(apiKey.UserId, apiKey.Id.Hex(), apiKey.ScopeNames(), nUser.IsSuspended || nUser.IsDeleted)
+	ctx.Set(principalKey, principal)
+	ctx.Next()
 }
 
 func (m *middleWare) CORS() gin.HandlerFunc {
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"DX/src/domain/entity/auth"
+	"github.com/gin-gonic/gin"
+	"strings"
+)
+
+const principalKey = "principal"
+
+// GetPrincipal returns who the request was authenticated as by Authorization.
+func GetPrincipal(ctx *gin.Context) (*auth.Principal, bool) {
+	value, ok := ctx.Get(principalKey)
+	if !ok {
+		return nil, false
+	}
+	principal, ok := value.(*auth.Principal)
+	return principal, ok
+}
+
+func bearerToken(ctx *gin.Context) (string, bool) {
+	bearer := strings.Fields(ctx.GetHeader("Authorization"))
+	if len(bearer) != 2 || !strings.EqualFold(bearer[0], "Bearer") {
+		return "", false
+	}
+	return bearer[1], true
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"DX/src/domain/entity/role"
+	"DX/src/pkg/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// RequirePermission refuses requests from users whose roles do not grant every one of the permissions.
+func (m *middleWare) RequirePermission(permissions ...role.Permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal, ok := GetPrincipal(ctx)
+		if !ok {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response.NewUnAuthorizedError())
+			return
+		}
+
+		if err := m.RoleUseCase.HasPermissions(principal.Roles, permissions...); err != nil {
+			AbortWithError(ctx, err)
+			return
+		}
+		ctx.Next()
+	}
+}
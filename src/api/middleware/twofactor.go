@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"DX/src/domain/entity/twofactor"
+	"DX/src/pkg/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// TwoFactor refuses requests from sessions that did not pass two-factor authentication.
+func (m *middleWare) TwoFactor() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal, ok := GetPrincipal(ctx)
+		if !ok {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response.NewUnAuthorizedError())
+			return
+		}
+
+		passed, err := m.SessionUseCase.HasPassedTwoFactor(principal)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response.NewUnAuthorizedError())
+			return
+		}
+		if !passed {
+			AbortWithError(ctx, twofactor.ErrRequired)
+			return
+		}
+		ctx.Next()
+	}
+}
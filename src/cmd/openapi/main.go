@@ -0,0 +1,34 @@
+package main
+
+import (
+	"DX/src/api/application"
+	"DX/src/pkg/openapi"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+)
+
+// Writes the OpenAPI document of the API and the client generated from it, see go:generate in application.
+func main() {
+	specPath := flag.String("spec", "sdk/openapi.json", "file the OpenAPI document is written to")
+	typescriptPath := flag.String("typescript", "sdk/typescript/client.ts", "file the TypeScript client is written to")
+	flag.Parse()
+
+	document := application.Spec()
+	spec, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	write(*specPath, append(spec, '\n'))
+	write(*typescriptPath, openapi.TypeScript(document))
+}
+
+func write(path string, content []byte) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		panic(err)
+	}
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,184 @@
+package apikey
+
+import (
+	"DX/src/domain/entity"
+	"DX/src/domain/entity/limiter"
+	"DX/src/domain/entity/otp"
+	"DX/src/pkg/apperror"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Scope is what a partner system may do with an API key.
+type Scope string
+
+const (
+	ErrandsCreate  Scope = "errands:create"
+	ErrandsRead    Scope = "errands:read"
+	WalletRead     Scope = "wallet:read"
+	WebhooksManage Scope = "webhooks:manage"
+)
+
+var Scopes = []Scope{ErrandsCreate, ErrandsRead, WalletRead, WebhooksManage}
+
+const (
+	Prefix           = "dxk_" // tells API keys apart from access tokens in the Authorization header
+	MaxKeys          = 10
+	DefaultRateLimit = 60 // requests a minute
+	MaxRateLimit     = 600
+	maxNameLength    = 60
+	lastUsedInterval = time.Minute // last use is recorded at most this often
+)
+
+var ErrInvalidKey = apperror.New(apperror.Unauthorized, "invalid_api_key", "invalid api key")
+var ErrUnknownScope = apperror.New(apperror.Invalid, "unknown_scope", "unknown scope")
+var ErrNoScopes = apperror.New(apperror.Invalid, "no_scopes", "an api key needs at least one scope")
+var ErrInvalidName = apperror.New(apperror.Invalid, "invalid_api_key_name", fmt.Sprintf("api key name is required and at most %d characters", maxNameLength))
+var ErrInvalidRateLimit = apperror.New(apperror.Invalid, "invalid_rate_limit", fmt.Sprintf("rate limit must be between 1 and %d requests a minute", MaxRateLimit))
+var ErrTooManyKeys = apperror.New(apperror.Conflict, "too_many_api_keys", fmt.Sprintf("you can have at most %d active api keys", MaxKeys))
+var ErrUnknownKey = apperror.New(apperror.NotFound, "api_key_not_found", "unknown api key")
+var ErrNotAccepted = apperror.New(apperror.Forbidden, "api_key_not_accepted", "api keys cannot be used for this request")
+var ErrMissingScope = apperror.New(apperror.Forbidden, "missing_scope", "api key is missing a required scope")
+
+// ApiKey lets a partner system act for the user that created it within its scopes. Only the hash of the
+// key is stored, the key itself is shown once when it is created.
+type ApiKey struct {
+	Id         entity.DatabaseId `json:"id" bson:"_id"`
+	UserId     string            `json:"-" bson:"user_id"`
+	Name       string            `json:"name" bson:"name"`
+	Hint       string            `json:"hint" bson:"hint"` // last characters of the key so it can be recognised
+	Hash       string            `json:"-" bson:"hash"`
+	Scopes     []Scope           `json:"scopes" bson:"scopes"`
+	RateLimit  int               `json:"rate_limit" bson:"rate_limit"`
+	CreatedAt  time.Time         `json:"created_at" bson:"created_at"`
+	LastUsedAt *time.Time        `json:"last_used_at" bson:"last_used_at"`
+	RevokedAt  *time.Time        `json:"revoked_at,omitempty" bson:"revoked_at"`
+}
+
+// Created is returned once when a key is created, it is the only time the key can be read.
+type Created struct {
+	*ApiKey
+	Key string `json:"key"`
+}
+
+// Request is the key a user creates, the rate limit defaults to DefaultRateLimit.
+type Request struct {
+	Name      string   `json:"name" binding:"required,notblank,max=60"`
+	Scopes    []string `json:"scopes" binding:"required,min=1,dive,notblank"`
+	RateLimit *int     `json:"rate_limit" binding:"omitempty,min=1,max=600"`
+}
+
+func New(userId string, request Request) (*Created, error) {
+	name := strings.TrimSpace(request.Name)
+	if name == "" || len(name) > maxNameLength {
+		return nil, ErrInvalidName
+	}
+
+	if len(request.Scopes) == 0 {
+		return nil, ErrNoScopes
+	}
+	var scopes []Scope
+	for _, value := range request.Scopes {
+		scope, err := GetScope(value)
+		if err != nil {
+			return nil, err
+		}
+		if !contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	rateLimit := DefaultRateLimit
+	if request.RateLimit != nil {
+		if *request.RateLimit < 1 || *request.RateLimit > MaxRateLimit {
+			return nil, ErrInvalidRateLimit
+		}
+		rateLimit = *request.RateLimit
+	}
+
+	key, err := newKey()
+	if err != nil {
+		return nil, err
+	}
+	return &Created{
+		ApiKey: &ApiKey{
+			Id:        entity.NewDatabaseId(),
+			UserId:    userId,
+			Name:      name,
+			Hint:      key[len(key)-4:],
+			Hash:      Hash(key),
+			Scopes:    scopes,
+			RateLimit: rateLimit,
+			CreatedAt: time.Now(),
+		},
+		Key: key,
+	}, nil
+}
+
+func (a *ApiKey) IsRevoked() bool {
+	return a.RevokedAt != nil
+}
+
+// Allows reports whether the key holds every one of the scopes.
+func (a *ApiKey) Allows(scopes ...Scope) bool {
+	for _, scope := range scopes {
+		if !contains(a.Scopes, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// Rule limits the requests made with the key to its rate limit.
+func (a *ApiKey) Rule() limiter.Rule {
+	return limiter.Rule{Name: "api-key", Limit: a.RateLimit, Window: time.Minute}
+}
+
+func (a *ApiKey) ScopeNames() []string {
+	names := make([]string, 0, len(a.Scopes))
+	for _, scope := range a.Scopes {
+		names = append(names, string(scope))
+	}
+	return names
+}
+
+// ShouldTouch reports whether the last use is stale enough to be recorded again.
+func (a *ApiKey) ShouldTouch(cTime time.Time) bool {
+	return a.LastUsedAt == nil || cTime.Sub(*a.LastUsedAt) >= lastUsedInterval
+}
+
+func IsKey(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
+
+func Hash(key string) string {
+	return otp.Hash(key)
+}
+
+func GetScope(value string) (Scope, error) {
+	scope := Scope(strings.ToLower(strings.TrimSpace(value)))
+	if !contains(Scopes, scope) {
+		return "", ErrUnknownScope.WithDetail(value)
+	}
+	return scope, nil
+}
+
+func newKey() (string, error) {
+	value := make([]byte, 32)
+	if _, err := rand.Read(value); err != nil {
+		return "", err
+	}
+	return Prefix + base64.RawURLEncoding.EncodeToString(value), nil
+}
+
+func contains(scopes []Scope, scope Scope) bool {
+	for _, value := range scopes {
+		if value == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package apikey
+
+import (
+	"DX/src/domain/entity"
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type reader interface {
+	GetByHash(string) (*ApiKey, error)
+	GetAll(string) ([]ApiKey, error)
+	CountActive(string) (int64, error)
+}
+
+type writer interface {
+	Create(*ApiKey) error
+	Touch(entity.DatabaseId, time.Time) error
+	Revoke(string, string) error
+}
+
+type Repository interface {
+	reader
+	writer
+}
+
+type repository struct {
+	Collection *mongo.Collection
+}
+
+func NewRepository(collection *mongo.Collection) Repository {
+	return &repository{
+		Collection: collection,
+	}
+}
+
+func (r *repository) Create(apiKey *ApiKey) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.Collection.InsertOne(ctx, apiKey)
+	return err
+}
+
+func (r *repository) GetByHash(hash string) (*ApiKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	apiKey := &ApiKey{}
+	if err := r.Collection.FindOne(ctx, bson.M{"hash": hash}).Decode(apiKey); err != nil {
+		return nil, err
+	}
+	return apiKey, nil
+}
+
+func (r *repository) GetAll(userId string) (apiKeys []ApiKey, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+	crs, err := r.Collection.Find(ctx, bson.M{"user_id": userId}, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err = crs.All(ctx, &apiKeys); err != nil {
+		return nil, err
+	}
+
+	return apiKeys, nil
+}
+
+func (r *repository) CountActive(userId string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return r.Collection.CountDocuments(ctx, bson.M{"user_id": userId, "revoked_at": nil})
+}
+
+func (r *repository) Touch(id entity.DatabaseId, usedAt time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.Collection.UpdateByID(ctx, id, bson.M{"$set": bson.M{"last_used_at": usedAt}})
+	return err
+}
+
+func (r *repository) Revoke(userId, id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	keyId, err := entity.StringToErrandId(id)
+	if err != nil {
+		return ErrUnknownKey
+	}
+	filter := bson.M{"_id": keyId, "user_id": userId, "revoked_at": nil}
+	result, err := r.Collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"revoked_at": time.Now()}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrUnknownKey
+	}
+	return nil
+}
@@ -1,13 +1,38 @@
 package auth
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"DX/src/pkg/apperror"
+	"DX/src/pkg/response"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
 
+var ErrUnauthorized = apperror.New(apperror.Unauthorized, apperror.Unauthorized.Code(), response.Unauthorized)
+
+const (
+	AccessTokenLifetime  = 15 * time.Minute
+	RefreshTokenLifetime = 30 * 24 * time.Hour
+)
+
+// Token is an access and refresh token pair issued to a device. Every refresh rotates the pair
+// within the same family, the previous pair is kept as rotated so a replayed refresh token can be detected.
 type Token struct {
-	AccessToken         string             `json:"access_token,omitempty" bson:"access_token"`
-	RefreshToken        string             `json:"-,omitempty" bson:"refresh_token,omitempty"`
-	AccessUuid          string             `json:"access_uuid,omitempty" bson:"access_uuid"`
-	RefreshUuid         string             `json:"refresh_uuid,omitempty" bson:"refresh_uuid,omitempty"`
-	AccessTokenExpires  primitive.DateTime `json:"access_token_expires,omitempty" bson:"access_token_expires"`
-	RefreshTokenExpires int64              `json:"refresh_token_expires,omitempty" bson:"refresh_token_expires,omitempty"`
-	UserId              string             `json:"user_id" bson:"user_id"`
+	AccessToken         string             `json:"access_token,omitempty" bson:"-"`
+	RefreshToken        string             `json:"refresh_token,omitempty" bson:"-"`
+	AccessUuid          string             `json:"-" bson:"access_uuid"`
+	RefreshUuid         string             `json:"-" bson:"refresh_uuid"`
+	Family              string             `json:"-" bson:"family"`
+	DeviceId            string             `json:"-" bson:"device_id"`
+	Rotated             bool               `json:"-" bson:"rotated"`
+	AccessTokenExpires  primitive.DateTime `json:"access_token_expires" bson:"access_token_expires"`
+	RefreshTokenExpires primitive.DateTime `json:"refresh_token_expires" bson:"refresh_token_expires"`
+	UserId              string             `json:"-" bson:"user_id"`
+}
+
+func (t *Token) AccessExpiry() time.Time {
+	return t.AccessTokenExpires.Time()
+}
+
+func (t *Token) RefreshExpiry() time.Time {
+	return t.RefreshTokenExpires.Time()
 }
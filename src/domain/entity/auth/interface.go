@@ -6,12 +6,17 @@ import (
 
 type reader interface {
 	Get(string) (*string, *response.BaseResponse)
+	GetRefreshToken(string) (*Token, *response.BaseResponse)
+	GetAccessToken(string) (*Token, *response.BaseResponse)
 }
 
 type writer interface {
 	CreateToken(*Token, string) *response.BaseResponse
 	RefreshToken(*Token, string, string) *response.BaseResponse
 	DeleteToken(string) *response.BaseResponse
+	DeleteFamily(string) *response.BaseResponse
+	DeleteDeviceTokens(string, string) *response.BaseResponse
+	DeleteUserTokens(string, string) *response.BaseResponse
 }
 
 type Repository interface {
@@ -21,4 +26,6 @@ type Repository interface {
 
 type Manager interface {
 	Repository
+	Refresh(string) (*Token, *response.BaseResponse)
+	GetToken(string) (*Token, *response.BaseResponse)
 }
@@ -3,6 +3,10 @@ package auth
 import (
 	"DX/src/pkg/response"
 	"DX/src/pkg/token_service"
+	"DX/src/utils/logger"
+	"errors"
+	"fmt"
+	"net/http"
 )
 
 type manager struct {
@@ -25,17 +29,21 @@ func (m *manager) Get(accessToken string) (*string, *response.BaseResponse) {
 	return m.Repository.Get(uuid)
 }
 
-func (m *manager) CreateToken(token *Token, userId string) *response.BaseResponse {
-	accessToken, refreshToken, err := m.Service.Create(int64(token.AccessTokenExpires), token.RefreshTokenExpires, token.AccessUuid, token.RefreshUuid, userId)
+// GetToken returns the stored token pair of the access token, its family identifies the session.
+func (m *manager) GetToken(accessToken string) (*Token, *response.BaseResponse) {
+	uuid, err := m.Service.GetTokenMetaData(accessToken, true)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return m.Repository.GetAccessToken(uuid)
+}
 
-	//Update token values
-	token.AccessToken = accessToken
-	token.RefreshToken = refreshToken
+func (m *manager) CreateToken(token *Token, userId string) *response.BaseResponse {
+	if err := m.sign(token, userId); err != nil {
+		return err
+	}
 
-	//Save tokens to redis
+	//Save tokens
 	return m.Repository.CreateToken(token, userId)
 }
 
@@ -47,6 +55,55 @@ func (m *manager) DeleteToken(accessToken string) *response.BaseResponse {
 	return m.Repository.DeleteToken(uuid)
 }
 
-func (m *manager) RefreshToken(*Token, string, string) *response.BaseResponse {
+func (m *manager) RefreshToken(token *Token, userId string, oldRefreshUuid string) *response.BaseResponse {
+	if err := m.sign(token, userId); err != nil {
+		return err
+	}
+	return m.Repository.RefreshToken(token, userId, oldRefreshUuid)
+}
+
+// Refresh exchanges a refresh token for a new token pair. Presenting a refresh token that has already
+// been rotated means it was stolen or replayed, so the whole family is revoked.
+func (m *manager) Refresh(refreshToken string) (*Token, *response.BaseResponse) {
+	uuid, resp := m.Service.GetTokenMetaData(refreshToken, false)
+	if resp != nil {
+		return nil, resp
+	}
+	oldToken, resp := m.Repository.GetRefreshToken(uuid)
+	if resp != nil {
+		return nil, resp
+	}
+	if oldToken.Rotated {
+		m.revokeFamily(oldToken)
+		return nil, response.NewUnAuthorizedError()
+	}
+
+	token := oldToken.Rotate()
+	if resp = m.RefreshToken(token, oldToken.UserId, uuid); resp != nil {
+		if resp.Code == http.StatusUnauthorized {
+			m.revokeFamily(oldToken)
+		}
+		return nil, resp
+	}
+
+	return token, nil
+}
+
+func (m *manager) revokeFamily(token *Token) {
+	logger.Error("refresh token reuse detected", fmt.Errorf("user: %s, family: %s", token.UserId, token.Family))
+	if resp := m.Repository.DeleteFamily(token.Family); resp != nil {
+		logger.Error("unable to revoke token family", errors.New(resp.Message))
+	}
+}
+
+func (m *manager) sign(token *Token, userId string) *response.BaseResponse {
+	accessToken, refreshToken, err := m.Service.Create(token.AccessExpiry().Unix(), token.RefreshExpiry().Unix(), token.AccessUuid, token.RefreshUuid, userId)
+	if err != nil {
+		return err
+	}
+
+	//Update token values
+	token.AccessToken = accessToken
+	token.RefreshToken = refreshToken
 	return nil
 }
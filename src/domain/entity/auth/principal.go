@@ -0,0 +1,44 @@
+package auth
+
+// Principal is who a request is authenticated as. The middleware resolves it once per request and the
+// use cases take it instead of the raw access token.
+type Principal struct {
+	UserId     string
+	SessionId  string // family of the token pair
+	DeviceId   string
+	AccessUuid string
+	Roles      []string
+	Suspended  bool     // suspended or deleted accounts may only sign out and manage their sessions
+	ApiKeyId   string   // set when a partner system authenticated with an API key instead of a token
+	Scopes     []string // what the API key may do
+}
+
+func NewPrincipal(token *Token, roles []string, suspended bool) *Principal {
+	return &Principal{
+		UserId:     token.UserId,
+		SessionId:  token.Family,
+		DeviceId:   token.DeviceId,
+		AccessUuid: token.AccessUuid,
+		Roles:      roles,
+		Suspended:  suspended,
+	}
+}
+
+// NewKeyPrincipal acts for the owner of an API key. It carries no roles, so keys never reach the admin side.
+func NewKeyPrincipal(userId, apiKeyId string, scopes []string, suspended bool) *Principal {
+	return &Principal{
+		UserId:    userId,
+		ApiKeyId:  apiKeyId,
+		Scopes:    scopes,
+		Suspended: suspended,
+	}
+}
+
+// IsAdmin reports whether the principal holds any role on the admin side.
+func (p *Principal) IsAdmin() bool {
+	return len(p.Roles) > 0
+}
+
+func (p *Principal) IsApiKey() bool {
+	return p.ApiKeyId != ""
+}
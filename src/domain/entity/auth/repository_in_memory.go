@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"DX/src/pkg/response"
+	"sync"
+	"time"
+)
+
+// inMemoryRepository keeps tokens in the process, it is meant for local development and tests
+// since tokens are lost on restart and not shared between instances.
+type inMemoryRepository struct {
+	mutex  sync.RWMutex
+	tokens map[string]Token  // keyed by refresh uuid
+	access map[string]string // access uuid to refresh uuid
+}
+
+func NewInMemoryRepository() Repository {
+	return &inMemoryRepository{
+		tokens: map[string]Token{},
+		access: map[string]string{},
+	}
+}
+
+func (r *inMemoryRepository) CreateToken(token *Token, userId string) *response.BaseResponse {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.create(token, userId)
+	return nil
+}
+
+func (r *inMemoryRepository) Get(tokenUuid string) (*string, *response.BaseResponse) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	token, ok := r.tokens[r.access[tokenUuid]]
+	if !ok || token.Rotated || time.Now().After(token.AccessExpiry()) {
+		return nil, response.NewUnAuthorizedError()
+	}
+	return &token.UserId, nil
+}
+
+func (r *inMemoryRepository) GetRefreshToken(refreshUuid string) (*Token, *response.BaseResponse) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	token, ok := r.tokens[refreshUuid]
+	if !ok || time.Now().After(token.RefreshExpiry()) {
+		return nil, response.NewUnAuthorizedError()
+	}
+	return &token, nil
+}
+
+func (r *inMemoryRepository) GetAccessToken(accessUuid string) (*Token, *response.BaseResponse) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	token, ok := r.tokens[r.access[accessUuid]]
+	if !ok || token.Rotated || time.Now().After(token.AccessExpiry()) {
+		return nil, response.NewUnAuthorizedError()
+	}
+	return &token, nil
+}
+
+func (r *inMemoryRepository) DeleteToken(tokenUuid string) *response.BaseResponse {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if token, ok := r.tokens[r.access[tokenUuid]]; ok {
+		r.deleteWhere(func(t Token) bool { return t.Family == token.Family })
+	}
+	return nil
+}
+
+func (r *inMemoryRepository) DeleteFamily(family string) *response.BaseResponse {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.deleteWhere(func(t Token) bool { return t.Family == family })
+	return nil
+}
+
+func (r *inMemoryRepository) DeleteDeviceTokens(userId, deviceId string) *response.BaseResponse {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.deleteWhere(func(t Token) bool { return t.UserId == userId && t.DeviceId == deviceId })
+	return nil
+}
+
+func (r *inMemoryRepository) DeleteUserTokens(userId, exceptFamily string) *response.BaseResponse {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.deleteWhere(func(t Token) bool { return t.UserId == userId && t.Family != exceptFamily })
+	return nil
+}
+
+func (r *inMemoryRepository) RefreshToken(token *Token, userId string, oldRefreshUuid string) *response.BaseResponse {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	oldToken, ok := r.tokens[oldRefreshUuid]
+	if !ok || oldToken.Rotated {
+		return response.NewUnAuthorizedError()
+	}
+	oldToken.Rotated = true
+	r.tokens[oldRefreshUuid] = oldToken
+
+	r.create(token, userId)
+	return nil
+}
+
+func (r *inMemoryRepository) create(token *Token, userId string) {
+	token.UserId = userId
+	r.tokens[token.RefreshUuid] = *token
+	r.access[token.AccessUuid] = token.RefreshUuid
+}
+
+func (r *inMemoryRepository) deleteWhere(match func(Token) bool) {
+	for refreshUuid, token := range r.tokens {
+		if match(token) {
+			delete(r.tokens, refreshUuid)
+			delete(r.access, token.AccessUuid)
+		}
+	}
+}
@@ -16,8 +16,8 @@ type mongoRepository struct {
 	error_service.Service
 }
 
-func NewMongoCacheRepository(collection *mongo.Collection) Repository {
-	return &mongoRepository{Collection: collection}
+func NewMongoCacheRepository(collection *mongo.Collection, errorService error_service.Service) Repository {
+	return &mongoRepository{Collection: collection, Service: errorService}
 }
 
 func (mr *mongoRepository) CreateToken(token *Token, userId string) *response.BaseResponse {
@@ -39,30 +39,131 @@ func (mr *mongoRepository) Get(tokenUuid string) (*string, *response.BaseRespons
 	defer cancel()
 	filter := bson.M{
 		"access_uuid": tokenUuid,
+		"rotated":     bson.M{"$ne": true},
 	}
 	token := &Token{}
 	if err := mr.Collection.FindOne(ctx, filter).Decode(token); err != nil {
 		logger.Error(fmt.Sprintf("error fetching token: %v", tokenUuid), err)
+		if err == mongo.ErrNoDocuments {
+			return nil, response.NewUnAuthorizedError()
+		}
 		return nil, mr.HandleMongoDbError("token", err)
 	}
 
 	return &token.UserId, nil
 }
 
-func (mr *mongoRepository) DeleteToken(tokenUuid string) *response.BaseResponse {
+func (mr *mongoRepository) GetRefreshToken(refreshUuid string) (*Token, *response.BaseResponse) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	token := &Token{}
+	if err := mr.Collection.FindOne(ctx, bson.M{"refresh_uuid": refreshUuid}).Decode(token); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, response.NewUnAuthorizedError()
+		}
+		return nil, mr.HandleMongoDbError("token", err)
+	}
+
+	return token, nil
+}
+
+func (mr *mongoRepository) GetAccessToken(accessUuid string) (*Token, *response.BaseResponse) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	filter := bson.M{
-		"access_uuid": tokenUuid,
+		"access_uuid": accessUuid,
+		"rotated":     bson.M{"$ne": true},
+	}
+	token := &Token{}
+	if err := mr.Collection.FindOne(ctx, filter).Decode(token); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, response.NewUnAuthorizedError()
+		}
+		return nil, mr.HandleMongoDbError("token", err)
 	}
-	if _, err := mr.Collection.DeleteOne(ctx, filter); err != nil {
+
+	return token, nil
+}
+
+// DeleteToken signs the device out by removing every token in the family of the access token.
+func (mr *mongoRepository) DeleteToken(tokenUuid string) *response.BaseResponse {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	token := &Token{}
+	if err := mr.Collection.FindOne(ctx, bson.M{"access_uuid": tokenUuid}).Decode(token); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		return mr.HandleMongoDbError("token", err)
+	}
+	if _, err := mr.Collection.DeleteMany(ctx, bson.M{"family": token.Family}); err != nil {
 		logger.GetLogger().Error(fmt.Sprintf("couldn't delete token. error: %v", err))
 		return response.NewInternalServerError("error deleting user")
 	}
 	return nil
 }
 
-func (mr *mongoRepository) RefreshToken(crudPayToken *Token, userId string, oldTokenUuid string) *response.BaseResponse {
+func (mr *mongoRepository) DeleteFamily(family string) *response.BaseResponse {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := mr.Collection.DeleteMany(ctx, bson.M{"family": family}); err != nil {
+		return mr.HandleMongoDbError("token", err)
+	}
 	return nil
 }
+
+func (mr *mongoRepository) DeleteDeviceTokens(userId, deviceId string) *response.BaseResponse {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"user_id":   userId,
+		"device_id": deviceId,
+	}
+	if _, err := mr.Collection.DeleteMany(ctx, filter); err != nil {
+		return mr.HandleMongoDbError("token", err)
+	}
+	return nil
+}
+
+// DeleteUserTokens revokes every token of the user but the family exceptFamily, which may be empty.
+func (mr *mongoRepository) DeleteUserTokens(userId, exceptFamily string) *response.BaseResponse {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"user_id": userId,
+		"family": bson.M{
+			"$ne": exceptFamily,
+		},
+	}
+	if _, err := mr.Collection.DeleteMany(ctx, filter); err != nil {
+		return mr.HandleMongoDbError("token", err)
+	}
+	return nil
+}
+
+// RefreshToken marks the old pair as rotated and stores the new pair. Only one caller can rotate a pair,
+// any other caller gets an unauthorized error.
+func (mr *mongoRepository) RefreshToken(token *Token, userId string, oldRefreshUuid string) *response.BaseResponse {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"refresh_uuid": oldRefreshUuid,
+		"rotated":      bson.M{"$ne": true},
+	}
+	result, err := mr.Collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"rotated": true}})
+	if err != nil {
+		return mr.HandleMongoDbError("token", err)
+	}
+	if result.ModifiedCount == 0 {
+		return response.NewUnAuthorizedError()
+	}
+
+	return mr.CreateToken(token, userId)
+}
@@ -8,31 +8,73 @@ import (
 	"time"
 )
 
-var (
-	redisContext = context.Background()
-	redisClient  *redis.Client
-	redisName    = "REDIS_HOST"
-)
-
 type redisRepository struct {
+	client       *redis.Client
 	errorService error_service.Service
 }
 
-func NewDatabaseRepository(errorService error_service.Service) Repository {
+func NewRedisRepository(client *redis.Client, errorService error_service.Service) Repository {
 	return &redisRepository{
+		client:       client,
 		errorService: errorService,
 	}
 }
 
+func accessKey(uuid string) string {
+	return "token:access:" + uuid
+}
+
+func refreshKey(uuid string) string {
+	return "token:refresh:" + uuid
+}
+
+func rotatedKey(uuid string) string {
+	return "token:rotated:" + uuid
+}
+
+func familyKey(family string) string {
+	return "token:family:" + family
+}
+
+func deviceKey(userId, deviceId string) string {
+	return "token:device:" + userId + ":" + deviceId
+}
+
+// userKey is the set of token families of a user.
+func userKey(userId string) string {
+	return "token:user:" + userId
+}
+
 func (repository *redisRepository) CreateToken(crudPayToken *Token, userId string) *response.BaseResponse {
-	accessTokenExpiration := time.Unix(int64(crudPayToken.AccessTokenExpires), 0).Sub(time.Now())
-	refreshTokenExpiration := time.Unix(crudPayToken.RefreshTokenExpires, 0).Sub(time.Now())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-	if redisSetError := redisClient.Set(redisContext, crudPayToken.AccessUuid, userId, accessTokenExpiration).Err(); redisSetError != nil {
-		return repository.errorService.HandleRedisDbError(redisSetError)
-	}
+	accessTokenExpiration := time.Until(crudPayToken.AccessExpiry())
+	refreshTokenExpiration := time.Until(crudPayToken.RefreshExpiry())
+	crudPayToken.UserId = userId
 
-	if redisSetError := redisClient.Set(redisContext, crudPayToken.RefreshUuid, userId, refreshTokenExpiration).Err(); redisSetError != nil {
+	pipe := repository.client.TxPipeline()
+	pipe.HSet(ctx, accessKey(crudPayToken.AccessUuid),
+		"user_id", userId,
+		"family", crudPayToken.Family,
+		"device_id", crudPayToken.DeviceId,
+		"refresh_uuid", crudPayToken.RefreshUuid,
+	)
+	pipe.Expire(ctx, accessKey(crudPayToken.AccessUuid), accessTokenExpiration)
+	pipe.HSet(ctx, refreshKey(crudPayToken.RefreshUuid),
+		"user_id", userId,
+		"family", crudPayToken.Family,
+		"device_id", crudPayToken.DeviceId,
+		"access_uuid", crudPayToken.AccessUuid,
+	)
+	pipe.Expire(ctx, refreshKey(crudPayToken.RefreshUuid), refreshTokenExpiration)
+	pipe.SAdd(ctx, familyKey(crudPayToken.Family), accessKey(crudPayToken.AccessUuid), refreshKey(crudPayToken.RefreshUuid), rotatedKey(crudPayToken.RefreshUuid))
+	pipe.Expire(ctx, familyKey(crudPayToken.Family), refreshTokenExpiration)
+	pipe.SAdd(ctx, deviceKey(userId, crudPayToken.DeviceId), crudPayToken.Family)
+	pipe.Expire(ctx, deviceKey(userId, crudPayToken.DeviceId), refreshTokenExpiration)
+	pipe.SAdd(ctx, userKey(userId), crudPayToken.Family)
+	pipe.Expire(ctx, userKey(userId), refreshTokenExpiration)
+	if _, redisSetError := pipe.Exec(ctx); redisSetError != nil {
 		return repository.errorService.HandleRedisDbError(redisSetError)
 	}
 
@@ -40,30 +82,155 @@ func (repository *redisRepository) CreateToken(crudPayToken *Token, userId strin
 }
 
 func (repository *redisRepository) Get(tokenUuid string) (*string, *response.BaseResponse) {
-	userId, resultError := redisClient.Get(redisContext, tokenUuid).Result()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	userId, resultError := repository.client.HGet(ctx, accessKey(tokenUuid), "user_id").Result()
 	if resultError != nil {
 		return nil, repository.errorService.HandleRedisDbError(resultError)
 	}
 	return &userId, nil
 }
 
-func (repository *redisRepository) DeleteToken(uuids string) *response.BaseResponse {
-	redisClient.Del(redisContext, uuids)
+func (repository *redisRepository) GetRefreshToken(refreshUuid string) (*Token, *response.BaseResponse) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	values, resultError := repository.client.HGetAll(ctx, refreshKey(refreshUuid)).Result()
+	if resultError != nil {
+		return nil, repository.errorService.HandleRedisDbError(resultError)
+	}
+	if len(values) == 0 {
+		return nil, response.NewUnAuthorizedError()
+	}
+	rotated, resultError := repository.client.Exists(ctx, rotatedKey(refreshUuid)).Result()
+	if resultError != nil {
+		return nil, repository.errorService.HandleRedisDbError(resultError)
+	}
+
+	token := &Token{
+		RefreshUuid: refreshUuid,
+		AccessUuid:  values["access_uuid"],
+		UserId:      values["user_id"],
+		Family:      values["family"],
+		DeviceId:    values["device_id"],
+		Rotated:     rotated > 0,
+	}
+	return token, nil
+}
+
+func (repository *redisRepository) GetAccessToken(accessUuid string) (*Token, *response.BaseResponse) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	values, resultError := repository.client.HGetAll(ctx, accessKey(accessUuid)).Result()
+	if resultError != nil {
+		return nil, repository.errorService.HandleRedisDbError(resultError)
+	}
+	if len(values) == 0 {
+		return nil, response.NewUnAuthorizedError()
+	}
+
+	token := &Token{
+		AccessUuid:  accessUuid,
+		RefreshUuid: values["refresh_uuid"],
+		UserId:      values["user_id"],
+		Family:      values["family"],
+		DeviceId:    values["device_id"],
+	}
+	return token, nil
+}
+
+func (repository *redisRepository) DeleteToken(uuid string) *response.BaseResponse {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	family, resultError := repository.client.HGet(ctx, accessKey(uuid), "family").Result()
+	if resultError == redis.Nil {
+		return nil
+	}
+	if resultError != nil {
+		return repository.errorService.HandleRedisDbError(resultError)
+	}
+	return repository.DeleteFamily(family)
+}
+
+func (repository *redisRepository) DeleteFamily(family string) *response.BaseResponse {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	keys, resultError := repository.client.SMembers(ctx, familyKey(family)).Result()
+	if resultError != nil {
+		return repository.errorService.HandleRedisDbError(resultError)
+	}
+	keys = append(keys, familyKey(family))
+	if redisDeleteError := repository.client.Del(ctx, keys...).Err(); redisDeleteError != nil {
+		return repository.errorService.HandleRedisDbError(redisDeleteError)
+	}
 	return nil
 }
 
-func (repository *redisRepository) RefreshToken(crudPayToken *Token, userId string, oldTokenUuid string) *response.BaseResponse {
-	accessTokenExpiration := time.Unix(int64(crudPayToken.AccessTokenExpires), 0).Sub(time.Now())
-	refreshTokenExpiration := time.Unix(crudPayToken.RefreshTokenExpires, 0).Sub(time.Now())
+func (repository *redisRepository) DeleteDeviceTokens(userId, deviceId string) *response.BaseResponse {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-	if redisSetError := redisClient.Set(redisContext, crudPayToken.AccessUuid, userId, accessTokenExpiration).Err(); redisSetError != nil {
-		return repository.errorService.HandleRedisDbError(redisSetError)
+	families, resultError := repository.client.SMembers(ctx, deviceKey(userId, deviceId)).Result()
+	if resultError != nil {
+		return repository.errorService.HandleRedisDbError(resultError)
 	}
-	if redisDeleteError := redisClient.Del(redisContext, oldTokenUuid).Err(); redisDeleteError != nil {
+	for _, family := range families {
+		if resp := repository.DeleteFamily(family); resp != nil {
+			return resp
+		}
+	}
+	if redisDeleteError := repository.client.Del(ctx, deviceKey(userId, deviceId)).Err(); redisDeleteError != nil {
 		return repository.errorService.HandleRedisDbError(redisDeleteError)
 	}
-	if redisSetError := redisClient.Set(redisContext, crudPayToken.RefreshUuid, userId, refreshTokenExpiration).Err(); redisSetError != nil {
-		return repository.errorService.HandleRedisDbError(redisSetError)
+	return nil
+}
+
+// DeleteUserTokens revokes every token of the user but the family exceptFamily, which may be empty.
+func (repository *redisRepository) DeleteUserTokens(userId, exceptFamily string) *response.BaseResponse {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	families, resultError := repository.client.SMembers(ctx, userKey(userId)).Result()
+	if resultError != nil {
+		return repository.errorService.HandleRedisDbError(resultError)
+	}
+	for _, family := range families {
+		if family == exceptFamily {
+			continue
+		}
+		if resp := repository.DeleteFamily(family); resp != nil {
+			return resp
+		}
+		if redisDeleteError := repository.client.SRem(ctx, userKey(userId), family).Err(); redisDeleteError != nil {
+			return repository.errorService.HandleRedisDbError(redisDeleteError)
+		}
 	}
 	return nil
 }
+
+// RefreshToken uses SETNX on the rotation marker so only the first caller can rotate a pair.
+func (repository *redisRepository) RefreshToken(crudPayToken *Token, userId string, oldTokenUuid string) *response.BaseResponse {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	oldToken, resp := repository.GetRefreshToken(oldTokenUuid)
+	if resp != nil {
+		return resp
+	}
+	rotated, redisSetError := repository.client.SetNX(ctx, rotatedKey(oldTokenUuid), true, time.Until(crudPayToken.RefreshExpiry())).Result()
+	if redisSetError != nil {
+		return repository.errorService.HandleRedisDbError(redisSetError)
+	}
+	if !rotated {
+		return response.NewUnAuthorizedError()
+	}
+	if redisDeleteError := repository.client.Del(ctx, accessKey(oldToken.AccessUuid)).Err(); redisDeleteError != nil {
+		return repository.errorService.HandleRedisDbError(redisDeleteError)
+	}
+
+	return repository.CreateToken(crudPayToken, userId)
+}
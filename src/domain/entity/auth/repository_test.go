@@ -0,0 +1,224 @@
+package auth
+
+import (
+	"DX/src/pkg/error_service"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"net/http"
+	"testing"
+)
+
+// Every token store backend must pass the same suite, Redis runs against an embedded server.
+
+func TestInMemoryRepository(t *testing.T) {
+	testRepository(t, func(t *testing.T) Repository {
+		return NewInMemoryRepository()
+	})
+}
+
+func TestRedisRepository(t *testing.T) {
+	testRepository(t, func(t *testing.T) Repository {
+		server := miniredis.RunT(t)
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() {
+			_ = client.Close()
+		})
+		return NewRedisRepository(client, error_service.New())
+	})
+}
+
+func testRepository(t *testing.T, newRepository func(*testing.T) Repository) {
+	t.Run("create and get", func(t *testing.T) {
+		repository := newRepository(t)
+		token := createToken(t, repository, "user-1", "device-1")
+
+		userId, resp := repository.Get(token.AccessUuid)
+		if resp != nil {
+			t.Fatalf("get: %s", resp.Message)
+		}
+		if *userId != "user-1" {
+			t.Fatalf("expected user-1, got %s", *userId)
+		}
+
+		stored, resp := repository.GetRefreshToken(token.RefreshUuid)
+		if resp != nil {
+			t.Fatalf("get refresh token: %s", resp.Message)
+		}
+		if stored.UserId != "user-1" || stored.Family != token.Family || stored.DeviceId != "device-1" || stored.Rotated {
+			t.Fatalf("unexpected refresh token %+v", stored)
+		}
+
+		stored, resp = repository.GetAccessToken(token.AccessUuid)
+		if resp != nil {
+			t.Fatalf("get access token: %s", resp.Message)
+		}
+		if stored.UserId != "user-1" || stored.Family != token.Family || stored.DeviceId != "device-1" || stored.RefreshUuid != token.RefreshUuid {
+			t.Fatalf("unexpected access token %+v", stored)
+		}
+	})
+
+	t.Run("unknown tokens are unauthorized", func(t *testing.T) {
+		repository := newRepository(t)
+
+		if _, resp := repository.Get("missing"); resp == nil || resp.Code != http.StatusUnauthorized {
+			t.Fatalf("expected unauthorized, got %+v", resp)
+		}
+		if _, resp := repository.GetRefreshToken("missing"); resp == nil || resp.Code != http.StatusUnauthorized {
+			t.Fatalf("expected unauthorized, got %+v", resp)
+		}
+		if _, resp := repository.GetAccessToken("missing"); resp == nil || resp.Code != http.StatusUnauthorized {
+			t.Fatalf("expected unauthorized, got %+v", resp)
+		}
+		if resp := repository.DeleteToken("missing"); resp != nil {
+			t.Fatalf("deleting a missing token: %s", resp.Message)
+		}
+	})
+
+	t.Run("refresh rotates the pair", func(t *testing.T) {
+		repository := newRepository(t)
+		token := createToken(t, repository, "user-1", "device-1")
+
+		next := token.Rotate()
+		if resp := repository.RefreshToken(next, "user-1", token.RefreshUuid); resp != nil {
+			t.Fatalf("refresh: %s", resp.Message)
+		}
+		if next.Family != token.Family || next.DeviceId != token.DeviceId {
+			t.Fatalf("rotated token left the family")
+		}
+		if _, resp := repository.Get(token.AccessUuid); resp == nil {
+			t.Fatal("rotated access token is still valid")
+		}
+		if _, resp := repository.GetAccessToken(token.AccessUuid); resp == nil {
+			t.Fatal("rotated access token is still valid")
+		}
+		if _, resp := repository.Get(next.AccessUuid); resp != nil {
+			t.Fatalf("new access token: %s", resp.Message)
+		}
+		old, resp := repository.GetRefreshToken(token.RefreshUuid)
+		if resp != nil {
+			t.Fatalf("rotated refresh token should be kept for reuse detection: %s", resp.Message)
+		}
+		if !old.Rotated {
+			t.Fatal("refresh token was not marked as rotated")
+		}
+	})
+
+	t.Run("a pair can only be rotated once", func(t *testing.T) {
+		repository := newRepository(t)
+		token := createToken(t, repository, "user-1", "device-1")
+
+		if resp := repository.RefreshToken(token.Rotate(), "user-1", token.RefreshUuid); resp != nil {
+			t.Fatalf("refresh: %s", resp.Message)
+		}
+		resp := repository.RefreshToken(token.Rotate(), "user-1", token.RefreshUuid)
+		if resp == nil || resp.Code != http.StatusUnauthorized {
+			t.Fatalf("expected unauthorized, got %+v", resp)
+		}
+	})
+
+	t.Run("delete token revokes the family", func(t *testing.T) {
+		repository := newRepository(t)
+		token := createToken(t, repository, "user-1", "device-1")
+		next := token.Rotate()
+		if resp := repository.RefreshToken(next, "user-1", token.RefreshUuid); resp != nil {
+			t.Fatalf("refresh: %s", resp.Message)
+		}
+		other := createToken(t, repository, "user-1", "device-2")
+
+		if resp := repository.DeleteToken(next.AccessUuid); resp != nil {
+			t.Fatalf("delete: %s", resp.Message)
+		}
+		if _, resp := repository.GetRefreshToken(token.RefreshUuid); resp == nil {
+			t.Fatal("family member survived")
+		}
+		if _, resp := repository.GetRefreshToken(next.RefreshUuid); resp == nil {
+			t.Fatal("family member survived")
+		}
+		if _, resp := repository.Get(other.AccessUuid); resp != nil {
+			t.Fatalf("other device was signed out: %s", resp.Message)
+		}
+	})
+
+	t.Run("delete family", func(t *testing.T) {
+		repository := newRepository(t)
+		token := createToken(t, repository, "user-1", "device-1")
+
+		if resp := repository.DeleteFamily(token.Family); resp != nil {
+			t.Fatalf("delete family: %s", resp.Message)
+		}
+		if _, resp := repository.Get(token.AccessUuid); resp == nil {
+			t.Fatal("access token survived")
+		}
+		if _, resp := repository.GetRefreshToken(token.RefreshUuid); resp == nil {
+			t.Fatal("refresh token survived")
+		}
+	})
+
+	t.Run("delete device tokens", func(t *testing.T) {
+		repository := newRepository(t)
+		first := createToken(t, repository, "user-1", "device-1")
+		second := createToken(t, repository, "user-1", "device-1")
+		other := createToken(t, repository, "user-1", "device-2")
+		otherUser := createToken(t, repository, "user-2", "device-1")
+
+		if resp := repository.DeleteDeviceTokens("user-1", "device-1"); resp != nil {
+			t.Fatalf("delete device tokens: %s", resp.Message)
+		}
+		for _, token := range []*Token{first, second} {
+			if _, resp := repository.Get(token.AccessUuid); resp == nil {
+				t.Fatal("device token survived")
+			}
+		}
+		for _, token := range []*Token{other, otherUser} {
+			if _, resp := repository.Get(token.AccessUuid); resp != nil {
+				t.Fatalf("unrelated token was revoked: %s", resp.Message)
+			}
+		}
+	})
+
+	t.Run("delete user tokens", func(t *testing.T) {
+		repository := newRepository(t)
+		userId := "user-" + NewUserToken("").Family
+		first := createToken(t, repository, userId, "device-1")
+		second := createToken(t, repository, userId, "device-2")
+		kept := createToken(t, repository, userId, "device-3")
+		otherUser := createToken(t, repository, "other-"+userId, "device-1")
+
+		rotated := second.Rotate()
+		if resp := repository.RefreshToken(rotated, userId, second.RefreshUuid); resp != nil {
+			t.Fatalf("refresh: %s", resp.Message)
+		}
+
+		if resp := repository.DeleteUserTokens(userId, kept.Family); resp != nil {
+			t.Fatalf("delete user tokens: %s", resp.Message)
+		}
+		for _, token := range []*Token{first, rotated} {
+			if _, resp := repository.Get(token.AccessUuid); resp == nil {
+				t.Fatal("user token survived")
+			}
+			if _, resp := repository.GetRefreshToken(token.RefreshUuid); resp == nil {
+				t.Fatal("user refresh token survived")
+			}
+		}
+		for _, token := range []*Token{kept, otherUser} {
+			if _, resp := repository.Get(token.AccessUuid); resp != nil {
+				t.Fatalf("unrelated token was revoked: %s", resp.Message)
+			}
+		}
+
+		if resp := repository.DeleteUserTokens(userId, ""); resp != nil {
+			t.Fatalf("delete user tokens: %s", resp.Message)
+		}
+		if _, resp := repository.Get(kept.AccessUuid); resp == nil {
+			t.Fatal("token survived revoking every token")
+		}
+	})
+}
+
+func createToken(t *testing.T, repository Repository, userId, deviceId string) *Token {
+	token := NewUserToken(deviceId)
+	if resp := repository.CreateToken(token, userId); resp != nil {
+		t.Fatalf("create: %s", resp.Message)
+	}
+	return token
+}
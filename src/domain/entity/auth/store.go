@@ -0,0 +1,38 @@
+package auth
+
+import "errors"
+
+// Store is a backend tokens or rate limits are persisted in.
+type Store int
+
+const (
+	MongoStore Store = iota
+	RedisStore
+	InMemoryStore
+)
+
+func (s Store) Id() string {
+	if s == MongoStore {
+		return "mongo"
+	}
+	if s == RedisStore {
+		return "redis"
+	}
+	if s == InMemoryStore {
+		return "memory"
+	}
+	return ""
+}
+
+func GetStore(value string) (Store, error) {
+	if value == "" || value == MongoStore.Id() {
+		return MongoStore, nil
+	}
+	if value == RedisStore.Id() {
+		return RedisStore, nil
+	}
+	if value == InMemoryStore.Id() {
+		return InMemoryStore, nil
+	}
+	return MongoStore, errors.New("unknown token store: " + value)
+}
@@ -6,12 +6,28 @@ import (
 	"time"
 )
 
-func NewUserToken() *Token {
+// NewUserToken starts a new token family for the device, a random device id is used when none is given.
+func NewUserToken(deviceId string) *Token {
+	if deviceId == "" {
+		deviceId = entity.NewDefaultId().String()
+	}
+	return newToken(entity.NewDefaultId().String(), deviceId)
+}
+
+// Rotate issues the next token pair in the family.
+func (t *Token) Rotate() *Token {
+	return newToken(t.Family, t.DeviceId)
+}
+
+func newToken(family, deviceId string) *Token {
+	cTime := time.Now()
 	errandToken := new(Token)
 	errandToken.AccessUuid = entity.NewDefaultId().String()
 	errandToken.RefreshUuid = entity.NewDefaultId().String()
-	errandToken.AccessTokenExpires = primitive.NewDateTimeFromTime(time.Now().Add(6 * 4 * 24 * 7 * time.Hour)) //6 months
-	//errandToken.RefreshTokenExpires = time.Now().Add(48 * time.Hour).Unix() //2 days
+	errandToken.Family = family
+	errandToken.DeviceId = deviceId
+	errandToken.AccessTokenExpires = primitive.NewDateTimeFromTime(cTime.Add(AccessTokenLifetime))
+	errandToken.RefreshTokenExpires = primitive.NewDateTimeFromTime(cTime.Add(RefreshTokenLifetime))
 
 	return errandToken
 }
@@ -46,23 +46,18 @@ func NewOfflineBid(errandId, runnerId string, amount int64) *Bid {
 	}
 }
 
-func FromPayload(data map[string]interface{}, errandId string) (*Bid, error) {
+func NewOpenBid(errandId, description string) *Bid {
 	cTime := time.Now()
-	nBid := &Bid{
-		Id:        entity.NewDatabaseId(),
-		CreatedAt: cTime,
-		UpdatedAt: cTime,
-		ErrandId:  errandId,
-		Haggles:   []haggle.Haggle{},
-		State:     Open.Id(),
-		BidState:  Open,
-	}
-
-	if description, ok := data["description"].(string); ok {
-		nBid.Description = description
+	return &Bid{
+		Id:          entity.NewDatabaseId(),
+		Description: description,
+		CreatedAt:   cTime,
+		UpdatedAt:   cTime,
+		ErrandId:    errandId,
+		Haggles:     []haggle.Haggle{},
+		State:       Open.Id(),
+		BidState:    Open,
 	}
-
-	return nBid, nil
 }
 
 func (b *Bid) Accept() {
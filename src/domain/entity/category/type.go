@@ -1,8 +1,8 @@
 package category
 
 import (
+	"DX/src/pkg/apperror"
 	"DX/src/utils"
-	"errors"
 	"strings"
 )
 
@@ -26,20 +26,24 @@ const (
 var Tasks = []Type{PickUpAndDelivery, Shopping, Transportation, MovingServices}
 var Services = []Type{Gardening, BabySitting, Laundry, Cleaning, ReadingAndWriting, FashionAndTailoring, AutoServices, Catering}
 
+var ErrInvalidType = apperror.New(apperror.Invalid, "invalid_errand_type", "invalid errand type")
+var ErrInvalidTaskCategory = apperror.New(apperror.Invalid, "invalid_errand_category", "invalid task errand category")
+var ErrInvalidServiceCategory = apperror.New(apperror.Invalid, "invalid_errand_category", "invalid service errand category")
+
 func IsValidCategory(errandType string, category string) (*Type, error) {
 	nCategory := getCategory(category)
 	if errandType == "task" {
 		if utils.Contains(Tasks, nCategory) {
 			return &nCategory, nil
 		}
-		return nil, errors.New("invalid task errand category")
+		return nil, ErrInvalidTaskCategory
 	} else if errandType == "service" {
 		if utils.Contains(Services, nCategory) {
 			return &nCategory, nil
 		}
-		return nil, errors.New("invalid service errand category")
+		return nil, ErrInvalidServiceCategory
 	} else {
-		return nil, errors.New("invalid errand type")
+		return nil, ErrInvalidType
 	}
 }
 
@@ -4,12 +4,49 @@ import (
 	"DX/src/domain/entity"
 	"DX/src/domain/entity/bid"
 	"DX/src/domain/entity/category"
+	"DX/src/domain/entity/fee"
 	"DX/src/domain/entity/timeline"
-	"errors"
-	"strings"
+	"DX/src/pkg/apperror"
 	"time"
 )
 
+// TipWindow is how long after completion the sender can tip the runner.
+const TipWindow = 72 * time.Hour
+
+var ErrInvalidRestriction = apperror.New(apperror.Invalid, "invalid_restriction_type", "invalid restriction type")
+var ErrInvalidDuration = apperror.New(apperror.Invalid, "invalid_duration", "invalid duration period")
+var ErrDurationTooLong = apperror.New(apperror.Invalid, "duration_too_long", "duration cannot be longer than 10 weeks")
+var ErrDropOffRequired = apperror.New(apperror.Invalid, "drop_off_required", "drop-off location is required for tasks")
+var ErrInvalidBid = apperror.New(apperror.Invalid, "invalid_bid", "bid not valid for errand")
+var ErrInvalidBidRunner = apperror.New(apperror.Invalid, "invalid_bid_runner", "invalid runner id for selected bid")
+var ErrInvalidRunner = apperror.New(apperror.Invalid, "invalid_runner_id", "invalid runner id")
+var ErrInvalidTip = apperror.New(apperror.Invalid, "invalid_tip_amount", "invalid tip amount")
+var ErrNotEditable = apperror.New(apperror.Conflict, "errand_not_editable", "errand can't be updated")
+var ErrBidAlreadyAccepted = apperror.New(apperror.Conflict, "bid_already_accepted", "user already accepted a bid for this errand")
+var ErrAcceptWithoutBid = apperror.New(apperror.Conflict, "no_accepted_bid", "can't accept contract for errand with no accepted bid")
+var ErrRejectWithoutBid = apperror.New(apperror.Conflict, "no_accepted_bid", "can't reject contract for errand with no accepted bid")
+var ErrNotBiddable = apperror.New(apperror.Conflict, "errand_not_biddable", "errand no longer available for bidding")
+var ErrActiveBid = apperror.New(apperror.Conflict, "bid_already_placed", "user already has a active bid for errand")
+var ErrNotInProgress = apperror.New(apperror.Conflict, "errand_not_in_progress", "errand not in progress")
+var ErrNotRateable = apperror.New(apperror.Conflict, "errand_not_completed", "can only rate user for a completed errand")
+var ErrNotTippable = apperror.New(apperror.Conflict, "errand_not_completed", "only completed errands can be tipped")
+var ErrTipWindowClosed = apperror.New(apperror.Conflict, "tip_window_closed", "the tipping window for this errand has closed")
+var ErrAlreadyTipped = apperror.New(apperror.Conflict, "errand_already_tipped", "runner has already been tipped for this errand")
+var ErrOwnErrand = apperror.New(apperror.Forbidden, "bid_on_own_errand", "sender not allowed to bid for own errand")
+var ErrCreateForbidden = apperror.New(apperror.Forbidden, "errand_forbidden", "user not authorized to create this errand")
+var ErrUpdateForbidden = apperror.New(apperror.Forbidden, "errand_forbidden", "user not authorized to update errand")
+var ErrAcceptBidForbidden = apperror.New(apperror.Forbidden, "errand_forbidden", "user not authorized to accept bid")
+var ErrRejectBidForbidden = apperror.New(apperror.Forbidden, "errand_forbidden", "user not authorized to reject bid")
+var ErrCancelForbidden = apperror.New(apperror.Forbidden, "errand_forbidden", "user not authorized to cancel errand")
+var ErrCompleteForbidden = apperror.New(apperror.Forbidden, "errand_forbidden", "user not authorized to complete errand")
+var ErrRequestUpdateForbidden = apperror.New(apperror.Forbidden, "errand_forbidden", "user not authorized to request update for this errand")
+var ErrTimelineForbidden = apperror.New(apperror.Forbidden, "errand_forbidden", "runner not authorized to update this errand timeline")
+var ErrTipForbidden = apperror.New(apperror.Forbidden, "errand_forbidden", "user not authorized to tip on this errand")
+var ErrBidForbidden = apperror.New(apperror.Forbidden, "bid_forbidden", "sender not authorized to update this bid")
+var ErrAssignForbidden = apperror.New(apperror.Forbidden, "errand_forbidden", "admin not authorized to assign this errand")
+var ErrSenderNotOffline = apperror.New(apperror.Invalid, "user_not_offline", "admin can only create errand for offline users")
+var ErrRunnerNotOffline = apperror.New(apperror.Invalid, "user_not_offline", "admin can only assign bid to offline user")
+
 func New(userId string) *Errand {
 	currTime := time.Now()
 	return &Errand{
@@ -48,14 +85,32 @@ type Errand struct {
 	DropOffAddress     *Address            `json:"dropoff_address,omitempty" bson:"dropoff_address,omitempty"`
 	Budget             int64               `json:"budget" bson:"budget"`
 	Amount             int64               `json:"amount" bson:"amount"` // Amount agreed after bidding is accepted
+	Fees               *fee.Breakdown      `json:"fees,omitempty" bson:"fees,omitempty"`
 	Status             string              `json:"status" bson:"status"`
 	ExpiryDate         time.Time           `json:"expiry_date" bson:"expiry_date,omitempty"`
 	CancellationReason string              `json:"cancellation_reason,omitempty" bson:"cancellation_reason"`
+	CompletedAt        *time.Time          `json:"completed_at,omitempty" bson:"completed_at,omitempty"`
+	PayoutPending      bool                `json:"-" bson:"payout_pending,omitempty"` // Completed but the runner has not been paid yet
 	ModifiedBy         []entity.ModifiedBy `json:"-" bson:"modified_by"`
 	CreatedAt          time.Time           `json:"created_at" bson:"created_at"`
 	UpdatedAt          time.Time           `json:"updated_at" bson:"updated_at"`
 }
 
+// PendingEarnings is the agreed amount on errands a runner has not been paid for yet.
+type PendingEarnings struct {
+	Escrowed             int64 `json:"escrowed" bson:"escrowed"`                           // Errand is pending or active
+	AwaitingConfirmation int64 `json:"awaiting_confirmation" bson:"awaiting_confirmation"` // Runner completed, sender has not confirmed
+	Total                int64 `json:"total" bson:"total"`
+	Errands              int64 `json:"errands" bson:"errands"`
+}
+
+type CategoryEarnings struct {
+	CategoryId string `json:"category_id" bson:"_id"`
+	Name       string `json:"name" bson:"name"`
+	Amount     int64  `json:"amount" bson:"amount"`
+	Errands    int64  `json:"errands" bson:"errands"`
+}
+
 type MarketErrand struct {
 	Id          entity.DatabaseId `json:"id" bson:"id"`
 	Bids        int               `json:"bids" bson:"bids"`
@@ -80,8 +135,34 @@ func (e *Errand) CanBeBiddedFor() bool {
 	return e.State == Open || e.State == Pending
 }
 
-func ValidateErrandFromMap(data map[string]interface{}, isAdmin bool) (*Errand, error) {
-	var duration Duration
+// Request is the errand a sender drafts, updates or an admin creates for a user.
+type Request struct {
+	Duration        *DurationRequest `json:"duration" binding:"required"`
+	Description     string           `json:"description" binding:"max=2000"`
+	Images          []string         `json:"images" binding:"max=3,dive,notblank"`
+	Audio           []string         `json:"audio" binding:"max=1,dive,notblank"`
+	Restriction     string           `json:"restriction" binding:"omitempty,oneof=qualification verification insurance"`
+	Category        string           `json:"category" binding:"required,objectid"`
+	PickupLocation  *Location        `json:"pickup_location" binding:"required"`
+	DropoffLocation *Location        `json:"dropoff_location"`
+	Budget          float64          `json:"budget" binding:"required,gt=0"`
+}
+
+type DurationRequest struct {
+	Period string `json:"period" binding:"required,oneof=hours days weeks"`
+	Value  int64  `json:"value" binding:"required,gt=0"`
+}
+
+type Location struct {
+	Latitude  *float64 `json:"lat" binding:"required,latitude"`
+	Longitude *float64 `json:"lng" binding:"required,longitude"`
+}
+
+func (l *Location) address() *Address {
+	return &Address{Latitude: *l.Latitude, Longitude: *l.Longitude}
+}
+
+func FromRequest(request Request, isAdmin bool) (*Errand, error) {
 	var nErrand *Errand
 
 	if isAdmin {
@@ -92,82 +173,31 @@ func ValidateErrandFromMap(data map[string]interface{}, isAdmin bool) (*Errand,
 		}
 	}
 
-	if dur, ok := data["duration"].(map[string]interface{}); !ok {
-		return nil, errors.New("duration is required")
-	} else {
-		//TODO handle potential errors here
-		duration.Period = dur["period"].(string)
-		duration.Value = int64(dur["value"].(float64))
-
-		err := duration.IsValid()
-		if err != nil {
-			return nil, err
-		}
-
-		nErrand.Duration = &duration
-	}
-	if description, ok := data["description"].(string); ok {
-		nErrand.Description = description
-	}
-	if imgFiles, ok := data["images"].([]interface{}); ok {
-		if len(imgFiles) > 3 {
-			return nil, errors.New("invalid number of images")
-		}
-		for _, imgFile := range imgFiles {
-			nErrand.Images = append(nErrand.Images, imgFile.(string))
-		}
-	}
-	if audFiles, ok := data["audio"].([]interface{}); ok {
-		if len(audFiles) > 1 {
-			return nil, errors.New("invalid number of audio files")
-		}
-		for _, audFile := range audFiles {
-			nErrand.Audio = append(nErrand.Audio, audFile.(string))
-		}
+	duration := Duration{Period: request.Duration.Period, Value: request.Duration.Value}
+	if err := duration.IsValid(); err != nil {
+		return nil, err
 	}
-	if rstn, ok := data["restriction"].(string); ok {
-		rstnValue := RestrictionType(rstn)
+	nErrand.Duration = &duration
+	nErrand.Description = request.Description
+	nErrand.Images = request.Images
+	nErrand.Audio = request.Audio
+	if request.Restriction != "" {
+		rstnValue := RestrictionType(request.Restriction)
 		if rstnValue == -1 {
-			return nil, errors.New("invalid restriction type")
+			return nil, ErrInvalidRestriction
 		}
 		nErrand.RestrictBy = rstnValue
-		nErrand.Restriction = rstn
+		nErrand.Restriction = request.Restriction
 	}
-	if categoryId, ok := data["category"].(string); !ok {
-		return nil, errors.New("errand category id is required")
-	} else {
-		catId, _ := entity.StringToErrandId(categoryId)
-		nErrand.Category = &category.Category{
-			Id: catId,
-		}
-	}
-	if pickupLocation, ok := data["pickup_location"].(map[string]interface{}); !ok {
-		return nil, errors.New("pick-up location is required")
-	} else {
-		//TODO handle potential errors here
-		address := &Address{
-			Latitude:  pickupLocation["lat"].(float64),
-			Longitude: pickupLocation["lng"].(float64),
-		}
-		nErrand.PickupAddress = address
-	}
-	if dropoffLocation, ok := data["dropoff_location"].(map[string]interface{}); ok {
-		//TODO handle potential errors here
-		address := &Address{
-			Latitude:  dropoffLocation["lat"].(float64),
-			Longitude: dropoffLocation["lng"].(float64),
-		}
-		nErrand.DropOffAddress = address
+	catId, _ := entity.StringToErrandId(request.Category)
+	nErrand.Category = &category.Category{
+		Id: catId,
 	}
-	if budget, ok := data["budget"].(interface{}); !ok {
-		return nil, errors.New("budget is required")
-	} else {
-		if budget.(float64) <= 0 {
-			return nil, errors.New("invalid budget")
-		} else {
-			nErrand.Budget = int64(budget.(float64))
-		}
+	nErrand.PickupAddress = request.PickupLocation.address()
+	if request.DropoffLocation != nil {
+		nErrand.DropOffAddress = request.DropoffLocation.address()
 	}
+	nErrand.Budget = int64(request.Budget)
 
 	return nErrand, nil
 }
@@ -198,20 +228,6 @@ func (e *Errand) UpdateForCreation(createdBy *entity.CreatedBy) {
 	e.CreatedBy = createdBy
 }
 
-func (e *Errand) Cancel(userId string, reason string) {
-	cTime := time.Now()
-	if strings.TrimSpace(reason) == "" {
-		e.CancellationReason = reason
-	}
-	e.ModifiedBy = append(e.ModifiedBy, entity.ModifiedBy{
-		Id:   userId,
-		Date: cTime,
-	})
-	e.State = Cancelled
-	e.Status = Cancelled.Id()
-	e.UpdatedAt = cTime
-}
-
 func (e *Errand) Complete(userId string, source string) {
 	cTime := time.Now()
 	var state State
@@ -223,6 +239,9 @@ func (e *Errand) Complete(userId string, source string) {
 	}
 	e.State = state
 	e.Status = state.Id()
+	if state == Completed {
+		e.CompletedAt = &cTime
+	}
 	e.ModifiedBy = append(e.ModifiedBy, entity.ModifiedBy{
 		Id:   userId,
 		Date: cTime,
@@ -249,13 +268,13 @@ func (e *Errand) IsValidBidAndRunner(bidId, runnerId string) (*bid.Bid, error) {
 		if cBid.Id.Hex() == bidId {
 			bidFound = true
 			if cBid.Runner != runnerId {
-				return nil, errors.New("invalid runner id for selected bid")
+				return nil, ErrInvalidBidRunner
 			}
 			return &cBid, nil
 		}
 	}
 	if !bidFound {
-		return nil, errors.New("bid not valid for errand")
+		return nil, ErrInvalidBid
 	}
 
 	return nil, nil
@@ -275,6 +294,20 @@ func (e *Errand) IsAdminErrand(userId string) bool {
 	return e.CreatedBy.Admin() && e.CreatedBy.Id == userId
 }
 
+// CanBeTipped allows a single tip from the sender within TipWindow of completing the errand.
+func (e *Errand) CanBeTipped() error {
+	if e.State != Completed || e.CompletedAt == nil {
+		return ErrNotTippable
+	}
+	if time.Since(*e.CompletedAt) > TipWindow {
+		return ErrTipWindowClosed
+	}
+	if e.Fees != nil && e.Fees.Tip > 0 {
+		return ErrAlreadyTipped
+	}
+	return nil
+}
+
 func (e *Errand) InProgress() bool {
 	return e.State == Active
 }
@@ -290,11 +323,11 @@ type Duration struct {
 
 func (d *Duration) IsValid() error {
 	if d.Period != "days" && d.Period != "weeks" && d.Period != "hours" {
-		return errors.New("invalid duration period")
+		return ErrInvalidDuration
 	}
 	if d.Period == "weeks" {
 		if d.Value > 10 {
-			return errors.New("duration cannot be longer than 10 weeks")
+			return ErrDurationTooLong
 		}
 	}
 	return nil
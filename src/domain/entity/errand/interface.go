@@ -2,8 +2,10 @@ package errand
 
 import (
 	"DX/src/domain/entity/bid"
+	"DX/src/domain/entity/fee"
 	"DX/src/domain/entity/haggle"
 	"DX/src/domain/entity/timeline"
+	"time"
 )
 
 type Writer interface {
@@ -21,6 +23,11 @@ type Writer interface {
 	AssignErrandToOfflineRunner(string, string, string, *bid.Bid) error
 	RunnerComplete(string, string) error
 	SenderComplete(string, string) error
+	Cancel(string, string, string, State) error
+	RestoreCancelled(string, State) error
+	SetPayout(string, *fee.Breakdown) error
+	Tip(string, int64, *fee.Breakdown) error
+	ClearTip(string, int64) error
 	Delete(string) error
 }
 
@@ -37,6 +44,10 @@ type Reader interface {
 	GetAllCancelledErrands() ([]Errand, error)
 	GetAllActiveErrands() ([]Errand, error)
 	GetAllAbandonedErrands() ([]Errand, error)
+	CountForSender(string) (int64, error)
+	GetPendingPayouts(time.Time) ([]Errand, error)
+	GetPendingEarnings(string) (*PendingEarnings, error)
+	GetTopCategories(string, time.Time, time.Time, int64) ([]CategoryEarnings, error)
 }
 
 type Repository interface {
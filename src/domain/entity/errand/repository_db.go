@@ -3,6 +3,7 @@ package errand
 import (
 	"DX/src/domain/entity"
 	"DX/src/domain/entity/bid"
+	"DX/src/domain/entity/fee"
 	"DX/src/domain/entity/haggle"
 	"DX/src/domain/entity/timeline"
 	"DX/src/pkg/error_service"
@@ -12,6 +13,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"strings"
 	"time"
 )
 
@@ -209,6 +211,107 @@ func (r *repository) GetAllAbandonedErrands() (errands []Errand, err error) {
 	return errands, nil
 }
 
+// CountForSender counts the errands a user has posted, drafts are excluded.
+func (r *repository) CountForSender(userId string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"user_id": userId,
+		"state": bson.M{
+			"$nin": bson.A{Draft, EditMode},
+		},
+	}
+
+	return r.Collection.CountDocuments(ctx, filter)
+}
+
+func (r *repository) GetPendingEarnings(runnerId string) (*PendingEarnings, error) {
+	var earnings []PendingEarnings
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	sumFor := func(states ...State) bson.D {
+		return bson.D{{"$sum", bson.D{
+			{"$cond", bson.A{
+				bson.D{{"$in", bson.A{"$state", states}}},
+				"$amount",
+				0,
+			}},
+		}}}
+	}
+	pipeline := mongo.Pipeline{
+		{{"$match", bson.D{
+			{"runner_id", runnerId},
+			{"state", bson.D{{"$in", bson.A{Pending, Active, RunnerCompleted}}}},
+		}}},
+		{{"$group", bson.D{
+			{"_id", nil},
+			{"escrowed", sumFor(Pending, Active)},
+			{"awaiting_confirmation", sumFor(RunnerCompleted)},
+			{"total", bson.D{{"$sum", "$amount"}}},
+			{"errands", bson.D{{"$sum", 1}}},
+		}}},
+	}
+	crs, err := r.Collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	if err = crs.All(ctx, &earnings); err != nil {
+		return nil, err
+	}
+	if len(earnings) == 0 {
+		return &PendingEarnings{}, nil
+	}
+
+	return &earnings[0], nil
+}
+
+// GetTopCategories ranks the categories of the errands a runner completed in the period by amount earned.
+func (r *repository) GetTopCategories(runnerId string, from, to time.Time, limit int64) ([]CategoryEarnings, error) {
+	var categories []CategoryEarnings
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	match := bson.D{
+		{"runner_id", runnerId},
+		{"state", Completed},
+	}
+	completedAt := bson.M{}
+	if !from.IsZero() {
+		completedAt["$gte"] = from
+	}
+	if !to.IsZero() {
+		completedAt["$lte"] = to
+	}
+	if len(completedAt) > 0 {
+		match = append(match, bson.E{Key: "completed_at", Value: completedAt})
+	}
+	pipeline := mongo.Pipeline{
+		{{"$match", match}},
+		{{"$group", bson.D{
+			{"_id", "$category._id"},
+			{"name", bson.D{{"$first", "$category.name"}}},
+			{"amount", bson.D{{"$sum", "$amount"}}},
+			{"errands", bson.D{{"$sum", 1}}},
+		}}},
+		{{"$sort", bson.D{{"amount", -1}, {"errands", -1}}}},
+		{{"$limit", limit}},
+		{{"$addFields", bson.D{{"_id", bson.D{{"$toString", "$_id"}}}}}},
+	}
+	crs, err := r.Collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	if err = crs.All(ctx, &categories); err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
 func (r *repository) GetBidForUser(id string, userId string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
 	defer cancel()
@@ -578,13 +681,14 @@ func (r *repository) RunnerComplete(eId string, userId string) error {
 	cTime := time.Now()
 
 	filter := bson.M{
-		"_id": errandId,
+		"_id":   errandId,
+		"state": Active,
 	}
 
 	param := bson.D{
 		{"$set", bson.D{
-			{"state", Completed},
-			{"status", Completed.Id()},
+			{"state", RunnerCompleted},
+			{"status", RunnerCompleted.Id()},
 			{"updated_at", cTime},
 		}},
 		{"$push", bson.D{
@@ -595,8 +699,10 @@ func (r *repository) RunnerComplete(eId string, userId string) error {
 		}},
 	}
 
-	if _, err := r.Collection.UpdateOne(ctx, filter, param); err != nil {
+	if res, err := r.Collection.UpdateOne(ctx, filter, param); err != nil {
 		return err
+	} else if res.ModifiedCount == 0 {
+		return error_service.ErrErrandState
 	}
 
 	return nil
@@ -610,13 +716,16 @@ func (r *repository) SenderComplete(eId string, userId string) error {
 	cTime := time.Now()
 
 	filter := bson.M{
-		"_id": errandId,
+		"_id":   errandId,
+		"state": bson.M{"$in": bson.A{Active, RunnerCompleted}},
 	}
 
 	param := bson.D{
 		{"$set", bson.D{
-			{"state", RunnerCompleted},
-			{"status", RunnerCompleted.Id()},
+			{"state", Completed},
+			{"status", Completed.Id()},
+			{"payout_pending", true},
+			{"completed_at", cTime},
 			{"updated_at", cTime},
 		}},
 		{"$push", bson.D{
@@ -627,6 +736,215 @@ func (r *repository) SenderComplete(eId string, userId string) error {
 		}},
 	}
 
+	if res, err := r.Collection.UpdateOne(ctx, filter, param); err != nil {
+		return err
+	} else if res.ModifiedCount == 0 {
+		return error_service.ErrErrandState
+	}
+
+	return nil
+}
+
+// Cancel cancels the errand only while it is still in the state it was read in, so the refund worked out
+// from that state is made once.
+func (r *repository) Cancel(eId, userId, reason string, from State) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	errandId, _ := entity.StringToErrandId(eId)
+	cTime := time.Now()
+
+	filter := bson.M{
+		"_id":   errandId,
+		"state": from,
+	}
+
+	set := bson.D{
+		{"state", Cancelled},
+		{"status", Cancelled.Id()},
+		{"updated_at", cTime},
+	}
+	if strings.TrimSpace(reason) != "" {
+		set = append(set, bson.E{Key: "cancellation_reason", Value: reason})
+	}
+	param := bson.D{
+		{"$set", set},
+		{"$push", bson.D{
+			{"modified_by", entity.ModifiedBy{
+				Id:   userId,
+				Date: cTime,
+			}},
+		}},
+	}
+
+	if res, err := r.Collection.UpdateOne(ctx, filter, param); err != nil {
+		return err
+	} else if res.ModifiedCount == 0 {
+		return error_service.ErrErrandState
+	}
+
+	return nil
+}
+
+// RestoreCancelled puts back an errand cancelled by Cancel when its refund could not be made.
+func (r *repository) RestoreCancelled(eId string, state State) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	errandId, _ := entity.StringToErrandId(eId)
+
+	filter := bson.M{
+		"_id":   errandId,
+		"state": Cancelled,
+	}
+	param := bson.D{
+		{"$set", bson.D{
+			{"state", state},
+			{"status", state.Id()},
+			{"updated_at", time.Now()},
+		}},
+		{"$unset", bson.D{
+			{"cancellation_reason", ""},
+		}},
+	}
+
+	if _, err := r.Collection.UpdateOne(ctx, filter, param); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetPayout records the payout of a completed errand and clears its pending payout. Only the payout
+// fields are written so a tip recorded at the same time is kept, fees is saved first when the errand has
+// no breakdown yet.
+func (r *repository) SetPayout(eId string, fees *fee.Breakdown) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	errandId, _ := entity.StringToErrandId(eId)
+
+	filter := bson.M{
+		"_id":  errandId,
+		"fees": nil,
+	}
+	param := bson.D{
+		{"$set", bson.D{
+			{"fees", fees},
+		}},
+	}
+	if _, err := r.Collection.UpdateOne(ctx, filter, param); err != nil {
+		return err
+	}
+
+	filter = bson.M{
+		"_id": errandId,
+	}
+	param = bson.D{
+		{"$set", bson.D{
+			{"fees.amount", fees.Amount},
+			{"fees.commission", fees.Commission},
+			{"fees.commission_waived", fees.CommissionWaived},
+			{"fees.runner_payout", fees.RunnerPayout},
+			{"updated_at", time.Now()},
+		}},
+		{"$unset", bson.D{
+			{"payout_pending", ""},
+		}},
+	}
+	if _, err := r.Collection.UpdateOne(ctx, filter, param); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetPendingPayouts returns the errands completed before the given time whose runner has not been paid.
+func (r *repository) GetPendingPayouts(before time.Time) (errands []Errand, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"state":          Completed,
+		"payout_pending": true,
+		"completed_at":   bson.M{"$lt": before},
+	}
+	crs, err := r.Collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if err = crs.All(ctx, &errands); err != nil {
+		return nil, err
+	}
+
+	return errands, nil
+}
+
+// Tip records the tip on a completed errand the runner has not been tipped on. fees is saved first when the
+// errand has no breakdown yet, so the tip does not land in an empty one.
+func (r *repository) Tip(eId string, amount int64, fees *fee.Breakdown) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	errandId, _ := entity.StringToErrandId(eId)
+	cTime := time.Now()
+
+	if fees != nil {
+		filter := bson.M{
+			"_id":  errandId,
+			"fees": nil,
+		}
+		param := bson.D{
+			{"$set", bson.D{
+				{"fees", fees},
+			}},
+		}
+		if _, err := r.Collection.UpdateOne(ctx, filter, param); err != nil {
+			return err
+		}
+	}
+
+	filter := bson.M{
+		"_id":      errandId,
+		"state":    Completed,
+		"fees.tip": bson.M{"$not": bson.M{"$gt": 0}},
+	}
+	param := bson.D{
+		{"$set", bson.D{
+			{"fees.tip", amount},
+			{"updated_at", cTime},
+		}},
+	}
+
+	if res, err := r.Collection.UpdateOne(ctx, filter, param); err != nil {
+		return err
+	} else if res.ModifiedCount == 0 {
+		return ErrAlreadyTipped
+	}
+
+	return nil
+}
+
+// ClearTip takes back a tip recorded by Tip when the money could not be moved.
+func (r *repository) ClearTip(eId string, amount int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	errandId, _ := entity.StringToErrandId(eId)
+
+	filter := bson.M{
+		"_id":      errandId,
+		"fees.tip": amount,
+	}
+	param := bson.D{
+		{"$unset", bson.D{
+			{"fees.tip", ""},
+		}},
+		{"$set", bson.D{
+			{"updated_at", time.Now()},
+		}},
+	}
+
 	if _, err := r.Collection.UpdateOne(ctx, filter, param); err != nil {
 		return err
 	}
@@ -677,6 +995,7 @@ func (r *repository) Update(errand *Errand) error {
 			{"modified_by", errand.ModifiedBy},
 			{"runner_id", errand.RunnerId},
 			{"timeline", errand.Timeline},
+			{"fees", errand.Fees},
 		}},
 	}
 	filter := bson.M{"_id": errand.Id}
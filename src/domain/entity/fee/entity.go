@@ -0,0 +1,193 @@
+package fee
+
+import (
+	"DX/src/domain/entity"
+	"DX/src/pkg/apperror"
+	"math"
+	"strings"
+	"time"
+)
+
+var ErrInvalidKind = apperror.New(apperror.Invalid, "invalid_fee_kind", "invalid fee kind")
+var ErrInvalidPercentage = apperror.New(apperror.Invalid, "invalid_fee_percentage", "fee percentage must be between 0 and 100")
+var ErrNegativeFee = apperror.New(apperror.Invalid, "negative_fee", "flat and minimum fees cannot be negative")
+var ErrInvalidWaiver = apperror.New(apperror.Invalid, "invalid_waiver_period", "waiver must end after it starts")
+
+type Kind int
+
+const (
+	ServiceFee Kind = iota
+	Commission
+)
+
+func (k Kind) String() string {
+	if k == ServiceFee {
+		return "Service Fee"
+	}
+	if k == Commission {
+		return "Commission"
+	}
+	return ""
+}
+
+func (k Kind) Id() string {
+	if k == ServiceFee {
+		return "service-fee"
+	}
+	if k == Commission {
+		return "commission"
+	}
+	return ""
+}
+
+func GetKind(value string) (Kind, error) {
+	if value == ServiceFee.Id() {
+		return ServiceFee, nil
+	}
+	if value == Commission.Id() {
+		return Commission, nil
+	}
+	return -1, ErrInvalidKind
+}
+
+// Rule prices a fee kind either platform-wide or, when CategoryId is set, for a single category.
+type Rule struct {
+	Id         entity.DatabaseId `json:"id" bson:"_id"`
+	FeeKind    Kind              `json:"-" bson:"fee_kind"`
+	Kind       string            `json:"kind" bson:"kind"`
+	CategoryId string            `json:"category_id,omitempty" bson:"category_id"`
+	Percentage float64           `json:"percentage" bson:"percentage"`
+	Flat       int64             `json:"flat" bson:"flat"`
+	Minimum    int64             `json:"minimum" bson:"minimum"`
+	CreatedBy  string            `json:"created_by" bson:"created_by"`
+	CreatedAt  time.Time         `json:"created_at" bson:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at" bson:"updated_at"`
+}
+
+// Waiver removes a fee kind for a period, optionally only for one category.
+type Waiver struct {
+	Id          entity.DatabaseId `json:"id" bson:"_id"`
+	FeeKind     Kind              `json:"-" bson:"fee_kind"`
+	Kind        string            `json:"kind" bson:"kind"`
+	CategoryId  string            `json:"category_id,omitempty" bson:"category_id"`
+	Description string            `json:"description" bson:"description"`
+	StartsAt    time.Time         `json:"starts_at" bson:"starts_at"`
+	EndsAt      time.Time         `json:"ends_at" bson:"ends_at"`
+	CreatedBy   string            `json:"created_by" bson:"created_by"`
+	CreatedAt   time.Time         `json:"created_at" bson:"created_at"`
+}
+
+// Breakdown is the fee summary stored on an errand and returned to clients.
+type Breakdown struct {
+	Budget           int64  `json:"budget" bson:"budget"`
+	ServiceFee       int64  `json:"service_fee" bson:"service_fee"`
+	ServiceFeeWaived bool   `json:"service_fee_waived" bson:"service_fee_waived"`
+	Discount         int64  `json:"discount,omitempty" bson:"discount,omitempty"`
+	PromoCode        string `json:"promo_code,omitempty" bson:"promo_code,omitempty"`
+	PromoCredit      int64  `json:"promo_credit,omitempty" bson:"promo_credit,omitempty"` // Part of TotalCharged paid with promotional credit
+	TotalCharged     int64  `json:"total_charged" bson:"total_charged"`
+	Amount           int64  `json:"amount,omitempty" bson:"amount,omitempty"`
+	Commission       int64  `json:"commission,omitempty" bson:"commission,omitempty"`
+	CommissionWaived bool   `json:"commission_waived,omitempty" bson:"commission_waived,omitempty"`
+	RunnerPayout     int64  `json:"runner_payout,omitempty" bson:"runner_payout,omitempty"`
+	Tip              int64  `json:"tip,omitempty" bson:"tip,omitempty"` // Paid in full to the runner, no commission is taken
+}
+
+// Charge is the outcome of pricing a single fee kind against an amount.
+type Charge struct {
+	Kind   Kind
+	Amount int64
+	Waived bool
+}
+
+var defaultRules = map[Kind]Rule{
+	ServiceFee: {FeeKind: ServiceFee, Kind: ServiceFee.Id(), Percentage: 5, Minimum: 100},
+	Commission: {FeeKind: Commission, Kind: Commission.Id(), Percentage: 10},
+}
+
+func DefaultRule(kind Kind) Rule {
+	return defaultRules[kind]
+}
+
+func NewRule(kind Kind, categoryId string, percentage float64, flat, minimum int64) (*Rule, error) {
+	if percentage < 0 || percentage > 100 {
+		return nil, ErrInvalidPercentage
+	}
+	if flat < 0 || minimum < 0 {
+		return nil, ErrNegativeFee
+	}
+
+	cTime := time.Now()
+	return &Rule{
+		Id:         entity.NewDatabaseId(),
+		FeeKind:    kind,
+		Kind:       kind.Id(),
+		CategoryId: strings.TrimSpace(categoryId),
+		Percentage: percentage,
+		Flat:       flat,
+		Minimum:    minimum,
+		CreatedAt:  cTime,
+		UpdatedAt:  cTime,
+	}, nil
+}
+
+func NewWaiver(kind Kind, categoryId, description string, startsAt, endsAt time.Time) (*Waiver, error) {
+	if !endsAt.After(startsAt) {
+		return nil, ErrInvalidWaiver
+	}
+
+	return &Waiver{
+		Id:          entity.NewDatabaseId(),
+		FeeKind:     kind,
+		Kind:        kind.Id(),
+		CategoryId:  strings.TrimSpace(categoryId),
+		Description: description,
+		StartsAt:    startsAt,
+		EndsAt:      endsAt,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+// Calculate applies the percentage and flat fee, raises the result to the minimum and never charges more than the amount.
+func (r Rule) Calculate(amount int64) int64 {
+	if amount <= 0 {
+		return 0
+	}
+	value := int64(math.Round(float64(amount)*r.Percentage/100)) + r.Flat
+	if value < r.Minimum {
+		value = r.Minimum
+	}
+	if value > amount {
+		value = amount
+	}
+	return value
+}
+
+func (w Waiver) IsActive(at time.Time) bool {
+	return !at.Before(w.StartsAt) && at.Before(w.EndsAt)
+}
+
+func (b *Breakdown) ApplyDiscount(promoCode string, discount int64) {
+	if discount > b.Budget+b.ServiceFee {
+		discount = b.Budget + b.ServiceFee
+	}
+	b.PromoCode = promoCode
+	b.Discount = discount
+	b.TotalCharged = b.Budget + b.ServiceFee - discount
+}
+
+func NewBreakdown(budget int64, serviceFee Charge) *Breakdown {
+	return &Breakdown{
+		Budget:           budget,
+		ServiceFee:       serviceFee.Amount,
+		ServiceFeeWaived: serviceFee.Waived,
+		TotalCharged:     budget + serviceFee.Amount,
+	}
+}
+
+func (b *Breakdown) AddPayout(amount int64, commission Charge) {
+	b.Amount = amount
+	b.Commission = commission.Amount
+	b.CommissionWaived = commission.Waived
+	b.RunnerPayout = amount - commission.Amount
+}
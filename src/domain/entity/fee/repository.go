@@ -0,0 +1,176 @@
+package fee
+
+import (
+	"DX/src/domain/entity"
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type reader interface {
+	GetRules() ([]Rule, error)
+	GetRule(Kind, string) (*Rule, error)
+	GetWaivers() ([]Waiver, error)
+	GetActiveWaivers(Kind, time.Time) ([]Waiver, error)
+}
+
+type writer interface {
+	SaveRule(*Rule) error
+	DeleteRule(string) error
+	CreateWaiver(*Waiver) error
+	DeleteWaiver(string) error
+}
+
+type Repository interface {
+	reader
+	writer
+}
+
+type repository struct {
+	RuleCollection   *mongo.Collection
+	WaiverCollection *mongo.Collection
+}
+
+func NewRepository(ruleCollection, waiverCollection *mongo.Collection) Repository {
+	return &repository{
+		RuleCollection:   ruleCollection,
+		WaiverCollection: waiverCollection,
+	}
+}
+
+func (r *repository) GetRules() (rules []Rule, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	crs, err := r.RuleCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if err = crs.All(ctx, &rules); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func (r *repository) GetRule(kind Kind, categoryId string) (rule *Rule, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"fee_kind":    kind,
+		"category_id": categoryId,
+	}
+	if err = r.RuleCollection.FindOne(ctx, filter).Decode(&rule); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (r *repository) SaveRule(rule *Rule) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"fee_kind":    rule.FeeKind,
+		"category_id": rule.CategoryId,
+	}
+	param := bson.M{
+		"$set": bson.M{
+			"kind":       rule.Kind,
+			"percentage": rule.Percentage,
+			"flat":       rule.Flat,
+			"minimum":    rule.Minimum,
+			"created_by": rule.CreatedBy,
+			"updated_at": rule.UpdatedAt,
+		},
+		"$setOnInsert": bson.M{
+			"_id":        rule.Id,
+			"created_at": rule.CreatedAt,
+		},
+	}
+	opts := options.Update().SetUpsert(true)
+	if _, err := r.RuleCollection.UpdateOne(ctx, filter, param, opts); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *repository) DeleteRule(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ruleId, _ := entity.StringToErrandId(id)
+	res, err := r.RuleCollection.DeleteOne(ctx, bson.M{"_id": ruleId})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (r *repository) GetWaivers() (waivers []Waiver, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	crs, err := r.WaiverCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if err = crs.All(ctx, &waivers); err != nil {
+		return nil, err
+	}
+
+	return waivers, nil
+}
+
+func (r *repository) GetActiveWaivers(kind Kind, at time.Time) (waivers []Waiver, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"fee_kind":  kind,
+		"starts_at": bson.M{"$lte": at},
+		"ends_at":   bson.M{"$gt": at},
+	}
+	crs, err := r.WaiverCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if err = crs.All(ctx, &waivers); err != nil {
+		return nil, err
+	}
+
+	return waivers, nil
+}
+
+func (r *repository) CreateWaiver(waiver *Waiver) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.WaiverCollection.InsertOne(ctx, waiver)
+	return err
+}
+
+func (r *repository) DeleteWaiver(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	waiverId, _ := entity.StringToErrandId(id)
+	res, err := r.WaiverCollection.DeleteOne(ctx, bson.M{"_id": waiverId})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
@@ -2,7 +2,6 @@ package haggle
 
 import (
 	"DX/src/domain/entity"
-	"errors"
 	"time"
 )
 
@@ -33,28 +32,24 @@ func NewOfflineHaggle(amount int64) Haggle {
 	}
 }
 
-func FromPayload(data map[string]interface{}) (*Haggle, error) {
-	nHaggle := &Haggle{
-		Id:        entity.NewDatabaseId(),
-		CreatedAt: time.Now(),
-	}
-	if amount, ok := data["amount"].(float64); !ok {
-		return nil, errors.New("bid amount is required")
-	} else {
-		nHaggle.Amount = int64(amount)
-	}
-	if source, ok := data["source"].(string); !ok {
-		return nil, errors.New("bid source is required")
-	} else {
-		hSource, err := entity.GetSource(source)
-		if err != nil {
-			return nil, err
-		}
-		nHaggle.HaggleSource = hSource
-		nHaggle.Source = source
-	}
-	if description, ok := data["description"].(string); ok {
-		nHaggle.Description = description
+// Request is an amount offered by the sender or the runner while bidding.
+type Request struct {
+	Amount      float64 `json:"amount" binding:"required,gt=0"`
+	Source      string  `json:"source" binding:"required,oneof=sender runner"`
+	Description string  `json:"description" binding:"max=1000"`
+}
+
+func FromRequest(request Request) (*Haggle, error) {
+	hSource, err := entity.GetSource(request.Source)
+	if err != nil {
+		return nil, err
 	}
-	return nHaggle, nil
+	return &Haggle{
+		Id:           entity.NewDatabaseId(),
+		CreatedAt:    time.Now(),
+		Amount:       int64(request.Amount),
+		Source:       request.Source,
+		HaggleSource: hSource,
+		Description:  request.Description,
+	}, nil
 }
@@ -0,0 +1,81 @@
+package idempotency
+
+import (
+	"DX/src/domain/entity"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+const (
+	HeaderKey = "Idempotency-Key"
+	Window    = 24 * time.Hour
+	// Lease is how long a request holds its key without renewing it, a retry after it takes the key over
+	// in case the server stopped before the request finished. A running request renews it every LeaseRenewal.
+	Lease        = 30 * time.Second
+	LeaseRenewal = Lease / 3
+)
+
+type State int
+
+const (
+	Processing State = iota
+	Completed
+)
+
+type Record struct {
+	Id          entity.DatabaseId `json:"id" bson:"_id"`
+	Key         string            `json:"key" bson:"key"`
+	UserId      string            `json:"user_id" bson:"user_id"`
+	Fingerprint string            `json:"-" bson:"fingerprint"`
+	State       State             `json:"-" bson:"state"`
+	StatusCode  int               `json:"status_code" bson:"status_code"`
+	ContentType string            `json:"content_type" bson:"content_type"`
+	Body        []byte            `json:"-" bson:"body"`
+	CreatedAt   time.Time         `json:"created_at" bson:"created_at"`
+	RenewedAt   time.Time         `json:"-" bson:"renewed_at"` // Last time the request holding the key renewed its lease
+	ExpiresAt   time.Time         `json:"expires_at" bson:"expires_at"`
+}
+
+func New(userId, key, fingerprint string) *Record {
+	cTime := time.Now()
+	return &Record{
+		Id:          entity.NewDatabaseId(),
+		Key:         key,
+		UserId:      userId,
+		Fingerprint: fingerprint,
+		State:       Processing,
+		CreatedAt:   cTime,
+		RenewedAt:   cTime,
+		ExpiresAt:   cTime.Add(Window),
+	}
+}
+
+// Fingerprint identifies a request by its method, path and body so a reused key with a different payload can be rejected.
+func Fingerprint(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method))
+	hash.Write([]byte(path))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (r *Record) Complete(statusCode int, contentType string, body []byte) {
+	r.State = Completed
+	r.StatusCode = statusCode
+	r.ContentType = contentType
+	r.Body = body
+}
+
+func (r *Record) IsCompleted() bool {
+	return r.State == Completed
+}
+
+// IsAbandoned reports whether the request that holds the key stopped renewing its lease without finishing.
+func (r *Record) IsAbandoned() bool {
+	return r.State == Processing && time.Since(r.RenewedAt) > Lease
+}
+
+func (r *Record) Matches(fingerprint string) bool {
+	return r.Fingerprint == fingerprint
+}
@@ -0,0 +1,140 @@
+package idempotency
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"time"
+)
+
+type reader interface {
+	Get(string, string) (*Record, error)
+}
+
+type writer interface {
+	Create(*Record) error
+	Complete(*Record) error
+	Renew(*Record) error
+	Delete(*Record) error
+	DeleteStale(string, string) error
+}
+
+type Repository interface {
+	reader
+	writer
+}
+
+type repository struct {
+	*mongo.Collection
+}
+
+func NewRepository(collection *mongo.Collection) Repository {
+	return &repository{Collection: collection}
+}
+
+func (r *repository) Get(userId, key string) (record *Record, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"user_id": userId,
+		"key":     key,
+		"expires_at": bson.M{
+			"$gt": time.Now(),
+		},
+	}
+	if err = r.Collection.FindOne(ctx, filter).Decode(&record); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+func (r *repository) Create(record *Record) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.Collection.InsertOne(ctx, record)
+	return err
+}
+
+func (r *repository) Complete(record *Record) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"_id": record.Id,
+	}
+	param := bson.M{
+		"$set": bson.M{
+			"state":        record.State,
+			"status_code":  record.StatusCode,
+			"content_type": record.ContentType,
+			"body":         record.Body,
+		},
+	}
+
+	if _, err := r.Collection.UpdateOne(ctx, filter, param); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Renew extends the lease of a request that is still processing, a finished or taken over record is left alone.
+func (r *repository) Renew(record *Record) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"_id":   record.Id,
+		"state": Processing,
+	}
+	param := bson.M{
+		"$set": bson.M{
+			"renewed_at": time.Now(),
+		},
+	}
+	if _, err := r.Collection.UpdateOne(ctx, filter, param); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Delete releases the key held by record, a record that has since been taken over is left alone.
+func (r *repository) Delete(record *Record) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"_id": record.Id,
+	}
+	if _, err := r.Collection.DeleteOne(ctx, filter); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteStale deletes the record of the key only when it has expired or its lease was not renewed, so a
+// request that is still running keeps its key and of two retries taking over the same key only one succeeds.
+func (r *repository) DeleteStale(userId, key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cTime := time.Now()
+	filter := bson.M{
+		"user_id": userId,
+		"key":     key,
+		"$or": bson.A{
+			bson.M{"expires_at": bson.M{"$lte": cTime}},
+			bson.M{"state": Processing, "renewed_at": bson.M{"$lte": cTime.Add(-Lease)}},
+		},
+	}
+	if _, err := r.Collection.DeleteOne(ctx, filter); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package limiter
+
+import (
+	"DX/src/pkg/apperror"
+	"fmt"
+	"math"
+	"time"
+)
+
+const MaxDelay = time.Minute
+
+// Rule limits the hits counted against a key within a sliding window.
+type Rule struct {
+	Name      string
+	Limit     int // hits allowed within Window
+	Window    time.Duration
+	Free      int           // hits before every further attempt is delayed
+	BaseDelay time.Duration // doubled for every hit past Free, up to MaxDelay
+	Lock      time.Duration // locks the key once Limit is reached, otherwise hits are rejected until the window slides
+}
+
+var (
+	LoginAccount       = Rule{Name: "login:account", Limit: 10, Window: time.Hour, Free: 3, BaseDelay: time.Second, Lock: 30 * time.Minute}
+	LoginIp            = Rule{Name: "login:ip", Limit: 100, Window: 15 * time.Minute, Free: 20, BaseDelay: time.Second}
+	SecurityQuestionIp = Rule{Name: "security-question:ip", Limit: 20, Window: 15 * time.Minute}
+	OtpIp              = Rule{Name: "otp:ip", Limit: 20, Window: 15 * time.Minute}
+	PasswordResetIp    = Rule{Name: "password-reset:ip", Limit: 30, Window: 15 * time.Minute}
+	TwoFactorIp        = Rule{Name: "two-factor:ip", Limit: 20, Window: 15 * time.Minute}
+	PinResetAccount    = Rule{Name: "pin-reset:account", Limit: 5, Window: time.Hour, Lock: time.Hour}
+)
+
+var ErrStore = apperror.New(apperror.Unavailable, "rate_limiter_unavailable", "rate limit store unavailable")
+
+// Usage is what has been counted against a key within the window.
+type Usage struct {
+	Count       int
+	First       time.Time
+	Last        time.Time
+	LockedUntil time.Time
+}
+
+// Error rejects an attempt until RetryAfter has passed.
+type Error struct {
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (r Rule) Key(value string) string {
+	return r.Name + ":" + value
+}
+
+// Delay is how long to wait after the last hit before another attempt is allowed.
+func (r Rule) Delay(count int) time.Duration {
+	if r.BaseDelay == 0 || count < r.Free {
+		return 0
+	}
+	exponent := float64(count - r.Free)
+	delay := time.Duration(float64(r.BaseDelay) * math.Pow(2, exponent))
+	if delay <= 0 || delay > MaxDelay {
+		return MaxDelay
+	}
+	return delay
+}
+
+func (u *Usage) IsLocked() bool {
+	return time.Now().Before(u.LockedUntil)
+}
+
+func NewLockedError(retryAfter time.Duration) *Error {
+	return &Error{
+		Message:    fmt.Sprintf("too many failed attempts. try again in %s", humanize(retryAfter)),
+		RetryAfter: retryAfter,
+	}
+}
+
+func NewLimitError(retryAfter time.Duration) *Error {
+	return &Error{
+		Message:    fmt.Sprintf("too many requests. try again in %s", humanize(retryAfter)),
+		RetryAfter: retryAfter,
+	}
+}
+
+func humanize(duration time.Duration) string {
+	if duration < time.Minute {
+		return fmt.Sprintf("%d seconds", int(math.Ceil(duration.Seconds())))
+	}
+	return fmt.Sprintf("%d minutes", int(math.Ceil(duration.Minutes())))
+}
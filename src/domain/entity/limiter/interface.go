@@ -0,0 +1,18 @@
+package limiter
+
+import "time"
+
+type reader interface {
+	Get(string, time.Duration) (*Usage, error)
+}
+
+type writer interface {
+	Hit(string, time.Duration) (*Usage, error)
+	Fail(string, Rule) (bool, error)
+	Clear(string) error
+}
+
+type Repository interface {
+	reader
+	writer
+}
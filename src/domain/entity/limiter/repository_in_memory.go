@@ -0,0 +1,62 @@
+package limiter
+
+import (
+	"sync"
+	"time"
+)
+
+type inMemoryRepository struct {
+	mutex    sync.Mutex
+	counters map[string]*counter
+}
+
+func NewInMemoryRepository() Repository {
+	return &inMemoryRepository{
+		counters: map[string]*counter{},
+	}
+}
+
+func (r *inMemoryRepository) Get(key string, window time.Duration) (*Usage, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	result, ok := r.counters[key]
+	if !ok {
+		return &Usage{}, nil
+	}
+	return result.usage(time.Now().Add(-window)), nil
+}
+
+func (r *inMemoryRepository) Hit(key string, window time.Duration) (*Usage, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	cTime := time.Now()
+	result, ok := r.counters[key]
+	if !ok {
+		result = &counter{Key: key}
+		r.counters[key] = result
+	}
+	result.hit(cTime, window)
+	return result.usage(cTime.Add(-window)), nil
+}
+
+func (r *inMemoryRepository) Fail(key string, rule Rule) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	result, ok := r.counters[key]
+	if !ok {
+		result = &counter{Key: key}
+		r.counters[key] = result
+	}
+	return result.fail(time.Now(), rule), nil
+}
+
+func (r *inMemoryRepository) Clear(key string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.counters, key)
+	return nil
+}
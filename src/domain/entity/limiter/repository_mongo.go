@@ -0,0 +1,167 @@
+package limiter
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+// maxHits bounds the hits kept per key, a key that keeps hitting past it stays over any limit.
+const maxHits = 1000
+
+type counter struct {
+	Key         string      `bson:"_id"`
+	Hits        []time.Time `bson:"hits"`
+	LockedUntil time.Time   `bson:"locked_until"`
+	ExpiresAt   time.Time   `bson:"expires_at"`
+}
+
+type mongoRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewMongoRepository(collection *mongo.Collection) Repository {
+	return &mongoRepository{
+		Collection: collection,
+	}
+}
+
+func (r *mongoRepository) Get(key string, window time.Duration) (*Usage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result := &counter{}
+	err := r.Collection.FindOne(ctx, bson.M{"_id": key}).Decode(result)
+	if err == mongo.ErrNoDocuments {
+		return &Usage{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result.usage(time.Now().Add(-window)), nil
+}
+
+// Hit records a hit and drops the ones that left the window in a single update.
+func (r *mongoRepository) Hit(key string, window time.Duration) (*Usage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cTime := time.Now()
+	cutoff := cTime.Add(-window)
+	hits := bson.D{{"$filter", bson.D{
+		{"input", bson.D{{"$ifNull", bson.A{"$hits", bson.A{}}}}},
+		{"cond", bson.D{{"$gt", bson.A{"$$this", cutoff}}}},
+	}}}
+	update := mongo.Pipeline{
+		{{"$set", bson.D{
+			{"hits", bson.D{{"$slice", bson.A{bson.D{{"$concatArrays", bson.A{hits, bson.A{cTime}}}}, -maxHits}}}},
+			{"expires_at", bson.D{{"$max", bson.A{"$locked_until", cTime.Add(window)}}}},
+		}}},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	result := &counter{}
+	if err := r.Collection.FindOneAndUpdate(ctx, bson.M{"_id": key}, update, opts).Decode(result); err != nil {
+		return nil, err
+	}
+	return result.usage(cutoff), nil
+}
+
+// Fail records a failed attempt and locks the key once the rule's limit is reached, in a single update.
+// The update is worked out again from the document it replaced to tell whether this call took the lock.
+func (r *mongoRepository) Fail(key string, rule Rule) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cTime := time.Now()
+	cutoff := cTime.Add(-rule.Window)
+	locked := bson.D{{"$gt", bson.A{"$locked_until", cTime}}}
+	hits := bson.D{{"$filter", bson.D{
+		{"input", bson.D{{"$ifNull", bson.A{"$hits", bson.A{}}}}},
+		{"cond", bson.D{{"$gt", bson.A{"$$this", cutoff}}}},
+	}}}
+	update := mongo.Pipeline{
+		{{"$set", bson.D{
+			{"hits", bson.D{{"$cond", bson.A{
+				locked,
+				bson.D{{"$ifNull", bson.A{"$hits", bson.A{}}}},
+				bson.D{{"$slice", bson.A{bson.D{{"$concatArrays", bson.A{hits, bson.A{cTime}}}}, -maxHits}}},
+			}}}},
+			{"expires_at", bson.D{{"$max", bson.A{"$locked_until", cTime.Add(rule.Window)}}}},
+		}}},
+	}
+	if rule.Lock > 0 {
+		until := cTime.Add(rule.Lock)
+		lock := bson.D{{"$and", bson.A{
+			bson.D{{"$not", bson.A{locked}}},
+			bson.D{{"$gte", bson.A{bson.D{{"$size", "$hits"}}, rule.Limit}}},
+		}}}
+		update = append(update, bson.D{{"$set", bson.D{
+			{"hits", bson.D{{"$cond", bson.A{lock, bson.A{}, "$hits"}}}},
+			{"locked_until", bson.D{{"$cond", bson.A{lock, until, "$locked_until"}}}},
+			{"expires_at", bson.D{{"$cond", bson.A{lock, until, "$expires_at"}}}},
+		}}})
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)
+
+	previous := &counter{}
+	err := r.Collection.FindOneAndUpdate(ctx, bson.M{"_id": key}, update, opts).Decode(previous)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return false, err
+	}
+	return previous.fail(cTime, rule), nil
+}
+
+func (r *mongoRepository) Clear(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.Collection.DeleteOne(ctx, bson.M{"_id": key})
+	return err
+}
+
+// fail counts a failed attempt at cTime, it reports whether the attempt locked the key.
+func (c *counter) fail(cTime time.Time, rule Rule) bool {
+	if cTime.Before(c.LockedUntil) {
+		return false
+	}
+	c.hit(cTime, rule.Window)
+	if rule.Lock == 0 || len(c.Hits) < rule.Limit {
+		return false
+	}
+	c.Hits = []time.Time{}
+	c.LockedUntil = cTime.Add(rule.Lock)
+	return true
+}
+
+// hit adds a hit at cTime and drops the ones that left the window.
+func (c *counter) hit(cTime time.Time, window time.Duration) {
+	cutoff := cTime.Add(-window)
+	hits := c.Hits[:0]
+	for _, hit := range c.Hits {
+		if hit.After(cutoff) {
+			hits = append(hits, hit)
+		}
+	}
+	c.Hits = append(hits, cTime)
+	if len(c.Hits) > maxHits {
+		c.Hits = c.Hits[len(c.Hits)-maxHits:]
+	}
+}
+
+func (c *counter) usage(cutoff time.Time) *Usage {
+	usage := &Usage{LockedUntil: c.LockedUntil}
+	for _, hit := range c.Hits {
+		if !hit.After(cutoff) {
+			continue
+		}
+		if usage.Count == 0 {
+			usage.First = hit
+		}
+		usage.Last = hit
+		usage.Count++
+	}
+	return usage
+}
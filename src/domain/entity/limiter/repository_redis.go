@@ -0,0 +1,124 @@
+package limiter
+
+import (
+	"DX/src/domain/entity"
+	"context"
+	"github.com/go-redis/redis/v8"
+	"strconv"
+	"time"
+)
+
+type redisRepository struct {
+	client *redis.Client
+}
+
+func NewRedisRepository(client *redis.Client) Repository {
+	return &redisRepository{
+		client: client,
+	}
+}
+
+func hitsKey(key string) string {
+	return "limit:hits:" + key
+}
+
+func lockKey(key string) string {
+	return "limit:lock:" + key
+}
+
+func (r *redisRepository) Get(key string, window time.Duration) (*Usage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pipe := r.client.TxPipeline()
+	pipe.ZRemRangeByScore(ctx, hitsKey(key), "-inf", score(time.Now().Add(-window)))
+	hits := pipe.ZRangeWithScores(ctx, hitsKey(key), 0, -1)
+	lock := pipe.Get(ctx, lockKey(key))
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+	return usage(hits.Val(), lock.Val()), nil
+}
+
+// Hit keeps the hits of a key in a sorted set scored by time, the ones that left the window are
+// dropped in the same transaction.
+func (r *redisRepository) Hit(key string, window time.Duration) (*Usage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cTime := time.Now()
+	pipe := r.client.TxPipeline()
+	pipe.ZRemRangeByScore(ctx, hitsKey(key), "-inf", score(cTime.Add(-window)))
+	pipe.ZAdd(ctx, hitsKey(key), &redis.Z{Score: float64(cTime.UnixNano()), Member: entity.NewDefaultId().String()})
+	pipe.ZRemRangeByRank(ctx, hitsKey(key), 0, -maxHits-1)
+	pipe.PExpire(ctx, hitsKey(key), window)
+	hits := pipe.ZRangeWithScores(ctx, hitsKey(key), 0, -1)
+	lock := pipe.Get(ctx, lockKey(key))
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+	return usage(hits.Val(), lock.Val()), nil
+}
+
+// failScript counts a failed attempt like Hit and swaps the hits for the lock once the limit is reached.
+// It runs as a script so concurrent failures cannot both take the lock. It returns 1 when it locked the key.
+var failScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[2]) == 1 then
+	return 0
+end
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", ARGV[2])
+redis.call("ZADD", KEYS[1], ARGV[1], ARGV[3])
+redis.call("ZREMRANGEBYRANK", KEYS[1], 0, -tonumber(ARGV[7]) - 1)
+redis.call("PEXPIRE", KEYS[1], ARGV[4])
+local lock = tonumber(ARGV[6])
+if lock == 0 or redis.call("ZCARD", KEYS[1]) < tonumber(ARGV[5]) then
+	return 0
+end
+redis.call("DEL", KEYS[1])
+redis.call("SET", KEYS[2], ARGV[8], "PX", lock)
+return 1
+`)
+
+func (r *redisRepository) Fail(key string, rule Rule) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cTime := time.Now()
+	locked, err := failScript.Run(ctx, r.client, []string{hitsKey(key), lockKey(key)},
+		strconv.FormatInt(cTime.UnixNano(), 10),
+		score(cTime.Add(-rule.Window)),
+		entity.NewDefaultId().String(),
+		rule.Window.Milliseconds(),
+		rule.Limit,
+		rule.Lock.Milliseconds(),
+		maxHits,
+		strconv.FormatInt(cTime.Add(rule.Lock).UnixNano(), 10),
+	).Int()
+	if err != nil {
+		return false, err
+	}
+	return locked == 1, nil
+}
+
+func (r *redisRepository) Clear(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return r.client.Del(ctx, hitsKey(key), lockKey(key)).Err()
+}
+
+func score(value time.Time) string {
+	return "(" + strconv.FormatInt(value.UnixNano(), 10)
+}
+
+func usage(hits []redis.Z, lockedUntil string) *Usage {
+	result := &Usage{Count: len(hits)}
+	if len(hits) > 0 {
+		result.First = time.Unix(0, int64(hits[0].Score))
+		result.Last = time.Unix(0, int64(hits[len(hits)-1].Score))
+	}
+	if nanos, err := strconv.ParseInt(lockedUntil, 10, 64); err == nil {
+		result.LockedUntil = time.Unix(0, nanos)
+	}
+	return result
+}
@@ -0,0 +1,298 @@
+package limiter
+
+import (
+	"DX/src/domain/entity"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Every limiter backend must pass the same suite, Redis runs against an embedded server. The Mongo backend
+// shares the window and lock logic of counter, which is tested on its own with fixed times.
+
+func TestInMemoryRepository(t *testing.T) {
+	testRepository(t, func(t *testing.T) Repository {
+		return NewInMemoryRepository()
+	})
+}
+
+func TestRedisRepository(t *testing.T) {
+	testRepository(t, func(t *testing.T) Repository {
+		return newRedisRepository(t, miniredis.RunT(t))
+	})
+}
+
+func newRedisRepository(t *testing.T, server *miniredis.Miniredis) Repository {
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+	return NewRedisRepository(client)
+}
+
+func TestCounterWindow(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	result := &counter{Key: "test"}
+	for _, offset := range []time.Duration{0, 30 * time.Second, 70 * time.Second} {
+		result.hit(start.Add(offset), time.Minute)
+	}
+
+	if len(result.Hits) != 2 {
+		t.Fatalf("%d hits kept, want the 2 within the window", len(result.Hits))
+	}
+	usage := result.usage(start.Add(70 * time.Second).Add(-time.Minute))
+	if usage.Count != 2 || !usage.First.Equal(start.Add(30*time.Second)) || !usage.Last.Equal(start.Add(70*time.Second)) {
+		t.Fatalf("unexpected usage %+v", usage)
+	}
+	if usage = result.usage(start.Add(3 * time.Minute).Add(-time.Minute)); usage.Count != 0 {
+		t.Fatalf("hits past the window were counted: %+v", usage)
+	}
+
+	for index := 0; index < maxHits+5; index++ {
+		result.hit(start.Add(2*time.Minute), time.Minute)
+	}
+	if len(result.Hits) != maxHits {
+		t.Fatalf("%d hits kept, want at most %d", len(result.Hits), maxHits)
+	}
+}
+
+func TestCounterLockExpires(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	rule := Rule{Name: "test", Limit: 2, Window: time.Minute, Lock: 10 * time.Minute}
+	result := &counter{Key: "test"}
+
+	if result.fail(start, rule) || !result.fail(start.Add(time.Second), rule) {
+		t.Fatal("the second failure did not lock the key")
+	}
+	if !result.LockedUntil.Equal(start.Add(time.Second + rule.Lock)) {
+		t.Fatalf("locked until %s, want %s", result.LockedUntil, start.Add(time.Second+rule.Lock))
+	}
+	if result.fail(start.Add(5*time.Minute), rule) || len(result.Hits) != 0 {
+		t.Fatalf("a failure while locked was counted: %+v", result)
+	}
+
+	// Once the lock is over failures count from zero again
+	after := result.LockedUntil.Add(time.Second)
+	if result.fail(after, rule) {
+		t.Fatal("the first failure after the lock locked the key")
+	}
+	if usage := result.usage(after.Add(-rule.Window)); usage.Count != 1 || usage.IsLocked() {
+		t.Fatalf("unexpected usage %+v", usage)
+	}
+}
+
+func TestRedisExpiry(t *testing.T) {
+	server := miniredis.RunT(t)
+	repository := newRedisRepository(t, server)
+	rule := Rule{Name: "test", Limit: 2, Window: time.Minute, Lock: 10 * time.Minute}
+
+	if _, err := repository.Hit("hits", rule.Window); err != nil {
+		t.Fatal(err)
+	}
+	if ttl := server.TTL(hitsKey("hits")); ttl != rule.Window {
+		t.Fatalf("hits expire in %s, want the window of %s", ttl, rule.Window)
+	}
+	server.FastForward(rule.Window + time.Second)
+	if server.Exists(hitsKey("hits")) {
+		t.Fatal("hits were kept past the window")
+	}
+
+	for index := 0; index < rule.Limit; index++ {
+		if _, err := repository.Fail("lock", rule); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if server.Exists(hitsKey("lock")) {
+		t.Fatal("the hits of a locked key were kept")
+	}
+	if ttl := server.TTL(lockKey("lock")); ttl != rule.Lock {
+		t.Fatalf("lock expires in %s, want %s", ttl, rule.Lock)
+	}
+
+	server.FastForward(rule.Lock + time.Second)
+	locked, err := repository.Fail("lock", rule)
+	if err != nil || locked {
+		t.Fatalf("first failure after the lock: locked %t, %v", locked, err)
+	}
+	usage, err := repository.Get("lock", rule.Window)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.Count != 1 || usage.IsLocked() {
+		t.Fatalf("unexpected usage %+v", usage)
+	}
+}
+
+func testRepository(t *testing.T, newRepository func(*testing.T) Repository) {
+	t.Run("hits are counted within the window", func(t *testing.T) {
+		repository := newRepository(t)
+		key := newKey(t, repository)
+
+		for index := 0; index < 3; index++ {
+			if _, err := repository.Hit(key, 200*time.Millisecond); err != nil {
+				t.Fatal(err)
+			}
+		}
+		usage, err := repository.Get(key, 200*time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if usage.Count != 3 || usage.First.IsZero() || usage.Last.Before(usage.First) {
+			t.Fatalf("unexpected usage %+v", usage)
+		}
+
+		time.Sleep(300 * time.Millisecond)
+		usage, err = repository.Hit(key, 200*time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if usage.Count != 1 {
+			t.Fatalf("hits outside the window were counted: %+v", usage)
+		}
+	})
+
+	t.Run("unknown keys are unused", func(t *testing.T) {
+		repository := newRepository(t)
+
+		usage, err := repository.Get(newKey(t, repository), time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if usage.Count != 0 || usage.IsLocked() {
+			t.Fatalf("unexpected usage %+v", usage)
+		}
+	})
+
+	t.Run("fail locks once the limit is reached", func(t *testing.T) {
+		repository := newRepository(t)
+		key := newKey(t, repository)
+		rule := Rule{Name: "test", Limit: 3, Window: time.Minute, Lock: time.Minute}
+
+		for index := 0; index < rule.Limit; index++ {
+			locked, err := repository.Fail(key, rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if locked != (index == rule.Limit-1) {
+				t.Fatalf("failure %d: locked %t", index+1, locked)
+			}
+		}
+		usage, err := repository.Get(key, rule.Window)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !usage.IsLocked() || usage.Count != 0 {
+			t.Fatalf("expected a locked key counting from zero, got %+v", usage)
+		}
+		if time.Until(usage.LockedUntil) > rule.Lock {
+			t.Fatalf("locked for longer than the rule: %s", time.Until(usage.LockedUntil))
+		}
+
+		locked, err := repository.Fail(key, rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if locked {
+			t.Fatal("a locked key was locked again")
+		}
+	})
+
+	t.Run("fail without a lock only counts", func(t *testing.T) {
+		repository := newRepository(t)
+		key := newKey(t, repository)
+		rule := Rule{Name: "test", Limit: 2, Window: time.Minute}
+
+		for index := 0; index < 5; index++ {
+			if locked, err := repository.Fail(key, rule); err != nil || locked {
+				t.Fatalf("failure %d: locked %t, %v", index+1, locked, err)
+			}
+		}
+		usage, err := repository.Get(key, rule.Window)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if usage.Count != 5 || usage.IsLocked() {
+			t.Fatalf("unexpected usage %+v", usage)
+		}
+	})
+
+	t.Run("concurrent failures lock once", func(t *testing.T) {
+		repository := newRepository(t)
+		key := newKey(t, repository)
+		rule := Rule{Name: "test", Limit: 5, Window: time.Minute, Lock: time.Minute}
+
+		var group sync.WaitGroup
+		var mutex sync.Mutex
+		locks := 0
+		for index := 0; index < 20; index++ {
+			group.Add(1)
+			go func() {
+				defer group.Done()
+				locked, err := repository.Fail(key, rule)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if locked {
+					mutex.Lock()
+					locks++
+					mutex.Unlock()
+				}
+			}()
+		}
+		group.Wait()
+
+		if locks != 1 {
+			t.Fatalf("expected a single lock, got %d", locks)
+		}
+	})
+
+	t.Run("clear lifts the lock", func(t *testing.T) {
+		repository := newRepository(t)
+		key := newKey(t, repository)
+		rule := Rule{Name: "test", Limit: 1, Window: time.Minute, Lock: time.Minute}
+
+		if locked, err := repository.Fail(key, rule); err != nil || !locked {
+			t.Fatalf("expected the key to lock: %t, %v", locked, err)
+		}
+		if err := repository.Clear(key); err != nil {
+			t.Fatal(err)
+		}
+		usage, err := repository.Get(key, rule.Window)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if usage.Count != 0 || usage.IsLocked() {
+			t.Fatalf("unexpected usage %+v", usage)
+		}
+	})
+
+	t.Run("keys are counted separately", func(t *testing.T) {
+		repository := newRepository(t)
+		key := newKey(t, repository)
+		other := newKey(t, repository)
+		rule := Rule{Name: "test", Limit: 1, Window: time.Minute, Lock: time.Minute}
+
+		if _, err := repository.Fail(key, rule); err != nil {
+			t.Fatal(err)
+		}
+		usage, err := repository.Get(other, rule.Window)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if usage.Count != 0 || usage.IsLocked() {
+			t.Fatalf("unrelated key was counted: %+v", usage)
+		}
+	})
+}
+
+// newKey is unique to the test.
+func newKey(t *testing.T, repository Repository) string {
+	key := "test:" + entity.NewDefaultId().String()
+	t.Cleanup(func() {
+		_ = repository.Clear(key)
+	})
+	return key
+}
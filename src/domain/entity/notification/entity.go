@@ -138,6 +138,99 @@ func NewRunnerErrandCompletedNotification(userId, errandId string) Notification
 	}
 }
 
+func NewTransactionPinNotification(userId, message string) Notification {
+	cTime := time.Now()
+	return Notification{
+		Id:               entity.NewDatabaseId(),
+		UserId:           userId,
+		Type:             InApp.Id(),
+		NotificationType: InApp,
+		Title:            "Transaction pin",
+		Message:          message,
+		CreatedAt:        cTime,
+	}
+}
+
+func NewRefundNotification(userId, errandId string, amount int64) Notification {
+	cTime := time.Now()
+	return Notification{
+		Id:               entity.NewDatabaseId(),
+		UserId:           userId,
+		Type:             InApp.Id(),
+		NotificationType: InApp,
+		Title:            "Refund issued",
+		Message:          fmt.Sprintf("A refund of %d has been credited to your wallet.", amount),
+		CreatedAt:        cTime,
+		ItemId:           errandId,
+	}
+}
+
+func NewTipNotification(runnerId, errandId string, amount int64) Notification {
+	cTime := time.Now()
+	return Notification{
+		Id:               entity.NewDatabaseId(),
+		UserId:           runnerId,
+		Type:             InApp.Id(),
+		NotificationType: InApp,
+		Title:            "You received a tip",
+		Message:          fmt.Sprintf("The sender tipped you %d for a completed errand.", amount),
+		CreatedAt:        cTime,
+		ItemId:           errandId,
+	}
+}
+
+func NewPromoCreditNotification(userId, message string) Notification {
+	cTime := time.Now()
+	return Notification{
+		Id:               entity.NewDatabaseId(),
+		UserId:           userId,
+		Type:             InApp.Id(),
+		NotificationType: InApp,
+		Title:            "Promo credit",
+		Message:          message,
+		CreatedAt:        cTime,
+	}
+}
+
+func NewPasswordResetNotification(userId string) Notification {
+	cTime := time.Now()
+	return Notification{
+		Id:               entity.NewDatabaseId(),
+		UserId:           userId,
+		Type:             InApp.Id(),
+		NotificationType: InApp,
+		Title:            "Password reset",
+		Message:          "Your password was reset and every device was signed out. If this wasn't you, contact support.",
+		CreatedAt:        cTime,
+	}
+}
+
+func NewAccountLockedNotification(userId string, duration time.Duration) Notification {
+	cTime := time.Now()
+	return Notification{
+		Id:               entity.NewDatabaseId(),
+		UserId:           userId,
+		Type:             InApp.Id(),
+		NotificationType: InApp,
+		Title:            "Account locked",
+		Message:          fmt.Sprintf("Sign in was locked for %d minutes after too many failed attempts. If this wasn't you, reset your password.", int(duration.Minutes())),
+		CreatedAt:        cTime,
+	}
+}
+
+func NewWebhookDisabledNotification(userId, url string) Notification {
+	cTime := time.Now()
+	return Notification{
+		Id:               entity.NewDatabaseId(),
+		UserId:           userId,
+		Type:             InApp.Id(),
+		NotificationType: InApp,
+		Title:            "Webhook disabled",
+		Message:          fmt.Sprintf("Your webhook to %s was disabled after repeated failed deliveries. Fix the endpoint and enable it again.", url),
+		CreatedAt:        cTime,
+	}
+}
+
 func (t Type) Id() string {
 	if t == SMS {
 		return "sms"
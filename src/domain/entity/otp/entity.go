@@ -0,0 +1,167 @@
+package otp
+
+import (
+	"DX/src/domain/entity"
+	"DX/src/pkg/apperror"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+const (
+	CodeLength     = 6
+	CodeLifetime   = 10 * time.Minute
+	ResendCooldown = time.Minute
+	SendWindow     = time.Hour
+	MaxSends       = 5 // per SendWindow
+	MaxAttempts    = 5 // per code
+	TicketLifetime = 30 * time.Minute
+)
+
+var ErrResendCooldown = apperror.New(apperror.RateLimited, "otp_resend_cooldown", "please wait before requesting another code")
+var ErrTooManySends = apperror.New(apperror.RateLimited, "too_many_otp_requests", "too many codes requested. try again later")
+var ErrNoCode = apperror.New(apperror.Invalid, "otp_not_requested", "no verification code has been requested")
+var ErrCodeExpired = apperror.New(apperror.Invalid, "otp_expired", "verification code has expired")
+var ErrTooManyAttempts = apperror.New(apperror.RateLimited, "too_many_otp_attempts", "too many incorrect attempts. request a new code")
+var ErrWrongCode = apperror.New(apperror.Invalid, "wrong_otp", "incorrect verification code")
+var ErrInvalidTicket = apperror.New(apperror.Invalid, "invalid_verification_ticket", "invalid or expired verification ticket")
+
+type Purpose int
+
+const (
+	SignUp Purpose = iota
+	PasswordReset
+)
+
+func (p Purpose) Id() string {
+	if p == SignUp {
+		return "sign-up"
+	}
+	if p == PasswordReset {
+		return "password-reset"
+	}
+	return ""
+}
+
+// Otp is the pending verification of a phone number for a purpose. The code and the ticket issued
+// once the code is verified are only stored as hashes.
+type Otp struct {
+	Id              entity.DatabaseId `json:"-" bson:"_id"`
+	Phone           string            `json:"-" bson:"phone"`
+	Purpose         string            `json:"-" bson:"purpose"`
+	CodeHash        string            `json:"-" bson:"code_hash"`
+	CodeExpiresAt   time.Time         `json:"-" bson:"code_expires_at"`
+	Attempts        int               `json:"-" bson:"attempts"`
+	Sends           int               `json:"-" bson:"sends"`
+	WindowStartedAt time.Time         `json:"-" bson:"window_started_at"`
+	LastSentAt      time.Time         `json:"-" bson:"last_sent_at"`
+	TicketHash      string            `json:"-" bson:"ticket_hash,omitempty"`
+	TicketExpiresAt time.Time         `json:"-" bson:"ticket_expires_at,omitempty"`
+	ExpiresAt       time.Time         `json:"-" bson:"expires_at"`
+	CreatedAt       time.Time         `json:"-" bson:"created_at"`
+}
+
+func New(phone string, purpose Purpose) *Otp {
+	return &Otp{
+		Id:        entity.NewDatabaseId(),
+		Phone:     phone,
+		Purpose:   purpose.Id(),
+		CreatedAt: time.Now(),
+	}
+}
+
+// Issue generates a new code. The send must already be counted in Sends, the repository claims it atomically
+// before the code is issued.
+func (o *Otp) Issue() (string, error) {
+	code, err := newCode()
+	if err != nil {
+		return "", err
+	}
+	o.CodeHash = o.hashCode(code)
+	o.CodeExpiresAt = time.Now().Add(CodeLifetime)
+	o.Attempts = 0
+	o.TicketHash = ""
+	o.TicketExpiresAt = time.Time{}
+	o.ExpiresAt = o.WindowStartedAt.Add(SendWindow)
+	if o.ExpiresAt.Before(o.CodeExpiresAt) {
+		o.ExpiresAt = o.CodeExpiresAt
+	}
+	return code, nil
+}
+
+// ClaimError explains why a send could not be claimed.
+func (o *Otp) ClaimError() error {
+	if time.Now().Before(o.LastSentAt.Add(ResendCooldown)) {
+		return ErrResendCooldown
+	}
+	return ErrTooManySends
+}
+
+// Verify checks the code and issues a ticket that proves the phone number was verified. The attempt
+// must already be counted in Attempts, the repository increments it atomically before the check.
+func (o *Otp) Verify(code string) (string, error) {
+	if o.CodeHash == "" {
+		return "", ErrNoCode
+	}
+	if time.Now().After(o.CodeExpiresAt) {
+		return "", ErrCodeExpired
+	}
+	if o.Attempts > MaxAttempts {
+		return "", ErrTooManyAttempts
+	}
+	if o.hashCode(code) != o.CodeHash {
+		if o.Attempts >= MaxAttempts {
+			return "", ErrTooManyAttempts
+		}
+		return "", ErrWrongCode
+	}
+
+	ticket, err := newTicket()
+	if err != nil {
+		return "", err
+	}
+	o.CodeHash = ""
+	o.TicketHash = Hash(ticket)
+	o.TicketExpiresAt = time.Now().Add(TicketLifetime)
+	if o.ExpiresAt.Before(o.TicketExpiresAt) {
+		o.ExpiresAt = o.TicketExpiresAt
+	}
+	return ticket, nil
+}
+
+func (o *Otp) HasValidTicket(ticket string) bool {
+	return o.TicketHash != "" && Hash(ticket) == o.TicketHash && time.Now().Before(o.TicketExpiresAt)
+}
+
+// hashCode salts the code with the record id so equal codes do not share a hash.
+func (o *Otp) hashCode(code string) string {
+	return Hash(o.Id.Hex() + ":" + code)
+}
+
+func Hash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+func newCode() (string, error) {
+	max := big.NewInt(1)
+	for index := 0; index < CodeLength; index++ {
+		max.Mul(max, big.NewInt(10))
+	}
+	value, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", CodeLength, value), nil
+}
+
+func newTicket() (string, error) {
+	value := make([]byte, 32)
+	if _, err := rand.Read(value); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(value), nil
+}
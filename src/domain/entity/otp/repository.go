@@ -0,0 +1,175 @@
+package otp
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type reader interface {
+	Get(string, Purpose) (*Otp, error)
+}
+
+type writer interface {
+	Save(*Otp) error
+	Claim(string, Purpose) (*Otp, error)
+	Release(*Otp) error
+	Attempt(string, Purpose) (*Otp, error)
+	Delete(string, Purpose) error
+	RedeemTicket(string, Purpose, string) error
+}
+
+type Repository interface {
+	reader
+	writer
+}
+
+type repository struct {
+	Collection *mongo.Collection
+}
+
+func NewRepository(collection *mongo.Collection) Repository {
+	return &repository{
+		Collection: collection,
+	}
+}
+
+func (r *repository) Get(phone string, purpose Purpose) (*Otp, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	otp := &Otp{}
+	if err := r.Collection.FindOne(ctx, bson.M{"phone": phone, "purpose": purpose.Id()}).Decode(otp); err != nil {
+		return nil, err
+	}
+	return otp, nil
+}
+
+func (r *repository) Save(otp *Otp) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Replace().SetUpsert(true)
+	_, err := r.Collection.ReplaceOne(ctx, bson.M{"_id": otp.Id}, otp, opts)
+	return err
+}
+
+// Claim counts a send and returns the updated record, there is no match while the resend cooldown runs or
+// once the sends in the window are used up. The record is created on the first send and its window starts
+// over once it has passed.
+func (r *repository) Claim(phone string, purpose Purpose) (*Otp, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cTime := time.Now()
+	nOtp := New(phone, purpose)
+	insert := bson.M{"$setOnInsert": bson.M{
+		"_id":               nOtp.Id,
+		"code_hash":         "",
+		"attempts":          0,
+		"sends":             0,
+		"window_started_at": cTime,
+		"last_sent_at":      time.Time{},
+		"expires_at":        cTime.Add(SendWindow),
+		"created_at":        nOtp.CreatedAt,
+	}}
+	_, err := r.Collection.UpdateOne(ctx, bson.M{"phone": phone, "purpose": purpose.Id()}, insert, options.Update().SetUpsert(true))
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return nil, err
+	}
+
+	filter := bson.M{
+		"phone":             phone,
+		"purpose":           purpose.Id(),
+		"window_started_at": bson.M{"$lte": cTime.Add(-SendWindow)},
+	}
+	restart := bson.M{"$set": bson.M{"window_started_at": cTime, "sends": 0}}
+	if _, err = r.Collection.UpdateOne(ctx, filter, restart); err != nil {
+		return nil, err
+	}
+
+	filter = bson.M{
+		"phone":        phone,
+		"purpose":      purpose.Id(),
+		"sends":        bson.M{"$lt": MaxSends},
+		"last_sent_at": bson.M{"$lte": cTime.Add(-ResendCooldown)},
+	}
+	update := bson.M{
+		"$inc": bson.M{"sends": 1},
+		"$set": bson.M{"last_sent_at": cTime},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	otp := &Otp{}
+	if err = r.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(otp); err != nil {
+		return nil, err
+	}
+	return otp, nil
+}
+
+// Release gives back a claimed send that was never delivered. claimed is the record returned by Claim, the
+// code it holds is the one issued before the claim so that code stays valid.
+func (r *repository) Release(claimed *Otp) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	previous := *claimed
+	previous.Sends--
+	previous.LastSentAt = time.Time{}
+
+	_, err := r.Collection.ReplaceOne(ctx, bson.M{"_id": claimed.Id, "last_sent_at": claimed.LastSentAt}, &previous)
+	return err
+}
+
+// Attempt counts a verification attempt against the current code and returns the updated record,
+// there is no match once the code is used up or the attempts are exhausted.
+func (r *repository) Attempt(phone string, purpose Purpose) (*Otp, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"phone":     phone,
+		"purpose":   purpose.Id(),
+		"code_hash": bson.M{"$ne": ""},
+		"attempts":  bson.M{"$lt": MaxAttempts},
+	}
+	update := bson.M{"$inc": bson.M{"attempts": 1}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	otp := &Otp{}
+	if err := r.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(otp); err != nil {
+		return nil, err
+	}
+	return otp, nil
+}
+
+func (r *repository) Delete(phone string, purpose Purpose) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.Collection.DeleteOne(ctx, bson.M{"phone": phone, "purpose": purpose.Id()})
+	return err
+}
+
+// RedeemTicket consumes a valid ticket so it cannot be used twice.
+func (r *repository) RedeemTicket(phone string, purpose Purpose, ticket string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"phone":             phone,
+		"purpose":           purpose.Id(),
+		"ticket_hash":       Hash(ticket),
+		"ticket_expires_at": bson.M{"$gt": time.Now()},
+	}
+	result, err := r.Collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrInvalidTicket
+	}
+	return nil
+}
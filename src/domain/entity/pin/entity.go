@@ -0,0 +1,72 @@
+package pin
+
+import (
+	"DX/src/domain/entity"
+	"DX/src/pkg/apperror"
+	"regexp"
+	"time"
+)
+
+const (
+	MaxAttempts          = 5
+	LockDuration         = 30 * time.Minute
+	LargeBudgetThreshold = int64(50000)
+)
+
+var pinRegex = regexp.MustCompile(`^[0-9]{4,6}$`)
+
+var ErrInvalidPin = apperror.New(apperror.Invalid, "invalid_pin", "transaction pin must be 4 to 6 digits")
+var ErrPinRequired = apperror.New(apperror.Invalid, "pin_required", "transaction pin is required")
+var ErrNoPin = apperror.New(apperror.Conflict, "pin_not_set", "transaction pin has not been set")
+var ErrPinExists = apperror.New(apperror.Conflict, "pin_already_exists", "transaction pin already exists")
+var ErrWrongPin = apperror.New(apperror.Invalid, "wrong_pin", "incorrect transaction pin")
+var ErrPinLocked = apperror.New(apperror.RateLimited, "pin_locked", "transaction pin is locked. try again later")
+
+type Pin struct {
+	Id             entity.DatabaseId `json:"-" bson:"_id"`
+	UserId         string            `json:"-" bson:"user_id"`
+	Hash           string            `json:"-" bson:"hash"`
+	FailedAttempts int               `json:"-" bson:"failed_attempts"` // counted before the pin is checked
+	LockedUntil    time.Time         `json:"-" bson:"locked_until"`
+	CreatedAt      time.Time         `json:"-" bson:"created_at"`
+	UpdatedAt      time.Time         `json:"-" bson:"updated_at"`
+}
+
+func New(userId, hash string) *Pin {
+	cTime := time.Now()
+	return &Pin{
+		Id:        entity.NewDatabaseId(),
+		UserId:    userId,
+		Hash:      hash,
+		CreatedAt: cTime,
+		UpdatedAt: cTime,
+	}
+}
+
+func IsValid(value string) error {
+	if !pinRegex.MatchString(value) {
+		return ErrInvalidPin
+	}
+	return nil
+}
+
+func (p *Pin) IsLocked() bool {
+	return time.Now().Before(p.LockedUntil)
+}
+
+// IsExhausted reports whether the attempt counted last used up the attempts at the pin.
+func (p *Pin) IsExhausted() bool {
+	return p.FailedAttempts >= MaxAttempts
+}
+
+func (p *Pin) Reset(hash string) {
+	p.Hash = hash
+	p.FailedAttempts = 0
+	p.LockedUntil = time.Time{}
+	p.UpdatedAt = time.Now()
+}
+
+func (p *Pin) Succeed() {
+	p.FailedAttempts = 0
+	p.UpdatedAt = time.Now()
+}
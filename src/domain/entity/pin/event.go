@@ -0,0 +1,59 @@
+package pin
+
+import (
+	"DX/src/domain/entity"
+	"time"
+)
+
+type EventType int
+
+const (
+	Created EventType = iota
+	Changed
+	Reset
+	Verified
+	Failed
+	Locked
+)
+
+type Event struct {
+	Id        entity.DatabaseId `json:"id" bson:"_id"`
+	UserId    string            `json:"-" bson:"user_id"`
+	EventType EventType         `json:"-" bson:"event_type"`
+	Type      string            `json:"type" bson:"type"`
+	Reason    string            `json:"reason,omitempty" bson:"reason,omitempty"`
+	CreatedAt time.Time         `json:"created_at" bson:"created_at"`
+}
+
+func NewEvent(userId string, eventType EventType, reason string) Event {
+	return Event{
+		Id:        entity.NewDatabaseId(),
+		UserId:    userId,
+		EventType: eventType,
+		Type:      eventType.Id(),
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	}
+}
+
+func (t EventType) Id() string {
+	if t == Created {
+		return "created"
+	}
+	if t == Changed {
+		return "changed"
+	}
+	if t == Reset {
+		return "reset"
+	}
+	if t == Verified {
+		return "verified"
+	}
+	if t == Failed {
+		return "failed"
+	}
+	if t == Locked {
+		return "locked"
+	}
+	return ""
+}
@@ -0,0 +1,145 @@
+package pin
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type reader interface {
+	Get(string) (*Pin, error)
+	GetEvents(string) ([]Event, error)
+}
+
+type writer interface {
+	Save(*Pin) error
+	Attempt(string) (*Pin, error)
+	Lock(string) (bool, error)
+	CreateEvent(Event) error
+}
+
+type Repository interface {
+	reader
+	writer
+}
+
+type repository struct {
+	Collection      *mongo.Collection
+	EventCollection *mongo.Collection
+}
+
+func NewRepository(collection *mongo.Collection, eventCollection *mongo.Collection) Repository {
+	return &repository{
+		Collection:      collection,
+		EventCollection: eventCollection,
+	}
+}
+
+func (r *repository) Get(userId string) (pin *Pin, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"user_id": userId,
+	}
+	if err = r.Collection.FindOne(ctx, filter).Decode(&pin); err != nil {
+		return nil, err
+	}
+
+	return pin, nil
+}
+
+func (r *repository) Save(pin *Pin) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"user_id": pin.UserId,
+	}
+	opts := options.Replace().SetUpsert(true)
+	if _, err := r.Collection.ReplaceOne(ctx, filter, pin, opts); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Attempt counts an attempt at the pin and returns the updated record, there is no match while the pin
+// is locked.
+func (r *repository) Attempt(userId string) (*Pin, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cTime := time.Now()
+	filter := bson.M{
+		"user_id":      userId,
+		"locked_until": bson.M{"$not": bson.M{"$gt": cTime}},
+	}
+	update := bson.M{
+		"$inc": bson.M{"failed_attempts": 1},
+		"$set": bson.M{"updated_at": cTime},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	pin := &Pin{}
+	if err := r.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(pin); err != nil {
+		return nil, err
+	}
+	return pin, nil
+}
+
+// Lock locks the pin and starts counting attempts again once the lock is over. It reports false when the
+// pin was already locked.
+func (r *repository) Lock(userId string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cTime := time.Now()
+	filter := bson.M{
+		"user_id":      userId,
+		"locked_until": bson.M{"$not": bson.M{"$gt": cTime}},
+	}
+	update := bson.M{"$set": bson.M{
+		"failed_attempts": 0,
+		"locked_until":    cTime.Add(LockDuration),
+		"updated_at":      cTime,
+	}}
+	result, err := r.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func (r *repository) GetEvents(userId string) (events []Event, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"user_id": userId,
+	}
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+
+	crs, err := r.EventCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err = crs.All(ctx, &events); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (r *repository) CreateEvent(event Event) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := r.EventCollection.InsertOne(ctx, event); err != nil {
+		return err
+	}
+
+	return nil
+}
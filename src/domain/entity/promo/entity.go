@@ -0,0 +1,218 @@
+package promo
+
+import (
+	"DX/src/domain/entity"
+	"DX/src/pkg/apperror"
+	"DX/src/utils"
+	"strings"
+	"time"
+)
+
+// ReferralReward is the promotional credit paid to a referrer when their invitee completes a first errand.
+const ReferralReward = int64(500)
+
+var (
+	ErrInvalidPromo        = apperror.New(apperror.Invalid, "invalid_promo_code", "invalid promo code")
+	ErrPromoExpired        = apperror.New(apperror.Invalid, "promo_code_expired", "promo code has expired")
+	ErrPromoUsedUp         = apperror.New(apperror.Invalid, "promo_code_used_up", "promo code has reached its usage limit")
+	ErrPromoCategory       = apperror.New(apperror.Invalid, "promo_code_wrong_category", "promo code is not valid for this category")
+	ErrFirstErrand         = apperror.New(apperror.Invalid, "promo_code_first_errand_only", "promo code is only valid on your first errand")
+	ErrPromoUsed           = apperror.New(apperror.Invalid, "promo_code_already_used", "you have already used this promo code")
+	ErrInvalidCode         = apperror.New(apperror.Invalid, "invalid_promo_code_length", "promo code must be between 4 and 20 characters")
+	ErrInvalidDiscount     = apperror.New(apperror.Invalid, "invalid_discount_value", "invalid discount value")
+	ErrInvalidDiscountType = apperror.New(apperror.Invalid, "invalid_discount_type", "invalid discount type")
+	ErrNegativeLimit       = apperror.New(apperror.Invalid, "negative_promo_limit", "promo limits cannot be negative")
+	ErrPastExpiry          = apperror.New(apperror.Invalid, "promo_expiry_in_past", "promo expiry date must be in the future")
+	ErrInvalidReferral     = apperror.New(apperror.Invalid, "invalid_referral_code", "invalid referral code")
+	ErrInvalidCredit       = apperror.New(apperror.Invalid, "invalid_credit_amount", "invalid credit amount")
+)
+
+type Promo struct {
+	Id              entity.DatabaseId `json:"id" bson:"_id"`
+	Code            string            `json:"code" bson:"code"`
+	DiscountKind    DiscountType      `json:"-" bson:"discount_kind"`
+	DiscountType    string            `json:"discount_type" bson:"discount_type"`
+	Value           int64             `json:"value" bson:"value"`
+	MaxDiscount     int64             `json:"max_discount,omitempty" bson:"max_discount,omitempty"`
+	FirstErrandOnly bool              `json:"first_errand_only" bson:"first_errand_only"`
+	CategoryIds     []string          `json:"category_ids" bson:"category_ids"`
+	PerUserLimit    int64             `json:"per_user_limit" bson:"per_user_limit"`
+	UsageLimit      int64             `json:"usage_limit" bson:"usage_limit"`
+	Uses            int64             `json:"uses" bson:"uses"`
+	ExpiresAt       time.Time         `json:"expires_at" bson:"expires_at"`
+	IsActive        bool              `json:"is_active" bson:"is_active"`
+	CreatedBy       string            `json:"created_by" bson:"created_by"`
+	CreatedAt       time.Time         `json:"created_at" bson:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at" bson:"updated_at"`
+}
+
+type Redemption struct {
+	Id        entity.DatabaseId `json:"id" bson:"_id"`
+	PromoId   string            `json:"promo_id" bson:"promo_id"`
+	Code      string            `json:"code" bson:"code"`
+	UserId    string            `json:"user_id" bson:"user_id"`
+	ErrandId  string            `json:"errand_id" bson:"errand_id"`
+	Discount  int64             `json:"discount" bson:"discount"`
+	Use       int64             `json:"-" bson:"use,omitempty"` // Which of the user's uses of a limited promo this is, unique per user
+	CreatedAt time.Time         `json:"created_at" bson:"created_at"`
+}
+
+type Referral struct {
+	Id         entity.DatabaseId `json:"id" bson:"_id"`
+	ReferrerId string            `json:"referrer_id" bson:"referrer_id"`
+	RefereeId  string            `json:"referee_id" bson:"referee_id"`
+	State      ReferralState     `json:"-" bson:"state"`
+	Status     string            `json:"status" bson:"status"`
+	Reward     int64             `json:"reward" bson:"reward"`
+	ErrandId   string            `json:"errand_id,omitempty" bson:"errand_id,omitempty"`
+	CreatedAt  time.Time         `json:"created_at" bson:"created_at"`
+	RewardedAt *time.Time        `json:"rewarded_at,omitempty" bson:"rewarded_at,omitempty"`
+}
+
+type DiscountType int
+
+const (
+	Percentage DiscountType = iota
+	Flat
+)
+
+type ReferralState int
+
+const (
+	ReferralPending ReferralState = iota
+	ReferralRewarded
+)
+
+func NewPromo(code string, discountType DiscountType, value, maxDiscount int64, firstErrandOnly bool, categoryIds []string, perUserLimit, usageLimit int64, expiresAt time.Time) (*Promo, error) {
+	code = NormalizeCode(code)
+	if len(code) < 4 || len(code) > 20 {
+		return nil, ErrInvalidCode
+	}
+	if value <= 0 || (discountType == Percentage && value > 100) {
+		return nil, ErrInvalidDiscount
+	}
+	if maxDiscount < 0 || perUserLimit < 0 || usageLimit < 0 {
+		return nil, ErrNegativeLimit
+	}
+	if !expiresAt.After(time.Now()) {
+		return nil, ErrPastExpiry
+	}
+	if categoryIds == nil {
+		categoryIds = []string{}
+	}
+
+	cTime := time.Now()
+	return &Promo{
+		Id:              entity.NewDatabaseId(),
+		Code:            code,
+		DiscountKind:    discountType,
+		DiscountType:    discountType.Id(),
+		Value:           value,
+		MaxDiscount:     maxDiscount,
+		FirstErrandOnly: firstErrandOnly,
+		CategoryIds:     categoryIds,
+		PerUserLimit:    perUserLimit,
+		UsageLimit:      usageLimit,
+		ExpiresAt:       expiresAt,
+		IsActive:        true,
+		CreatedAt:       cTime,
+		UpdatedAt:       cTime,
+	}, nil
+}
+
+// Validate checks the promo rules for an errand, usage limits of zero are unlimited.
+func (p *Promo) Validate(categoryId string, isFirstErrand bool, userUses int64) error {
+	if !p.IsActive {
+		return ErrInvalidPromo
+	}
+	if time.Now().After(p.ExpiresAt) {
+		return ErrPromoExpired
+	}
+	if p.UsageLimit > 0 && p.Uses >= p.UsageLimit {
+		return ErrPromoUsedUp
+	}
+	if p.PerUserLimit > 0 && userUses >= p.PerUserLimit {
+		return ErrPromoUsed
+	}
+	if len(p.CategoryIds) > 0 && !utils.Contains(p.CategoryIds, categoryId) {
+		return ErrPromoCategory
+	}
+	if p.FirstErrandOnly && !isFirstErrand {
+		return ErrFirstErrand
+	}
+	return nil
+}
+
+func (p *Promo) Discount(amount int64) int64 {
+	var discount int64
+	if p.DiscountKind == Percentage {
+		discount = amount * p.Value / 100
+	} else {
+		discount = p.Value
+	}
+	if p.MaxDiscount > 0 && discount > p.MaxDiscount {
+		discount = p.MaxDiscount
+	}
+	if discount > amount {
+		discount = amount
+	}
+	return discount
+}
+
+func NewRedemption(nPromo *Promo, userId, errandId string, discount int64) *Redemption {
+	return &Redemption{
+		Id:        entity.NewDatabaseId(),
+		PromoId:   nPromo.Id.Hex(),
+		Code:      nPromo.Code,
+		UserId:    userId,
+		ErrandId:  errandId,
+		Discount:  discount,
+		CreatedAt: time.Now(),
+	}
+}
+
+func NewReferral(referrerId, refereeId string) *Referral {
+	return &Referral{
+		Id:         entity.NewDatabaseId(),
+		ReferrerId: referrerId,
+		RefereeId:  refereeId,
+		State:      ReferralPending,
+		Status:     ReferralPending.Id(),
+		Reward:     ReferralReward,
+		CreatedAt:  time.Now(),
+	}
+}
+
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func GetDiscountType(discountType string) (DiscountType, error) {
+	if discountType == Percentage.Id() {
+		return Percentage, nil
+	}
+	if discountType == Flat.Id() {
+		return Flat, nil
+	}
+	return Percentage, ErrInvalidDiscountType
+}
+
+func (d DiscountType) Id() string {
+	if d == Percentage {
+		return "percentage"
+	}
+	if d == Flat {
+		return "flat"
+	}
+	return ""
+}
+
+func (r ReferralState) Id() string {
+	if r == ReferralPending {
+		return "pending"
+	}
+	if r == ReferralRewarded {
+		return "rewarded"
+	}
+	return ""
+}
@@ -0,0 +1,258 @@
+package promo
+
+import (
+	"DX/src/domain/entity"
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type reader interface {
+	GetPromos() ([]Promo, error)
+	GetPromoByCode(string) (*Promo, error)
+	CountRedemptions(string, string) (int64, error)
+	GetRedemption(string) (*Redemption, error)
+	GetReferrals(string) ([]Referral, error)
+}
+
+type writer interface {
+	CreatePromo(*Promo) error
+	DeactivatePromo(string) error
+	Redeem(*Promo, *Redemption) error
+	ReleaseRedemption(*Redemption) error
+	CreateReferral(*Referral) error
+	RewardReferral(string, string) (*Referral, error)
+}
+
+type Repository interface {
+	reader
+	writer
+}
+
+type repository struct {
+	PromoCollection      *mongo.Collection
+	RedemptionCollection *mongo.Collection
+	ReferralCollection   *mongo.Collection
+}
+
+func NewRepository(promoCollection, redemptionCollection, referralCollection *mongo.Collection) Repository {
+	return &repository{
+		PromoCollection:      promoCollection,
+		RedemptionCollection: redemptionCollection,
+		ReferralCollection:   referralCollection,
+	}
+}
+
+func (r *repository) CreatePromo(promo *Promo) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.PromoCollection.InsertOne(ctx, promo)
+	return err
+}
+
+func (r *repository) GetPromos() (promos []Promo, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+	crs, err := r.PromoCollection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err = crs.All(ctx, &promos); err != nil {
+		return nil, err
+	}
+
+	return promos, nil
+}
+
+func (r *repository) GetPromoByCode(code string) (promo *Promo, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err = r.PromoCollection.FindOne(ctx, bson.M{"code": NormalizeCode(code)}).Decode(&promo); err != nil {
+		return nil, err
+	}
+
+	return promo, nil
+}
+
+func (r *repository) DeactivatePromo(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	promoId, _ := entity.StringToErrandId(id)
+	update := bson.D{
+		{"$set", bson.D{
+			{"is_active", false},
+			{"updated_at", time.Now()},
+		}},
+	}
+	res, err := r.PromoCollection.UpdateOne(ctx, bson.M{"_id": promoId}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// Redeem claims a use of the promo only while it is still under its global usage limit. A promo limited
+// per user takes one of the user's numbered uses, two redemptions racing for the same use fail on the
+// unique index and the second returns ErrPromoUsed.
+func (r *repository) Redeem(promo *Promo, redemption *Redemption) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if promo.PerUserLimit > 0 {
+		use, err := r.freeUse(ctx, promo, redemption.UserId)
+		if err != nil {
+			return err
+		}
+		redemption.Use = use
+	}
+
+	filter := bson.M{
+		"_id":       promo.Id,
+		"is_active": true,
+		"$or": bson.A{
+			bson.M{"usage_limit": 0},
+			bson.M{"$expr": bson.M{"$lt": bson.A{"$uses", "$usage_limit"}}},
+		},
+	}
+	update := bson.D{
+		{"$inc", bson.D{{"uses", 1}}},
+		{"$set", bson.D{{"updated_at", time.Now()}}},
+	}
+	res, err := r.PromoCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.ModifiedCount == 0 {
+		return ErrPromoUsedUp
+	}
+
+	if _, err = r.RedemptionCollection.InsertOne(ctx, redemption); err != nil {
+		_, _ = r.PromoCollection.UpdateOne(ctx, bson.M{"_id": promo.Id}, bson.D{{"$inc", bson.D{{"uses", -1}}}})
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrPromoUsed
+		}
+		return err
+	}
+
+	return nil
+}
+
+// freeUse returns the lowest of the user's uses of the promo that is not taken, a released redemption
+// frees its use again.
+func (r *repository) freeUse(ctx context.Context, promo *Promo, userId string) (int64, error) {
+	var redemptions []Redemption
+	opts := options.Find().SetProjection(bson.M{"use": 1})
+	crs, err := r.RedemptionCollection.Find(ctx, bson.M{"promo_id": promo.Id.Hex(), "user_id": userId}, opts)
+	if err != nil {
+		return 0, err
+	}
+	if err = crs.All(ctx, &redemptions); err != nil {
+		return 0, err
+	}
+	if int64(len(redemptions)) >= promo.PerUserLimit {
+		return 0, ErrPromoUsed
+	}
+
+	taken := make(map[int64]bool, len(redemptions))
+	for _, redemption := range redemptions {
+		taken[redemption.Use] = true
+	}
+	use := int64(1)
+	for taken[use] {
+		use++
+	}
+	return use, nil
+}
+
+func (r *repository) ReleaseRedemption(redemption *Redemption) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := r.RedemptionCollection.DeleteOne(ctx, bson.M{"_id": redemption.Id}); err != nil {
+		return err
+	}
+	promoId, _ := entity.StringToErrandId(redemption.PromoId)
+	_, err := r.PromoCollection.UpdateOne(ctx, bson.M{"_id": promoId}, bson.D{{"$inc", bson.D{{"uses", -1}}}})
+	return err
+}
+
+func (r *repository) CountRedemptions(promoId, userId string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return r.RedemptionCollection.CountDocuments(ctx, bson.M{
+		"promo_id": promoId,
+		"user_id":  userId,
+	})
+}
+
+func (r *repository) GetRedemption(errandId string) (redemption *Redemption, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err = r.RedemptionCollection.FindOne(ctx, bson.M{"errand_id": errandId}).Decode(&redemption); err != nil {
+		return nil, err
+	}
+
+	return redemption, nil
+}
+
+func (r *repository) CreateReferral(referral *Referral) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.ReferralCollection.InsertOne(ctx, referral)
+	return err
+}
+
+func (r *repository) GetReferrals(referrerId string) (referrals []Referral, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+	crs, err := r.ReferralCollection.Find(ctx, bson.M{"referrer_id": referrerId}, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err = crs.All(ctx, &referrals); err != nil {
+		return nil, err
+	}
+
+	return referrals, nil
+}
+
+// RewardReferral marks the pending referral of the referee as rewarded, so concurrent completions only reward once.
+func (r *repository) RewardReferral(refereeId, errandId string) (referral *Referral, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"referee_id": refereeId,
+		"state":      ReferralPending,
+	}
+	update := bson.D{
+		{"$set", bson.D{
+			{"state", ReferralRewarded},
+			{"status", ReferralRewarded.Id()},
+			{"errand_id", errandId},
+			{"rewarded_at", time.Now()},
+		}},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	if err = r.ReferralCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&referral); err != nil {
+		return nil, err
+	}
+
+	return referral, nil
+}
@@ -0,0 +1,200 @@
+package reconciliation
+
+import (
+	"DX/src/domain/entity"
+	"DX/src/pkg/apperror"
+	"sort"
+	"time"
+)
+
+var ErrInvalidPeriod = apperror.New(apperror.Invalid, "invalid_reconciliation_period", "invalid reconciliation period")
+var ErrInvalidStatus = apperror.New(apperror.Invalid, "invalid_discrepancy_state", "invalid discrepancy state")
+var ErrAlreadyResolved = apperror.New(apperror.Conflict, "discrepancy_already_resolved", "discrepancy has already been resolved")
+
+type Report struct {
+	Id             entity.DatabaseId `json:"id" bson:"_id"`
+	From           time.Time         `json:"from" bson:"from"`
+	To             time.Time         `json:"to" bson:"to"`
+	LedgerRecords  int               `json:"ledger_records" bson:"ledger_records"`
+	GatewayRecords int               `json:"gateway_records" bson:"gateway_records"`
+	Matched        int               `json:"matched" bson:"matched"`
+	Discrepancies  int               `json:"discrepancies" bson:"discrepancies"`
+	CreatedAt      time.Time         `json:"created_at" bson:"created_at"`
+}
+
+type Discrepancy struct {
+	Id              entity.DatabaseId `json:"id" bson:"_id"`
+	ReportId        string            `json:"report_id" bson:"report_id"`
+	Reference       string            `json:"reference" bson:"reference"`
+	DiscrepancyKind Kind              `json:"-" bson:"discrepancy_kind"`
+	Kind            string            `json:"kind" bson:"kind"`
+	LedgerAmount    int64             `json:"ledger_amount" bson:"ledger_amount"`
+	GatewayAmount   int64             `json:"gateway_amount" bson:"gateway_amount"`
+	LedgerCount     int               `json:"ledger_count" bson:"ledger_count"`
+	GatewayCount    int               `json:"gateway_count" bson:"gateway_count"`
+	TransactionIds  []string          `json:"transaction_ids" bson:"transaction_ids"`
+	Status          Status            `json:"-" bson:"status"`
+	State           string            `json:"state" bson:"state"`
+	Note            string            `json:"note,omitempty" bson:"note,omitempty"`
+	ResolvedBy      string            `json:"resolved_by,omitempty" bson:"resolved_by,omitempty"`
+	ResolvedAt      *time.Time        `json:"resolved_at,omitempty" bson:"resolved_at,omitempty"`
+	CreatedAt       time.Time         `json:"created_at" bson:"created_at"`
+}
+
+// Entry is a ledger transaction or gateway record reduced to a signed amount, credits are positive.
+type Entry struct {
+	Reference     string
+	Amount        int64
+	TransactionId string
+}
+
+type Kind int
+
+const (
+	MissingInLedger Kind = iota
+	MissingAtGateway
+	Duplicate
+	AmountMismatch
+)
+
+type Status int
+
+const (
+	Open Status = iota
+	Resolved
+)
+
+func NewReport(from, to time.Time) *Report {
+	return &Report{
+		Id:        entity.NewDatabaseId(),
+		From:      from,
+		To:        to,
+		CreatedAt: time.Now(),
+	}
+}
+
+// Compare matches ledger entries against gateway entries by reference and fills the report counts.
+func (r *Report) Compare(ledger, gateway []Entry) []Discrepancy {
+	ledgerEntries := groupByReference(ledger)
+	gatewayEntries := groupByReference(gateway)
+
+	references := make([]string, 0, len(ledgerEntries)+len(gatewayEntries))
+	for reference := range ledgerEntries {
+		references = append(references, reference)
+	}
+	for reference := range gatewayEntries {
+		if _, ok := ledgerEntries[reference]; !ok {
+			references = append(references, reference)
+		}
+	}
+	sort.Strings(references)
+
+	var discrepancies []Discrepancy
+	for _, reference := range references {
+		ledgerGroup, gatewayGroup := ledgerEntries[reference], gatewayEntries[reference]
+		kind, ok := classify(ledgerGroup, gatewayGroup)
+		if !ok {
+			r.Matched++
+			continue
+		}
+		discrepancies = append(discrepancies, r.newDiscrepancy(reference, kind, ledgerGroup, gatewayGroup))
+	}
+
+	r.LedgerRecords = len(ledger)
+	r.GatewayRecords = len(gateway)
+	r.Discrepancies = len(discrepancies)
+	return discrepancies
+}
+
+func classify(ledger, gateway []Entry) (Kind, bool) {
+	if len(ledger) == 0 {
+		return MissingInLedger, true
+	}
+	if len(gateway) == 0 {
+		return MissingAtGateway, true
+	}
+	if len(ledger) > 1 || len(gateway) > 1 {
+		return Duplicate, true
+	}
+	if ledger[0].Amount != gateway[0].Amount {
+		return AmountMismatch, true
+	}
+	return 0, false
+}
+
+func (r *Report) newDiscrepancy(reference string, kind Kind, ledger, gateway []Entry) Discrepancy {
+	discrepancy := Discrepancy{
+		Id:              entity.NewDatabaseId(),
+		ReportId:        r.Id.Hex(),
+		Reference:       reference,
+		DiscrepancyKind: kind,
+		Kind:            kind.Id(),
+		LedgerCount:     len(ledger),
+		GatewayCount:    len(gateway),
+		TransactionIds:  []string{},
+		Status:          Open,
+		State:           Open.Id(),
+		CreatedAt:       time.Now(),
+	}
+	for _, entry := range ledger {
+		discrepancy.LedgerAmount += entry.Amount
+		discrepancy.TransactionIds = append(discrepancy.TransactionIds, entry.TransactionId)
+	}
+	for _, entry := range gateway {
+		discrepancy.GatewayAmount += entry.Amount
+	}
+	return discrepancy
+}
+
+func (d *Discrepancy) Resolve(adminId, note string) {
+	cTime := time.Now()
+	d.Status = Resolved
+	d.State = Resolved.Id()
+	d.ResolvedBy = adminId
+	d.ResolvedAt = &cTime
+	d.Note = note
+}
+
+func groupByReference(entries []Entry) map[string][]Entry {
+	groups := make(map[string][]Entry)
+	for _, entry := range entries {
+		groups[entry.Reference] = append(groups[entry.Reference], entry)
+	}
+	return groups
+}
+
+func (k Kind) Id() string {
+	if k == MissingInLedger {
+		return "missing-in-ledger"
+	}
+	if k == MissingAtGateway {
+		return "missing-at-gateway"
+	}
+	if k == Duplicate {
+		return "duplicate"
+	}
+	if k == AmountMismatch {
+		return "amount-mismatch"
+	}
+	return ""
+}
+
+func (s Status) Id() string {
+	if s == Open {
+		return "open"
+	}
+	if s == Resolved {
+		return "resolved"
+	}
+	return ""
+}
+
+func GetStatus(state string) (Status, bool) {
+	if state == Open.Id() {
+		return Open, true
+	}
+	if state == Resolved.Id() {
+		return Resolved, true
+	}
+	return Open, false
+}
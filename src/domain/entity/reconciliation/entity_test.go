@@ -0,0 +1,135 @@
+package reconciliation
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name     string
+		ledger   []Entry
+		gateway  []Entry
+		kind     Kind
+		mismatch bool
+	}{
+		{
+			name:    "matched",
+			ledger:  []Entry{{Reference: "ref-1", Amount: 5000}},
+			gateway: []Entry{{Reference: "ref-1", Amount: 5000}},
+		},
+		{
+			name:     "missing in ledger",
+			gateway:  []Entry{{Reference: "ref-1", Amount: 5000}},
+			kind:     MissingInLedger,
+			mismatch: true,
+		},
+		{
+			name:     "missing at gateway",
+			ledger:   []Entry{{Reference: "ref-1", Amount: 5000}},
+			kind:     MissingAtGateway,
+			mismatch: true,
+		},
+		{
+			name:     "duplicated in ledger",
+			ledger:   []Entry{{Reference: "ref-1", Amount: 5000}, {Reference: "ref-1", Amount: 5000}},
+			gateway:  []Entry{{Reference: "ref-1", Amount: 5000}},
+			kind:     Duplicate,
+			mismatch: true,
+		},
+		{
+			name:     "duplicated at gateway",
+			ledger:   []Entry{{Reference: "ref-1", Amount: 5000}},
+			gateway:  []Entry{{Reference: "ref-1", Amount: 5000}, {Reference: "ref-1", Amount: 5000}},
+			kind:     Duplicate,
+			mismatch: true,
+		},
+		{
+			name:     "amount mismatch",
+			ledger:   []Entry{{Reference: "ref-1", Amount: 5000}},
+			gateway:  []Entry{{Reference: "ref-1", Amount: 4900}},
+			kind:     AmountMismatch,
+			mismatch: true,
+		},
+		{
+			name:     "sign mismatch",
+			ledger:   []Entry{{Reference: "ref-1", Amount: 5000}},
+			gateway:  []Entry{{Reference: "ref-1", Amount: -5000}},
+			kind:     AmountMismatch,
+			mismatch: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kind, mismatch := classify(test.ledger, test.gateway)
+			if mismatch != test.mismatch || (mismatch && kind != test.kind) {
+				t.Fatalf("expected %s %t, got %s %t", test.kind.Id(), test.mismatch, kind.Id(), mismatch)
+			}
+		})
+	}
+}
+
+func TestReportCompare(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	report := NewReport(from, from.Add(24*time.Hour))
+
+	ledger := []Entry{
+		{Reference: "matched", Amount: 1000, TransactionId: "txn-1"},
+		{Reference: "mismatch", Amount: 2000, TransactionId: "txn-2"},
+		{Reference: "duplicate", Amount: 3000, TransactionId: "txn-3"},
+		{Reference: "duplicate", Amount: 3000, TransactionId: "txn-4"},
+		{Reference: "ledger-only", Amount: 4000, TransactionId: "txn-5"},
+	}
+	gateway := []Entry{
+		{Reference: "matched", Amount: 1000},
+		{Reference: "mismatch", Amount: 2500},
+		{Reference: "duplicate", Amount: 3000},
+		{Reference: "gateway-only", Amount: 6000},
+	}
+
+	discrepancies := report.Compare(ledger, gateway)
+
+	if report.LedgerRecords != 5 || report.GatewayRecords != 4 || report.Matched != 1 || report.Discrepancies != 4 {
+		t.Fatalf("unexpected counts %+v", report)
+	}
+
+	expected := []struct {
+		reference      string
+		kind           Kind
+		ledgerAmount   int64
+		gatewayAmount  int64
+		transactionIds int
+	}{
+		{"duplicate", Duplicate, 6000, 3000, 2},
+		{"gateway-only", MissingInLedger, 0, 6000, 0},
+		{"ledger-only", MissingAtGateway, 4000, 0, 1},
+		{"mismatch", AmountMismatch, 2000, 2500, 1},
+	}
+	if len(discrepancies) != len(expected) {
+		t.Fatalf("expected %d discrepancies, got %+v", len(expected), discrepancies)
+	}
+	for index, want := range expected {
+		got := discrepancies[index]
+		if got.Reference != want.reference || got.DiscrepancyKind != want.kind || got.Kind != want.kind.Id() {
+			t.Fatalf("discrepancy %d: expected %s %s, got %s %s", index, want.reference, want.kind.Id(), got.Reference, got.Kind)
+		}
+		if got.LedgerAmount != want.ledgerAmount || got.GatewayAmount != want.gatewayAmount || len(got.TransactionIds) != want.transactionIds {
+			t.Fatalf("discrepancy %s: unexpected amounts %+v", got.Reference, got)
+		}
+		if got.ReportId != report.Id.Hex() || got.Status != Open || got.State != Open.Id() {
+			t.Fatalf("discrepancy %s is not open on the report: %+v", got.Reference, got)
+		}
+	}
+}
+
+func TestReportCompareNothing(t *testing.T) {
+	report := NewReport(time.Now().Add(-time.Hour), time.Now())
+
+	if discrepancies := report.Compare(nil, nil); len(discrepancies) != 0 {
+		t.Fatalf("expected no discrepancies, got %+v", discrepancies)
+	}
+	if report.Matched != 0 || report.Discrepancies != 0 {
+		t.Fatalf("unexpected counts %+v", report)
+	}
+}
@@ -0,0 +1,127 @@
+package reconciliation
+
+import (
+	"DX/src/domain/entity"
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type reader interface {
+	GetReports(int64) ([]Report, error)
+	GetDiscrepancies(Status) ([]Discrepancy, error)
+	GetDiscrepancy(string) (*Discrepancy, error)
+}
+
+type writer interface {
+	CreateReport(*Report) error
+	SaveDiscrepancies([]Discrepancy) error
+	UpdateDiscrepancy(*Discrepancy) error
+}
+
+type Repository interface {
+	reader
+	writer
+}
+
+type repository struct {
+	ReportCollection      *mongo.Collection
+	DiscrepancyCollection *mongo.Collection
+}
+
+func NewRepository(reportCollection, discrepancyCollection *mongo.Collection) Repository {
+	return &repository{
+		ReportCollection:      reportCollection,
+		DiscrepancyCollection: discrepancyCollection,
+	}
+}
+
+func (r *repository) CreateReport(report *Report) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.ReportCollection.InsertOne(ctx, report)
+	return err
+}
+
+func (r *repository) GetReports(limit int64) (reports []Report, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1}).SetLimit(limit)
+	crs, err := r.ReportCollection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err = crs.All(ctx, &reports); err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
+
+// SaveDiscrepancies records new discrepancies, a reference already flagged for the same kind keeps its original record.
+func (r *repository) SaveDiscrepancies(discrepancies []Discrepancy) error {
+	if len(discrepancies) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	models := make([]mongo.WriteModel, 0, len(discrepancies))
+	for _, discrepancy := range discrepancies {
+		filter := bson.M{
+			"reference":        discrepancy.Reference,
+			"discrepancy_kind": discrepancy.DiscrepancyKind,
+		}
+		update := bson.M{
+			"$setOnInsert": discrepancy,
+		}
+		models = append(models, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true))
+	}
+
+	_, err := r.DiscrepancyCollection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+func (r *repository) GetDiscrepancies(status Status) (discrepancies []Discrepancy, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+	crs, err := r.DiscrepancyCollection.Find(ctx, bson.M{"status": status}, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err = crs.All(ctx, &discrepancies); err != nil {
+		return nil, err
+	}
+
+	return discrepancies, nil
+}
+
+func (r *repository) GetDiscrepancy(discrepancyId string) (discrepancy *Discrepancy, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	id, err := entity.StringToErrandId(discrepancyId)
+	if err != nil {
+		return nil, err
+	}
+	if err = r.DiscrepancyCollection.FindOne(ctx, bson.M{"_id": id}).Decode(&discrepancy); err != nil {
+		return nil, err
+	}
+
+	return discrepancy, nil
+}
+
+func (r *repository) UpdateDiscrepancy(discrepancy *Discrepancy) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.DiscrepancyCollection.ReplaceOne(ctx, bson.M{"_id": discrepancy.Id}, discrepancy)
+	return err
+}
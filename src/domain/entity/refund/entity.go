@@ -0,0 +1,140 @@
+package refund
+
+import (
+	"DX/src/domain/entity"
+	"DX/src/domain/entity/errand"
+	"DX/src/pkg/apperror"
+	"time"
+)
+
+// CompensationPercentage of the agreed amount is paid to the runner when an errand is cancelled after it has started.
+const CompensationPercentage = int64(25)
+
+var ErrCompleted = apperror.New(apperror.Conflict, "errand_completed", "completed errands can't be cancelled or refunded")
+var ErrAlreadyCancelled = apperror.New(apperror.Conflict, "errand_already_cancelled", "errand has already been cancelled")
+var ErrInvalidAmount = apperror.New(apperror.Invalid, "invalid_refund_amount", "invalid refund amount")
+var ErrNoReason = apperror.New(apperror.Invalid, "refund_reason_required", "refund reason is required")
+var ErrNotRefundable = apperror.New(apperror.Invalid, "transaction_not_refundable", "only debits from a user wallet can be refunded")
+
+type Refund struct {
+	Id             entity.DatabaseId `json:"id" bson:"_id"`
+	ErrandId       string            `json:"errand_id,omitempty" bson:"errand_id,omitempty"`
+	UserId         string            `json:"user_id" bson:"user_id"`
+	RunnerId       string            `json:"runner_id,omitempty" bson:"runner_id,omitempty"`
+	RefundKind     Kind              `json:"-" bson:"refund_kind"`
+	Kind           string            `json:"kind" bson:"kind"`
+	Amount         int64             `json:"amount" bson:"amount"`
+	ServiceFee     int64             `json:"service_fee" bson:"service_fee"`
+	Compensation   int64             `json:"compensation" bson:"compensation"`
+	Discount       int64             `json:"discount,omitempty" bson:"discount,omitempty"`
+	PromoCredit    int64             `json:"promo_credit,omitempty" bson:"promo_credit,omitempty"`
+	Reason         string            `json:"reason" bson:"reason"`
+	IssuedBy       string            `json:"issued_by" bson:"issued_by"`
+	TransactionIds []string          `json:"transaction_ids" bson:"transaction_ids"`
+	CreatedAt      time.Time         `json:"created_at" bson:"created_at"`
+}
+
+type Kind int
+
+const (
+	Full Kind = iota
+	Partial
+	Manual
+)
+
+// ForCancellation applies the cancellation policy to a funded errand. Until the runner starts everything that was
+// charged is returned, once the runner has started they are compensated from the budget and the service fee is kept,
+// and nothing is returned after completion. A promo discount is taken back out of whatever is returned.
+func ForCancellation(oErrand *errand.Errand, cancelledBy string) (*Refund, error) {
+	nRefund := &Refund{
+		Id:             entity.NewDatabaseId(),
+		ErrandId:       oErrand.Id.Hex(),
+		UserId:         oErrand.UserId,
+		Reason:         "Errand cancelled",
+		IssuedBy:       cancelledBy,
+		TransactionIds: []string{},
+		CreatedAt:      time.Now(),
+	}
+
+	switch oErrand.State {
+	case errand.Open, errand.Pending:
+		nRefund.setKind(Full)
+		nRefund.Amount = oErrand.Budget
+		if oErrand.Fees != nil {
+			nRefund.ServiceFee = oErrand.Fees.ServiceFee
+		}
+	case errand.Active:
+		nRefund.setKind(Partial)
+		nRefund.RunnerId = oErrand.RunnerId
+		nRefund.Compensation = oErrand.Amount * CompensationPercentage / 100
+		if nRefund.Compensation > oErrand.Budget {
+			nRefund.Compensation = oErrand.Budget
+		}
+		nRefund.Amount = oErrand.Budget - nRefund.Compensation
+	case errand.Completed, errand.RunnerCompleted, errand.Review:
+		return nil, ErrCompleted
+	case errand.Cancelled, errand.Abandoned:
+		return nil, ErrAlreadyCancelled
+	default:
+		return nil, nil
+	}
+
+	if oErrand.Fees != nil {
+		nRefund.Discount = oErrand.Fees.Discount
+		if nRefund.Discount > nRefund.Amount+nRefund.ServiceFee {
+			nRefund.Discount = nRefund.Amount + nRefund.ServiceFee
+		}
+		// Whatever was paid with promotional credit goes back as promotional credit so it can't be withdrawn.
+		nRefund.PromoCredit = oErrand.Fees.PromoCredit
+		if nRefund.PromoCredit > nRefund.Returned() {
+			nRefund.PromoCredit = nRefund.Returned()
+		}
+	}
+
+	return nRefund, nil
+}
+
+// Returned is what goes back to the sender's wallet.
+func (r *Refund) Returned() int64 {
+	return r.Amount + r.ServiceFee - r.Discount
+}
+
+func NewManualRefund(userId, errandId, issuedBy, reason string, amount int64) (*Refund, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if reason == "" {
+		return nil, ErrNoReason
+	}
+
+	nRefund := &Refund{
+		Id:             entity.NewDatabaseId(),
+		ErrandId:       errandId,
+		UserId:         userId,
+		Amount:         amount,
+		Reason:         reason,
+		IssuedBy:       issuedBy,
+		TransactionIds: []string{},
+		CreatedAt:      time.Now(),
+	}
+	nRefund.setKind(Manual)
+	return nRefund, nil
+}
+
+func (r *Refund) setKind(kind Kind) {
+	r.RefundKind = kind
+	r.Kind = kind.Id()
+}
+
+func (k Kind) Id() string {
+	if k == Full {
+		return "full"
+	}
+	if k == Partial {
+		return "partial"
+	}
+	if k == Manual {
+		return "manual"
+	}
+	return ""
+}
@@ -0,0 +1,116 @@
+package refund
+
+import (
+	"DX/src/domain/entity"
+	"DX/src/domain/entity/errand"
+	"DX/src/domain/entity/fee"
+	"testing"
+)
+
+func TestForCancellation(t *testing.T) {
+	// A budget of 10000 with a 500 service fee, 300 off with a promo code and 1000 of it paid with promo credit.
+	paid := func() *fee.Breakdown {
+		return &fee.Breakdown{Budget: 10000, ServiceFee: 500, Discount: 300, PromoCode: "SAVE", PromoCredit: 1000, TotalCharged: 10200}
+	}
+
+	tests := []struct {
+		name   string
+		state  errand.State
+		amount int64
+		fees   *fee.Breakdown
+		want   *Refund
+		err    bool
+	}{
+		{
+			name:  "draft errands were never funded",
+			state: errand.Draft,
+		},
+		{
+			name:  "open returns everything charged",
+			state: errand.Open,
+			fees:  paid(),
+			want:  &Refund{Kind: "full", Amount: 10000, ServiceFee: 500, Discount: 300, PromoCredit: 1000},
+		},
+		{
+			name:   "pending returns everything charged",
+			state:  errand.Pending,
+			amount: 8000,
+			fees:   paid(),
+			want:   &Refund{Kind: "full", Amount: 10000, ServiceFee: 500, Discount: 300, PromoCredit: 1000},
+		},
+		{
+			name:  "pending without a fee breakdown returns the budget",
+			state: errand.Pending,
+			want:  &Refund{Kind: "full", Amount: 10000},
+		},
+		{
+			name:   "active compensates the runner and keeps the service fee",
+			state:  errand.Active,
+			amount: 8000,
+			fees:   paid(),
+			want:   &Refund{Kind: "partial", RunnerId: "runner-1", Amount: 8000, Compensation: 2000, Discount: 300, PromoCredit: 1000},
+		},
+		{
+			name:   "active compensation is capped at the budget",
+			state:  errand.Active,
+			amount: 50000,
+			fees:   paid(),
+			want:   &Refund{Kind: "partial", RunnerId: "runner-1", Compensation: 10000},
+		},
+		{
+			name:   "promo credit is capped at what is returned",
+			state:  errand.Active,
+			amount: 36000,
+			fees:   paid(),
+			want:   &Refund{Kind: "partial", RunnerId: "runner-1", Amount: 1000, Compensation: 9000, Discount: 300, PromoCredit: 700},
+		},
+		{name: "runner completed", state: errand.RunnerCompleted, err: true},
+		{name: "in review", state: errand.Review, err: true},
+		{name: "completed", state: errand.Completed, err: true},
+		{name: "cancelled", state: errand.Cancelled, err: true},
+		{name: "abandoned", state: errand.Abandoned, err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			oErrand := &errand.Errand{
+				Id:       entity.NewDatabaseId(),
+				UserId:   "sender-1",
+				RunnerId: "runner-1",
+				State:    test.state,
+				Budget:   10000,
+				Amount:   test.amount,
+				Fees:     test.fees,
+			}
+
+			got, err := ForCancellation(oErrand, "sender-1")
+			if test.err {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.want == nil {
+				if got != nil {
+					t.Fatalf("expected no refund, got %+v", got)
+				}
+				return
+			}
+
+			if got.Kind != test.want.Kind || got.RunnerId != test.want.RunnerId || got.Amount != test.want.Amount ||
+				got.ServiceFee != test.want.ServiceFee || got.Compensation != test.want.Compensation ||
+				got.Discount != test.want.Discount || got.PromoCredit != test.want.PromoCredit {
+				t.Fatalf("expected %+v, got %+v", test.want, got)
+			}
+			if got.UserId != "sender-1" || got.ErrandId != oErrand.Id.Hex() || got.IssuedBy != "sender-1" {
+				t.Fatalf("refund is not linked to the errand: %+v", got)
+			}
+			if got.Returned() < 0 {
+				t.Fatalf("refund takes money from the sender: %d", got.Returned())
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package refund
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type reader interface {
+	GetRefunds(string, string) ([]Refund, error)
+}
+
+type writer interface {
+	Create(*Refund) error
+}
+
+type Repository interface {
+	reader
+	writer
+}
+
+type repository struct {
+	Collection *mongo.Collection
+}
+
+func NewRepository(collection *mongo.Collection) Repository {
+	return &repository{
+		Collection: collection,
+	}
+}
+
+func (r *repository) Create(refund *Refund) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.Collection.InsertOne(ctx, refund)
+	return err
+}
+
+func (r *repository) GetRefunds(userId, errandId string) (refunds []Refund, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if userId != "" {
+		filter["user_id"] = userId
+	}
+	if errandId != "" {
+		filter["errand_id"] = errandId
+	}
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1}).SetLimit(100)
+	crs, err := r.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err = crs.All(ctx, &refunds); err != nil {
+		return nil, err
+	}
+
+	return refunds, nil
+}
@@ -0,0 +1,101 @@
+package reset
+
+import (
+	"DX/src/domain/entity"
+	"DX/src/domain/entity/otp"
+	"DX/src/domain/entity/security"
+	"DX/src/pkg/apperror"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+const (
+	Lifetime      = time.Hour // of the whole reset, attempts are not restored by requesting new codes
+	TokenLifetime = 15 * time.Minute
+	MaxAttempts   = 5 // security tickets and reset tokens combined
+)
+
+var ErrNotStarted = apperror.New(apperror.Invalid, "reset_not_started", "no password reset is in progress")
+var ErrTooManyAttempts = apperror.New(apperror.RateLimited, "too_many_reset_attempts", "too many incorrect attempts. try again later")
+var ErrInvalidToken = apperror.New(apperror.Invalid, "invalid_reset_token", "invalid or expired reset token")
+var ErrUnverifiedEmail = apperror.New(apperror.Conflict, "unverified_email", "no verified email address on the account")
+var ErrInvalidChannel = apperror.New(apperror.Invalid, "invalid_reset_channel", "invalid channel")
+var ErrNoPassword = apperror.New(apperror.Invalid, "password_required", "password is required")
+
+type Channel int
+
+const (
+	Sms Channel = iota
+	Email
+)
+
+func (c Channel) Id() string {
+	if c == Sms {
+		return "sms"
+	}
+	if c == Email {
+		return "email"
+	}
+	return ""
+}
+
+func GetChannel(value string) (Channel, error) {
+	if value == "" || value == Sms.Id() {
+		return Sms, nil
+	}
+	if value == Email.Id() {
+		return Email, nil
+	}
+	return 0, ErrInvalidChannel
+}
+
+// Reset tracks a password reset from the code being requested until the new password is set. The
+// reset token is only stored as a hash.
+type Reset struct {
+	Id             entity.DatabaseId `json:"-" bson:"_id"`
+	UserId         string            `json:"-" bson:"user_id"`
+	Phone          string            `json:"-" bson:"phone"`
+	Attempts       int               `json:"-" bson:"attempts"`
+	TokenHash      string            `json:"-" bson:"token_hash,omitempty"`
+	TokenExpiresAt time.Time         `json:"-" bson:"token_expires_at,omitempty"`
+	ExpiresAt      time.Time         `json:"-" bson:"expires_at"`
+	CreatedAt      time.Time         `json:"-" bson:"created_at"`
+}
+
+// Progress is returned after each step and tells the client what to send next.
+type Progress struct {
+	Ticket            string            `json:"ticket,omitempty"`
+	SecurityQuestions []security.Prompt `json:"security_questions,omitempty"`
+	ResetToken        string            `json:"reset_token,omitempty"`
+}
+
+func New(userId, phone string) *Reset {
+	cTime := time.Now()
+	return &Reset{
+		Id:        entity.NewDatabaseId(),
+		UserId:    userId,
+		Phone:     phone,
+		ExpiresAt: cTime.Add(Lifetime),
+		CreatedAt: cTime,
+	}
+}
+
+func (r *Reset) IssueToken() (string, error) {
+	value := make([]byte, 32)
+	if _, err := rand.Read(value); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(value)
+
+	r.TokenHash = otp.Hash(token)
+	r.TokenExpiresAt = time.Now().Add(TokenLifetime)
+	if r.ExpiresAt.Before(r.TokenExpiresAt) {
+		r.ExpiresAt = r.TokenExpiresAt
+	}
+	return token, nil
+}
+
+func (r *Reset) IsExpired() bool {
+	return time.Now().After(r.ExpiresAt)
+}
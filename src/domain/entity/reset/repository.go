@@ -0,0 +1,106 @@
+package reset
+
+import (
+	"DX/src/domain/entity/otp"
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type reader interface {
+	Get(string) (*Reset, error)
+}
+
+type writer interface {
+	Save(*Reset) error
+	Attempt(string) (*Reset, error)
+	RedeemToken(string, string) (*Reset, error)
+	Delete(string) error
+}
+
+type Repository interface {
+	reader
+	writer
+}
+
+type repository struct {
+	Collection *mongo.Collection
+}
+
+func NewRepository(collection *mongo.Collection) Repository {
+	return &repository{
+		Collection: collection,
+	}
+}
+
+func (r *repository) Get(phone string) (*Reset, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	reset := &Reset{}
+	if err := r.Collection.FindOne(ctx, bson.M{"phone": phone}).Decode(reset); err != nil {
+		return nil, err
+	}
+	return reset, nil
+}
+
+func (r *repository) Save(reset *Reset) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Replace().SetUpsert(true)
+	_, err := r.Collection.ReplaceOne(ctx, bson.M{"_id": reset.Id}, reset, opts)
+	return err
+}
+
+// Attempt counts an attempt against the reset and returns the updated record, there is no match
+// once the attempts are exhausted or the reset has expired.
+func (r *repository) Attempt(phone string) (*Reset, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"phone":      phone,
+		"attempts":   bson.M{"$lt": MaxAttempts},
+		"expires_at": bson.M{"$gt": time.Now()},
+	}
+	update := bson.M{"$inc": bson.M{"attempts": 1}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	reset := &Reset{}
+	if err := r.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(reset); err != nil {
+		return nil, err
+	}
+	return reset, nil
+}
+
+// RedeemToken consumes a valid reset token so it cannot be used twice.
+func (r *repository) RedeemToken(phone, token string) (*Reset, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"phone":            phone,
+		"token_hash":       otp.Hash(token),
+		"token_expires_at": bson.M{"$gt": time.Now()},
+	}
+
+	reset := &Reset{}
+	if err := r.Collection.FindOneAndDelete(ctx, filter).Decode(reset); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	return reset, nil
+}
+
+func (r *repository) Delete(phone string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.Collection.DeleteOne(ctx, bson.M{"phone": phone})
+	return err
+}
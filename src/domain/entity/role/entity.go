@@ -0,0 +1,187 @@
+package role
+
+import (
+	"DX/src/domain/entity"
+	"DX/src/pkg/apperror"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Permission is a single action on the admin side, named <resource>:<action>.
+type Permission string
+
+const (
+	AdminAccess            Permission = "admin:access"
+	UsersRead              Permission = "users:read"
+	UsersWrite             Permission = "users:write"
+	UsersSuspend           Permission = "users:suspend"
+	UsersDelete            Permission = "users:delete"
+	ErrandsRead            Permission = "errands:read"
+	ErrandsWrite           Permission = "errands:write"
+	ErrandsAssign          Permission = "errands:assign"
+	CategoriesWrite        Permission = "categories:write"
+	FeesRead               Permission = "fees:read"
+	FeesWrite              Permission = "fees:write"
+	WalletsRead            Permission = "wallets:read"
+	PromosWrite            Permission = "promos:write"
+	RefundsRead            Permission = "refunds:read"
+	RefundsWrite           Permission = "refunds:write"
+	SecurityQuestionsWrite Permission = "security-questions:write"
+	ReconciliationRead     Permission = "reconciliation:read"
+	ReconciliationWrite    Permission = "reconciliation:write"
+	RolesWrite             Permission = "roles:write"
+)
+
+var Permissions = []Permission{
+	AdminAccess, UsersRead, UsersWrite, UsersSuspend, UsersDelete, ErrandsRead, ErrandsWrite, ErrandsAssign,
+	CategoriesWrite, FeesRead, FeesWrite, WalletsRead, PromosWrite, RefundsRead, RefundsWrite,
+	SecurityQuestionsWrite, ReconciliationRead, ReconciliationWrite, RolesWrite,
+}
+
+// Names of the built-in roles, they match the ids of the legacy user types.
+const (
+	SuperAdmin    = "super-admin"
+	Admin         = "admin"
+	ClientManager = "client-manager"
+)
+
+var ErrUnknownRole = apperror.New(apperror.NotFound, "role_not_found", "unknown role")
+var ErrUnknownPermission = apperror.New(apperror.Invalid, "unknown_permission", "unknown permission")
+var ErrBuiltIn = apperror.New(apperror.Conflict, "built_in_role", "built-in roles cannot be changed")
+var ErrInvalidName = apperror.New(apperror.Invalid, "invalid_role_name", "role name must be lowercase letters, digits and dashes")
+var ErrNoPermissions = apperror.New(apperror.Invalid, "no_permissions", "a role needs at least one permission")
+var ErrForbidden = apperror.New(apperror.Forbidden, "forbidden", "you do not have permission to do this")
+var ErrOwnRoles = apperror.New(apperror.Forbidden, "own_roles", "you cannot change your own roles")
+
+var namePattern = regexp.MustCompile(`^[a-z][a-z0-9-]{1,39}$`)
+
+// Role bundles permissions, users are granted every permission of each of their roles.
+type Role struct {
+	Id          entity.DatabaseId `json:"-" bson:"_id"`
+	Name        string            `json:"name" bson:"name"`
+	Description string            `json:"description" bson:"description"`
+	Permissions []Permission      `json:"permissions" bson:"permissions"`
+	BuiltIn     bool              `json:"built_in" bson:"-"`
+	CreatedBy   string            `json:"-" bson:"created_by"`
+	CreatedAt   time.Time         `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at" bson:"updated_at"`
+}
+
+// BuiltIn are the roles every deployment has, they live in code and cannot be edited.
+var BuiltIn = []Role{
+	{
+		Name:        SuperAdmin,
+		Description: "Full access, including managing roles",
+		Permissions: Permissions,
+		BuiltIn:     true,
+	},
+	{
+		Name:        Admin,
+		Description: "Full access except managing roles",
+		Permissions: without(Permissions, RolesWrite),
+		BuiltIn:     true,
+	},
+	{
+		Name:        ClientManager,
+		Description: "Manages offline users and their errands",
+		Permissions: []Permission{AdminAccess, UsersRead, UsersWrite, ErrandsRead, ErrandsWrite, ErrandsAssign, WalletsRead},
+		BuiltIn:     true,
+	},
+}
+
+// Definition is what an admin sets on a custom role.
+type Definition struct {
+	Description string   `json:"description" binding:"max=200"`
+	Permissions []string `json:"permissions" binding:"required,min=1,dive,notblank"`
+}
+
+type Request struct {
+	Name string `json:"name" binding:"required,notblank"`
+	Definition
+}
+
+func New(request Request, createdBy string) (*Role, error) {
+	name := strings.ToLower(strings.TrimSpace(request.Name))
+	if !namePattern.MatchString(name) {
+		return nil, ErrInvalidName
+	}
+	if GetBuiltIn(name) != nil {
+		return nil, ErrBuiltIn
+	}
+
+	currentTime := time.Now()
+	nRole := &Role{
+		Id:        entity.NewDatabaseId(),
+		Name:      name,
+		CreatedBy: createdBy,
+		CreatedAt: currentTime,
+		UpdatedAt: currentTime,
+	}
+	if err := nRole.Set(request.Definition); err != nil {
+		return nil, err
+	}
+	return nRole, nil
+}
+
+// Set replaces the description and permissions of the role.
+func (r *Role) Set(definition Definition) error {
+	if len(definition.Permissions) == 0 {
+		return ErrNoPermissions
+	}
+
+	var permissions []Permission
+	for _, value := range definition.Permissions {
+		permission, err := GetPermission(value)
+		if err != nil {
+			return err
+		}
+		if !contains(permissions, permission) {
+			permissions = append(permissions, permission)
+		}
+	}
+	r.Description = strings.TrimSpace(definition.Description)
+	r.Permissions = permissions
+	r.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *Role) Grants(permission Permission) bool {
+	return contains(r.Permissions, permission)
+}
+
+func GetPermission(value string) (Permission, error) {
+	permission := Permission(strings.ToLower(strings.TrimSpace(value)))
+	if !contains(Permissions, permission) {
+		return "", ErrUnknownPermission.WithDetail(value)
+	}
+	return permission, nil
+}
+
+func GetBuiltIn(name string) *Role {
+	for index := range BuiltIn {
+		if BuiltIn[index].Name == name {
+			return &BuiltIn[index]
+		}
+	}
+	return nil
+}
+
+func contains(permissions []Permission, permission Permission) bool {
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func without(permissions []Permission, excluded Permission) []Permission {
+	var result []Permission
+	for _, permission := range permissions {
+		if permission != excluded {
+			result = append(result, permission)
+		}
+	}
+	return result
+}
@@ -0,0 +1,141 @@
+package role
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type reader interface {
+	Get(string) (*Role, error)
+	GetAll() ([]Role, error)
+	GetMany([]string) ([]Role, error)
+}
+
+type writer interface {
+	Create(*Role) error
+	Update(*Role) error
+	Delete(string) error
+}
+
+type Repository interface {
+	reader
+	writer
+}
+
+// repository only stores the custom roles, the built-in ones are returned from code.
+type repository struct {
+	Collection *mongo.Collection
+}
+
+func NewRepository(collection *mongo.Collection) Repository {
+	return &repository{
+		Collection: collection,
+	}
+}
+
+func (r *repository) Get(name string) (*Role, error) {
+	if builtIn := GetBuiltIn(name); builtIn != nil {
+		return builtIn, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	nRole := &Role{}
+	if err := r.Collection.FindOne(ctx, bson.M{"name": name}).Decode(nRole); err != nil {
+		return nil, err
+	}
+	return nRole, nil
+}
+
+func (r *repository) GetAll() ([]Role, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := r.Collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"name": 1}))
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make([]Role, 0)
+	if err = cursor.All(ctx, &roles); err != nil {
+		return nil, err
+	}
+	return append(append([]Role{}, BuiltIn...), roles...), nil
+}
+
+// GetMany returns the roles with the names, unknown names are skipped.
+func (r *repository) GetMany(names []string) ([]Role, error) {
+	var roles []Role
+	var custom []string
+	for _, name := range names {
+		if builtIn := GetBuiltIn(name); builtIn != nil {
+			roles = append(roles, *builtIn)
+		} else {
+			custom = append(custom, name)
+		}
+	}
+	if len(custom) == 0 {
+		return roles, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := r.Collection.Find(ctx, bson.M{"name": bson.M{"$in": custom}})
+	if err != nil {
+		return nil, err
+	}
+
+	var customRoles []Role
+	if err = cursor.All(ctx, &customRoles); err != nil {
+		return nil, err
+	}
+	return append(roles, customRoles...), nil
+}
+
+func (r *repository) Create(role *Role) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.Collection.InsertOne(ctx, role)
+	return err
+}
+
+func (r *repository) Update(role *Role) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	param := bson.M{
+		"$set": bson.M{
+			"description": role.Description,
+			"permissions": role.Permissions,
+			"updated_at":  role.UpdatedAt,
+		},
+	}
+	result, err := r.Collection.UpdateOne(ctx, bson.M{"name": role.Name}, param)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *repository) Delete(name string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := r.Collection.DeleteOne(ctx, bson.M{"name": name})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
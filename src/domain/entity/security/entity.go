@@ -1,40 +1,210 @@
 package security
 
 import (
-	"errors"
+	"DX/src/domain/entity"
+	"DX/src/domain/entity/otp"
+	"DX/src/pkg/apperror"
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"time"
 )
 
+const (
+	MinAnswers        = 2
+	MaxAnswers        = 5
+	QuestionsAsked    = 2
+	MaxAttempts       = 5
+	LockDuration      = 30 * time.Minute
+	ChallengeLifetime = 10 * time.Minute
+	TicketLifetime    = 15 * time.Minute
+	legacyQuestionId  = "legacy"
+)
+
+var ErrWrongAnswer = apperror.New(apperror.Invalid, "wrong_security_answer", "wrong answer to security question")
+var ErrLocked = apperror.New(apperror.RateLimited, "security_questions_locked", "security questions are locked. try again later")
+var ErrNotSet = apperror.New(apperror.Conflict, "security_questions_not_set", "security questions have not been set")
+var ErrNoChallenge = apperror.New(apperror.Invalid, "security_challenge_not_requested", "request the security questions before answering them")
+var ErrInvalidTicket = apperror.New(apperror.Invalid, "invalid_security_ticket", "invalid or expired security ticket")
+var ErrUnknownQuestion = apperror.New(apperror.Invalid, "unknown_security_question", "unknown security question")
+var ErrDuplicateQuestion = apperror.New(apperror.Invalid, "duplicate_security_question", "each security question can only be answered once")
+var ErrAnswerCount = apperror.New(apperror.Invalid, "invalid_answer_count", "answer between 2 and 5 security questions")
+var ErrNoQuestion = apperror.New(apperror.Invalid, "security_question_required", "question is required")
+
+// Question is an entry of the admin managed catalogue users pick their questions from.
+type Question struct {
+	Id        entity.DatabaseId `json:"id" bson:"_id"`
+	Text      string            `json:"question" bson:"text"`
+	Active    bool              `json:"active" bson:"active"`
+	CreatedBy string            `json:"-" bson:"created_by"`
+	CreatedAt time.Time         `json:"-" bson:"created_at"`
+}
+
+type Answer struct {
+	QuestionId string `json:"question_id" bson:"question_id"`
+	Question   string `json:"question" bson:"question"`
+	Hash       string `json:"-" bson:"hash"`
+}
+
+// Prompt is a question the user has to answer.
+type Prompt struct {
+	QuestionId string `json:"question_id"`
+	Question   string `json:"question"`
+}
+
+// Security holds the hashed answers of a user and the state of their verification.
 type Security struct {
-	Question    string `json:"question,omitempty" bson:"question"`
-	Answer      string `json:"-" bson:"answer"`
-	UserId      string `json:"-" bson:"user_id"`
-	PhoneNumber string `json:"-" bson:"phone_number"`
+	UserId             string    `json:"-" bson:"user_id"`
+	PhoneNumber        string    `json:"-" bson:"phone_number"`
+	Answers            []Answer  `json:"-" bson:"answers"`
+	Challenge          []string  `json:"-" bson:"challenge"`
+	ChallengeExpiresAt time.Time `json:"-" bson:"challenge_expires_at"`
+	FailedAttempts     int       `json:"-" bson:"failed_attempts"` // counted before the answers are checked
+	LockedUntil        time.Time `json:"-" bson:"locked_until"`
+	TicketHash         string    `json:"-" bson:"ticket_hash,omitempty"`
+	TicketExpiresAt    time.Time `json:"-" bson:"ticket_expires_at,omitempty"`
+	UpdatedAt          time.Time `json:"-" bson:"updated_at"`
+	// The single plain text answer stored before answers were hashed, it is moved into Answers on first use
+	LegacyQuestion string `json:"-" bson:"question,omitempty"`
+	LegacyAnswer   string `json:"-" bson:"answer,omitempty"`
+}
+
+func NewQuestion(text, createdBy string) (*Question, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, ErrNoQuestion
+	}
+	return &Question{
+		Id:        entity.NewDatabaseId(),
+		Text:      text,
+		Active:    true,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+	}, nil
 }
 
-func Create(data map[string]interface{}) (*Security, error) {
-	var question, answer string
-	var ok bool
+// AnswerRequest is the answer to one question as sent by a client.
+type AnswerRequest struct {
+	QuestionId string `json:"question_id" binding:"required,notblank"`
+	Answer     string `json:"answer" binding:"required,notblank,max=200"`
+}
 
-	if question, ok = data["question"].(string); !ok {
-		return nil, errors.New("question is required")
+// GetAnswers maps the question ids of a request to their answers.
+func GetAnswers(values []AnswerRequest) (map[string]string, error) {
+	answers := map[string]string{}
+	for _, value := range values {
+		if _, exists := answers[value.QuestionId]; exists {
+			return nil, ErrDuplicateQuestion
+		}
+		answers[value.QuestionId] = value.Answer
 	}
-	if answer, ok = data["answer"].(string); !ok {
-		return nil, errors.New("answer is required")
+	return answers, nil
+}
+
+// Normalize makes answers insensitive to case and spacing before they are hashed or compared.
+func Normalize(answer string) string {
+	return strings.Join(strings.Fields(strings.ToLower(answer)), " ")
+}
+
+func (s *Security) IsLocked() bool {
+	return time.Now().Before(s.LockedUntil)
+}
+
+func (s *Security) HasLegacyAnswer() bool {
+	return s.LegacyAnswer != ""
+}
+
+// MigrateLegacyAnswer replaces the plain text answer with its hash.
+func (s *Security) MigrateLegacyAnswer(hash string) {
+	s.Answers = append(s.Answers, Answer{
+		QuestionId: legacyQuestionId,
+		Question:   s.LegacyQuestion,
+		Hash:       hash,
+	})
+	s.LegacyQuestion = ""
+	s.LegacyAnswer = ""
+	s.UpdatedAt = time.Now()
+}
+
+func (s *Security) Prompts() []Prompt {
+	prompts := make([]Prompt, 0, len(s.Answers))
+	for _, answer := range s.Answers {
+		prompts = append(prompts, Prompt{QuestionId: answer.QuestionId, Question: answer.Question})
 	}
-	return &Security{
-		Question: question,
-		Answer:   answer,
-	}, nil
+	return prompts
+}
+
+func (s *Security) Answer(questionId string) (Answer, bool) {
+	for _, answer := range s.Answers {
+		if answer.QuestionId == questionId {
+			return answer, true
+		}
+	}
+	return Answer{}, false
 }
 
-func GetAnswer(data map[string]interface{}) (string, string, error) {
-	var phone, answer string
-	var ok bool
-	if phone, ok = data["phone_number"].(string); !ok {
-		return "", "", errors.New("phone number is required")
+// Ask picks the questions to verify the user with. The same questions are asked until they expire so
+// the user cannot keep asking for easier ones.
+func (s *Security) Ask() ([]Prompt, error) {
+	cTime := time.Now()
+	if s.HasChallenge() {
+		return s.challengePrompts(), nil
 	}
-	if answer, ok = data["answer"].(string); !ok {
-		return "", "", errors.New("answer is required")
+
+	questionIds := make([]string, 0, len(s.Answers))
+	for _, answer := range s.Answers {
+		questionIds = append(questionIds, answer.QuestionId)
+	}
+	for index := len(questionIds) - 1; index > 0; index-- {
+		value, err := rand.Int(rand.Reader, big.NewInt(int64(index+1)))
+		if err != nil {
+			return nil, err
+		}
+		swap := int(value.Int64())
+		questionIds[index], questionIds[swap] = questionIds[swap], questionIds[index]
+	}
+	if len(questionIds) > QuestionsAsked {
+		questionIds = questionIds[:QuestionsAsked]
+	}
+
+	s.Challenge = questionIds
+	s.ChallengeExpiresAt = cTime.Add(ChallengeLifetime)
+	s.UpdatedAt = cTime
+	return s.challengePrompts(), nil
+}
+
+func (s *Security) HasChallenge() bool {
+	return len(s.Challenge) > 0 && time.Now().Before(s.ChallengeExpiresAt)
+}
+
+// IsExhausted reports whether the attempt counted last used up the attempts at the challenge.
+func (s *Security) IsExhausted() bool {
+	return s.FailedAttempts >= MaxAttempts
+}
+
+// Succeed clears the challenge and issues a ticket that proves the user answered it.
+func (s *Security) Succeed() (string, error) {
+	value := make([]byte, 32)
+	if _, err := rand.Read(value); err != nil {
+		return "", err
+	}
+	ticket := hex.EncodeToString(value)
+
+	s.FailedAttempts = 0
+	s.Challenge = nil
+	s.UpdatedAt = time.Now()
+	s.TicketHash = otp.Hash(ticket)
+	s.TicketExpiresAt = s.UpdatedAt.Add(TicketLifetime)
+	return ticket, nil
+}
+
+func (s *Security) challengePrompts() []Prompt {
+	prompts := make([]Prompt, 0, len(s.Challenge))
+	for _, questionId := range s.Challenge {
+		if answer, ok := s.Answer(questionId); ok {
+			prompts = append(prompts, Prompt{QuestionId: answer.QuestionId, Question: answer.Question})
+		}
 	}
-	return phone, answer, nil
+	return prompts
 }
@@ -2,10 +2,17 @@ package security
 
 type reader interface {
 	Get(string) (*Security, error)
+	GetQuestion(string) (*Question, error)
+	GetQuestions(bool) ([]Question, error)
 }
 
 type writer interface {
-	Create(*Security) error
+	Save(*Security) error
+	Attempt(string) (*Security, error)
+	Lock(string) error
+	RedeemTicket(string, string) error
+	CreateQuestion(*Question) error
+	DeactivateQuestion(string) error
 }
 
 type Repository interface {
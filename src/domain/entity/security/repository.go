@@ -1,19 +1,26 @@
 package security
 
 import (
+	"DX/src/domain/entity"
+	"DX/src/domain/entity/otp"
 	"context"
 	"fmt"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"time"
 )
 
 type repository struct {
-	Collection *mongo.Collection
+	Collection          *mongo.Collection
+	CatalogueCollection *mongo.Collection
 }
 
-func NewDatabaseRepository(collection *mongo.Collection) Repository {
-	return &repository{Collection: collection}
+func NewDatabaseRepository(collection *mongo.Collection, catalogueCollection *mongo.Collection) Repository {
+	return &repository{
+		Collection:          collection,
+		CatalogueCollection: catalogueCollection,
+	}
 }
 
 func (r *repository) Get(value string) (*Security, error) {
@@ -33,10 +40,139 @@ func (r *repository) Get(value string) (*Security, error) {
 	return sec, nil
 }
 
-func (r *repository) Create(sec *Security) error {
+func (r *repository) Save(sec *Security) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Replace().SetUpsert(true)
+	_, err := r.Collection.ReplaceOne(ctx, bson.M{"user_id": sec.UserId}, sec, opts)
+	return err
+}
+
+// Attempt counts an attempt at the challenge of the user and returns the updated record, there is no
+// match while the questions are locked.
+func (r *repository) Attempt(userId string) (*Security, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cTime := time.Now()
+	filter := bson.M{
+		"user_id":      userId,
+		"locked_until": bson.M{"$not": bson.M{"$gt": cTime}},
+	}
+	update := bson.M{
+		"$inc": bson.M{"failed_attempts": 1},
+		"$set": bson.M{"updated_at": cTime},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	sec := &Security{}
+	if err := r.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(sec); err != nil {
+		return nil, err
+	}
+	return sec, nil
+}
+
+// Lock locks the questions and starts counting attempts again once the lock is over.
+func (r *repository) Lock(userId string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cTime := time.Now()
+	filter := bson.M{
+		"user_id":      userId,
+		"locked_until": bson.M{"$not": bson.M{"$gt": cTime}},
+	}
+	update := bson.M{"$set": bson.M{
+		"failed_attempts": 0,
+		"locked_until":    cTime.Add(LockDuration),
+		"challenge":       nil,
+		"updated_at":      cTime,
+	}}
+	_, err := r.Collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// RedeemTicket consumes a valid ticket so it cannot be used twice.
+func (r *repository) RedeemTicket(userId, ticket string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"user_id":           userId,
+		"ticket_hash":       otp.Hash(ticket),
+		"ticket_expires_at": bson.M{"$gt": time.Now()},
+	}
+	update := bson.M{"$unset": bson.M{"ticket_hash": "", "ticket_expires_at": ""}}
+	result, err := r.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrInvalidTicket
+	}
+	return nil
+}
+
+func (r *repository) GetQuestion(id string) (*Question, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	questionId, err := entity.StringToErrandId(id)
+	if err != nil {
+		return nil, ErrUnknownQuestion
+	}
+	question := &Question{}
+	if err = r.CatalogueCollection.FindOne(ctx, bson.M{"_id": questionId}).Decode(question); err != nil {
+		return nil, err
+	}
+	return question, nil
+}
+
+func (r *repository) GetQuestions(activeOnly bool) ([]Question, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if activeOnly {
+		filter["active"] = true
+	}
+	opts := options.Find().SetSort(bson.M{"created_at": 1})
+	cursor, err := r.CatalogueCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	questions := make([]Question, 0)
+	if err = cursor.All(ctx, &questions); err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+func (r *repository) CreateQuestion(question *Question) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	_, err := r.Collection.InsertOne(ctx, sec)
+	_, err := r.CatalogueCollection.InsertOne(ctx, question)
 	return err
 }
+
+// DeactivateQuestion hides the question from the catalogue, users who already answered it keep it.
+func (r *repository) DeactivateQuestion(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	questionId, err := entity.StringToErrandId(id)
+	if err != nil {
+		return ErrUnknownQuestion
+	}
+	result, err := r.CatalogueCollection.UpdateOne(ctx, bson.M{"_id": questionId}, bson.M{"$set": bson.M{"active": false}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package session
+
+import (
+	"DX/src/domain/entity/auth"
+	"time"
+)
+
+// Session is a signed in device. Its id is the token family, so revoking a session revokes
+// every token issued to the device.
+type Session struct {
+	Id         string    `json:"id" bson:"_id"`
+	UserId     string    `json:"-" bson:"user_id"`
+	DeviceId   string    `json:"device_id" bson:"device_id"`
+	Client     string    `json:"client" bson:"client"`
+	Ip         string    `json:"ip" bson:"ip"`
+	UserAgent  string    `json:"user_agent" bson:"user_agent"`
+	TwoFactor  bool      `json:"two_factor" bson:"two_factor"` // whether the sign in passed two-factor authentication
+	Current    bool      `json:"current" bson:"-"`
+	CreatedAt  time.Time `json:"created_at" bson:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at" bson:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at" bson:"expires_at"`
+}
+
+// Info describes the request a session was started or refreshed from.
+type Info struct {
+	Client    string
+	Ip        string
+	UserAgent string
+}
+
+func New(token *auth.Token, info Info) *Session {
+	cTime := time.Now()
+	return &Session{
+		Id:         token.Family,
+		UserId:     token.UserId,
+		DeviceId:   token.DeviceId,
+		Client:     info.Client,
+		Ip:         info.Ip,
+		UserAgent:  info.UserAgent,
+		CreatedAt:  cTime,
+		LastSeenAt: cTime,
+		ExpiresAt:  token.RefreshExpiry(),
+	}
+}
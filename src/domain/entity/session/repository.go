@@ -0,0 +1,123 @@
+package session
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type reader interface {
+	Get(string, string) (*Session, error)
+	GetSessions(string) ([]Session, error)
+	GetDeviceSessions(string, string) ([]Session, error)
+}
+
+type writer interface {
+	Create(*Session) error
+	Touch(string, Info, time.Time) error
+	SetTwoFactor(string, string) error
+	Delete(string, ...string) error
+}
+
+type Repository interface {
+	reader
+	writer
+}
+
+type repository struct {
+	Collection *mongo.Collection
+}
+
+func NewRepository(collection *mongo.Collection) Repository {
+	return &repository{
+		Collection: collection,
+	}
+}
+
+func (r *repository) Create(session *Session) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.Collection.InsertOne(ctx, session)
+	return err
+}
+
+func (r *repository) Get(userId, id string) (*Session, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	session := &Session{}
+	if err := r.Collection.FindOne(ctx, bson.M{"_id": id, "user_id": userId}).Decode(session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (r *repository) GetSessions(userId string) ([]Session, error) {
+	return r.find(bson.M{"user_id": userId})
+}
+
+func (r *repository) GetDeviceSessions(userId, deviceId string) ([]Session, error) {
+	return r.find(bson.M{"user_id": userId, "device_id": deviceId})
+}
+
+// Touch records activity on the session and extends it to the new refresh token expiry.
+func (r *repository) Touch(id string, info Info, expiresAt time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	update := bson.M{
+		"$set": bson.M{
+			"ip":           info.Ip,
+			"user_agent":   info.UserAgent,
+			"last_seen_at": time.Now(),
+			"expires_at":   expiresAt,
+		},
+	}
+	_, err := r.Collection.UpdateByID(ctx, id, update)
+	return err
+}
+
+func (r *repository) SetTwoFactor(userId, id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := r.Collection.UpdateOne(ctx, bson.M{"_id": id, "user_id": userId}, bson.M{"$set": bson.M{"two_factor": true}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *repository) Delete(userId string, ids ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"user_id": userId,
+		"_id":     bson.M{"$in": ids},
+	}
+	_, err := r.Collection.DeleteMany(ctx, filter)
+	return err
+}
+
+func (r *repository) find(filter bson.M) (sessions []Session, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.M{"last_seen_at": -1})
+	crs, err := r.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err = crs.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
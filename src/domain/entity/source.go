@@ -1,6 +1,8 @@
 package entity
 
-import "errors"
+import "DX/src/pkg/apperror"
+
+var ErrInvalidSource = apperror.New(apperror.Invalid, "invalid_bid_source", "invalid bid source")
 
 type Source int
 
@@ -20,7 +22,7 @@ func GetSource(value string) (Source, error) {
 	if value == Admin.Id() {
 		return Admin, nil
 	}
-	return -1, errors.New("invalid bid source")
+	return -1, ErrInvalidSource
 }
 
 func (s Source) String() string {
@@ -0,0 +1,119 @@
+package twofactor
+
+import (
+	"DX/src/domain/entity"
+	"DX/src/domain/entity/otp"
+	"DX/src/pkg/apperror"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+const (
+	RecoveryCodeCount = 10
+	ChallengeLifetime = 5 * time.Minute
+	MaxAttempts       = 5 // per challenge
+)
+
+var ErrNotEnrolled = apperror.New(apperror.Conflict, "two_factor_not_enabled", "two-factor authentication is not enabled")
+var ErrAlreadyEnabled = apperror.New(apperror.Conflict, "two_factor_already_enabled", "two-factor authentication is already enabled")
+var ErrWrongCode = apperror.New(apperror.Invalid, "wrong_two_factor_code", "incorrect authentication code")
+var ErrInvalidChallenge = apperror.New(apperror.Unauthorized, "invalid_two_factor_challenge", "invalid or expired sign in challenge. sign in again")
+var ErrRequired = apperror.New(apperror.Forbidden, "two_factor_required", "two-factor authentication is required")
+var ErrMandatory = apperror.New(apperror.Forbidden, "two_factor_mandatory", "two-factor authentication cannot be disabled on admin accounts")
+
+// TwoFactor is the authenticator app enrolled by a user. Recovery codes are only stored as hashes.
+type TwoFactor struct {
+	Id            entity.DatabaseId `json:"-" bson:"_id"`
+	UserId        string            `json:"-" bson:"user_id"`
+	Secret        string            `json:"-" bson:"secret"`
+	Enabled       bool              `json:"-" bson:"enabled"`
+	RecoveryCodes []string          `json:"-" bson:"recovery_codes"`
+	LastStep      int64             `json:"-" bson:"last_step"` // the last code used, so it cannot be replayed
+	CreatedAt     time.Time         `json:"-" bson:"created_at"`
+	EnabledAt     time.Time         `json:"-" bson:"enabled_at,omitempty"`
+}
+
+// StepUp is returned by sign in instead of the tokens when the account has two-factor authentication.
+type StepUp struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	Challenge         string `json:"challenge"`
+}
+
+// Enrollment is shown once, when the user starts setting up their authenticator app.
+type Enrollment struct {
+	Secret          string   `json:"secret"`
+	ProvisioningUri string   `json:"provisioning_uri"`
+	RecoveryCodes   []string `json:"recovery_codes"`
+}
+
+// Challenge is a sign in waiting for the second factor.
+type Challenge struct {
+	Id        entity.DatabaseId `json:"-" bson:"_id"`
+	Hash      string            `json:"-" bson:"hash"`
+	UserId    string            `json:"-" bson:"user_id"`
+	DeviceId  string            `json:"-" bson:"device_id"`
+	Client    string            `json:"-" bson:"client"`
+	Attempts  int               `json:"-" bson:"attempts"`
+	ExpiresAt time.Time         `json:"-" bson:"expires_at"`
+}
+
+func New(userId, secret string) *TwoFactor {
+	return &TwoFactor{
+		Id:        entity.NewDatabaseId(),
+		UserId:    userId,
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	}
+}
+
+func NewChallenge(userId, deviceId, client string) (*Challenge, string, error) {
+	value, err := newSecret(32)
+	if err != nil {
+		return nil, "", err
+	}
+	return &Challenge{
+		Id:        entity.NewDatabaseId(),
+		Hash:      otp.Hash(value),
+		UserId:    userId,
+		DeviceId:  deviceId,
+		Client:    client,
+		ExpiresAt: time.Now().Add(ChallengeLifetime),
+	}, value, nil
+}
+
+// NewRecoveryCodes returns the codes to show the user and the hashes to store.
+func NewRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, RecoveryCodeCount)
+	hashes := make([]string, 0, RecoveryCodeCount)
+	for index := 0; index < RecoveryCodeCount; index++ {
+		value, err := newSecret(5)
+		if err != nil {
+			return nil, nil, err
+		}
+		code := value[:5] + "-" + value[5:]
+		codes = append(codes, code)
+		hashes = append(hashes, HashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// HashRecoveryCode ignores case, spaces and dashes so codes can be typed as they are read.
+func HashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	return otp.Hash(code)
+}
+
+func (t *TwoFactor) Enable() {
+	t.Enabled = true
+	t.EnabledAt = time.Now()
+}
+
+func newSecret(size int) (string, error) {
+	value := make([]byte, size)
+	if _, err := rand.Read(value); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(value), nil
+}
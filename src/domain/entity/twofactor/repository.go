@@ -0,0 +1,140 @@
+package twofactor
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type reader interface {
+	Get(string) (*TwoFactor, error)
+}
+
+type writer interface {
+	Save(*TwoFactor) error
+	Delete(string) error
+	UseStep(string, int64) error
+	UseRecoveryCode(string, string) error
+	CreateChallenge(*Challenge) error
+	AttemptChallenge(string) (*Challenge, error)
+	DeleteChallenge(*Challenge) error
+}
+
+type Repository interface {
+	reader
+	writer
+}
+
+type repository struct {
+	Collection          *mongo.Collection
+	ChallengeCollection *mongo.Collection
+}
+
+func NewRepository(collection *mongo.Collection, challengeCollection *mongo.Collection) Repository {
+	return &repository{
+		Collection:          collection,
+		ChallengeCollection: challengeCollection,
+	}
+}
+
+func (r *repository) Get(userId string) (*TwoFactor, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	twoFactor := &TwoFactor{}
+	if err := r.Collection.FindOne(ctx, bson.M{"user_id": userId}).Decode(twoFactor); err != nil {
+		return nil, err
+	}
+	return twoFactor, nil
+}
+
+func (r *repository) Save(twoFactor *TwoFactor) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Replace().SetUpsert(true)
+	_, err := r.Collection.ReplaceOne(ctx, bson.M{"user_id": twoFactor.UserId}, twoFactor, opts)
+	return err
+}
+
+func (r *repository) Delete(userId string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.Collection.DeleteOne(ctx, bson.M{"user_id": userId})
+	return err
+}
+
+// UseStep records the step of an accepted code, it fails if a code from that step was already used.
+func (r *repository) UseStep(userId string, step int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"user_id": userId, "last_step": bson.M{"$lt": step}}
+	result, err := r.Collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"last_step": step}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrWrongCode
+	}
+	return nil
+}
+
+// UseRecoveryCode removes the code so it can only be used once.
+func (r *repository) UseRecoveryCode(userId, hash string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"user_id": userId, "enabled": true, "recovery_codes": hash}
+	result, err := r.Collection.UpdateOne(ctx, filter, bson.M{"$pull": bson.M{"recovery_codes": hash}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrWrongCode
+	}
+	return nil
+}
+
+func (r *repository) CreateChallenge(challenge *Challenge) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.ChallengeCollection.InsertOne(ctx, challenge)
+	return err
+}
+
+// AttemptChallenge counts an attempt against the challenge before the code is checked, there is no
+// match once it has expired or its attempts are used up.
+func (r *repository) AttemptChallenge(hash string) (*Challenge, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"hash":       hash,
+		"attempts":   bson.M{"$lt": MaxAttempts},
+		"expires_at": bson.M{"$gt": time.Now()},
+	}
+	update := bson.M{"$inc": bson.M{"attempts": 1}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	challenge := &Challenge{}
+	if err := r.ChallengeCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(challenge); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrInvalidChallenge
+		}
+		return nil, err
+	}
+	return challenge, nil
+}
+
+func (r *repository) DeleteChallenge(challenge *Challenge) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.ChallengeCollection.DeleteOne(ctx, bson.M{"_id": challenge.Id})
+	return err
+}
@@ -2,13 +2,18 @@ package user
 
 import (
 	"DX/src/domain/entity"
-	"DX/src/pkg/response"
-	"DX/src/utils"
-	"errors"
+	"DX/src/pkg/apperror"
 	"strings"
 	"time"
 )
 
+var ErrSuspended = apperror.New(apperror.Forbidden, "user_suspended", "user is suspended")
+var ErrWrongPassword = apperror.New(apperror.Invalid, "wrong_password", "current password is incorrect")
+var ErrInvalidPhone = apperror.New(apperror.Invalid, "invalid_phone_number", "invalid phone number")
+var ErrPhoneNotVerified = apperror.New(apperror.Invalid, "phone_not_verified", "phone number has not been verified")
+var ErrAdminClient = apperror.New(apperror.Invalid, "admin_web_only", "admin can only be created on web clients")
+var ErrInvalidCredentials = apperror.New(apperror.Invalid, "invalid_credentials", "invalid login credentials")
+
 type Client string
 
 type Type int
@@ -32,11 +37,16 @@ type User struct {
 	Email                     string              `json:"email,omitempty" bson:"email"`
 	ProfilePicture            string              `json:"profile_picture,omitempty" bson:"profile_picture,omitempty"`
 	Password                  string              `json:"-" bson:"password"`
-	Token                     string              `json:"token" bson:"token"`
-	RefreshToken              string              `json:"-" bson:"refresh_token,omitempty"`
+	Token                     string              `json:"token,omitempty" bson:"-"`
+	RefreshToken              string              `json:"refresh_token,omitempty" bson:"-"`
+	TokenExpiresAt            *time.Time          `json:"token_expires_at,omitempty" bson:"-"`
+	DeviceId                  string              `json:"-" bson:"-"` // Device the tokens are issued to, supplied at sign up and login
+	VerificationTicket        string              `json:"-" bson:"-"` // Proof the phone number was verified, supplied at sign up
+	TwoFactorChallenge        string              `json:"-" bson:"-"` // Set instead of the tokens when sign in needs a second factor
 	CategoryInterest          []string            `json:"category_interest,omitempty" bson:"category_interest"`
 	AccountNumbers            []Account           `json:"account_numbers" bson:"account_numbers"`
 	UserType                  Type                `json:"-" bson:"user_type"`
+	Roles                     []string            `json:"roles,omitempty" bson:"roles"`
 	Type                      string              `json:"-" bson:"type"`
 	PhoneNumber               string              `json:"phone_number" bson:"phone_number"`
 	Verification              int                 `json:"verification" bson:"verification,omitempty"`
@@ -45,6 +55,8 @@ type User struct {
 	HasVerifiedEmail          bool                `json:"-" bson:"has_verified_email"`
 	HasVerifiedAddress        bool                `json:"-" bson:"has_verified_address"`
 	HasTransactionPin         bool                `json:"-" bson:"has_transaction_pin"`
+	ReferralCode              string              `json:"referral_code,omitempty" bson:"referral_code,omitempty"`
+	InvitedWith               string              `json:"-" bson:"-"` // Referral code supplied at sign up
 	UserId                    string              `json:"-" bson:"user_id"`
 	Ratings                   []float64           `json:"-" bson:"ratings"`
 	Rating                    float64             `json:"rating" bson:"rating"`
@@ -104,68 +116,85 @@ func (t Type) Id() string {
 	return ""
 }
 
-func Create(data map[string]interface{}) (*User, error) {
-	var firstName, lastName, phone, password, client string
-	var catInterest []string
-	var ok bool
-	currentTime := time.Now()
+// SignUp is the request creating an account.
+type SignUp struct {
+	FirstName          string   `json:"first_name" binding:"required,notblank,max=100"`
+	LastName           string   `json:"last_name" binding:"required,notblank,max=100"`
+	PhoneNumber        string   `json:"phone_number" binding:"required,notblank"`
+	Password           string   `json:"password" binding:"required,notblank"`
+	Client             Client   `json:"client" binding:"required,oneof=web android ios"`
+	DeviceId           string   `json:"device_id" binding:"max=255"`
+	VerificationTicket string   `json:"verification_ticket" binding:"max=255"`
+	ReferralCode       string   `json:"referral_code" binding:"max=32"`
+	Interests          []string `json:"interests" binding:"max=50,dive,notblank"`
+}
 
-	nUser := &User{
-		Id:               entity.NewDatabaseId(),
-		UserId:           entity.NewDefaultId().String(),
-		UserType:         Normal,
-		Type:             Normal.Id(),
-		CreatedAt:        currentTime,
-		UpdatedAt:        currentTime,
-		CreatedBy:        entity.ByUser,
-		Ratings:          []float64{},
-		Rating:           0.0,
-		ErrandsCompleted: 0,
-		AccountNumbers:   []Account{},
-		ModifiedBy:       []entity.ModifiedBy{},
-	}
+// AdminUser is the request an admin creates an offline user with.
+type AdminUser struct {
+	FirstName   string          `json:"first_name" binding:"required,notblank,max=100"`
+	LastName    string          `json:"last_name" binding:"required,notblank,max=100"`
+	Email       string          `json:"email" binding:"omitempty,email"`
+	PhoneNumber string          `json:"phone_number" binding:"required,notblank,phone"`
+	Interests   []string        `json:"interests" binding:"max=50,dive,notblank"`
+	Account     *AccountDetails `json:"account"`
+}
 
-	if firstName, ok = data["first_name"].(string); !ok {
-		return nil, errors.New("first name is required")
-	}
-	if lastName, ok = data["last_name"].(string); !ok {
-		return nil, errors.New("last name is required")
-	}
-	if phone, ok = data["phone_number"].(string); !ok {
-		return nil, errors.New("phone number is required")
-	}
-	if password, ok = data["password"].(string); !ok {
-		return nil, errors.New("password is required")
-	}
-	if client, ok = data["client"].(string); !ok {
-		return nil, errors.New("client is required")
-	}
-	if _, ok = data["admin"].(bool); ok {
-		nUser.UserType = Admin
-		nUser.Type = Admin.Id()
-	}
-	if tInterests, ok := data["interests"].([]interface{}); ok {
-		for _, interest := range tInterests {
-			catInterest = append(catInterest, interest.(string))
-		}
-	}
+// AdminUserUpdate changes only the fields that are sent.
+type AdminUserUpdate struct {
+	FirstName   string `json:"first_name" binding:"omitempty,notblank,max=100"`
+	LastName    string `json:"last_name" binding:"omitempty,notblank,max=100"`
+	Email       string `json:"email" binding:"omitempty,email"`
+	PhoneNumber string `json:"phone_number" binding:"omitempty,phone"`
+}
 
-	if client != string(Web) && client != string(Android) && client != string(iOS) {
-		return nil, errors.New("invalid client type")
-	}
+// AccountDetails is a bank account as sent by a client.
+type AccountDetails struct {
+	Name     string `json:"name" binding:"required,notblank,max=100"`
+	Number   string `json:"number" binding:"required,numeric,max=20"`
+	Type     string `json:"type" binding:"required,notblank,max=50"`
+	BankCode string `json:"bank_code" binding:"required,notblank,max=20"`
+}
 
-	nUser.FirstName = strings.TrimSpace(firstName)
-	nUser.LastName = strings.TrimSpace(lastName)
-	nUser.PhoneNumber = strings.TrimSpace(phone)
-	nUser.Password = strings.TrimSpace(password)
-	nUser.Client = Client(strings.TrimSpace(client))
+type Login struct {
+	PhoneNumber string `json:"phone_number" binding:"required,notblank"`
+	Password    string `json:"password" binding:"required"`
+	DeviceId    string `json:"device_id" binding:"max=255"`
+}
 
-	return nUser, nil
+func Create(data SignUp) *User {
+	currentTime := time.Now()
+	interests := make([]string, 0, len(data.Interests))
+	for _, interest := range data.Interests {
+		interests = append(interests, strings.TrimSpace(interest))
+	}
+
+	return &User{
+		Id:                 entity.NewDatabaseId(),
+		UserId:             entity.NewDefaultId().String(),
+		UserType:           Normal,
+		Type:               Normal.Id(),
+		FirstName:          strings.TrimSpace(data.FirstName),
+		LastName:           strings.TrimSpace(data.LastName),
+		PhoneNumber:        strings.TrimSpace(data.PhoneNumber),
+		Password:           strings.TrimSpace(data.Password),
+		Client:             data.Client,
+		DeviceId:           strings.TrimSpace(data.DeviceId),
+		VerificationTicket: strings.TrimSpace(data.VerificationTicket),
+		InvitedWith:        strings.ToUpper(strings.TrimSpace(data.ReferralCode)),
+		CategoryInterest:   interests,
+		CreatedAt:          currentTime,
+		UpdatedAt:          currentTime,
+		CreatedBy:          entity.ByUser,
+		Ratings:            []float64{},
+		Rating:             0.0,
+		ErrandsCompleted:   0,
+		AccountNumbers:     []Account{},
+		ModifiedBy:         []entity.ModifiedBy{},
+		ReferralCode:       NewReferralCode(),
+	}
 }
 
-func CreateForAdmin(data map[string]interface{}) (*User, error) {
-	var firstName, lastName, phone, email string
-	var ok bool
+func CreateForAdmin(data AdminUser) *User {
 	currentTime := time.Now()
 
 	nUser := &User{
@@ -173,6 +202,10 @@ func CreateForAdmin(data map[string]interface{}) (*User, error) {
 		UserId:           entity.NewDefaultId().String(),
 		UserType:         Normal,
 		Type:             Normal.Id(),
+		FirstName:        strings.TrimSpace(data.FirstName),
+		LastName:         strings.TrimSpace(data.LastName),
+		Email:            strings.TrimSpace(data.Email),
+		PhoneNumber:      strings.TrimSpace(data.PhoneNumber),
 		CreatedBy:        entity.ByAdmin,
 		CreatedAt:        currentTime,
 		UpdatedAt:        currentTime,
@@ -181,136 +214,109 @@ func CreateForAdmin(data map[string]interface{}) (*User, error) {
 		CategoryInterest: []string{},
 		ModifiedBy:       []entity.ModifiedBy{},
 		Ratings:          []float64{},
+		ReferralCode:     NewReferralCode(),
+		HasVerifiedPhone: true,
+		Client:           Web,
 	}
-
-	if firstName, ok = data["first_name"].(string); !ok {
-		return nil, errors.New("first name is required")
+	for _, interest := range data.Interests {
+		nUser.CategoryInterest = append(nUser.CategoryInterest, strings.TrimSpace(interest))
 	}
-	if lastName, ok = data["last_name"].(string); !ok {
-		return nil, errors.New("last name is required")
+	if data.Account != nil {
+		nUser.AccountNumbers = append(nUser.AccountNumbers, NewAccount(*data.Account))
 	}
-	if email, ok = data["email"].(string); ok {
-		nUser.Email = strings.TrimSpace(email)
-	}
-	if phone, ok = data["phone_number"].(string); !ok {
-		return nil, errors.New("phone number is required")
-	} else {
-		if !utils.IsValidPhoneNumber(phone) {
-			return nil, errors.New("phone number is not valid")
-		}
-	}
-	if tInterests, ok := data["interests"].([]interface{}); ok {
-		for _, interest := range tInterests {
-			nUser.CategoryInterest = append(nUser.CategoryInterest, interest.(string))
-		}
-	}
-	if tAccount, ok := data["account"].(map[string]interface{}); ok {
-		account, err := NewAccountDetails(tAccount)
-		if err != nil {
-			return nil, err
-		}
-		nUser.AccountNumbers = append(nUser.AccountNumbers, account)
-	}
-
-	nUser.FirstName = strings.TrimSpace(firstName)
-	nUser.LastName = strings.TrimSpace(lastName)
-	nUser.PhoneNumber = strings.TrimSpace(phone)
-	nUser.HasVerifiedPhone = true
 	nUser.UpdateVerification()
-	nUser.Client = Web
 
-	return nUser, nil
+	return nUser
 }
 
-func NewAccountDetails(data map[string]interface{}) (Account, error) {
-	account := Account{
+func NewAccount(details AccountDetails) Account {
+	return Account{
+		Name:        strings.TrimSpace(details.Name),
+		Number:      strings.TrimSpace(details.Number),
+		Type:        strings.TrimSpace(details.Type),
+		BankCode:    strings.TrimSpace(details.BankCode),
 		CountryCode: "NG",
 	}
-	if name, ok := data["name"].(string); !ok {
-		return Account{}, errors.New("account name is required")
-	} else {
-		account.Name = name
-	}
-	if number, ok := data["number"].(string); !ok {
-		return Account{}, errors.New("account number is required")
-	} else {
-		account.Number = number
-	}
-	if aType, ok := data["type"].(string); !ok {
-		return Account{}, errors.New("account type is required")
-	} else {
-		account.Type = aType
-	}
-	if bankCode, ok := data["bank_code"].(string); !ok {
-		return Account{}, errors.New("bank code is required")
-	} else {
-		account.BankCode = bankCode
-	}
-
-	return account, nil
 }
 
-func UpdateForAdmin(data map[string]interface{}) (*User, error) {
-	var firstName, lastName, phone, email string
-	var ok bool
-
-	nUser := &User{}
-
-	if firstName, ok = data["first_name"].(string); ok {
-		nUser.FirstName = strings.TrimSpace(firstName)
+func UpdateForAdmin(data AdminUserUpdate) *User {
+	return &User{
+		FirstName:   strings.TrimSpace(data.FirstName),
+		LastName:    strings.TrimSpace(data.LastName),
+		Email:       strings.TrimSpace(data.Email),
+		PhoneNumber: strings.TrimSpace(data.PhoneNumber),
 	}
-	if lastName, ok = data["last_name"].(string); ok {
-		nUser.LastName = strings.TrimSpace(lastName)
-	}
-	if email, ok = data["email"].(string); ok {
-		nUser.Email = strings.TrimSpace(email)
-	}
-	if phone, ok = data["phone_number"].(string); ok {
-		if !utils.IsValidPhoneNumber(phone) {
-			return nil, errors.New("phone number is not valid")
-		} else {
-			nUser.PhoneNumber = strings.TrimSpace(phone)
-		}
-	}
-
-	return nUser, nil
 }
 
-func CreateForLogin(data map[string]interface{}) (*User, error) {
-	var phone, password string
-	var ok bool
-
-	if phone, ok = data["phone_number"].(string); !ok {
-		return nil, errors.New("phone number is required")
-	}
-	if password, ok = data["password"].(string); !ok {
-		return nil, errors.New("password is required")
+func CreateForLogin(data Login) *User {
+	return &User{
+		PhoneNumber: data.PhoneNumber,
+		Password:    data.Password,
+		DeviceId:    strings.TrimSpace(data.DeviceId),
 	}
+}
 
-	nUser := &User{
-		PhoneNumber: phone,
-		Password:    password,
-	}
-	return nUser, nil
+func (u *User) SetToken(accessToken, refreshToken string, expiresAt time.Time) {
+	u.Token = accessToken
+	u.RefreshToken = refreshToken
+	u.TokenExpiresAt = &expiresAt
 }
 
-func (u *User) IsValidForInitialCreation() *response.BaseResponse {
+func (u *User) IsValidForInitialCreation() error {
 	if u.IsAdmin() && u.Client != Web {
-		return response.NewBadRequestError("admin can only be created on web clients")
+		return ErrAdminClient
 	}
 	return nil
 }
 
+func NewReferralCode() string {
+	return strings.ToUpper(strings.ReplaceAll(entity.NewDefaultId().String(), "-", "")[:8])
+}
+
 func (u *User) IsOffline() bool {
 	return u.CreatedBy == entity.ByAdmin && !u.IsSuspended && !u.IsDeleted
 }
 
+// RoleNames returns the roles of the user, accounts created before roles were assigned get the role
+// named after their user type.
+func (u *User) RoleNames() []string {
+	if u.Roles != nil {
+		return u.Roles
+	}
+	if u.UserType != Normal {
+		return []string{u.UserType.Id()}
+	}
+	return nil
+}
+
 func (u *User) IsSuperAdmin() bool {
-	return u.UserType == SuperAdmin
+	for _, name := range u.RoleNames() {
+		if name == SuperAdmin.Id() {
+			return true
+		}
+	}
+	return false
 }
 
+// IsAdmin reports whether the user holds any role on the admin side.
 func (u *User) IsAdmin() bool {
-	return u.UserType == Admin
+	return len(u.RoleNames()) > 0
+}
+
+// TypeFor is the user type kept in step with the roles, for anything still reading it.
+func TypeFor(roles []string) Type {
+	userType := Normal
+	for _, name := range roles {
+		if name == SuperAdmin.Id() {
+			return SuperAdmin
+		}
+		if name == ClientManager.Id() && userType == Normal {
+			userType = ClientManager
+		} else {
+			userType = Admin
+		}
+	}
+	return userType
 }
 
 func (u *User) UpdateVerification() {
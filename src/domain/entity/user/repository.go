@@ -4,7 +4,6 @@ import (
 	"DX/src/domain/entity"
 	"DX/src/pkg/error_service"
 	"context"
-	"errors"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"time"
@@ -15,6 +14,7 @@ type reader interface {
 	Get(*User) error
 	GetWithId(string) (*User, error)
 	GetWithPhone(string) (*User, error)
+	GetWithReferralCode(string) (*User, error)
 	GetAllUsers() ([]User, error)
 	GetAllDeletedUsers() ([]User, error)
 	GetAllSuspendedUsers() ([]User, error)
@@ -25,6 +25,11 @@ type writer interface {
 	Update(*User) error
 	CompleteErrand(string) error
 	RateUser(string, int64) error
+	SetTransactionPin(string, bool) error
+	AddAccount(string, Account) error
+	SetReferralCode(string, string) error
+	SetRoles(string, string, []string) error
+	RemoveRole(string) error
 	Suspend(string, string) error
 	SuspendMany(string, []string) error
 	Restore(string, string) error
@@ -77,7 +82,7 @@ func (r *repository) Get(user *User) error {
 		return err
 	}
 	if user.IsSuspended || user.IsDeleted {
-		return errors.New("user is suspended")
+		return ErrSuspended
 	}
 	return nil
 }
@@ -171,7 +176,7 @@ func (r *repository) GetWithId(userId string) (user *User, err error) {
 		return nil, err
 	}
 	if user.IsSuspended || user.IsDeleted {
-		return nil, errors.New("user is suspended")
+		return nil, ErrSuspended
 	}
 	return user, nil
 }
@@ -189,6 +194,41 @@ func (r *repository) GetWithPhone(phone string) (user *User, err error) {
 	return user, nil
 }
 
+func (r *repository) GetWithReferralCode(code string) (user *User, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"referral_code": code,
+		"is_deleted":    false,
+	}
+	if err := r.Collection.FindOne(ctx, filter).Decode(&user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *repository) SetReferralCode(uId string, code string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	userId, _ := entity.StringToErrandId(uId)
+	filter := bson.M{
+		"_id": userId,
+	}
+	param := bson.D{
+		{"$set", bson.D{
+			{"referral_code", code},
+			{"updated_at", time.Now()},
+		}},
+	}
+
+	if _, err := r.Collection.UpdateOne(ctx, filter, param); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (r *repository) Create(user *User) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -246,6 +286,54 @@ func (r *repository) RateUser(uId string, rating int64) error {
 	return nil
 }
 
+func (r *repository) SetTransactionPin(uId string, hasPin bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	userId, _ := entity.StringToErrandId(uId)
+
+	filter := bson.D{
+		{"_id", userId},
+	}
+	param := bson.D{
+		{"$set", bson.D{
+			{"has_transaction_pin", hasPin},
+			{"updated_at", time.Now()},
+		}},
+	}
+
+	if _, err := r.Collection.UpdateOne(ctx, filter, param); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *repository) AddAccount(uId string, account Account) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	userId, _ := entity.StringToErrandId(uId)
+
+	filter := bson.D{
+		{"_id", userId},
+	}
+	param := bson.D{
+		{"$set", bson.D{
+			{"updated_at", time.Now()},
+		}},
+		{"$push", bson.D{
+			{"account_numbers", account},
+		}},
+	}
+
+	if _, err := r.Collection.UpdateOne(ctx, filter, param); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (r *repository) Update(user *User) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -255,7 +343,6 @@ func (r *repository) Update(user *User) error {
 			{"first_name", user.FirstName},
 			{"last_name", user.LastName},
 			{"phone_number", user.PhoneNumber},
-			{"is_admin", user.IsAdmin},
 			{"updated_at", "$$NOW"},
 			{"is_suspended", user.IsSuspended},
 			{"is_deleted", user.IsDeleted},
@@ -274,7 +361,6 @@ func (r *repository) Update(user *User) error {
 			{"rating", user.Rating},
 			{"errands_completed", user.ErrandsCompleted},
 			{"errands_cancelled", user.ErrandsCancelled},
-			{"type", user.UserType},
 		}},
 	}
 	filter := bson.M{"_id": user.Id}
@@ -284,6 +370,48 @@ func (r *repository) Update(user *User) error {
 	return nil
 }
 
+func (r *repository) SetRoles(userId string, adminId string, roles []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	id, _ := entity.StringToErrandId(userId)
+	cTime := time.Now()
+	userType := TypeFor(roles)
+
+	param := bson.D{
+		{"$set", bson.D{
+			{"roles", roles},
+			{"user_type", userType},
+			{"type", userType.Id()},
+			{"updated_at", cTime},
+		}},
+		{"$push", bson.D{
+			{"modified_by", entity.ModifiedBy{
+				Id:   adminId,
+				Date: cTime,
+			}},
+		}},
+	}
+
+	result, err := r.Collection.UpdateOne(ctx, bson.M{"_id": id}, param)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// RemoveRole takes a deleted role away from everyone holding it.
+func (r *repository) RemoveRole(name string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.Collection.UpdateMany(ctx, bson.M{"roles": name}, bson.M{"$pull": bson.M{"roles": name}})
+	return err
+}
+
 func (r *repository) Suspend(userId string, adminId string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
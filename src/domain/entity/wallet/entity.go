@@ -2,26 +2,112 @@ package wallet
 
 import (
 	"DX/src/domain/entity"
+	"DX/src/pkg/apperror"
 	"time"
 )
 
+// Wallet holds the latest transactions only, when TotalTransactions is larger than the list the rest are
+// paged through with the transactions endpoint.
 type Wallet struct {
-	Balance      int64         `json:"balance"`
-	Escrow       int64         `json:"escrow"`
-	Transactions []Transaction `json:"transactions"`
+	Balance           int64         `json:"balance"`
+	PromoBalance      int64         `json:"promo_balance"`
+	Escrow            int64         `json:"escrow"`
+	Transactions      []Transaction `json:"transactions"`
+	TotalTransactions int64         `json:"total_transactions"`
 }
 
+// PlatformAccountId is the wallet that collects service fees and commissions.
+const PlatformAccountId = "platform"
+
+var ErrInsufficientFunds = apperror.New(apperror.Invalid, "insufficient_funds", "insufficient funds. kindly top up your wallet")
+var ErrRefundExceedsDebit = apperror.New(apperror.Invalid, "refund_exceeds_balance", "refund amount exceeds the refundable balance of the transaction")
+var ErrInvalidPeriod = apperror.New(apperror.Invalid, "invalid_period", "invalid period")
+var ErrInvalidWithdrawal = apperror.New(apperror.Invalid, "invalid_withdrawal_amount", "invalid withdrawal amount")
+var ErrInvalidDateRange = apperror.New(apperror.Invalid, "invalid_date_range", "invalid date range")
+var ErrInvalidStatementPeriod = apperror.New(apperror.Invalid, "invalid_statement_period", "invalid statement period")
+var ErrStatementTooLong = apperror.New(apperror.Invalid, "statement_period_too_long", "statement period cannot exceed one year")
+var ErrEarningsTooLong = apperror.New(apperror.Invalid, "earnings_period_too_long", "earnings period cannot exceed one year")
+
 type Transaction struct {
-	Id              entity.DatabaseId `json:"id" bson:"_id"`
-	UserId          string            `json:"user_id" bson:"user_id"`
-	TransactionType Type              `json:"transaction_type" bson:"transaction_type"`
-	Type            string            `json:"type" bson:"type"`
-	ItemId          string            `json:"-" json:"item_id"`
-	Amount          int64             `json:"amount" bson:"amount"`
-	Description     string            `json:"description" bson:"description"`
-	CreatedAt       time.Time         `json:"created_at" bson:"created_at"`
+	Id                  entity.DatabaseId `json:"id" bson:"_id"`
+	UserId              string            `json:"user_id" bson:"user_id"`
+	TransactionType     Type              `json:"transaction_type" bson:"transaction_type"`
+	Type                string            `json:"type" bson:"type"`
+	TransactionCategory Category          `json:"-" bson:"transaction_category"`
+	Category            string            `json:"category" bson:"category"`
+	ItemId              string            `json:"item_id,omitempty" bson:"itemid"` // Existing transactions store it under itemid
+	Reference           string            `json:"reference" bson:"reference"`
+	RefundOf            string            `json:"refund_of,omitempty" bson:"refund_of,omitempty"`     // Id of the debit being refunded
+	Key                 string            `json:"-" bson:"key,omitempty"`                             // Unique, a transfer repeating a key fails as a duplicate
+	Promotional         bool              `json:"promotional,omitempty" bson:"promotional,omitempty"` // Promotional credit can be spent on errands but not withdrawn
+	Amount              int64             `json:"amount" bson:"amount"`
+	Gross               int64             `json:"gross,omitempty" bson:"gross,omitempty"`
+	Fee                 int64             `json:"fee,omitempty" bson:"fee,omitempty"`
+	Description         string            `json:"description" bson:"description"`
+	CreatedAt           time.Time         `json:"created_at" bson:"created_at"`
 }
 
+type Filter struct {
+	UserId   string
+	Type     string
+	Category string
+	ItemId   string
+	From     time.Time
+	To       time.Time
+	Page     int64
+	Limit    int64
+}
+
+type Page struct {
+	Transactions []Transaction `json:"transactions"`
+	Total        int64         `json:"total"`
+	Page         int64         `json:"page"`
+	Limit        int64         `json:"limit"`
+}
+
+type Earnings struct {
+	Gross        int64 `json:"gross" bson:"gross"`
+	Commission   int64 `json:"commission" bson:"commission"`
+	Payouts      int64 `json:"payouts" bson:"payouts"`
+	Tips         int64 `json:"tips" bson:"tips"`
+	Compensation int64 `json:"compensation" bson:"compensation"`
+	Total        int64 `json:"total" bson:"total"`
+	Errands      int64 `json:"errands" bson:"errands"`
+}
+
+// EarningsPeriod is the earnings for a single day, ISO week or month.
+type EarningsPeriod struct {
+	Period   string `json:"period" bson:"_id"`
+	Earnings `bson:",inline"`
+}
+
+type Statement struct {
+	UserId         string        `json:"user_id"`
+	From           time.Time     `json:"from"`
+	To             time.Time     `json:"to"`
+	OpeningBalance int64         `json:"opening_balance"`
+	ClosingBalance int64         `json:"closing_balance"`
+	TotalCredits   int64         `json:"total_credits"`
+	TotalDebits    int64         `json:"total_debits"`
+	PromoCredits   int64         `json:"promo_credits"` // Promotional credit is listed but kept out of the balance and its totals
+	PromoDebits    int64         `json:"promo_debits"`
+	Transactions   []Transaction `json:"transactions"`
+	GeneratedAt    time.Time     `json:"generated_at"`
+}
+
+const (
+	DefaultPageLimit = int64(20)
+	MaxPageLimit     = int64(100)
+)
+
+type Period int
+
+const (
+	Day Period = iota
+	Week
+	Month
+)
+
 type Type int
 
 const (
@@ -29,6 +115,151 @@ const (
 	Credit
 )
 
+type Category int
+
+const (
+	TopUp Category = iota
+	Withdrawal
+	ErrandPayment
+	ServiceFee
+	ErrandPayout
+	Commission
+	Refund
+	RunnerCompensation
+	Tip
+	PromoDiscount
+	PromoCredit
+	ReferralReward
+)
+
+func (c Category) String() string {
+	if c == TopUp {
+		return "Top Up"
+	}
+	if c == Withdrawal {
+		return "Withdrawal"
+	}
+	if c == ErrandPayment {
+		return "Errand Payment"
+	}
+	if c == ServiceFee {
+		return "Service Fee"
+	}
+	if c == ErrandPayout {
+		return "Errand Payout"
+	}
+	if c == Commission {
+		return "Commission"
+	}
+	if c == Refund {
+		return "Refund"
+	}
+	if c == RunnerCompensation {
+		return "Runner Compensation"
+	}
+	if c == Tip {
+		return "Tip"
+	}
+	if c == PromoDiscount {
+		return "Promo Discount"
+	}
+	if c == PromoCredit {
+		return "Promo Credit"
+	}
+	if c == ReferralReward {
+		return "Referral Reward"
+	}
+	return ""
+}
+
+func (c Category) Id() string {
+	if c == TopUp {
+		return "top-up"
+	}
+	if c == Withdrawal {
+		return "withdrawal"
+	}
+	if c == ErrandPayment {
+		return "errand-payment"
+	}
+	if c == ServiceFee {
+		return "service-fee"
+	}
+	if c == ErrandPayout {
+		return "errand-payout"
+	}
+	if c == Commission {
+		return "commission"
+	}
+	if c == Refund {
+		return "refund"
+	}
+	if c == RunnerCompensation {
+		return "runner-compensation"
+	}
+	if c == Tip {
+		return "tip"
+	}
+	if c == PromoDiscount {
+		return "promo-discount"
+	}
+	if c == PromoCredit {
+		return "promo-credit"
+	}
+	if c == ReferralReward {
+		return "referral-reward"
+	}
+	return ""
+}
+
+func (p Period) Id() string {
+	if p == Day {
+		return "day"
+	}
+	if p == Week {
+		return "week"
+	}
+	if p == Month {
+		return "month"
+	}
+	return ""
+}
+
+// Format is the $dateToString format used to bucket transactions into the period.
+func (p Period) Format() string {
+	if p == Week {
+		return "%G-W%V"
+	}
+	if p == Month {
+		return "%Y-%m"
+	}
+	return "%Y-%m-%d"
+}
+
+// Span is the default window covered by the period when no range is given.
+func (p Period) Span(to time.Time) time.Time {
+	if p == Week {
+		return to.AddDate(0, 0, -7*12)
+	}
+	if p == Month {
+		return to.AddDate(-1, 0, 0)
+	}
+	return to.AddDate(0, 0, -30)
+}
+
+func GetPeriod(value string) (Period, error) {
+	if value == "" || value == Day.Id() {
+		return Day, nil
+	}
+	if value == Week.Id() {
+		return Week, nil
+	}
+	if value == Month.Id() {
+		return Month, nil
+	}
+	return Day, ErrInvalidPeriod
+}
+
 func (t Type) String() string {
 	if t == Debit {
 		return "debit"
@@ -39,28 +270,143 @@ func (t Type) String() string {
 	return ""
 }
 
-func NewDebitTransaction(userId, description, itemId string, amount int64) Transaction {
+func NewFilter(userId string, page, limit int64) Filter {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = DefaultPageLimit
+	}
+	if limit > MaxPageLimit {
+		limit = MaxPageLimit
+	}
+	return Filter{
+		UserId: userId,
+		Page:   page,
+		Limit:  limit,
+	}
+}
+
+func (f Filter) Skip() int64 {
+	return (f.Page - 1) * f.Limit
+}
+
+// NewStatement totals the transactions in the period and derives the closing balance from the opening balance.
+func NewStatement(userId string, from, to time.Time, openingBalance int64, transactions []Transaction) *Statement {
+	statement := &Statement{
+		UserId:         userId,
+		From:           from,
+		To:             to,
+		OpeningBalance: openingBalance,
+		Transactions:   transactions,
+		GeneratedAt:    time.Now(),
+	}
+	if statement.Transactions == nil {
+		statement.Transactions = []Transaction{}
+	}
+	for _, txn := range transactions {
+		switch {
+		case txn.Promotional && txn.TransactionType == Credit:
+			statement.PromoCredits += txn.Amount
+		case txn.Promotional:
+			statement.PromoDebits += txn.Amount
+		case txn.TransactionType == Credit:
+			statement.TotalCredits += txn.Amount
+		default:
+			statement.TotalDebits += txn.Amount
+		}
+	}
+	statement.ClosingBalance = openingBalance + statement.TotalCredits - statement.TotalDebits
+	return statement
+}
+
+// Net is how much the transactions change the withdrawable and the promotional balance of the user.
+func Net(userId string, transactions []Transaction) (cash int64, promo int64) {
+	for _, txn := range transactions {
+		if txn.UserId != userId {
+			continue
+		}
+		amount := txn.Amount
+		if txn.TransactionType == Debit {
+			amount = -amount
+		}
+		if txn.Promotional {
+			promo += amount
+		} else {
+			cash += amount
+		}
+	}
+	return cash, promo
+}
+
+func NewDebitTransaction(userId, description, itemId string, amount int64, category Category) Transaction {
+	id := entity.NewDatabaseId()
 	return Transaction{
-		Id:              entity.NewDatabaseId(),
-		UserId:          userId,
-		Type:            Debit.String(),
-		TransactionType: Debit,
-		CreatedAt:       time.Now(),
-		Description:     description,
-		Amount:          amount,
-		ItemId:          itemId,
+		Id:                  id,
+		Reference:           id.Hex(),
+		UserId:              userId,
+		Type:                Debit.String(),
+		TransactionType:     Debit,
+		TransactionCategory: category,
+		Category:            category.Id(),
+		CreatedAt:           time.Now(),
+		Description:         description,
+		Amount:              amount,
+		ItemId:              itemId,
 	}
 }
 
-func NewCreditTransaction(userId, description, itemId string, amount int64) Transaction {
+func NewCreditTransaction(userId, description, itemId string, amount int64, category Category) Transaction {
+	id := entity.NewDatabaseId()
 	return Transaction{
-		Id:              entity.NewDatabaseId(),
-		UserId:          userId,
-		Type:            Credit.String(),
-		TransactionType: Credit,
-		CreatedAt:       time.Now(),
-		Description:     description,
-		Amount:          amount,
-		ItemId:          itemId,
+		Id:                  id,
+		Reference:           id.Hex(),
+		UserId:              userId,
+		Type:                Credit.String(),
+		TransactionType:     Credit,
+		TransactionCategory: category,
+		Category:            category.Id(),
+		CreatedAt:           time.Now(),
+		Description:         description,
+		Amount:              amount,
+		ItemId:              itemId,
 	}
 }
+
+// NewRefundTransaction credits a refund and links it to the debit it returns, refundOf is empty when that
+// debit is unknown.
+func NewRefundTransaction(userId, description, itemId, refundOf string, amount int64) Transaction {
+	txn := NewCreditTransaction(userId, description, itemId, amount, Refund)
+	txn.RefundOf = refundOf
+	return txn
+}
+
+func NewPromoCreditTransaction(userId, description, itemId string, amount int64, category Category) Transaction {
+	txn := NewCreditTransaction(userId, description, itemId, amount, category)
+	txn.Promotional = true
+	return txn
+}
+
+func NewPromoDebitTransaction(userId, description, itemId string, amount int64, category Category) Transaction {
+	txn := NewDebitTransaction(userId, description, itemId, amount, category)
+	txn.Promotional = true
+	return txn
+}
+
+// NewPayoutTransaction credits the net amount and records the gross amount and the fee deducted from it.
+// An errand is paid out once, paying it again fails as a duplicate.
+func NewPayoutTransaction(userId, description, itemId string, gross, fee int64) Transaction {
+	txn := NewCreditTransaction(userId, description, itemId, gross-fee, ErrandPayout)
+	txn.Gross = gross
+	txn.Fee = fee
+	txn.Key = ErrandPayout.Id() + ":" + itemId
+	return txn
+}
+
+// NewErrandPaymentTransaction debits the budget of an errand. An errand is funded once, funding it again
+// fails as a duplicate.
+func NewErrandPaymentTransaction(userId, description, itemId string, amount int64) Transaction {
+	txn := NewDebitTransaction(userId, description, itemId, amount, ErrandPayment)
+	txn.Key = ErrandPayment.Id() + ":" + itemId
+	return txn
+}
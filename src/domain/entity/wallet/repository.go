@@ -1,19 +1,31 @@
 package wallet
 
 import (
+	"DX/src/domain/entity"
 	"context"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"time"
 )
 
 type reader interface {
 	CreateTransaction(Transaction) error
+	Transfer(...Transaction) error
 }
 
 type writer interface {
 	GetTransactionsFor(string) ([]Transaction, error)
 	GetBalance(string) (int64, error)
+	GetPromoBalance(string) (int64, error)
+	GetBalanceBefore(string, time.Time) (int64, error)
+	GetTransactions(Filter) ([]Transaction, int64, error)
+	GetStatementTransactions(string, time.Time, time.Time) ([]Transaction, error)
+	GetGatewayTransactions(time.Time, time.Time) ([]Transaction, error)
+	GetTransaction(string) (*Transaction, error)
+	GetItemTransaction(string, string, Category) (*Transaction, error)
+	GetEarnings(string) (*Earnings, error)
+	GetEarningsSeries(string, Period, time.Time, time.Time) ([]EarningsPeriod, error)
 }
 
 type Repository interface {
@@ -22,12 +34,14 @@ type Repository interface {
 }
 
 type repository struct {
-	Collection *mongo.Collection
+	Collection     *mongo.Collection
+	LockCollection *mongo.Collection
 }
 
-func NewWalletRepository(Collection *mongo.Collection) Repository {
+func NewWalletRepository(Collection *mongo.Collection, LockCollection *mongo.Collection) Repository {
 	return &repository{
-		Collection: Collection,
+		Collection:     Collection,
+		LockCollection: LockCollection,
 	}
 }
 
@@ -42,6 +56,105 @@ func (r *repository) CreateTransaction(txn Transaction) error {
 	return nil
 }
 
+// Transfer writes the transactions together, or none of them. It fails with ErrInsufficientFunds when they
+// would take the withdrawable or promotional balance of a debited user below zero, the platform account
+// can go below zero, and with ErrRefundExceedsDebit when refunds would return more than the debit they
+// refund. Transfers for the same wallet or refunded debit update its lock document, so concurrent
+// transfers conflict and the retried one sees the other's transactions.
+func (r *repository) Transfer(txns ...Transaction) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	session, err := r.Collection.Database().Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (interface{}, error) {
+		for _, userId := range debitedUsers(txns) {
+			lock := bson.M{"$inc": bson.M{"version": 1}, "$set": bson.M{"updated_at": time.Now()}}
+			if _, err := r.LockCollection.UpdateOne(sessionContext, bson.M{"_id": userId}, lock, options.Update().SetUpsert(true)); err != nil {
+				return nil, err
+			}
+
+			balance, err := r.balance(sessionContext, cashMatch(userId))
+			if err != nil {
+				return nil, err
+			}
+			promoBalance, err := r.balance(sessionContext, promoMatch(userId))
+			if err != nil {
+				return nil, err
+			}
+			cash, promo := Net(userId, txns)
+			if balance+cash < 0 || promoBalance+promo < 0 {
+				return nil, ErrInsufficientFunds
+			}
+		}
+		for refundOf, amount := range refunds(txns) {
+			if err := r.checkRefund(sessionContext, refundOf, amount); err != nil {
+				return nil, err
+			}
+		}
+
+		documents := make([]interface{}, 0, len(txns))
+		for _, txn := range txns {
+			documents = append(documents, txn)
+		}
+		_, err := r.Collection.InsertMany(sessionContext, documents)
+		return nil, err
+	})
+	return err
+}
+
+func debitedUsers(txns []Transaction) []string {
+	var userIds []string
+	seen := map[string]bool{}
+	for _, txn := range txns {
+		if txn.TransactionType == Debit && txn.UserId != PlatformAccountId && !seen[txn.UserId] {
+			seen[txn.UserId] = true
+			userIds = append(userIds, txn.UserId)
+		}
+	}
+	return userIds
+}
+
+// refunds totals the transactions by the debit they refund.
+func refunds(txns []Transaction) map[string]int64 {
+	amounts := map[string]int64{}
+	for _, txn := range txns {
+		if txn.RefundOf != "" {
+			amounts[txn.RefundOf] += txn.Amount
+		}
+	}
+	return amounts
+}
+
+// checkRefund locks the refunded debit and fails when amount and what was already refunded exceed it.
+func (r *repository) checkRefund(ctx mongo.SessionContext, refundOf string, amount int64) error {
+	lock := bson.M{"$inc": bson.M{"version": 1}, "$set": bson.M{"updated_at": time.Now()}}
+	if _, err := r.LockCollection.UpdateOne(ctx, bson.M{"_id": "refund:" + refundOf}, lock, options.Update().SetUpsert(true)); err != nil {
+		return err
+	}
+
+	id, err := entity.StringToErrandId(refundOf)
+	if err != nil {
+		return err
+	}
+	var debit Transaction
+	if err = r.Collection.FindOne(ctx, bson.M{"_id": id}).Decode(&debit); err != nil {
+		return err
+	}
+	refunded, err := r.refunded(ctx, refundOf)
+	if err != nil {
+		return err
+	}
+	if refunded+amount > debit.Amount {
+		return ErrRefundExceedsDebit
+	}
+	return nil
+}
+
 func (r *repository) GetTransactionsFor(userId string) (transactions []Transaction, err error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -62,19 +175,271 @@ func (r *repository) GetTransactionsFor(userId string) (transactions []Transacti
 	return transactions, nil
 }
 
-func (r *repository) GetBalance(userId string) (int64, error) {
-	var balances []struct {
-		Balance int64 `bson:"balance"`
+func (r *repository) GetTransactions(f Filter) (transactions []Transaction, total int64, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"user_id": f.UserId,
+	}
+	if f.Type != "" {
+		filter["type"] = f.Type
+	}
+	if f.Category != "" {
+		filter["category"] = f.Category
+	}
+	if f.ItemId != "" {
+		filter["itemid"] = f.ItemId
+	}
+	if dateRange := createdAtRange(f.From, f.To); len(dateRange) > 0 {
+		filter["created_at"] = dateRange
+	}
+
+	total, err = r.Collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
 	}
 
+	opts := options.Find().SetSort(bson.M{"created_at": -1}).SetSkip(f.Skip()).SetLimit(f.Limit)
+	crs, err := r.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err = crs.All(ctx, &transactions); err != nil {
+		return nil, 0, err
+	}
+
+	return transactions, total, nil
+}
+
+func (r *repository) GetStatementTransactions(userId string, from, to time.Time) (transactions []Transaction, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"user_id":    userId,
+		"created_at": createdAtRange(from, to),
+	}
+	opts := options.Find().SetSort(bson.M{"created_at": 1})
+
+	crs, err := r.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err = crs.All(ctx, &transactions); err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+// GetGatewayTransactions returns the top ups, the only transactions that go through the payment gateway.
+// Withdrawals are debited from the wallet alone so the gateway has nothing to match them against.
+func (r *repository) GetGatewayTransactions(from, to time.Time) (transactions []Transaction, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"transaction_category": TopUp,
+		"created_at":           createdAtRange(from, to),
+	}
+
+	crs, err := r.Collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if err = crs.All(ctx, &transactions); err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+func (r *repository) GetTransaction(transactionId string) (txn *Transaction, err error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	id, err := entity.StringToErrandId(transactionId)
+	if err != nil {
+		return nil, err
+	}
+	if err = r.Collection.FindOne(ctx, bson.M{"_id": id}).Decode(&txn); err != nil {
+		return nil, err
+	}
+
+	return txn, nil
+}
+
+func (r *repository) GetItemTransaction(userId, itemId string, category Category) (txn *Transaction, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"user_id":              userId,
+		"itemid":               itemId,
+		"transaction_category": category,
+	}
+	if err = r.Collection.FindOne(ctx, filter).Decode(&txn); err != nil {
+		return nil, err
+	}
+
+	return txn, nil
+}
+
+func (r *repository) refunded(ctx context.Context, transactionId string) (int64, error) {
+	var refunds []struct {
+		Amount int64 `bson:"amount"`
+	}
+
 	pipeline := mongo.Pipeline{
 		{{"$match", bson.D{
-			{"user_id", userId},
-		},
-		}},
+			{"refund_of", transactionId},
+		}}},
+		{{"$group", bson.D{
+			{"_id", nil},
+			{"amount", bson.D{{"$sum", "$amount"}}},
+		}}},
+	}
+	crs, err := r.Collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, err
+	}
+	if err = crs.All(ctx, &refunds); err != nil {
+		return 0, err
+	}
+	if len(refunds) == 0 {
+		return 0, nil
+	}
+
+	return refunds[0].Amount, nil
+}
+
+func (r *repository) GetEarnings(userId string) (*Earnings, error) {
+	var earnings []Earnings
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		{{"$match", earningsMatch(userId)}},
+		{{"$group", earningsGroup(nil)}},
+	}
+	crs, err := r.Collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	if err = crs.All(ctx, &earnings); err != nil {
+		return nil, err
+	}
+	if len(earnings) == 0 {
+		return &Earnings{}, nil
+	}
+
+	return &earnings[0], nil
+}
+
+func (r *repository) GetEarningsSeries(userId string, period Period, from, to time.Time) ([]EarningsPeriod, error) {
+	var series []EarningsPeriod
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	match := append(earningsMatch(userId), bson.E{Key: "created_at", Value: createdAtRange(from, to)})
+	pipeline := mongo.Pipeline{
+		{{"$match", match}},
+		{{"$group", earningsGroup(bson.D{{"$dateToString", bson.D{
+			{"format", period.Format()},
+			{"date", "$created_at"},
+		}}})}},
+		{{"$sort", bson.D{{"_id", 1}}}},
+	}
+	crs, err := r.Collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	if err = crs.All(ctx, &series); err != nil {
+		return nil, err
+	}
+
+	return series, nil
+}
+
+// earningsMatch selects the credits a runner earns from errands.
+func earningsMatch(userId string) bson.D {
+	return bson.D{
+		{"user_id", userId},
+		{"type", Credit.String()},
+		{"transaction_category", bson.D{{"$in", bson.A{ErrandPayout, Tip, RunnerCompensation}}}},
+	}
+}
+
+func earningsGroup(id interface{}) bson.D {
+	sumFor := func(category Category, field interface{}) bson.D {
+		return bson.D{{"$sum", bson.D{
+			{"$cond", bson.A{
+				bson.D{{"$eq", bson.A{"$transaction_category", category}}},
+				field,
+				0,
+			}},
+		}}}
+	}
+	return bson.D{
+		{"_id", id},
+		{"gross", sumFor(ErrandPayout, "$gross")},
+		{"commission", sumFor(ErrandPayout, "$fee")},
+		{"payouts", sumFor(ErrandPayout, "$amount")},
+		{"tips", sumFor(Tip, "$amount")},
+		{"compensation", sumFor(RunnerCompensation, "$amount")},
+		{"total", bson.D{{"$sum", "$amount"}}},
+		{"errands", sumFor(ErrandPayout, 1)},
+	}
+}
+
+// GetBalance returns the withdrawable balance, promotional credit is excluded.
+func (r *repository) GetBalance(userId string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return r.balance(ctx, cashMatch(userId))
+}
+
+func (r *repository) GetPromoBalance(userId string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return r.balance(ctx, promoMatch(userId))
+}
+
+func cashMatch(userId string) bson.D {
+	return bson.D{
+		{"user_id", userId},
+		{"promotional", bson.D{{"$ne", true}}},
+	}
+}
+
+func promoMatch(userId string) bson.D {
+	return bson.D{
+		{"user_id", userId},
+		{"promotional", true},
+	}
+}
+
+func (r *repository) GetBalanceBefore(userId string, before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return r.balance(ctx, append(cashMatch(userId), bson.E{Key: "created_at", Value: bson.D{
+		{"$lt", before},
+	}}))
+}
+
+func (r *repository) balance(ctx context.Context, match bson.D) (int64, error) {
+	var balances []struct {
+		Balance int64 `bson:"balance"`
+	}
+
+	pipeline := mongo.Pipeline{
+		{{"$match", match}},
 		{{
 			"$group", bson.D{
 				{"_id", nil},
@@ -111,3 +476,14 @@ func (r *repository) GetBalance(userId string) (int64, error) {
 	}
 	return balances[0].Balance, nil
 }
+
+func createdAtRange(from, to time.Time) bson.M {
+	dateRange := bson.M{}
+	if !from.IsZero() {
+		dateRange["$gte"] = from
+	}
+	if !to.IsZero() {
+		dateRange["$lte"] = to
+	}
+	return dateRange
+}
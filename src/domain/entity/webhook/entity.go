@@ -0,0 +1,300 @@
+package webhook
+
+import (
+	"DX/src/domain/entity"
+	"DX/src/pkg/apperror"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is something that happened to an errand that partners can subscribe to.
+type Event string
+
+const (
+	BidAccepted     Event = "bid.accepted"
+	ErrandStarted   Event = "errand.started"
+	ErrandCompleted Event = "errand.completed"
+)
+
+var Events = []Event{BidAccepted, ErrandStarted, ErrandCompleted}
+
+type Status string
+
+const (
+	Pending   Status = "pending"
+	Succeeded Status = "succeeded"
+	Failed    Status = "failed"
+)
+
+const (
+	MaxSubscriptions = 10
+	MaxAttempts      = 10 // a delivery is given up after this many attempts
+	MaxFailures      = 30 // consecutive failed attempts before the endpoint is disabled
+	BaseDelay        = time.Minute
+	MaxDelay         = 6 * time.Hour
+	SignatureHeader  = "X-DX-Signature"
+	EventHeader      = "X-DX-Event"
+	DeliveryHeader   = "X-DX-Delivery"
+	maxUrlLength     = 2048
+	maxResponseBody  = 1024
+)
+
+var ErrInvalidUrl = apperror.New(apperror.Invalid, "invalid_webhook_url", "webhook url must be an absolute https url")
+var ErrUnknownEvent = apperror.New(apperror.Invalid, "unknown_event", "unknown event")
+var ErrNoEvents = apperror.New(apperror.Invalid, "no_events", "a webhook needs at least one event")
+var ErrTooManySubscriptions = apperror.New(apperror.Conflict, "too_many_webhooks", fmt.Sprintf("you can have at most %d webhooks", MaxSubscriptions))
+var ErrUnknownSubscription = apperror.New(apperror.NotFound, "webhook_not_found", "unknown webhook")
+var ErrUnknownDelivery = apperror.New(apperror.NotFound, "webhook_delivery_not_found", "unknown webhook delivery")
+var ErrDisabled = apperror.New(apperror.Conflict, "webhook_disabled", "webhook is disabled")
+
+// Subscription sends the events it filters for to the url of a partner, signed with its secret.
+type Subscription struct {
+	Id         entity.DatabaseId `json:"id" bson:"_id"`
+	UserId     string            `json:"-" bson:"user_id"`
+	Url        string            `json:"url" bson:"url"`
+	Events     []Event           `json:"events" bson:"events"`
+	Secret     string            `json:"-" bson:"secret"`
+	Active     bool              `json:"active" bson:"active"`
+	Failures   int               `json:"failures" bson:"failures"` // consecutive failed attempts
+	DisabledAt *time.Time        `json:"disabled_at,omitempty" bson:"disabled_at"`
+	CreatedAt  time.Time         `json:"created_at" bson:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at" bson:"updated_at"`
+}
+
+// Created is returned once when a webhook is created, it is the only time the secret can be read.
+type Created struct {
+	*Subscription
+	Secret string `json:"secret"`
+}
+
+// Attempt is one try at delivering, with what the endpoint answered.
+type Attempt struct {
+	At           time.Time `json:"at" bson:"at"`
+	ResponseCode int       `json:"response_code,omitempty" bson:"response_code"`
+	ResponseBody string    `json:"response_body,omitempty" bson:"response_body"`
+	Error        string    `json:"error,omitempty" bson:"error"`
+	Duration     int64     `json:"duration_ms" bson:"duration_ms"`
+}
+
+// Delivery is an event on its way to a subscription. Redelivering creates a new delivery of the same event.
+type Delivery struct {
+	Id             entity.DatabaseId `json:"id" bson:"_id"`
+	SubscriptionId string            `json:"subscription_id" bson:"subscription_id"`
+	UserId         string            `json:"-" bson:"user_id"`
+	EventId        string            `json:"event_id" bson:"event_id"` // the same for every delivery of the event
+	Event          Event             `json:"event" bson:"event"`
+	Payload        string            `json:"payload" bson:"payload"`
+	Status         Status            `json:"status" bson:"status"`
+	Attempts       []Attempt         `json:"attempts" bson:"attempts"`
+	RedeliveryOf   string            `json:"redelivery_of,omitempty" bson:"redelivery_of,omitempty"`
+	NextAttemptAt  time.Time         `json:"next_attempt_at" bson:"next_attempt_at"`
+	CreatedAt      time.Time         `json:"created_at" bson:"created_at"`
+	DeliveredAt    *time.Time        `json:"delivered_at,omitempty" bson:"delivered_at"`
+}
+
+// Payload is the body posted to the endpoint.
+type Payload struct {
+	Id        string      `json:"id"`
+	Type      Event       `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// ErrandData describes the errand an event happened to.
+type ErrandData struct {
+	ErrandId string `json:"errand_id"`
+	Status   string `json:"status"`
+	RunnerId string `json:"runner_id,omitempty"`
+	BidId    string `json:"bid_id,omitempty"`
+	Amount   int64  `json:"amount"`
+}
+
+// Request is the endpoint a user subscribes.
+type Request struct {
+	Url    string   `json:"url" binding:"required,url,max=2048"`
+	Events []string `json:"events" binding:"required,min=1,dive,notblank"`
+}
+
+// Update changes only the fields that are sent, setting active re-enables a disabled endpoint.
+type Update struct {
+	Url    *string  `json:"url" binding:"omitempty,url,max=2048"`
+	Events []string `json:"events" binding:"omitempty,min=1,dive,notblank"`
+	Active *bool    `json:"active"`
+}
+
+func NewSubscription(userId string, request Request) (*Created, error) {
+	secret, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+	cTime := time.Now()
+	nSubscription := &Subscription{
+		Id:        entity.NewDatabaseId(),
+		UserId:    userId,
+		Secret:    secret,
+		Active:    true,
+		CreatedAt: cTime,
+	}
+	if err = nSubscription.Set(Update{Url: &request.Url, Events: request.Events}); err != nil {
+		return nil, err
+	}
+	return &Created{Subscription: nSubscription, Secret: secret}, nil
+}
+
+func (s *Subscription) Set(update Update) error {
+	if update.Url != nil || s.Url == "" {
+		var rawUrl string
+		if update.Url != nil {
+			rawUrl = strings.TrimSpace(*update.Url)
+		}
+		if err := validateUrl(rawUrl); err != nil {
+			return err
+		}
+		s.Url = rawUrl
+	}
+
+	if update.Events != nil || len(s.Events) == 0 {
+		if len(update.Events) == 0 {
+			return ErrNoEvents
+		}
+		var events []Event
+		for _, name := range update.Events {
+			event, err := GetEvent(name)
+			if err != nil {
+				return err
+			}
+			if !contains(events, event) {
+				events = append(events, event)
+			}
+		}
+		s.Events = events
+	}
+
+	if update.Active != nil {
+		s.Active = *update.Active
+		if s.Active {
+			s.Failures = 0
+			s.DisabledAt = nil
+		}
+	}
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+func (s *Subscription) Subscribes(event Event) bool {
+	return contains(s.Events, event)
+}
+
+// Sign covers the timestamp and the body, receivers should reject old timestamps to stop replays.
+func (s *Subscription) Sign(timestamp time.Time, body []byte) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(s.Secret))
+	mac.Write([]byte(unix + "."))
+	mac.Write(body)
+	return "t=" + unix + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func NewDelivery(subscription *Subscription, eventId string, event Event, payload string) *Delivery {
+	cTime := time.Now()
+	return &Delivery{
+		Id:             entity.NewDatabaseId(),
+		SubscriptionId: subscription.Id.Hex(),
+		UserId:         subscription.UserId,
+		EventId:        eventId,
+		Event:          event,
+		Payload:        payload,
+		Status:         Pending,
+		Attempts:       []Attempt{},
+		NextAttemptAt:  cTime,
+		CreatedAt:      cTime,
+	}
+}
+
+// Redeliver sends the same event again as a new delivery.
+func (d *Delivery) Redeliver(subscription *Subscription) *Delivery {
+	nDelivery := NewDelivery(subscription, d.EventId, d.Event, d.Payload)
+	nDelivery.RedeliveryOf = d.Id.Hex()
+	return nDelivery
+}
+
+// Record logs the attempt and schedules the next one with exponential backoff until MaxAttempts.
+func (d *Delivery) Record(attempt Attempt) {
+	if len(attempt.ResponseBody) > maxResponseBody {
+		attempt.ResponseBody = attempt.ResponseBody[:maxResponseBody]
+	}
+	d.Attempts = append(d.Attempts, attempt)
+
+	switch {
+	case attempt.Succeeded():
+		d.Status = Succeeded
+		d.DeliveredAt = &attempt.At
+	case len(d.Attempts) >= MaxAttempts:
+		d.Status = Failed
+	default:
+		d.NextAttemptAt = attempt.At.Add(Backoff(len(d.Attempts)))
+	}
+}
+
+// Abandon gives up on the delivery without another attempt.
+func (d *Delivery) Abandon(reason string) {
+	d.Attempts = append(d.Attempts, Attempt{At: time.Now(), Error: reason})
+	d.Status = Failed
+}
+
+// Succeeded reports whether the endpoint answered with a 2xx status.
+func (a Attempt) Succeeded() bool {
+	return a.Error == "" && a.ResponseCode >= 200 && a.ResponseCode < 300
+}
+
+// Backoff is the wait after the given number of failed attempts.
+func Backoff(attempts int) time.Duration {
+	delay := time.Duration(float64(BaseDelay) * math.Pow(2, float64(attempts-1)))
+	if delay <= 0 || delay > MaxDelay {
+		return MaxDelay
+	}
+	return delay
+}
+
+func GetEvent(value string) (Event, error) {
+	event := Event(strings.ToLower(strings.TrimSpace(value)))
+	if !contains(Events, event) {
+		return "", ErrUnknownEvent.WithDetail(value)
+	}
+	return event, nil
+}
+
+func validateUrl(rawUrl string) error {
+	if len(rawUrl) > maxUrlLength {
+		return ErrInvalidUrl
+	}
+	parsed, err := url.Parse(rawUrl)
+	if err != nil || parsed.Scheme != "https" || parsed.Hostname() == "" || parsed.User != nil {
+		return ErrInvalidUrl
+	}
+	return nil
+}
+
+func newSecret() (string, error) {
+	value := make([]byte, 32)
+	if _, err := rand.Read(value); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(value), nil
+}
+
+func contains(events []Event, event Event) bool {
+	for _, value := range events {
+		if value == event {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,258 @@
+package webhook
+
+import (
+	"DX/src/domain/entity"
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type reader interface {
+	GetSubscription(string, string) (*Subscription, error)
+	GetSubscriptions(string) ([]Subscription, error)
+	GetSubscribers(string, Event) ([]Subscription, error)
+	CountSubscriptions(string) (int64, error)
+	GetDelivery(string, string) (*Delivery, error)
+	GetDeliveries(string, string, int64) ([]Delivery, error)
+}
+
+type writer interface {
+	CreateSubscription(*Subscription) error
+	UpdateSubscription(*Subscription) error
+	DeleteSubscription(string, string) error
+	Succeed(entity.DatabaseId) error
+	Fail(entity.DatabaseId) (bool, error)
+	CreateDeliveries(...*Delivery) error
+	ClaimDelivery(time.Time, time.Duration) (*Delivery, error)
+	SaveDelivery(*Delivery) error
+}
+
+type Repository interface {
+	reader
+	writer
+}
+
+type repository struct {
+	SubscriptionCollection *mongo.Collection
+	DeliveryCollection     *mongo.Collection
+}
+
+func NewRepository(subscriptionCollection, deliveryCollection *mongo.Collection) Repository {
+	return &repository{
+		SubscriptionCollection: subscriptionCollection,
+		DeliveryCollection:     deliveryCollection,
+	}
+}
+
+func (r *repository) CreateSubscription(subscription *Subscription) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.SubscriptionCollection.InsertOne(ctx, subscription)
+	return err
+}
+
+func (r *repository) GetSubscription(userId, id string) (*Subscription, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	subscriptionId, err := entity.StringToErrandId(id)
+	if err != nil {
+		return nil, ErrUnknownSubscription
+	}
+	subscription := &Subscription{}
+	err = r.SubscriptionCollection.FindOne(ctx, bson.M{"_id": subscriptionId, "user_id": userId}).Decode(subscription)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrUnknownSubscription
+	}
+	if err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (r *repository) GetSubscriptions(userId string) (subscriptions []Subscription, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+	crs, err := r.SubscriptionCollection.Find(ctx, bson.M{"user_id": userId}, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err = crs.All(ctx, &subscriptions); err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+// GetSubscribers returns the active subscriptions of the user filtering for the event.
+func (r *repository) GetSubscribers(userId string, event Event) (subscriptions []Subscription, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	crs, err := r.SubscriptionCollection.Find(ctx, bson.M{"user_id": userId, "events": event, "active": true})
+	if err != nil {
+		return nil, err
+	}
+	if err = crs.All(ctx, &subscriptions); err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (r *repository) CountSubscriptions(userId string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return r.SubscriptionCollection.CountDocuments(ctx, bson.M{"user_id": userId})
+}
+
+func (r *repository) UpdateSubscription(subscription *Subscription) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	update := bson.M{
+		"$set": bson.M{
+			"url":         subscription.Url,
+			"events":      subscription.Events,
+			"active":      subscription.Active,
+			"failures":    subscription.Failures,
+			"disabled_at": subscription.DisabledAt,
+			"updated_at":  subscription.UpdatedAt,
+		},
+	}
+	_, err := r.SubscriptionCollection.UpdateOne(ctx, bson.M{"_id": subscription.Id, "user_id": subscription.UserId}, update)
+	return err
+}
+
+func (r *repository) DeleteSubscription(userId, id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	subscriptionId, err := entity.StringToErrandId(id)
+	if err != nil {
+		return ErrUnknownSubscription
+	}
+	result, err := r.SubscriptionCollection.DeleteOne(ctx, bson.M{"_id": subscriptionId, "user_id": userId})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrUnknownSubscription
+	}
+	return nil
+}
+
+// Succeed resets the consecutive failures of the subscription.
+func (r *repository) Succeed(id entity.DatabaseId) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.SubscriptionCollection.UpdateOne(ctx, bson.M{"_id": id, "failures": bson.M{"$gt": 0}}, bson.M{"$set": bson.M{"failures": 0}})
+	return err
+}
+
+// Fail counts a failed attempt and disables the subscription once it reaches MaxFailures in a row, it
+// reports whether this attempt disabled it.
+func (r *repository) Fail(id entity.DatabaseId) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := r.SubscriptionCollection.UpdateByID(ctx, id, bson.M{"$inc": bson.M{"failures": 1}}); err != nil {
+		return false, err
+	}
+
+	filter := bson.M{"_id": id, "active": true, "failures": bson.M{"$gte": MaxFailures}}
+	cTime := time.Now()
+	update := bson.M{"$set": bson.M{"active": false, "disabled_at": cTime, "updated_at": cTime}}
+	result, err := r.SubscriptionCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func (r *repository) CreateDeliveries(deliveries ...*Delivery) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	documents := make([]interface{}, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		documents = append(documents, delivery)
+	}
+	_, err := r.DeliveryCollection.InsertMany(ctx, documents)
+	return err
+}
+
+func (r *repository) GetDelivery(userId, id string) (*Delivery, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	deliveryId, err := entity.StringToErrandId(id)
+	if err != nil {
+		return nil, ErrUnknownDelivery
+	}
+	delivery := &Delivery{}
+	err = r.DeliveryCollection.FindOne(ctx, bson.M{"_id": deliveryId, "user_id": userId}).Decode(delivery)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrUnknownDelivery
+	}
+	if err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+func (r *repository) GetDeliveries(userId, subscriptionId string, limit int64) (deliveries []Delivery, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1}).SetLimit(limit)
+	crs, err := r.DeliveryCollection.Find(ctx, bson.M{"user_id": userId, "subscription_id": subscriptionId}, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err = crs.All(ctx, &deliveries); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// ClaimDelivery takes the next due delivery and holds it for the lease, so another worker does not send
+// it at the same time and it is retried if this one stops before saving the attempt.
+func (r *repository) ClaimDelivery(now time.Time, lease time.Duration) (*Delivery, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"status": Pending, "next_attempt_at": bson.M{"$lte": now}}
+	update := bson.M{"$set": bson.M{"next_attempt_at": now.Add(lease)}}
+	opts := options.FindOneAndUpdate().SetSort(bson.M{"next_attempt_at": 1})
+
+	delivery := &Delivery{}
+	if err := r.DeliveryCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+func (r *repository) SaveDelivery(delivery *Delivery) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":          delivery.Status,
+			"attempts":        delivery.Attempts,
+			"next_attempt_at": delivery.NextAttemptAt,
+			"delivered_at":    delivery.DeliveredAt,
+		},
+	}
+	_, err := r.DeliveryCollection.UpdateByID(ctx, delivery.Id, update)
+	return err
+}
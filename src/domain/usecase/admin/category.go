@@ -6,67 +6,54 @@ import (
 	"DX/src/domain/entity/file"
 	"DX/src/domain/entity/user"
 	"DX/src/pkg/error_service"
-	"errors"
 	"strings"
 )
 
 type CategoryUseCase interface {
-	CreateNewCategory(string, string, string, []*file.File) error
-	UpdateCategory(string, string, string) error
+	CreateNewCategory(*auth.Principal, string, string, []*file.File) error
+	UpdateCategory(*auth.Principal, string, string) error
 	GetAllCategories(int) ([]category.Category, error)
-	DeleteCategory(string, string) error
+	DeleteCategory(string) error
 }
 
 type categoryImpl struct {
 	FileManager        file.Repository
-	AuthManager        auth.Manager
 	UserManager        user.Repository
 	CategoryRepository category.Repository
 	error_service.Service
 }
 
-func NewCategoryUseCase(FileManager file.Repository, AuthManager auth.Manager, UserManager user.Repository,
+func NewCategoryUseCase(FileManager file.Repository, UserManager user.Repository,
 	CategoryRepo category.Repository, Service error_service.Service) CategoryUseCase {
 	return &categoryImpl{
 		FileManager:        FileManager,
-		AuthManager:        AuthManager,
 		UserManager:        UserManager,
 		Service:            Service,
 		CategoryRepository: CategoryRepo,
 	}
 }
 
-func (c *categoryImpl) CreateNewCategory(token, name, categoryType string, icon []*file.File) error {
-	adminUserId, resp := c.AuthManager.Get(token)
-	if resp != nil {
-		return errors.New(resp.Message)
-	}
-
+func (c *categoryImpl) CreateNewCategory(principal *auth.Principal, name, categoryType string, icon []*file.File) error {
 	iconFile := icon[0]
-	err := c.FileManager.UploadCategoryIcon(*adminUserId, iconFile)
+	err := c.FileManager.UploadCategoryIcon(principal.UserId, iconFile)
 	if err != nil {
-		return errors.New(c.Service.HandleGoogleStorageError(err).Message)
+		return c.Service.StorageError(err)
 	}
 
-	nCategory := category.New(name, *adminUserId, categoryType, iconFile.UploadedUrl)
+	nCategory := category.New(name, principal.UserId, categoryType, iconFile.UploadedUrl)
 
 	err = c.CategoryRepository.Create(nCategory)
 	if err != nil {
-		return errors.New(c.Service.HandleMongoDbError("category", err).Message)
+		return c.Service.MongoDbError("category", err)
 	}
 
 	return nil
 }
 
-func (c *categoryImpl) UpdateCategory(token, categoryId, name string) error {
-	adminUserId, resp := c.AuthManager.Get(token)
-	if resp != nil {
-		return errors.New(resp.Message)
-	}
-
-	err := c.CategoryRepository.Update(categoryId, name, strings.ToLower(name), *adminUserId)
+func (c *categoryImpl) UpdateCategory(principal *auth.Principal, categoryId, name string) error {
+	err := c.CategoryRepository.Update(categoryId, name, strings.ToLower(name), principal.UserId)
 	if err != nil {
-		return errors.New(c.Service.HandleMongoDbError("category", err).Message)
+		return c.Service.MongoDbError("category", err)
 	}
 
 	return nil
@@ -80,16 +67,16 @@ func (c *categoryImpl) GetAllCategories(limit int) (categories []category.Catego
 	}
 
 	if err != nil {
-		return nil, errors.New(c.Service.HandleMongoDbError("categories", err).Message)
+		return nil, c.Service.MongoDbError("categories", err)
 	}
 
 	return categories, nil
 }
 
-func (c *categoryImpl) DeleteCategory(_ string, categoryId string) error {
+func (c *categoryImpl) DeleteCategory(categoryId string) error {
 	err := c.CategoryRepository.Delete(categoryId)
 	if err != nil {
-		return errors.New(c.Service.HandleMongoDbError("category", err).Message)
+		return c.Service.MongoDbError("category", err)
 	}
 
 	return nil
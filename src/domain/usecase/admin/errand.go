@@ -10,18 +10,16 @@ import (
 	"DX/src/domain/entity/timeline"
 	"DX/src/domain/entity/user"
 	"DX/src/pkg/error_service"
-	"errors"
 	"time"
 )
 
 type ErrandUseCase interface {
-	CreateErrand(string, string, *errand.Errand) error
-	AssignErrand(string, string, string) error
+	CreateErrand(*auth.Principal, string, *errand.Errand) error
+	AssignErrand(*auth.Principal, string, string) error
 	GetAllErrands(string) ([]errand.Errand, error)
 }
 
 type errandImpl struct {
-	auth.Manager
 	errand.Repository
 	UserRepo           user.Repository
 	NotificationRepo   notification.Repository
@@ -30,7 +28,6 @@ type errandImpl struct {
 }
 
 func NewErrandUseCase(
-	manager auth.Manager,
 	repository errand.Repository,
 	service error_service.Service,
 	userRepo user.Repository,
@@ -38,7 +35,6 @@ func NewErrandUseCase(
 	categoryRepository category.Repository,
 ) ErrandUseCase {
 	return &errandImpl{
-		Manager:            manager,
 		Repository:         repository,
 		Service:            service,
 		UserRepo:           userRepo,
@@ -47,67 +43,57 @@ func NewErrandUseCase(
 	}
 }
 
-func (e *errandImpl) CreateErrand(token, userId string, errand *errand.Errand) error {
-	adminUserId, resp := e.Manager.Get(token)
-	if resp != nil {
-		return errors.New(resp.Message)
-	}
-
+func (e *errandImpl) CreateErrand(principal *auth.Principal, userId string, nErrand *errand.Errand) error {
 	oUser, err := e.UserRepo.GetWithId(userId)
 	if err != nil {
-		return errors.New(e.Service.HandleMongoDbError("user", err).Message)
+		return e.Service.MongoDbError("user", err)
 	}
 	if !oUser.IsOffline() {
-		return errors.New("admin can only create errand for offline users")
+		return errand.ErrSenderNotOffline
 	}
 
-	if nCategory, err := e.CategoryRepository.Get(errand.Category.Id.Hex()); err != nil {
-		return errors.New(e.Service.HandleMongoDbError("category", err).Message)
+	if nCategory, err := e.CategoryRepository.Get(nErrand.Category.Id.Hex()); err != nil {
+		return e.Service.MongoDbError("category", err)
 	} else {
-		if errand.DropOffAddress == nil && nCategory.Type == "task" {
-			return errors.New("drop-off location is required for tasks")
+		if nErrand.DropOffAddress == nil && nCategory.Type == "task" {
+			return errand.ErrDropOffRequired
 		}
-		errand.Category = nCategory
+		nErrand.Category = nCategory
 	}
-	errand.CreatedAt = time.Now()
-	errand.UpdateForCreation(entity.CreatedByAdmin(*adminUserId))
-	errand.Timeline = timeline.NewTimeline(errand.Id.Hex())
-	errand.UserId = userId
+	nErrand.CreatedAt = time.Now()
+	nErrand.UpdateForCreation(entity.CreatedByAdmin(principal.UserId))
+	nErrand.Timeline = timeline.NewTimeline(nErrand.Id.Hex())
+	nErrand.UserId = userId
 
-	err = e.Repository.Update(errand)
+	err = e.Repository.Update(nErrand)
 	if err != nil {
-		return errors.New(e.Service.HandleMongoDbError("nErrand", err).Message)
+		return e.Service.MongoDbError("nErrand", err)
 	}
 
 	return nil
 }
 
-func (e *errandImpl) AssignErrand(token, errandId, userId string) error {
-	adminUserId, resp := e.Manager.Get(token)
-	if resp != nil {
-		return errors.New(resp.Message)
-	}
-
+func (e *errandImpl) AssignErrand(principal *auth.Principal, errandId, userId string) error {
 	nErrand, err := e.Repository.Get(errandId)
 	if err != nil {
-		return errors.New(e.Service.HandleMongoDbError("errand", err).Message)
+		return e.Service.MongoDbError("errand", err)
 	}
 
-	if !nErrand.IsAdminErrand(*adminUserId) {
-		return errors.New("admin not authorized to assign this errand")
+	if !nErrand.IsAdminErrand(principal.UserId) {
+		return errand.ErrAssignForbidden
 	}
 
 	cUser, err := e.UserRepo.GetWithId(userId)
 	if err != nil {
-		return errors.New(e.Service.HandleMongoDbError("user", err).Message)
+		return e.Service.MongoDbError("user", err)
 	}
 
 	if !cUser.IsOffline() {
-		return errors.New("admin can only assign bid to offline user")
+		return errand.ErrRunnerNotOffline
 	}
 	nBid := bid.NewOfflineBid(errandId, userId, nErrand.Budget)
-	if err = e.Repository.AssignErrandToOfflineRunner(*adminUserId, errandId, userId, nBid); err != nil {
-		return errors.New(e.Service.HandleMongoDbError("errand", err).Message)
+	if err = e.Repository.AssignErrandToOfflineRunner(principal.UserId, errandId, userId, nBid); err != nil {
+		return e.Service.MongoDbError("errand", err)
 	}
 
 	return nil
@@ -129,7 +115,7 @@ func (e *errandImpl) GetAllErrands(errandType string) ([]errand.Errand, error) {
 	}
 
 	if err != nil {
-		return nil, errors.New(e.Service.HandleMongoDbError("errands", nil).Message)
+		return nil, e.Service.MongoDbError("errands", nil)
 	}
 
 	return errands, nil
@@ -0,0 +1,93 @@
+package admin
+
+import (
+	"DX/src/domain/entity/auth"
+	"DX/src/domain/entity/fee"
+	"DX/src/domain/entity/wallet"
+	"DX/src/pkg/error_service"
+)
+
+type FeeUseCase interface {
+	GetRules() ([]fee.Rule, error)
+	SaveRule(*auth.Principal, *fee.Rule) error
+	DeleteRule(string) error
+	GetWaivers() ([]fee.Waiver, error)
+	CreateWaiver(*auth.Principal, *fee.Waiver) error
+	DeleteWaiver(string) error
+	GetRevenue() (int64, error)
+}
+
+type feeImpl struct {
+	fee.Repository
+	WalletRepo wallet.Repository
+	error_service.Service
+}
+
+func NewFeeUseCase(repository fee.Repository, walletRepo wallet.Repository, service error_service.Service) FeeUseCase {
+	return &feeImpl{
+		Repository: repository,
+		WalletRepo: walletRepo,
+		Service:    service,
+	}
+}
+
+func (f *feeImpl) GetRules() ([]fee.Rule, error) {
+	rules, err := f.Repository.GetRules()
+	if err != nil {
+		return nil, f.Service.MongoDbError("fee rules", err)
+	}
+
+	return rules, nil
+}
+
+func (f *feeImpl) SaveRule(principal *auth.Principal, rule *fee.Rule) error {
+	rule.CreatedBy = principal.UserId
+	if err := f.Repository.SaveRule(rule); err != nil {
+		return f.Service.MongoDbError("fee rule", err)
+	}
+
+	return nil
+}
+
+func (f *feeImpl) DeleteRule(ruleId string) error {
+	if err := f.Repository.DeleteRule(ruleId); err != nil {
+		return f.Service.MongoDbError("fee rule", err)
+	}
+
+	return nil
+}
+
+func (f *feeImpl) GetWaivers() ([]fee.Waiver, error) {
+	waivers, err := f.Repository.GetWaivers()
+	if err != nil {
+		return nil, f.Service.MongoDbError("fee waivers", err)
+	}
+
+	return waivers, nil
+}
+
+func (f *feeImpl) CreateWaiver(principal *auth.Principal, waiver *fee.Waiver) error {
+	waiver.CreatedBy = principal.UserId
+	if err := f.Repository.CreateWaiver(waiver); err != nil {
+		return f.Service.MongoDbError("fee waiver", err)
+	}
+
+	return nil
+}
+
+func (f *feeImpl) DeleteWaiver(waiverId string) error {
+	if err := f.Repository.DeleteWaiver(waiverId); err != nil {
+		return f.Service.MongoDbError("fee waiver", err)
+	}
+
+	return nil
+}
+
+func (f *feeImpl) GetRevenue() (int64, error) {
+	balance, err := f.WalletRepo.GetBalance(wallet.PlatformAccountId)
+	if err != nil {
+		return 0, f.Service.MongoDbError("revenue", err)
+	}
+
+	return balance, nil
+}
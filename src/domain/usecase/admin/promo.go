@@ -0,0 +1,88 @@
+package admin
+
+import (
+	"DX/src/domain/entity/auth"
+	"DX/src/domain/entity/notification"
+	"DX/src/domain/entity/promo"
+	"DX/src/domain/entity/user"
+	"DX/src/domain/entity/wallet"
+	"DX/src/pkg/error_service"
+	"DX/src/utils/logger"
+	"fmt"
+)
+
+type PromoUseCase interface {
+	CreatePromo(*auth.Principal, *promo.Promo) error
+	GetPromos() ([]promo.Promo, error)
+	DeactivatePromo(string) error
+	GrantCredit(*auth.Principal, string, int64, string) error
+}
+
+type promoImpl struct {
+	promo.Repository
+	UserRepo         user.Repository
+	WalletRepo       wallet.Repository
+	NotificationRepo notification.Repository
+	error_service.Service
+}
+
+func NewPromoUseCase(repository promo.Repository, userRepo user.Repository, walletRepo wallet.Repository,
+	notificationRepo notification.Repository, service error_service.Service) PromoUseCase {
+	return &promoImpl{
+		Repository:       repository,
+		UserRepo:         userRepo,
+		WalletRepo:       walletRepo,
+		NotificationRepo: notificationRepo,
+		Service:          service,
+	}
+}
+
+func (p *promoImpl) CreatePromo(principal *auth.Principal, nPromo *promo.Promo) error {
+	nPromo.CreatedBy = principal.UserId
+	if err := p.Repository.CreatePromo(nPromo); err != nil {
+		return p.Service.MongoDbError("promo", err)
+	}
+
+	return nil
+}
+
+func (p *promoImpl) GetPromos() ([]promo.Promo, error) {
+	promos, err := p.Repository.GetPromos()
+	if err != nil {
+		return nil, p.Service.MongoDbError("promos", err)
+	}
+	if promos == nil {
+		promos = []promo.Promo{}
+	}
+
+	return promos, nil
+}
+
+func (p *promoImpl) DeactivatePromo(promoId string) error {
+	if err := p.Repository.DeactivatePromo(promoId); err != nil {
+		return p.Service.MongoDbError("promo", err)
+	}
+
+	return nil
+}
+
+func (p *promoImpl) GrantCredit(principal *auth.Principal, userId string, amount int64, description string) error {
+	if amount <= 0 {
+		return promo.ErrInvalidCredit
+	}
+	if _, err := p.UserRepo.GetWithId(userId); err != nil {
+		return p.Service.MongoDbError("user", err)
+	}
+
+	txn := wallet.NewPromoCreditTransaction(userId, description, principal.UserId, amount, wallet.PromoCredit)
+	if err := p.WalletRepo.CreateTransaction(txn); err != nil {
+		return p.Service.MongoDbError("wallet", err)
+	}
+
+	message := fmt.Sprintf("%d promo credit has been added to your wallet.", amount)
+	if err := p.NotificationRepo.SendNotification(notification.NewPromoCreditNotification(userId, message)); err != nil {
+		logger.Error("Failed to send notifications", err)
+	}
+
+	return nil
+}
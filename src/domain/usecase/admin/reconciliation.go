@@ -0,0 +1,82 @@
+package admin
+
+import (
+	"DX/src/domain/entity/auth"
+	"DX/src/domain/entity/reconciliation"
+	reconciliationUseCase "DX/src/domain/usecase/reconciliation"
+	"DX/src/pkg/error_service"
+	"time"
+)
+
+const reportLimit = int64(30)
+
+type ReconciliationUseCase interface {
+	Run(time.Time, time.Time) (*reconciliation.Report, error)
+	GetReports() ([]reconciliation.Report, error)
+	GetDiscrepancies(string) ([]reconciliation.Discrepancy, error)
+	ResolveDiscrepancy(*auth.Principal, string, string) error
+}
+
+type reconciliationImpl struct {
+	reconciliation.Repository
+	ReconciliationUseCase reconciliationUseCase.UseCase
+	error_service.Service
+}
+
+func NewReconciliationUseCase(repository reconciliation.Repository, useCase reconciliationUseCase.UseCase, service error_service.Service) ReconciliationUseCase {
+	return &reconciliationImpl{
+		Repository:            repository,
+		ReconciliationUseCase: useCase,
+		Service:               service,
+	}
+}
+
+func (r *reconciliationImpl) Run(from, to time.Time) (*reconciliation.Report, error) {
+	return r.ReconciliationUseCase.Reconcile(from, to)
+}
+
+func (r *reconciliationImpl) GetReports() ([]reconciliation.Report, error) {
+	reports, err := r.Repository.GetReports(reportLimit)
+	if err != nil {
+		return nil, r.Service.MongoDbError("reconciliation reports", err)
+	}
+	if reports == nil {
+		reports = []reconciliation.Report{}
+	}
+
+	return reports, nil
+}
+
+func (r *reconciliationImpl) GetDiscrepancies(state string) ([]reconciliation.Discrepancy, error) {
+	status, ok := reconciliation.GetStatus(state)
+	if !ok {
+		return nil, reconciliation.ErrInvalidStatus
+	}
+
+	discrepancies, err := r.Repository.GetDiscrepancies(status)
+	if err != nil {
+		return nil, r.Service.MongoDbError("discrepancies", err)
+	}
+	if discrepancies == nil {
+		discrepancies = []reconciliation.Discrepancy{}
+	}
+
+	return discrepancies, nil
+}
+
+func (r *reconciliationImpl) ResolveDiscrepancy(principal *auth.Principal, discrepancyId, note string) error {
+	discrepancy, err := r.Repository.GetDiscrepancy(discrepancyId)
+	if err != nil {
+		return r.Service.MongoDbError("discrepancy", err)
+	}
+	if discrepancy.Status == reconciliation.Resolved {
+		return reconciliation.ErrAlreadyResolved
+	}
+
+	discrepancy.Resolve(principal.UserId, note)
+	if err = r.Repository.UpdateDiscrepancy(discrepancy); err != nil {
+		return r.Service.MongoDbError("discrepancy", err)
+	}
+
+	return nil
+}
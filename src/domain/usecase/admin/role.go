@@ -0,0 +1,122 @@
+package admin
+
+import (
+	"DX/src/domain/entity/auth"
+	"DX/src/domain/entity/role"
+	"DX/src/domain/entity/user"
+	"DX/src/pkg/error_service"
+	"go.mongodb.org/mongo-driver/mongo"
+	"strings"
+)
+
+type RoleUseCase interface {
+	GetRoles() ([]role.Role, error)
+	CreateRole(*auth.Principal, role.Request) (*role.Role, error)
+	UpdateRole(string, role.Definition) (*role.Role, error)
+	DeleteRole(string) error
+	AssignRoles(*auth.Principal, string, []string) error
+}
+
+type roleImpl struct {
+	role.Repository
+	UserRepo user.Repository
+	error_service.Service
+}
+
+func NewRoleUseCase(repository role.Repository, userRepo user.Repository, service error_service.Service) RoleUseCase {
+	return &roleImpl{
+		Repository: repository,
+		UserRepo:   userRepo,
+		Service:    service,
+	}
+}
+
+func (r *roleImpl) GetRoles() ([]role.Role, error) {
+	roles, err := r.Repository.GetAll()
+	if err != nil {
+		return nil, r.Service.MongoDbError("roles", err)
+	}
+
+	return roles, nil
+}
+
+func (r *roleImpl) CreateRole(principal *auth.Principal, request role.Request) (*role.Role, error) {
+	nRole, err := role.New(request, principal.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if err = r.Repository.Create(nRole); err != nil {
+		return nil, r.Service.MongoDbError("role", err)
+	}
+
+	return nRole, nil
+}
+
+func (r *roleImpl) UpdateRole(name string, definition role.Definition) (*role.Role, error) {
+	if role.GetBuiltIn(name) != nil {
+		return nil, role.ErrBuiltIn
+	}
+
+	nRole, err := r.Repository.Get(name)
+	if err != nil {
+		return nil, r.roleError(err)
+	}
+	if err = nRole.Set(definition); err != nil {
+		return nil, err
+	}
+	if err = r.Repository.Update(nRole); err != nil {
+		return nil, r.roleError(err)
+	}
+
+	return nRole, nil
+}
+
+// DeleteRole removes a custom role and takes it away from everyone holding it.
+func (r *roleImpl) DeleteRole(name string) error {
+	if role.GetBuiltIn(name) != nil {
+		return role.ErrBuiltIn
+	}
+
+	if err := r.Repository.Delete(name); err != nil {
+		return r.roleError(err)
+	}
+	if err := r.UserRepo.RemoveRole(name); err != nil {
+		return r.Service.MongoDbError("user", err)
+	}
+
+	return nil
+}
+
+// AssignRoles replaces the roles of the user, an empty list takes away their admin access.
+func (r *roleImpl) AssignRoles(principal *auth.Principal, userId string, names []string) error {
+	if principal.UserId == userId {
+		return role.ErrOwnRoles
+	}
+
+	roles := make([]string, 0, len(names))
+	assigned := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if assigned[name] {
+			continue
+		}
+		if _, err := r.Repository.Get(name); err != nil {
+			return r.roleError(err)
+		}
+		assigned[name] = true
+		roles = append(roles, name)
+	}
+
+	if err := r.UserRepo.SetRoles(userId, principal.UserId, roles); err != nil {
+		return r.Service.MongoDbError("user", err)
+	}
+
+	return nil
+}
+
+func (r *roleImpl) roleError(err error) error {
+	if err == mongo.ErrNoDocuments {
+		return role.ErrUnknownRole
+	}
+	return r.Service.MongoDbError("role", err)
+}
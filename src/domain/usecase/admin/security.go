@@ -0,0 +1,57 @@
+package admin
+
+import (
+	"DX/src/domain/entity/auth"
+	"DX/src/domain/entity/security"
+	"DX/src/pkg/error_service"
+)
+
+type SecurityQuestionUseCase interface {
+	GetQuestions() ([]security.Question, error)
+	CreateQuestion(*auth.Principal, string) (*security.Question, error)
+	DeactivateQuestion(string) error
+}
+
+type securityQuestionImpl struct {
+	security.Repository
+	error_service.Service
+}
+
+func NewSecurityQuestionUseCase(repository security.Repository, service error_service.Service) SecurityQuestionUseCase {
+	return &securityQuestionImpl{
+		Repository: repository,
+		Service:    service,
+	}
+}
+
+func (s *securityQuestionImpl) GetQuestions() ([]security.Question, error) {
+	questions, err := s.Repository.GetQuestions(false)
+	if err != nil {
+		return nil, s.Service.MongoDbError("security questions", err)
+	}
+
+	return questions, nil
+}
+
+func (s *securityQuestionImpl) CreateQuestion(principal *auth.Principal, text string) (*security.Question, error) {
+	question, err := security.NewQuestion(text, principal.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.Repository.CreateQuestion(question); err != nil {
+		return nil, s.Service.MongoDbError("security question", err)
+	}
+
+	return question, nil
+}
+
+func (s *securityQuestionImpl) DeactivateQuestion(id string) error {
+	if err := s.Repository.DeactivateQuestion(id); err != nil {
+		if err == security.ErrUnknownQuestion {
+			return err
+		}
+		return s.Service.MongoDbError("security question", err)
+	}
+
+	return nil
+}
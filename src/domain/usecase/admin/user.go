@@ -2,10 +2,11 @@ package admin
 
 import (
 	"DX/src/domain/entity/auth"
+	"DX/src/domain/entity/limiter"
 	"DX/src/domain/entity/user"
+	limiterUseCase "DX/src/domain/usecase/limiter"
 	"DX/src/pkg/error_service"
 	"DX/src/utils/logger"
-	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -14,68 +15,55 @@ import (
 type UserUseCase interface {
 	GetUser(string) (*user.User, error)
 	GetAllUsers(string) ([]user.User, error)
-	CreateNewUser(string, *user.User) error
-	UpdateUser(string, string, *user.User) error
-	SuspendUser(string, string) error
-	SuspendUsers(string, []string) error
-	RestoreUser(string, string) error
-	DeleteUser(string, string) error
-	DeleteUsers(string, []string) error
+	CreateNewUser(*auth.Principal, *user.User) error
+	UpdateUser(*auth.Principal, string, *user.User) error
+	SuspendUser(*auth.Principal, string) error
+	SuspendUsers(*auth.Principal, []string) error
+	RestoreUser(*auth.Principal, string) error
+	UnlockUser(string) error
+	DeleteUser(*auth.Principal, string) error
+	DeleteUsers(*auth.Principal, []string) error
 }
 
 type userImpl struct {
-	auth.Manager
 	user.Repository
 	error_service.Service
+	LimiterUseCase limiterUseCase.UseCase
 }
 
-func NewUserUseCase(manager auth.Manager, repository user.Repository, service error_service.Service) UserUseCase {
+func NewUserUseCase(repository user.Repository, service error_service.Service, limiterUseCase limiterUseCase.UseCase) UserUseCase {
 	return &userImpl{
-		Manager:    manager,
-		Repository: repository,
-		Service:    service,
+		Repository:     repository,
+		Service:        service,
+		LimiterUseCase: limiterUseCase,
 	}
 }
 
-func (i *userImpl) CreateNewUser(userToken string, user *user.User) error {
-	adminUserId, resp := i.Manager.Get(userToken)
-	if resp != nil {
-		return errors.New(resp.Message)
-	}
-
-	token := auth.NewUserToken()
-	if resp := i.Manager.CreateToken(token, user.Id.Hex()); resp != nil {
-		return errors.New(resp.Message)
-	}
-	user.Token = token.AccessToken
-	user.RefreshToken = token.RefreshToken
-	user.AdminId = *adminUserId
+// CreateNewUser creates an offline user. No tokens are issued, the response doesn't carry them and a token
+// without a session could not be seen or revoked by the user.
+func (i *userImpl) CreateNewUser(principal *auth.Principal, user *user.User) error {
+	user.AdminId = principal.UserId
 
 	err := i.Repository.Create(user)
 	if err != nil {
 		logger.Error(fmt.Sprintf("value: %v", user), err)
-		return errors.New(i.HandleMongoDbError("user", err).Message)
+		return i.MongoDbError("user", err)
 	}
 	return nil
 }
 
-func (i *userImpl) UpdateUser(token string, userId string, user *user.User) error {
-	adminUserId, resp := i.Manager.Get(token)
-	if resp != nil {
-		return errors.New(resp.Message)
-	}
-
+func (i *userImpl) UpdateUser(principal *auth.Principal, userId string, user *user.User) error {
 	user.UpdatedAt = time.Now()
 	oUser, err := i.Repository.GetWithId(userId)
 	if err != nil {
-		return errors.New(i.Service.HandleMongoDbError("user", err).Message)
+		return i.Service.MongoDbError("user", err)
 	}
 
-	oUser.UpdateUserDataForAdmin(user, *adminUserId)
+	oUser.UpdateUserDataForAdmin(user, principal.UserId)
 
 	err = i.Repository.Update(oUser)
 	if err != nil {
-		return errors.New(i.Service.HandleMongoDbError("user", err).Message)
+		return i.Service.MongoDbError("user", err)
 	}
 
 	return nil
@@ -84,7 +72,7 @@ func (i *userImpl) UpdateUser(token string, userId string, user *user.User) erro
 func (i *userImpl) GetUser(userId string) (*user.User, error) {
 	nUser, err := i.Repository.GetWithId(userId)
 	if err != nil {
-		return nil, errors.New(i.Service.HandleMongoDbError("user", err).Message)
+		return nil, i.Service.MongoDbError("user", err)
 	}
 
 	return nUser, nil
@@ -105,69 +93,54 @@ func (i *userImpl) GetAllUsers(filter string) (users []user.User, err error) {
 	return users, nil
 }
 
-func (i *userImpl) SuspendUser(token string, userId string) error {
-	adminUserId, resp := i.Manager.Get(token)
-	if resp != nil {
-		return errors.New(resp.Message)
-	}
-
-	err := i.Repository.Suspend(userId, *adminUserId)
+func (i *userImpl) SuspendUser(principal *auth.Principal, userId string) error {
+	err := i.Repository.Suspend(userId, principal.UserId)
 	if err != nil {
-		return errors.New(i.Service.HandleMongoDbError("user", err).Message)
+		return i.Service.MongoDbError("user", err)
 	}
 
 	return nil
 }
 
-func (i *userImpl) SuspendUsers(token string, ids []string) error {
-	adminUserId, resp := i.Manager.Get(token)
-	if resp != nil {
-		return errors.New(resp.Message)
-	}
-
-	if err := i.Repository.SuspendMany(*adminUserId, ids); err != nil {
-		return errors.New(i.Service.HandleMongoDbError("users", err).Message)
+func (i *userImpl) SuspendUsers(principal *auth.Principal, ids []string) error {
+	if err := i.Repository.SuspendMany(principal.UserId, ids); err != nil {
+		return i.Service.MongoDbError("users", err)
 	}
 
 	return nil
 }
 
-func (i *userImpl) RestoreUser(token string, userId string) error {
-	adminUserId, resp := i.Manager.Get(token)
-	if resp != nil {
-		return errors.New(resp.Message)
-	}
-
-	err := i.Repository.Restore(userId, *adminUserId)
+func (i *userImpl) RestoreUser(principal *auth.Principal, userId string) error {
+	err := i.Repository.Restore(userId, principal.UserId)
 	if err != nil {
-		return errors.New(i.Service.HandleMongoDbError("user", err).Message)
+		return i.Service.MongoDbError("user", err)
 	}
 
 	return nil
 }
 
-func (i *userImpl) DeleteUser(token string, userId string) error {
-	adminUserId, resp := i.Manager.Get(token)
-	if resp != nil {
-		return errors.New(resp.Message)
+// UnlockUser lifts the sign in lock on the account and forgets its failed attempts.
+func (i *userImpl) UnlockUser(userId string) error {
+	nUser, err := i.Repository.GetWithId(userId)
+	if err != nil {
+		return i.Service.MongoDbError("user", err)
 	}
 
-	err := i.Repository.Delete(*adminUserId, userId)
+	return i.LimiterUseCase.Clear(limiter.LoginAccount, strings.TrimSpace(nUser.PhoneNumber))
+}
+
+func (i *userImpl) DeleteUser(principal *auth.Principal, userId string) error {
+	err := i.Repository.Delete(principal.UserId, userId)
 	if err != nil {
-		return errors.New(i.Service.HandleMongoDbError("user", err).Message)
+		return i.Service.MongoDbError("user", err)
 	}
 
 	return nil
 }
 
-func (i *userImpl) DeleteUsers(token string, users []string) error {
-	adminUserId, resp := i.Manager.Get(token)
-	if resp != nil {
-		return errors.New(resp.Message)
-	}
-
-	if err := i.Repository.DeleteMany(*adminUserId, users); err != nil {
-		return errors.New(i.Service.HandleMongoDbError("users", err).Message)
+func (i *userImpl) DeleteUsers(principal *auth.Principal, users []string) error {
+	if err := i.Repository.DeleteMany(principal.UserId, users); err != nil {
+		return i.Service.MongoDbError("users", err)
 	}
 
 	return nil
@@ -0,0 +1,86 @@
+package apikey
+
+import (
+	"DX/src/domain/entity/apikey"
+	"DX/src/domain/entity/auth"
+	"DX/src/pkg/error_service"
+	"DX/src/utils/logger"
+	"go.mongodb.org/mongo-driver/mongo"
+	"time"
+)
+
+type impl struct {
+	apikey.Repository
+	error_service.Service
+}
+
+func NewUseCase(repository apikey.Repository, service error_service.Service) UseCase {
+	return &impl{
+		Repository: repository,
+		Service:    service,
+	}
+}
+
+// CreateKey issues a key for the user, the returned key is not stored and cannot be shown again.
+func (i *impl) CreateKey(principal *auth.Principal, request apikey.Request) (*apikey.Created, error) {
+	count, err := i.Repository.CountActive(principal.UserId)
+	if err != nil {
+		return nil, i.Service.MongoDbError("api key", err)
+	}
+	if count >= apikey.MaxKeys {
+		return nil, apikey.ErrTooManyKeys
+	}
+
+	created, err := apikey.New(principal.UserId, request)
+	if err != nil {
+		return nil, err
+	}
+	if err = i.Repository.Create(created.ApiKey); err != nil {
+		return nil, i.Service.MongoDbError("api key", err)
+	}
+	return created, nil
+}
+
+func (i *impl) GetKeys(principal *auth.Principal) ([]apikey.ApiKey, error) {
+	apiKeys, err := i.Repository.GetAll(principal.UserId)
+	if err != nil {
+		return nil, i.Service.MongoDbError("api key", err)
+	}
+	if apiKeys == nil {
+		apiKeys = []apikey.ApiKey{}
+	}
+	return apiKeys, nil
+}
+
+func (i *impl) RevokeKey(principal *auth.Principal, id string) error {
+	err := i.Repository.Revoke(principal.UserId, id)
+	if err == apikey.ErrUnknownKey {
+		return err
+	}
+	if err != nil {
+		return i.Service.MongoDbError("api key", err)
+	}
+	return nil
+}
+
+// Authenticate finds the active key and records that it was used.
+func (i *impl) Authenticate(key string) (*apikey.ApiKey, error) {
+	apiKey, err := i.Repository.GetByHash(apikey.Hash(key))
+	if err == mongo.ErrNoDocuments {
+		return nil, apikey.ErrInvalidKey
+	}
+	if err != nil {
+		return nil, i.Service.MongoDbError("api key", err)
+	}
+	if apiKey.IsRevoked() {
+		return nil, apikey.ErrInvalidKey
+	}
+
+	cTime := time.Now()
+	if apiKey.ShouldTouch(cTime) {
+		if err = i.Repository.Touch(apiKey.Id, cTime); err != nil {
+			logger.Error("unable to record api key use", err)
+		}
+	}
+	return apiKey, nil
+}
@@ -0,0 +1,13 @@
+package apikey
+
+import (
+	"DX/src/domain/entity/apikey"
+	"DX/src/domain/entity/auth"
+)
+
+type UseCase interface {
+	CreateKey(*auth.Principal, apikey.Request) (*apikey.Created, error)
+	GetKeys(*auth.Principal) ([]apikey.ApiKey, error)
+	RevokeKey(*auth.Principal, string) error
+	Authenticate(string) (*apikey.ApiKey, error)
+}
@@ -3,15 +3,28 @@ package authentication
 import (
 	"DX/src/domain/entity"
 	"DX/src/domain/entity/auth"
+	"DX/src/domain/entity/limiter"
 	"DX/src/domain/entity/notification"
+	"DX/src/domain/entity/otp"
+	"DX/src/domain/entity/promo"
+	"DX/src/domain/entity/session"
 	"DX/src/domain/entity/user"
+	limiterUseCase "DX/src/domain/usecase/limiter"
+	otpUseCase "DX/src/domain/usecase/otp"
+	pinUseCase "DX/src/domain/usecase/pin"
+	promoUseCase "DX/src/domain/usecase/promo"
+	sessionUseCase "DX/src/domain/usecase/session"
+	twoFactorUseCase "DX/src/domain/usecase/twofactor"
+	"DX/src/pkg/apperror"
 	"DX/src/pkg/error_service"
 	"DX/src/pkg/password_service"
 	"DX/src/pkg/response"
 	"DX/src/utils"
 	"DX/src/utils/logger"
 	"errors"
-	"fmt"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+	"strings"
 )
 
 type impl struct {
@@ -20,125 +33,294 @@ type impl struct {
 	password         password_service.Service
 	manager          auth.Manager
 	NotificationRepo notification.Repository
+	PinUseCase       pinUseCase.UseCase
+	PromoUseCase     promoUseCase.UseCase
+	SessionUseCase   sessionUseCase.UseCase
+	OtpUseCase       otpUseCase.UseCase
+	LimiterUseCase   limiterUseCase.UseCase
+	TwoFactorUseCase twoFactorUseCase.UseCase
 }
 
 func NewUseCase(repo user.Repository, errorHandler error_service.Service, passwordService password_service.Service, authManager auth.Manager,
-	notificationRepo notification.Repository) UseCase {
+	notificationRepo notification.Repository, pinUseCase pinUseCase.UseCase, promoUseCase promoUseCase.UseCase, sessionUseCase sessionUseCase.UseCase,
+	otpUseCase otpUseCase.UseCase, limiterUseCase limiterUseCase.UseCase, twoFactorUseCase twoFactorUseCase.UseCase) UseCase {
 	return &impl{
 		repository:       repo,
 		Service:          errorHandler,
 		password:         passwordService,
 		manager:          authManager,
 		NotificationRepo: notificationRepo,
+		PinUseCase:       pinUseCase,
+		PromoUseCase:     promoUseCase,
+		SessionUseCase:   sessionUseCase,
+		OtpUseCase:       otpUseCase,
+		LimiterUseCase:   limiterUseCase,
+		TwoFactorUseCase: twoFactorUseCase,
 	}
 }
 
-func (i *impl) ValidatePhoneNumber(phone string) *response.BaseResponse {
+func (i *impl) ValidatePhoneNumber(phone string) error {
 	if !utils.IsValidPhoneNumber(phone) {
-		return response.NewBadRequestError("invalid phone number")
+		return user.ErrInvalidPhone
 	}
-	err := i.repository.Phone(phone)
-	if err != nil {
-		return i.Service.HandleMongoDbError("phone", err)
+	if err := i.repository.Phone(phone); err != nil {
+		return i.Service.MongoDbError("phone", err)
 	}
 
-	return nil
+	return i.OtpUseCase.Send(phone, otp.SignUp)
+}
+
+func (i *impl) ConfirmPhoneNumber(phone, code string) (string, error) {
+	return i.OtpUseCase.Verify(phone, otp.SignUp, code)
 }
 
-func (i *impl) CreateUser(user *user.User) *response.BaseResponse {
-	if err := user.IsValidForInitialCreation(); err != nil {
+func (i *impl) CreateUser(nUser *user.User, info session.Info) error {
+	if err := nUser.IsValidForInitialCreation(); err != nil {
+		return err
+	}
+	if nUser.VerificationTicket == "" {
+		return user.ErrPhoneNotVerified
+	}
+	if nUser.InvitedWith != "" {
+		if _, err := i.repository.GetWithReferralCode(nUser.InvitedWith); err == mongo.ErrNoDocuments {
+			return promo.ErrInvalidReferral
+		} else if err != nil {
+			return i.Service.MongoDbError("user", err)
+		}
+	}
+	// The ticket is redeemed first so two requests with the same ticket cannot both create a user
+	if err := i.OtpUseCase.Redeem(nUser.PhoneNumber, otp.SignUp, nUser.VerificationTicket); err != nil {
 		return err
 	}
 
-	token := auth.NewUserToken()
-	if err := i.manager.CreateToken(token, user.Id.Hex()); err != nil {
+	token := auth.NewUserToken(nUser.DeviceId)
+	if err := tokenError(i.manager.CreateToken(token, nUser.Id.Hex())); err != nil {
 		return err
 	}
-	user.Token = token.AccessToken
-	user.RefreshToken = token.RefreshToken
+	nUser.SetToken(token.AccessToken, token.RefreshToken, token.AccessExpiry())
 
-	hash, err := i.password.Generate(user.Password)
+	hash, err := i.password.Generate(nUser.Password)
 	if err != nil {
-		logger.GetLogger().Error(fmt.Sprintf("error creating user: %v", err))
-		return response.NewInternalServerError("error creating user")
+		return apperror.Unexpected(err)
 	}
-	user.Password = hash
-	user.HasVerifiedPhone = true
+	nUser.Password = hash
+	nUser.HasVerifiedPhone = true
 
-	err = i.repository.Create(user)
+	err = i.repository.Create(nUser)
 	if err != nil {
-		logger.Error(fmt.Sprintf("value: %v", user), err)
-		return i.Service.HandleMongoDbError("user", err)
+		return i.Service.MongoDbError("user", err)
+	}
+	if nUser.InvitedWith != "" {
+		if err = i.PromoUseCase.CreateReferral(nUser.Id.Hex(), nUser.InvitedWith); err != nil {
+			logger.Error("unable to create referral", err)
+		}
+	}
+	info.Client = string(nUser.Client)
+	if err = i.SessionUseCase.Start(token, info); err != nil {
+		// The account is kept, only the sign in is given up so the user can sign in to it
+		i.revokeSignIn(token)
+		return apperror.Wrap(err, apperror.Internal, "sign_in_failed", "your account was created but we couldn't sign you in. please sign in")
 	}
 	return nil
 }
 
-func (i *impl) LoginUser(user *user.User) *response.BaseResponse {
+// LoginUser counts failed attempts per phone number and per IP address. Attempts are delayed after a
+// few failures, and the account is locked and its owner notified once the limit is reached.
+func (i *impl) LoginUser(user *user.User, info session.Info) error {
 	password := user.Password
+	phone := strings.TrimSpace(user.PhoneNumber)
+
+	if err := i.LimiterUseCase.Check(limiter.LoginAccount, phone); err != nil {
+		return err
+	}
+	if err := i.LimiterUseCase.Check(limiter.LoginIp, info.Ip); err != nil {
+		return err
+	}
 
 	err := i.repository.Get(user)
+	if err == mongo.ErrNoDocuments {
+		return i.failLogin(phone, info.Ip, "")
+	}
 	if err != nil {
-		return i.Service.HandleMongoDbError("user", err)
+		return i.Service.MongoDbError("user", err)
 	}
 
 	err = i.password.Compare(user.Password, password)
 	if err != nil {
-		return response.NewBadRequestError("invalid login credentials")
+		return i.failLogin(phone, info.Ip, user.Id.Hex())
+	}
+	if err = i.LimiterUseCase.Clear(limiter.LoginAccount, phone); err != nil {
+		logger.Error("unable to clear failed sign in attempts", err)
 	}
 
-	token := auth.NewUserToken()
-	if err := i.manager.CreateToken(token, user.Id.Hex()); err != nil {
+	enabled, err := i.TwoFactorUseCase.IsEnabled(user.Id.Hex())
+	if err != nil {
 		return err
 	}
-	user.Token = token.AccessToken
-	user.RefreshToken = token.RefreshToken
+	if enabled {
+		challenge, err := i.TwoFactorUseCase.Challenge(user)
+		if err != nil {
+			return err
+		}
+		user.TwoFactorChallenge = challenge
+		return nil
+	}
+	return i.signIn(user, info, false)
+}
 
+// LoginWithTwoFactor completes a sign in held for two-factor authentication.
+func (i *impl) LoginWithTwoFactor(challenge, code string, info session.Info) (*user.User, error) {
+	nChallenge, err := i.TwoFactorUseCase.VerifyChallenge(challenge, code)
+	if err != nil {
+		return nil, err
+	}
+
+	nUser, err := i.repository.GetWithId(nChallenge.UserId)
+	if err != nil {
+		return nil, i.Service.MongoDbError("user", err)
+	}
+	if nUser.IsSuspended || nUser.IsDeleted {
+		return nil, auth.ErrUnauthorized
+	}
+	nUser.DeviceId = nChallenge.DeviceId
+	nUser.Client = user.Client(nChallenge.Client)
+
+	if err = i.signIn(nUser, info, true); err != nil {
+		return nil, err
+	}
+	return nUser, nil
+}
+
+func (i *impl) signIn(user *user.User, info session.Info, twoFactor bool) error {
+	if user.DeviceId != "" {
+		if err := tokenError(i.manager.DeleteDeviceTokens(user.Id.Hex(), user.DeviceId)); err != nil {
+			return err
+		}
+	}
+	token := auth.NewUserToken(user.DeviceId)
+	if err := tokenError(i.manager.CreateToken(token, user.Id.Hex())); err != nil {
+		return err
+	}
+	user.SetToken(token.AccessToken, token.RefreshToken, token.AccessExpiry())
+
+	info.Client = string(user.Client)
+	if err := i.SessionUseCase.Start(token, info); err != nil {
+		return i.abandonSignIn(token, err)
+	}
+	if twoFactor {
+		if err := i.SessionUseCase.PassTwoFactor(token.UserId, token.Family); err != nil {
+			return i.abandonSignIn(token, err)
+		}
+	}
 	return nil
 }
 
-func (i *impl) SuspendUser(token string) *response.BaseResponse {
-	nUser := new(user.User)
-	userId, resp := i.manager.Get(token)
+// abandonSignIn revokes the tokens of a sign in whose session could not be recorded, a session that is not
+// listed could not be seen or revoked by its owner.
+func (i *impl) abandonSignIn(token *auth.Token, err error) error {
+	i.revokeSignIn(token)
+	return apperror.Wrap(err, apperror.Internal, "sign_in_failed", "unable to sign in. please try again")
+}
+
+func (i *impl) revokeSignIn(token *auth.Token) {
+	if resp := i.manager.DeleteFamily(token.Family); resp != nil {
+		logger.Error("unable to revoke tokens", errors.New(resp.Message))
+	}
+}
+
+func (i *impl) failLogin(phone, ip, userId string) error {
+	i.LimiterUseCase.Fail(limiter.LoginIp, ip)
+	if !i.LimiterUseCase.Fail(limiter.LoginAccount, phone) {
+		return user.ErrInvalidCredentials
+	}
+
+	if userId != "" {
+		if err := i.NotificationRepo.SendNotification(notification.NewAccountLockedNotification(userId, limiter.LoginAccount.Lock)); err != nil {
+			logger.Error("unable to send account locked notification", err)
+		}
+	}
+	return limiter.NewLockedError(limiter.LoginAccount.Lock)
+}
+
+// tokenError turns the response of a failed token manager call into an error with the same status and code.
+func tokenError(resp *response.BaseResponse) error {
+	if resp == nil {
+		return nil
+	}
+	var kind apperror.Kind
+	switch resp.Code {
+	case http.StatusBadRequest:
+		kind = apperror.Invalid
+	case http.StatusUnauthorized:
+		kind = apperror.Unauthorized
+	case http.StatusNotFound:
+		kind = apperror.NotFound
+	case http.StatusConflict:
+		kind = apperror.Conflict
+	case http.StatusServiceUnavailable:
+		kind = apperror.Unavailable
+	default:
+		kind = apperror.Internal
+	}
+	code := resp.ErrorCode
+	if code == "" {
+		code = kind.Code()
+	}
+	return apperror.New(kind, code, resp.Message)
+}
+
+func (i *impl) RefreshToken(refreshToken string, info session.Info) (*auth.Token, error) {
+	token, resp := i.manager.Refresh(refreshToken)
 	if resp != nil {
-		return resp
+		return nil, tokenError(resp)
+	}
+
+	nUser, err := i.repository.GetWithId(token.UserId)
+	if err != nil {
+		return nil, i.Service.MongoDbError("user", err)
 	}
-	entId, err := entity.StringToErrandId(*userId)
+	if nUser.IsSuspended || nUser.IsDeleted {
+		if resp = i.manager.DeleteFamily(token.Family); resp != nil {
+			logger.Error("unable to revoke tokens", errors.New(resp.Message))
+		}
+		return nil, auth.ErrUnauthorized
+	}
+	if err = i.SessionUseCase.Touch(token, info); err != nil {
+		logger.Error("unable to update session", err)
+	}
+
+	return token, nil
+}
+
+func (i *impl) SuspendUser(principal *auth.Principal) error {
+	nUser := new(user.User)
+	entId, err := entity.StringToErrandId(principal.UserId)
 	if err != nil {
-		return response.NewBadRequestError(err.Error())
+		return auth.ErrUnauthorized
 	}
 
 	nUser.Id = entId
 	err = i.repository.Get(nUser)
 	if err != nil {
-		return i.Service.HandleMongoDbError("user", err)
+		return i.Service.MongoDbError("user", err)
 	}
 
 	nUser.IsSuspended = true
 
 	err = i.repository.Update(nUser)
 	if err != nil {
-		return i.Service.HandleMongoDbError("user", err)
+		return i.Service.MongoDbError("user", err)
 	}
 
 	return nil
 }
 
-func (i *impl) LogOut(token string) *response.BaseResponse {
-	resp := i.manager.DeleteToken(token)
-	if resp != nil {
-		return resp
-	}
-
-	return nil
+func (i *impl) LogOut(principal *auth.Principal) error {
+	return i.SessionUseCase.End(principal)
 }
 
-func (i *impl) GetUserProfile(token string) (*user.User, error) {
-	userId, resp := i.manager.Get(token)
-	if resp != nil {
-		return nil, errors.New(resp.Message)
-	}
-
-	nUser, err := i.repository.GetWithId(*userId)
+func (i *impl) GetUserProfile(principal *auth.Principal) (*user.User, error) {
+	nUser, err := i.repository.GetWithId(principal.UserId)
 	if err != nil {
 		return nil, err
 	}
@@ -153,45 +335,50 @@ func (i *impl) GetUser(userId string) (*user.User, error) {
 	return nUser, nil
 }
 
-func (i *impl) UpdateUserPassword(phone string, password string) error {
-	nUser, err := i.repository.GetWithPhone(phone)
+// ChangePassword updates the password of a signed in user and signs out every other session.
+func (i *impl) ChangePassword(principal *auth.Principal, currentPassword, newPassword string) error {
+	nUser, err := i.repository.GetWithId(principal.UserId)
 	if err != nil {
-		return errors.New(i.Service.HandleMongoDbError("user", err).Message)
+		return i.Service.MongoDbError("user", err)
+	}
+	if err = i.password.Compare(nUser.Password, currentPassword); err != nil {
+		return user.ErrWrongPassword
 	}
 
-	hash, err := i.password.Generate(password)
+	hash, err := i.password.Generate(newPassword)
 	if err != nil {
-		logger.GetLogger().Error(fmt.Sprintf("error creating user: %v", err))
-		return errors.New("error creating user")
+		logger.Error("unable to hash password", err)
+		return apperror.Wrap(err, apperror.Internal, apperror.Internal.Code(), "error updating password")
 	}
-
 	nUser.Password = hash
-	err = i.repository.Update(nUser)
-	if err != nil {
-		return errors.New(i.Service.HandleMongoDbError("user", err).Message)
+	if err = i.repository.Update(nUser); err != nil {
+		return i.Service.MongoDbError("user", err)
 	}
 
-	return nil
+	return i.SessionUseCase.RevokeAll(principal, true)
 }
 
-func (i *impl) GetNotifications(token string) ([]notification.Notification, error) {
-	userId, resp := i.manager.Get(token)
-	if resp != nil {
-		return nil, errors.New(resp.Message)
-	}
-
-	nNotifications, err := i.NotificationRepo.GetAllNotifications(*userId)
+func (i *impl) GetNotifications(principal *auth.Principal) ([]notification.Notification, error) {
+	nNotifications, err := i.NotificationRepo.GetAllNotifications(principal.UserId)
 	if err != nil {
-		return nil, errors.New(i.Service.HandleMongoDbError("notification", err).Message)
+		return nil, i.Service.MongoDbError("notification", err)
 	}
 
 	return nNotifications, nil
 }
 
-func (i *impl) ResetUserPassword(user *user.User) *response.BaseResponse {
+func (i *impl) AddBankAccount(principal *auth.Principal, account user.Account, transactionPin string) error {
+	if err := i.PinUseCase.Verify(principal.UserId, transactionPin); err != nil {
+		return err
+	}
+
+	if err := i.repository.AddAccount(principal.UserId, account); err != nil {
+		return i.Service.MongoDbError("account", err)
+	}
+
 	return nil
 }
 
-func (i *impl) DeleteUserAccount(user *user.User) *response.BaseResponse {
+func (i *impl) DeleteUserAccount(user *user.User) error {
 	return nil
 }
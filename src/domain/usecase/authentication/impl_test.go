@@ -0,0 +1,101 @@
+package authentication
+
+import (
+	"DX/src/domain/entity"
+	"DX/src/domain/entity/limiter"
+	"DX/src/domain/entity/notification"
+	"DX/src/domain/entity/session"
+	"DX/src/domain/entity/user"
+	limiterUseCase "DX/src/domain/usecase/limiter"
+	"DX/src/pkg/apperror"
+	"DX/src/pkg/error_service"
+	"errors"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+	"testing"
+)
+
+type fakeUserRepository struct {
+	user.Repository
+	err error
+}
+
+func (f fakeUserRepository) Get(nUser *user.User) error {
+	nUser.Id = entity.NewDatabaseId()
+	nUser.Password = "hash"
+	return f.err
+}
+
+type fakePasswordService struct{}
+
+func (fakePasswordService) Generate(password string) (string, error) {
+	return password, nil
+}
+
+func (fakePasswordService) Compare(string, string) error {
+	return errors.New("mismatched hash and password")
+}
+
+// fakeLimiterUseCase locks the account once it has failed lockAfter times.
+type fakeLimiterUseCase struct {
+	limiterUseCase.UseCase
+	lockAfter int
+	failures  int
+}
+
+func (f *fakeLimiterUseCase) Check(limiter.Rule, string) error {
+	return nil
+}
+
+func (f *fakeLimiterUseCase) Fail(rule limiter.Rule, _ string) bool {
+	if rule.Name != limiter.LoginAccount.Name {
+		return false
+	}
+	f.failures++
+	return f.failures >= f.lockAfter
+}
+
+type fakeNotificationRepository struct {
+	notification.Repository
+}
+
+func (fakeNotificationRepository) SendNotification(notification.Notification) error {
+	return nil
+}
+
+func TestLoginUserErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		lookup    error
+		lockAfter int
+		status    int
+		code      string
+	}{
+		{name: "wrong password", lockAfter: 5, status: http.StatusBadRequest, code: "invalid_credentials"},
+		{name: "unknown phone", lookup: mongo.ErrNoDocuments, lockAfter: 5, status: http.StatusBadRequest, code: "invalid_credentials"},
+		{name: "database down", lookup: mongo.CommandError{Labels: []string{"NetworkError"}}, lockAfter: 5, status: http.StatusServiceUnavailable, code: "unavailable"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			useCase := NewUseCase(fakeUserRepository{err: test.lookup}, error_service.New(), fakePasswordService{}, nil,
+				fakeNotificationRepository{}, nil, nil, nil, nil, &fakeLimiterUseCase{lockAfter: test.lockAfter}, nil)
+
+			err := useCase.LoginUser(&user.User{PhoneNumber: "+2348000000000", Password: "guess"}, session.Info{Ip: "203.0.113.7"})
+			if appErr := apperror.From(err); appErr.Status() != test.status || appErr.Code != test.code {
+				t.Fatalf("LoginUser() = %v, want %d %s", err, test.status, test.code)
+			}
+		})
+	}
+}
+
+func TestLoginUserLocksAccount(t *testing.T) {
+	useCase := NewUseCase(fakeUserRepository{}, error_service.New(), fakePasswordService{}, nil,
+		fakeNotificationRepository{}, nil, nil, nil, nil, &fakeLimiterUseCase{lockAfter: 1}, nil)
+
+	err := useCase.LoginUser(&user.User{PhoneNumber: "+2348000000000", Password: "guess"}, session.Info{Ip: "203.0.113.7"})
+	var limitError *limiter.Error
+	if !errors.As(err, &limitError) || limitError.RetryAfter != limiter.LoginAccount.Lock {
+		t.Fatalf("LoginUser() = %v, want the account locked for %s", err, limiter.LoginAccount.Lock)
+	}
+}
@@ -1,21 +1,25 @@
 package authentication
 
 import (
+	"DX/src/domain/entity/auth"
 	"DX/src/domain/entity/notification"
+	"DX/src/domain/entity/session"
 	"DX/src/domain/entity/user"
-	"DX/src/pkg/response"
 )
 
 type UseCase interface {
-	ValidatePhoneNumber(string) *response.BaseResponse
-	CreateUser(*user.User) *response.BaseResponse
-	LoginUser(*user.User) *response.BaseResponse
-	LogOut(string) *response.BaseResponse
-	ResetUserPassword(*user.User) *response.BaseResponse
-	SuspendUser(string) *response.BaseResponse
-	DeleteUserAccount(*user.User) *response.BaseResponse
-	GetUserProfile(string) (*user.User, error)
+	ValidatePhoneNumber(string) error
+	ConfirmPhoneNumber(string, string) (string, error)
+	CreateUser(*user.User, session.Info) error
+	LoginUser(*user.User, session.Info) error
+	LoginWithTwoFactor(string, string, session.Info) (*user.User, error)
+	LogOut(*auth.Principal) error
+	RefreshToken(string, session.Info) (*auth.Token, error)
+	SuspendUser(*auth.Principal) error
+	DeleteUserAccount(*user.User) error
+	GetUserProfile(*auth.Principal) (*user.User, error)
 	GetUser(string) (*user.User, error)
-	UpdateUserPassword(string, string) error
-	GetNotifications(string) ([]notification.Notification, error)
+	ChangePassword(*auth.Principal, string, string) error
+	GetNotifications(*auth.Principal) ([]notification.Notification, error)
+	AddBankAccount(*auth.Principal, user.Account, string) error
 }
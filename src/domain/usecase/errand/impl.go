@@ -6,19 +6,32 @@ import (
 	"DX/src/domain/entity/bid"
 	"DX/src/domain/entity/category"
 	"DX/src/domain/entity/errand"
+	"DX/src/domain/entity/fee"
 	"DX/src/domain/entity/haggle"
 	"DX/src/domain/entity/notification"
+	"DX/src/domain/entity/pin"
+	"DX/src/domain/entity/promo"
+	"DX/src/domain/entity/refund"
 	"DX/src/domain/entity/timeline"
 	"DX/src/domain/entity/user"
 	"DX/src/domain/entity/wallet"
+	"DX/src/domain/entity/webhook"
+	feeUseCase "DX/src/domain/usecase/fee"
+	pinUseCase "DX/src/domain/usecase/pin"
+	promoUseCase "DX/src/domain/usecase/promo"
+	refundUseCase "DX/src/domain/usecase/refund"
+	webhookUseCase "DX/src/domain/usecase/webhook"
+	"DX/src/pkg/apperror"
 	"DX/src/pkg/error_service"
 	"DX/src/utils/logger"
-	"errors"
 	"go.mongodb.org/mongo-driver/mongo"
+	"time"
 )
 
+// payoutRetryDelay leaves a payout that is still being made by the completing request alone.
+const payoutRetryDelay = time.Minute
+
 type impl struct {
-	auth.Manager
 	errand.Repository
 	UserRepo user.Repository
 	error_service.Service
@@ -26,10 +39,14 @@ type impl struct {
 	CategoryRepository category.Repository
 	ErrandRepo         errand.Repository
 	WalletRepo         wallet.Repository
+	PinUseCase         pinUseCase.UseCase
+	FeeUseCase         feeUseCase.UseCase
+	RefundUseCase      refundUseCase.UseCase
+	PromoUseCase       promoUseCase.UseCase
+	WebhookUseCase     webhookUseCase.UseCase
 }
 
 func NewUseCase(
-	manager auth.Manager,
 	repository errand.Repository,
 	userRepo user.Repository,
 	service error_service.Service,
@@ -37,9 +54,13 @@ func NewUseCase(
 	categoryRepository category.Repository,
 	errandRepo errand.Repository,
 	walletRepo wallet.Repository,
+	pinUseCase pinUseCase.UseCase,
+	feeUseCase feeUseCase.UseCase,
+	refundUseCase refundUseCase.UseCase,
+	promoUseCase promoUseCase.UseCase,
+	webhookUseCase webhookUseCase.UseCase,
 ) UseCase {
 	return &impl{
-		Manager:            manager,
 		Repository:         repository,
 		UserRepo:           userRepo,
 		Service:            service,
@@ -47,116 +68,166 @@ func NewUseCase(
 		CategoryRepository: categoryRepository,
 		ErrandRepo:         errandRepo,
 		WalletRepo:         walletRepo,
+		PinUseCase:         pinUseCase,
+		FeeUseCase:         feeUseCase,
+		RefundUseCase:      refundUseCase,
+		PromoUseCase:       promoUseCase,
+		WebhookUseCase:     webhookUseCase,
 	}
 }
 
-func (i *impl) CreateDraftErrand(token string) (*errand.Errand, error) {
-	userId, resp := i.Manager.Get(token)
-	if resp != nil {
-		return nil, errors.New(resp.Message)
-	}
-
-	draft, err := i.Repository.GetDraft(*userId)
+func (i *impl) CreateDraftErrand(principal *auth.Principal) (*errand.Errand, error) {
+	draft, err := i.Repository.GetDraft(principal.UserId)
 	if err != nil {
-		errMessage := i.Service.HandleMongoDbError("errand", err).Message
-		return nil, errors.New(errMessage)
+		return nil, i.Service.MongoDbError("errand", err)
 	}
 
 	if draft == nil {
-		draft = errand.New(*userId)
+		draft = errand.New(principal.UserId)
 		err = i.Repository.Create(draft)
 		if err != nil {
-			return nil, errors.New(i.Service.HandleMongoDbError("errand", err).Message)
+			return nil, i.Service.MongoDbError("errand", err)
 		}
 	}
 
 	return draft, nil
 }
 
-func (i *impl) UpdateErrand(token string, errand *errand.Errand) error {
-	userId, resp := i.Manager.Get(token)
-	if resp != nil {
-		return errors.New(resp.Message)
-	}
-
-	oErrand, err := i.Repository.Get(errand.Id.Hex())
+func (i *impl) UpdateErrand(principal *auth.Principal, nErrand *errand.Errand) error {
+	oErrand, err := i.Repository.Get(nErrand.Id.Hex())
 	if err != nil {
-		return errors.New(i.Service.HandleMongoDbError("errand", err).Message)
+		return i.Service.MongoDbError("errand", err)
 	}
 
-	if *userId != oErrand.UserId {
-		return errors.New("user not authorized to update errand")
+	if principal.UserId != oErrand.UserId {
+		return errand.ErrUpdateForbidden
 	}
 
-	oErrand.Update(errand)
+	oErrand.Update(nErrand)
 
 	err = i.Repository.Update(oErrand)
 	if err != nil {
-		return errors.New(i.Service.HandleMongoDbError("errand", err).Message)
+		return i.Service.MongoDbError("errand", err)
 	}
 
 	return nil
 }
 
-func (i *impl) CreateErrand(token string, errandId string, nErrand *errand.Errand) error {
-	userId, resp := i.Manager.Get(token)
-	if resp != nil {
-		return errors.New(resp.Message)
-	}
-
+func (i *impl) CreateErrand(principal *auth.Principal, errandId string, nErrand *errand.Errand, transactionPin, promoCode string) error {
 	oErrand, err := i.Repository.Get(errandId)
 	if err != nil {
-		return errors.New(i.Service.HandleMongoDbError("errand", err).Message)
+		return i.Service.MongoDbError("errand", err)
 	}
 
-	if *userId != oErrand.UserId {
-		return errors.New("user not authorized to create this errand")
+	if principal.UserId != oErrand.UserId {
+		return errand.ErrCreateForbidden
 	}
 
 	if !oErrand.CanBeUpdated() {
-		return errors.New("errand can't be updated")
+		return errand.ErrNotEditable
 	}
-	if balance, err := i.WalletRepo.GetBalance(*userId); err != nil {
-		return errors.New(i.Service.HandleMongoDbError("wallet", err).Message)
-	} else {
-		if nErrand.Budget > balance {
-			return errors.New("insufficient funds. kindly top up your wallet")
+	if nErrand.Budget >= pin.LargeBudgetThreshold {
+		if err = i.PinUseCase.Verify(principal.UserId, transactionPin); err != nil {
+			return err
 		}
 	}
-
 	if nCategory, err := i.CategoryRepository.Get(nErrand.Category.Id.Hex()); err != nil {
-		return errors.New(i.Service.HandleMongoDbError("category", err).Message)
+		return i.Service.MongoDbError("category", err)
 	} else {
 		if nErrand.DropOffAddress == nil && nCategory.Type == "task" {
-			return errors.New("drop-off location is required for tasks")
+			return errand.ErrDropOffRequired
 		}
 		nErrand.Category = nCategory
 	}
-	nErrand.UpdateForCreation(entity.CreatedByUser(*userId))
+
+	serviceFee, err := i.FeeUseCase.Quote(fee.ServiceFee, nErrand.Category.Id.Hex(), nErrand.Budget)
+	if err != nil {
+		return err
+	}
+	nErrand.Fees = fee.NewBreakdown(nErrand.Budget, serviceFee)
+
+	var nPromo *promo.Promo
+	if promoCode != "" {
+		var discount int64
+		if nPromo, discount, err = i.PromoUseCase.Quote(principal.UserId, promoCode, nErrand.Category.Id.Hex(), nErrand.Fees.TotalCharged); err != nil {
+			return err
+		}
+		nErrand.Fees.ApplyDiscount(nPromo.Code, discount)
+	}
+
+	balance, err := i.WalletRepo.GetBalance(principal.UserId)
+	if err != nil {
+		return i.Service.MongoDbError("wallet", err)
+	}
+	promoBalance, err := i.WalletRepo.GetPromoBalance(principal.UserId)
+	if err != nil {
+		return i.Service.MongoDbError("wallet", err)
+	}
+	if nErrand.Fees.TotalCharged > balance+promoBalance {
+		return wallet.ErrInsufficientFunds
+	}
+	// Promotional credit is spent before cash.
+	nErrand.Fees.PromoCredit = promoBalance
+	if nErrand.Fees.PromoCredit > nErrand.Fees.TotalCharged {
+		nErrand.Fees.PromoCredit = nErrand.Fees.TotalCharged
+	}
+	// The promo is claimed before the money moves so its limits hold, it is released when the transfer fails.
+	if nPromo != nil {
+		if err = i.PromoUseCase.Redeem(nPromo, principal.UserId, errandId, nErrand.Fees.Discount); err != nil {
+			return err
+		}
+	}
+
+	nErrand.UpdateForCreation(entity.CreatedByUser(principal.UserId))
 	nErrand.UserId = oErrand.UserId
 	nErrand.Id = oErrand.Id
 	nErrand.CreatedAt = oErrand.CreatedAt
 	nErrand.Timeline = timeline.NewTimeline(errandId)
 
-	debitTxn := wallet.NewDebitTransaction(*userId, "Errand creation", nErrand.Id.Hex(), nErrand.Budget)
-	if err = i.WalletRepo.CreateTransaction(debitTxn); err != nil {
-		return errors.New(i.Service.HandleMongoDbError("wallet", err).Message)
+	// Every leg goes through one transfer, it re-checks the balance with the wallet locked so two errands
+	// created at once can not overdraw it, and the keyed payment fails the second creation of the same errand.
+	txns := []wallet.Transaction{
+		wallet.NewErrandPaymentTransaction(principal.UserId, "Errand creation", nErrand.Id.Hex(), nErrand.Budget),
+	}
+	if serviceFee.Amount > 0 {
+		txns = append(txns,
+			wallet.NewDebitTransaction(principal.UserId, "Errand service fee", nErrand.Id.Hex(), serviceFee.Amount, wallet.ServiceFee),
+			wallet.NewCreditTransaction(wallet.PlatformAccountId, "Errand service fee", nErrand.Id.Hex(), serviceFee.Amount, wallet.ServiceFee),
+		)
+	}
+	if nErrand.Fees.Discount > 0 {
+		txns = append(txns,
+			wallet.NewCreditTransaction(principal.UserId, "Promo discount", nErrand.Id.Hex(), nErrand.Fees.Discount, wallet.PromoDiscount),
+			wallet.NewDebitTransaction(wallet.PlatformAccountId, "Promo discount", nErrand.Id.Hex(), nErrand.Fees.Discount, wallet.PromoDiscount),
+		)
+	}
+	if nErrand.Fees.PromoCredit > 0 {
+		txns = append(txns,
+			wallet.NewPromoDebitTransaction(principal.UserId, "Promo credit applied", nErrand.Id.Hex(), nErrand.Fees.PromoCredit, wallet.PromoCredit),
+			wallet.NewCreditTransaction(principal.UserId, "Promo credit applied", nErrand.Id.Hex(), nErrand.Fees.PromoCredit, wallet.PromoCredit),
+		)
+	}
+	if err = i.WalletRepo.Transfer(txns...); err != nil {
+		if nPromo != nil {
+			if _, releaseErr := i.PromoUseCase.Release(errandId); releaseErr != nil {
+				logger.Error("unable to release promo of an errand that was not paid for", releaseErr)
+			}
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			return errand.ErrNotEditable
+		}
+		return i.Service.MongoDbError("wallet", err)
 	}
 
 	err = i.Repository.Update(nErrand)
 	if err != nil {
-		return errors.New(i.Service.HandleMongoDbError("nErrand", err).Message)
+		return i.Service.MongoDbError("nErrand", err)
 	}
 
 	return nil
 }
 
-func (i *impl) GetErrand(token string, errandId string) (*errand.Errand, error) {
-	_, resp := i.Manager.Get(token)
-	if resp != nil {
-		return nil, errors.New(resp.Message)
-	}
-
+func (i *impl) GetErrand(principal *auth.Principal, errandId string) (*errand.Errand, error) {
 	nErrand, err := i.Repository.Get(errandId)
 	if err != nil {
 		return nil, err
@@ -165,123 +236,130 @@ func (i *impl) GetErrand(token string, errandId string) (*errand.Errand, error)
 	return nErrand, nil
 }
 
-func (i *impl) AcceptBid(token, errandId, bidId, runnerId string, amount float64) error {
-	userId, resp := i.Manager.Get(token)
-	if resp != nil {
-		return errors.New(resp.Message)
-	}
-
+func (i *impl) AcceptBid(principal *auth.Principal, errandId, bidId, runnerId string, amount float64) error {
 	nErrand, err := i.Repository.Get(errandId)
 	if err != nil {
-		return errors.New(i.Service.HandleMongoDbError("errand", err).Message)
+		return i.Service.MongoDbError("errand", err)
 	}
 	// Check if user can accept bid
-	if nErrand.UserId != *userId {
-		return errors.New("user not authorized to accept bid")
+	if nErrand.UserId != principal.UserId {
+		return errand.ErrAcceptBidForbidden
 	}
 	if _, err = nErrand.IsValidBidAndRunner(bidId, runnerId); err != nil {
 		return err
 	}
 	if nErrand.HasAcceptedBid() {
-		return errors.New("user already accepted a bid for this errand")
+		return errand.ErrBidAlreadyAccepted
 	}
 
 	timelineMessage := "Bid accepted"
 	update := timeline.NewUpdate(timelineMessage, timeline.BidAccepted, entity.Sender.Id())
-	err = i.Repository.AcceptBid(errandId, bidId, *userId, int64(amount), update)
+	err = i.Repository.AcceptBid(errandId, bidId, principal.UserId, int64(amount), update)
 	if err != nil {
-		return errors.New(i.Service.HandleMongoDbError("errand", err).Message)
+		return i.Service.MongoDbError("errand", err)
 	}
 
-	bidNotification := notification.NewBidAcceptedNotification(*userId, bidId)
+	bidNotification := notification.NewBidAcceptedNotification(principal.UserId, bidId)
 	err = i.NotificationRepo.SendNotification(bidNotification)
 	if err != nil {
 		logger.Error("Failed to send notifications", err)
 	}
+	go i.publish(webhook.BidAccepted, nErrand.UserId, webhook.ErrandData{
+		ErrandId: errandId,
+		Status:   errand.Pending.Id(),
+		RunnerId: runnerId,
+		BidId:    bidId,
+		Amount:   int64(amount),
+	})
 
 	return nil
 }
 
-func (i *impl) RejectBid(token, errandId, bidId string) error {
-	userId, resp := i.Manager.Get(token)
-	if resp != nil {
-		return errors.New(resp.Message)
-	}
-
+func (i *impl) RejectBid(principal *auth.Principal, errandId, bidId string) error {
 	nErrand, err := i.Repository.Get(errandId)
 	if err != nil {
-		return errors.New(i.Service.HandleMongoDbError("errand", err).Message)
+		return i.Service.MongoDbError("errand", err)
 	}
 	// Check if user can accept bid
-	if nErrand.UserId != *userId {
-		return errors.New("user not authorized to reject bid")
+	if nErrand.UserId != principal.UserId {
+		return errand.ErrRejectBidForbidden
 	}
 
 	err = i.Repository.RejectBid(errandId, bidId)
 	if err != nil {
-		return errors.New(i.Service.HandleMongoDbError("bid", err).Message)
+		return i.Service.MongoDbError("bid", err)
 	}
 
 	return nil
 }
 
-func (i *impl) CancelErrand(token, errandId, reason string) error {
-	userId, resp := i.Manager.Get(token)
-	if resp != nil {
-		return errors.New(resp.Message)
-	}
-
+// CancelErrand cancels the errand only from the state it was read in and refunds it after, so concurrent
+// cancellations or a completion racing it can't both go through. When the refund fails the errand is put
+// back so the cancellation can be retried.
+func (i *impl) CancelErrand(principal *auth.Principal, errandId, reason string) error {
 	oErrand, err := i.Repository.Get(errandId)
 	if err != nil {
-		return errors.New(i.Service.HandleMongoDbError("errand", err).Message)
+		return i.Service.MongoDbError("errand", err)
 	}
 
-	if oErrand.UserId != *userId {
-		return errors.New("user not authorized to cancel errand")
+	if oErrand.UserId != principal.UserId {
+		return errand.ErrCancelForbidden
+	}
+	if _, err = refund.ForCancellation(oErrand, principal.UserId); err != nil {
+		return err
 	}
 
-	oErrand.Cancel(*userId, reason)
-	err = i.Repository.Update(oErrand)
-	if err != nil {
-		return errors.New(i.Service.HandleMongoDbError("errand", err).Message)
+	if err = i.Repository.Cancel(errandId, principal.UserId, reason, oErrand.State); err != nil {
+		return i.Service.MongoDbError("errand", err)
+	}
+
+	if _, err = i.RefundUseCase.RefundCancellation(oErrand, principal.UserId); err != nil {
+		logger.Error("unable to refund cancelled errand", err)
+		if restoreErr := i.Repository.RestoreCancelled(errandId, oErrand.State); restoreErr != nil {
+			logger.Error("unable to restore errand after a failed refund", restoreErr)
+		}
+		return apperror.Wrap(err, apperror.Internal, "refund_failed", "errand could not be cancelled, please try again")
 	}
 
 	return nil
 }
 
-func (i *impl) CompleteErrand(token, errandId, source string) error {
-	userId, resp := i.Manager.Get(token)
-	if resp != nil {
-		return errors.New(resp.Message)
-	}
-
+func (i *impl) CompleteErrand(principal *auth.Principal, errandId, source string) error {
 	oErrand, err := i.Repository.Get(errandId)
 	if err != nil {
-		return errors.New(i.Service.HandleMongoDbError("errand", err).Message)
+		return i.Service.MongoDbError("errand", err)
 	}
 
 	if source == "sender" {
-		if oErrand.UserId != *userId {
-			return errors.New("user not authorized to complete errand")
+		if oErrand.UserId != principal.UserId {
+			return errand.ErrCompleteForbidden
 		}
-		if err = i.Repository.SenderComplete(errandId, *userId); err != nil {
-			return errors.New(i.Service.HandleMongoDbError("errand", err).Message)
+		if err = i.Repository.SenderComplete(errandId, principal.UserId); err != nil {
+			return i.Service.MongoDbError("errand", err)
 		} else {
 			if err = i.UserRepo.CompleteErrand(oErrand.RunnerId); err != nil {
-				return errors.New(i.Service.HandleMongoDbError("user", err).Message)
+				return i.Service.MongoDbError("user", err)
 			}
 		}
-		txn := wallet.NewCreditTransaction(oErrand.RunnerId, "Completed errand", oErrand.Id.Hex(), oErrand.Amount)
-		if err = i.WalletRepo.CreateTransaction(txn); err != nil {
-			return errors.New(i.Service.HandleMongoDbError("wallet", err).Message)
+		// The errand stays pending payout until the runner is paid, a failure here is retried by PayPendingRunners.
+		if err = i.payRunner(oErrand); err != nil {
+			logger.Error("unable to pay runner, the payout will be retried", err)
 		}
+		go i.PromoUseCase.RewardReferral(oErrand.UserId, errandId)
+		go i.PromoUseCase.RewardReferral(oErrand.RunnerId, errandId)
 		go i.sendNotification(notification.NewSenderErrandCompletedNotification(oErrand.RunnerId, errandId))
+		go i.publish(webhook.ErrandCompleted, oErrand.UserId, webhook.ErrandData{
+			ErrandId: errandId,
+			Status:   errand.Completed.Id(),
+			RunnerId: oErrand.RunnerId,
+			Amount:   oErrand.Amount,
+		})
 	} else {
-		if oErrand.RunnerId != *userId {
-			return errors.New("user not authorized to complete errand")
+		if oErrand.RunnerId != principal.UserId {
+			return errand.ErrCompleteForbidden
 		}
-		if err = i.Repository.RunnerComplete(errandId, *userId); err != nil {
-			return errors.New(i.Service.HandleMongoDbError("errand", err).Message)
+		if err = i.Repository.RunnerComplete(errandId, principal.UserId); err != nil {
+			return i.Service.MongoDbError("errand", err)
 		}
 		go i.sendNotification(notification.NewRunnerErrandCompletedNotification(oErrand.UserId, errandId))
 	}
@@ -290,70 +368,61 @@ func (i *impl) CompleteErrand(token, errandId, source string) error {
 	return nil
 }
 
-func (i *impl) AcceptContract(token, errandId, bidId string) error {
-	userId, resp := i.Manager.Get(token)
-	if resp != nil {
-		return errors.New(resp.Message)
-	}
-
+func (i *impl) AcceptContract(principal *auth.Principal, errandId, bidId string) error {
 	oErrand, err := i.Repository.Get(errandId)
 	if err != nil {
-		return errors.New(i.Service.HandleMongoDbError("errand", err).Message)
+		return i.Service.MongoDbError("errand", err)
 	}
 
 	if !oErrand.HasAcceptedBid() {
-		return errors.New("can't accept contract for errand with no accepted bid")
+		return errand.ErrAcceptWithoutBid
 	}
-	if _, err = oErrand.IsValidBidAndRunner(bidId, *userId); err != nil {
+	if _, err = oErrand.IsValidBidAndRunner(bidId, principal.UserId); err != nil {
 		return err
 	}
 
 	update := timeline.NewUpdate("Errand contract accepted", timeline.ErrandStarted, entity.Runner.Id())
-	err = i.Repository.StartErrand(errandId, *userId, update)
+	err = i.Repository.StartErrand(errandId, principal.UserId, update)
 	if err != nil {
-		logger.Error("unable to start errand", err)
-		return errors.New("unable to start errand")
+		return i.Service.MongoDbError("errand", err)
 	}
 
 	go i.sendNotification(notification.NewErrandStartedNotification(oErrand.UserId, errandId))
+	go i.publish(webhook.ErrandStarted, oErrand.UserId, webhook.ErrandData{
+		ErrandId: errandId,
+		Status:   errand.Active.Id(),
+		RunnerId: principal.UserId,
+		BidId:    bidId,
+		Amount:   oErrand.Amount,
+	})
 
 	return nil
 }
 
-func (i *impl) RejectContract(token string, errandId string, bidId string) error {
-	userId, resp := i.Manager.Get(token)
-	if resp != nil {
-		return errors.New(resp.Message)
-	}
-
+func (i *impl) RejectContract(principal *auth.Principal, errandId string, bidId string) error {
 	oErrand, err := i.Repository.Get(errandId)
 	if err != nil {
-		return errors.New(i.Service.HandleMongoDbError("errand", err).Message)
+		return i.Service.MongoDbError("errand", err)
 	}
 
 	if !oErrand.HasAcceptedBid() {
-		return errors.New("can't reject contract for errand with no accepted bid")
+		return errand.ErrRejectWithoutBid
 	}
-	if _, err = oErrand.IsValidBidAndRunner(bidId, *userId); err != nil {
+	if _, err = oErrand.IsValidBidAndRunner(bidId, principal.UserId); err != nil {
 		return err
 	}
 
-	err = i.Repository.ResetErrandBids(errandId, *userId)
+	err = i.Repository.ResetErrandBids(errandId, principal.UserId)
 	if err != nil {
-		return errors.New(i.Service.HandleMongoDbError("bid", err).Message)
+		return i.Service.MongoDbError("bid", err)
 	}
 	go i.sendNotification(notification.NewBidProposalRejectedNotification(oErrand.UserId, errandId))
 
 	return nil
 }
 
-func (i *impl) GetAllErrands(token string) ([]errand.Errand, error) {
+func (i *impl) GetAllErrands(principal *auth.Principal) ([]errand.Errand, error) {
 	// TODO Use the id to optimize marketplace response sent to user
-	_, resp := i.Manager.Get(token)
-	if resp != nil {
-		return nil, errors.New(resp.Message)
-	}
-
 	errands, err := i.Repository.GetAllMarketErrands()
 	if err != nil {
 		return nil, err
@@ -362,13 +431,8 @@ func (i *impl) GetAllErrands(token string) ([]errand.Errand, error) {
 	return errands, nil
 }
 
-func (i *impl) GetErrandsFor(token string) ([]errand.Errand, error) {
-	userId, resp := i.Manager.Get(token)
-	if resp != nil {
-		return nil, errors.New(resp.Message)
-	}
-
-	errands, err := i.Repository.GetFor(*userId)
+func (i *impl) GetErrandsFor(principal *auth.Principal) ([]errand.Errand, error) {
+	errands, err := i.Repository.GetFor(principal.UserId)
 	if err != nil {
 		return nil, err
 	}
@@ -376,40 +440,35 @@ func (i *impl) GetErrandsFor(token string) ([]errand.Errand, error) {
 	return errands, nil
 }
 
-func (i *impl) BidForErrand(token string, bid *bid.Bid, haggle *haggle.Haggle) error {
+func (i *impl) BidForErrand(principal *auth.Principal, bid *bid.Bid, haggle *haggle.Haggle) error {
 	// Get user id
-	userId, resp := i.Manager.Get(token)
-	if resp != nil {
-		return errors.New(resp.Message)
-	}
-
 	// Get errand
 	nErrand, err := i.Repository.Get(bid.ErrandId)
 	if err != nil {
-		return errors.New(i.Service.HandleMongoDbError("errand", err).Message)
+		return i.Service.MongoDbError("errand", err)
 	}
 	// Check if this is the errand creator
-	if nErrand.UserId == *userId {
-		return errors.New("sender not allowed to bid for own errand")
+	if nErrand.UserId == principal.UserId {
+		return errand.ErrOwnErrand
 	}
 	if !nErrand.CanBeBiddedFor() {
-		return errors.New("errand no longer available for bidding")
+		return errand.ErrNotBiddable
 	}
 
 	// TODO Check if runner is qualified to bid for the errand
 	// Check if runner has an existing bid for this errand
-	err = i.Repository.GetBidForUser(bid.ErrandId, *userId)
+	err = i.Repository.GetBidForUser(bid.ErrandId, principal.UserId)
 	// The query should return mongo.ErrNoDocuments
 	if err != mongo.ErrNoDocuments {
-		return errors.New("user already has a active bid for errand")
+		return errand.ErrActiveBid
 	}
 
 	// Create bid for errand
 	bid.Haggles = append(bid.Haggles, *haggle)
-	bid.Runner = *userId
-	err = i.Repository.AddBidToErrand(bid.ErrandId, *userId, bid)
+	bid.Runner = principal.UserId
+	err = i.Repository.AddBidToErrand(bid.ErrandId, principal.UserId, bid)
 	if err != nil {
-		return errors.New(i.HandleMongoDbError("bid", err).Message)
+		return i.MongoDbError("bid", err)
 	}
 
 	err = i.NotificationRepo.SendNotification(notification.NewBidNotification(nErrand.UserId, nErrand.Id.Hex()))
@@ -420,24 +479,19 @@ func (i *impl) BidForErrand(token string, bid *bid.Bid, haggle *haggle.Haggle) e
 	return nil
 }
 
-func (i *impl) UpdateErrandBid(token string, errandId string, bidId string, haggle *haggle.Haggle) error {
+func (i *impl) UpdateErrandBid(principal *auth.Principal, errandId string, bidId string, haggle *haggle.Haggle) error {
 	var runnerId string
-	userId, resp := i.Manager.Get(token)
-	if resp != nil {
-		return errors.New(resp.Message)
-	}
-
 	nErrand, err := i.Repository.Get(errandId)
 	if err != nil {
-		return errors.New(i.Service.HandleMongoDbError("errand", err).Message)
+		return i.Service.MongoDbError("errand", err)
 	}
 
 	if haggle.FromSender() {
-		if nErrand.UserId != *userId {
-			return errors.New("sender not authorized to update this bid")
+		if nErrand.UserId != principal.UserId {
+			return errand.ErrBidForbidden
 		}
 	} else {
-		if cBid, err := nErrand.IsValidBidAndRunner(bidId, *userId); err != nil {
+		if cBid, err := nErrand.IsValidBidAndRunner(bidId, principal.UserId); err != nil {
 			return err
 		} else {
 			runnerId = cBid.Runner
@@ -446,7 +500,7 @@ func (i *impl) UpdateErrandBid(token string, errandId string, bidId string, hagg
 
 	err = i.Repository.UpdateBidHaggle(errandId, bidId, haggle)
 	if err != nil {
-		return errors.New(i.Service.HandleMongoDbError("haggle", err).Message)
+		return i.Service.MongoDbError("haggle", err)
 	}
 
 	if haggle.FromSender() {
@@ -461,28 +515,23 @@ func (i *impl) UpdateErrandBid(token string, errandId string, bidId string, hagg
 	return nil
 }
 
-func (i *impl) RequestErrandTimelineUpdate(token string, errandId string) error {
-	userId, resp := i.Manager.Get(token)
-	if resp != nil {
-		return errors.New(resp.Message)
-	}
-
+func (i *impl) RequestErrandTimelineUpdate(principal *auth.Principal, errandId string) error {
 	nErrand, err := i.Repository.Get(errandId)
 	if err != nil {
-		return errors.New(i.Service.HandleMongoDbError("errand", err).Message)
+		return i.Service.MongoDbError("errand", err)
 	}
 
-	if nErrand.UserId != *userId {
-		return errors.New("user not authorized to request update for this errand")
+	if nErrand.UserId != principal.UserId {
+		return errand.ErrRequestUpdateForbidden
 	}
 	if !nErrand.InProgress() {
-		return errors.New("errand not in progress")
+		return errand.ErrNotInProgress
 	}
 
 	timelineMessage := "Update request"
-	err = i.Repository.UpdateTimeline(errandId, *userId, timeline.NewUpdate(timelineMessage, timeline.SenderRequest, entity.Sender.Id()))
+	err = i.Repository.UpdateTimeline(errandId, principal.UserId, timeline.NewUpdate(timelineMessage, timeline.SenderRequest, entity.Sender.Id()))
 	if err != nil {
-		return errors.New(i.Service.HandleMongoDbError("timeline", err).Message)
+		return i.Service.MongoDbError("timeline", err)
 	}
 
 	// TODO update for other notification types
@@ -496,24 +545,19 @@ func (i *impl) RequestErrandTimelineUpdate(token string, errandId string) error
 	return nil
 }
 
-func (i *impl) UpdateErrandTimeline(token, errandId, message string) error {
-	userId, resp := i.Manager.Get(token)
-	if resp != nil {
-		return errors.New(resp.Message)
-	}
-
+func (i *impl) UpdateErrandTimeline(principal *auth.Principal, errandId, message string) error {
 	nErrand, err := i.Repository.Get(errandId)
 	if err != nil {
-		return errors.New(i.Service.HandleMongoDbError("errand", err).Message)
+		return i.Service.MongoDbError("errand", err)
 	}
 
-	if nErrand.RunnerId != *userId {
-		return errors.New("runner not authorized to update this errand timeline")
+	if nErrand.RunnerId != principal.UserId {
+		return errand.ErrTimelineForbidden
 	}
 
-	err = i.Repository.UpdateTimeline(errandId, *userId, timeline.NewUpdate(message, timeline.RunnerUpdate, entity.Runner.Id()))
+	err = i.Repository.UpdateTimeline(errandId, principal.UserId, timeline.NewUpdate(message, timeline.RunnerUpdate, entity.Runner.Id()))
 	if err != nil {
-		return errors.New(i.Service.HandleMongoDbError("timeline", err).Message)
+		return i.Service.MongoDbError("timeline", err)
 	}
 
 	title := "Errand timeline update"
@@ -526,37 +570,132 @@ func (i *impl) UpdateErrandTimeline(token, errandId, message string) error {
 	return nil
 }
 
-func (i *impl) RateUser(token, runnerId, errandId string, rating int64) error {
-	userId, resp := i.Manager.Get(token)
-	if resp != nil {
-		return errors.New(resp.Message)
-	}
-
+func (i *impl) RateUser(principal *auth.Principal, runnerId, errandId string, rating int64) error {
 	oErrand, err := i.ErrandRepo.Get(errandId)
 	if err != nil {
-		return errors.New(i.Service.HandleMongoDbError("errand", err).Message)
+		return i.Service.MongoDbError("errand", err)
 	}
 
-	if oErrand.UserId != *userId {
-		return errors.New("user not authorized to update errand")
+	if oErrand.UserId != principal.UserId {
+		return errand.ErrUpdateForbidden
 	}
 	if !oErrand.IsCompleted() {
-		return errors.New("can only rate user for a completed errand")
+		return errand.ErrNotRateable
 	}
 	if oErrand.RunnerId != runnerId {
-		return errors.New("invalid runner id")
+		return errand.ErrInvalidRunner
 	}
 
 	if err = i.UserRepo.RateUser(runnerId, rating); err != nil {
-		return errors.New(i.Service.HandleMongoDbError("user", err).Message)
+		return i.Service.MongoDbError("user", err)
+	}
+
+	return nil
+}
+
+// TipRunner moves a one-off tip from the sender to the runner of a completed errand. The tip is recorded on
+// the errand before any money moves, so only one tip can get through.
+func (i *impl) TipRunner(principal *auth.Principal, errandId string, amount int64, transactionPin string) error {
+	if amount <= 0 {
+		return errand.ErrInvalidTip
+	}
+
+	oErrand, err := i.Repository.Get(errandId)
+	if err != nil {
+		return i.Service.MongoDbError("errand", err)
+	}
+	if oErrand.UserId != principal.UserId {
+		return errand.ErrTipForbidden
+	}
+	if err = oErrand.CanBeTipped(); err != nil {
+		return err
+	}
+	if amount >= pin.LargeBudgetThreshold {
+		if err = i.PinUseCase.Verify(principal.UserId, transactionPin); err != nil {
+			return err
+		}
+	}
+
+	var fees *fee.Breakdown
+	if oErrand.Fees == nil {
+		fees = fee.NewBreakdown(oErrand.Budget, fee.Charge{Kind: fee.ServiceFee})
+	}
+	if err = i.Repository.Tip(errandId, amount, fees); err != nil {
+		return i.Service.MongoDbError("errand", err)
+	}
+
+	debitTxn := wallet.NewDebitTransaction(principal.UserId, "Errand tip", errandId, amount, wallet.Tip)
+	creditTxn := wallet.NewCreditTransaction(oErrand.RunnerId, "Errand tip", errandId, amount, wallet.Tip)
+	if err = i.WalletRepo.Transfer(debitTxn, creditTxn); err != nil {
+		if clearErr := i.Repository.ClearTip(errandId, amount); clearErr != nil {
+			logger.Error("unable to clear errand tip", clearErr)
+		}
+		return i.Service.MongoDbError("wallet", err)
+	}
+
+	go i.sendNotification(notification.NewTipNotification(oErrand.RunnerId, errandId, amount))
+
+	return nil
+}
+
+// payRunner credits the runner with the agreed amount less the platform commission and posts the commission to the platform account.
+func (i *impl) payRunner(oErrand *errand.Errand) error {
+	var categoryId string
+	if oErrand.Category != nil {
+		categoryId = oErrand.Category.Id.Hex()
+	}
+	commission, err := i.FeeUseCase.Quote(fee.Commission, categoryId, oErrand.Amount)
+	if err != nil {
+		return err
+	}
+
+	// The payout is keyed on the errand, a retry of an errand that was already paid fails as a duplicate
+	// and only the fees are saved again.
+	txns := []wallet.Transaction{
+		wallet.NewPayoutTransaction(oErrand.RunnerId, "Completed errand", oErrand.Id.Hex(), oErrand.Amount, commission.Amount),
+	}
+	if commission.Amount > 0 {
+		txns = append(txns, wallet.NewCreditTransaction(wallet.PlatformAccountId, "Errand commission", oErrand.Id.Hex(), commission.Amount, wallet.Commission))
+	}
+	if err = i.WalletRepo.Transfer(txns...); err != nil && !mongo.IsDuplicateKeyError(err) {
+		return i.Service.MongoDbError("wallet", err)
+	}
+
+	fees := oErrand.Fees
+	if fees == nil {
+		fees = fee.NewBreakdown(oErrand.Budget, fee.Charge{Kind: fee.ServiceFee})
+	}
+	fees.AddPayout(oErrand.Amount, commission)
+	if err = i.Repository.SetPayout(oErrand.Id.Hex(), fees); err != nil {
+		return i.Service.MongoDbError("errand", err)
 	}
 
 	return nil
 }
 
+// PayPendingRunners pays the runners of errands that were completed but whose payout failed.
+func (i *impl) PayPendingRunners() {
+	errands, err := i.Repository.GetPendingPayouts(time.Now().Add(-payoutRetryDelay))
+	if err != nil {
+		logger.Error("fetching errands with pending payouts", err)
+		return
+	}
+	for n := range errands {
+		if err = i.payRunner(&errands[n]); err != nil {
+			logger.Error("unable to pay runner of errand "+errands[n].Id.Hex(), err)
+		}
+	}
+}
+
 func (i *impl) sendNotification(notification notification.Notification) {
 	err := i.NotificationRepo.SendNotification(notification)
 	if err != nil {
 		logger.Error("Failed to send notifications", err)
 	}
 }
+
+func (i *impl) publish(event webhook.Event, userId string, data webhook.ErrandData) {
+	if err := i.WebhookUseCase.Publish(userId, event, data); err != nil {
+		logger.Error("Failed to publish webhook event", err)
+	}
+}
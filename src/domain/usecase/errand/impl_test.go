@@ -0,0 +1,517 @@
+package errand
+
+import (
+	"DX/src/domain/entity"
+	"DX/src/domain/entity/auth"
+	"DX/src/domain/entity/category"
+	"DX/src/domain/entity/errand"
+	"DX/src/domain/entity/fee"
+	"DX/src/domain/entity/notification"
+	"DX/src/domain/entity/promo"
+	"DX/src/domain/entity/refund"
+	"DX/src/domain/entity/user"
+	"DX/src/domain/entity/wallet"
+	"DX/src/domain/entity/webhook"
+	promoUseCase "DX/src/domain/usecase/promo"
+	refundUseCase "DX/src/domain/usecase/refund"
+	webhookUseCase "DX/src/domain/usecase/webhook"
+	"DX/src/pkg/apperror"
+	"DX/src/pkg/error_service"
+	"errors"
+	"go.mongodb.org/mongo-driver/mongo"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeErrandRepository struct {
+	errand.Repository
+	mutex   sync.Mutex
+	errands map[string]errand.Errand
+	// beforeCancel runs between reading the errand and cancelling it, like a request racing the cancellation
+	beforeCancel func()
+}
+
+func (f *fakeErrandRepository) Get(id string) (*errand.Errand, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	nErrand, ok := f.errands[id]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	return &nErrand, nil
+}
+
+func (f *fakeErrandRepository) setState(id string, state errand.State) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	nErrand := f.errands[id]
+	nErrand.State = state
+	f.errands[id] = nErrand
+}
+
+func (f *fakeErrandRepository) state(id string) errand.State {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.errands[id].State
+}
+
+func (f *fakeErrandRepository) Cancel(id, userId, reason string, from errand.State) error {
+	if f.beforeCancel != nil {
+		f.beforeCancel()
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	nErrand := f.errands[id]
+	if nErrand.State != from {
+		return error_service.ErrErrandState
+	}
+	nErrand.State = errand.Cancelled
+	nErrand.CancellationReason = reason
+	f.errands[id] = nErrand
+	return nil
+}
+
+func (f *fakeErrandRepository) Update(nErrand *errand.Errand) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.errands[nErrand.Id.Hex()] = *nErrand
+	return nil
+}
+
+func (f *fakeErrandRepository) SenderComplete(id, userId string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	nErrand := f.errands[id]
+	nErrand.State = errand.Completed
+	nErrand.PayoutPending = true
+	f.errands[id] = nErrand
+	return nil
+}
+
+func (f *fakeErrandRepository) SetPayout(id string, fees *fee.Breakdown) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	nErrand := f.errands[id]
+	nErrand.Fees = fees
+	nErrand.PayoutPending = false
+	f.errands[id] = nErrand
+	return nil
+}
+
+func (f *fakeErrandRepository) GetPendingPayouts(time.Time) (errands []errand.Errand, err error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for _, nErrand := range f.errands {
+		if nErrand.PayoutPending {
+			errands = append(errands, nErrand)
+		}
+	}
+	return errands, nil
+}
+
+func (f *fakeErrandRepository) RestoreCancelled(id string, state errand.State) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if nErrand := f.errands[id]; nErrand.State == errand.Cancelled {
+		nErrand.State = state
+		nErrand.CancellationReason = ""
+		f.errands[id] = nErrand
+	}
+	return nil
+}
+
+// fakeWalletRepository moves money the way the ledger does, a transfer is checked and written as a whole
+// and a repeated key fails as a duplicate.
+type fakeWalletRepository struct {
+	wallet.Repository
+	mutex        sync.Mutex
+	transactions []wallet.Transaction
+	transfers    int
+	fail         error
+}
+
+func (f *fakeWalletRepository) Transfer(txns ...wallet.Transaction) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.fail != nil {
+		return f.fail
+	}
+	for _, txn := range txns {
+		for _, oTxn := range f.transactions {
+			if txn.Key != "" && txn.Key == oTxn.Key {
+				return mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000}}}
+			}
+		}
+	}
+	all := append(append([]wallet.Transaction{}, f.transactions...), txns...)
+	for _, txn := range txns {
+		if cash, promo := wallet.Net(txn.UserId, all); txn.UserId != wallet.PlatformAccountId && (cash < 0 || promo < 0) {
+			return wallet.ErrInsufficientFunds
+		}
+	}
+	f.transactions = all
+	f.transfers++
+	return nil
+}
+
+func (f *fakeWalletRepository) GetItemTransaction(userId, itemId string, category wallet.Category) (*wallet.Transaction, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for _, txn := range f.transactions {
+		if txn.UserId == userId && txn.ItemId == itemId && txn.TransactionCategory == category {
+			return &txn, nil
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (f *fakeWalletRepository) GetBalance(userId string) (int64, error) {
+	cash, _ := f.balance(userId)
+	return cash, nil
+}
+
+func (f *fakeWalletRepository) GetPromoBalance(userId string) (int64, error) {
+	_, promo := f.balance(userId)
+	return promo, nil
+}
+
+func (f *fakeWalletRepository) count() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.transfers
+}
+
+func (f *fakeWalletRepository) balance(userId string) (int64, int64) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return wallet.Net(userId, f.transactions)
+}
+
+type fakeRefundRepository struct {
+	refund.Repository
+	created int32
+}
+
+func (f *fakeRefundRepository) Create(*refund.Refund) error {
+	atomic.AddInt32(&f.created, 1)
+	return nil
+}
+
+type fakeNotificationRepository struct {
+	notification.Repository
+}
+
+func (fakeNotificationRepository) SendNotification(notification.Notification) error {
+	return nil
+}
+
+type fakeUserRepository struct {
+	user.Repository
+}
+
+func (fakeUserRepository) CompleteErrand(string) error {
+	return nil
+}
+
+type fakeCategoryRepository struct {
+	category.Repository
+}
+
+func (fakeCategoryRepository) Get(id string) (*category.Category, error) {
+	categoryId, _ := entity.StringToErrandId(id)
+	return &category.Category{Id: categoryId, Type: "errand"}, nil
+}
+
+// fakeFeeUseCase charges a service fee of 500 and a commission of 1000.
+type fakeFeeUseCase struct{}
+
+func (fakeFeeUseCase) Quote(kind fee.Kind, _ string, _ int64) (fee.Charge, error) {
+	if kind == fee.Commission {
+		return fee.Charge{Kind: kind, Amount: 1000}, nil
+	}
+	return fee.Charge{Kind: kind, Amount: 500}, nil
+}
+
+type fakePromoUseCase struct {
+	promoUseCase.UseCase
+}
+
+func (fakePromoUseCase) RewardReferral(string, string) {}
+
+// fakeRedeemingPromoUseCase gives a discount of 300 and counts the uses of the promo still claimed.
+type fakeRedeemingPromoUseCase struct {
+	fakePromoUseCase
+	redeemed int
+}
+
+func (f *fakeRedeemingPromoUseCase) Quote(string, string, string, int64) (*promo.Promo, int64, error) {
+	return &promo.Promo{Code: "WELCOME"}, 300, nil
+}
+
+func (f *fakeRedeemingPromoUseCase) Redeem(*promo.Promo, string, string, int64) error {
+	f.redeemed++
+	return nil
+}
+
+func (f *fakeRedeemingPromoUseCase) Release(string) (*promo.Redemption, error) {
+	f.redeemed--
+	return &promo.Redemption{}, nil
+}
+
+type fakeWebhookUseCase struct {
+	webhookUseCase.UseCase
+}
+
+func (fakeWebhookUseCase) Publish(string, webhook.Event, interface{}) error {
+	return nil
+}
+
+// fundedErrand is an active errand whose budget of 10000 and service fee of 500 were paid from a wallet
+// that held exactly that.
+func fundedErrand(walletRepo *fakeWalletRepository) errand.Errand {
+	nErrand := errand.Errand{
+		Id:       entity.NewDatabaseId(),
+		UserId:   "sender-1",
+		RunnerId: "runner-1",
+		State:    errand.Active,
+		Budget:   10000,
+		Amount:   8000,
+		Fees:     &fee.Breakdown{Budget: 10000, ServiceFee: 500, TotalCharged: 10500},
+	}
+	errandId := nErrand.Id.Hex()
+	walletRepo.transactions = []wallet.Transaction{
+		wallet.NewCreditTransaction("sender-1", "Wallet top up", "", 10500, wallet.TopUp),
+		wallet.NewDebitTransaction("sender-1", "Errand creation", errandId, 10000, wallet.ErrandPayment),
+		wallet.NewDebitTransaction("sender-1", "Errand service fee", errandId, 500, wallet.ServiceFee),
+		wallet.NewCreditTransaction(wallet.PlatformAccountId, "Errand service fee", errandId, 500, wallet.ServiceFee),
+	}
+	return nErrand
+}
+
+func newCancelUseCase(repository *fakeErrandRepository, walletRepo *fakeWalletRepository, refundRepo *fakeRefundRepository) UseCase {
+	refunds := refundUseCase.NewUseCase(refundRepo, walletRepo, fakeNotificationRepository{}, nil, error_service.New())
+	return NewUseCase(repository, nil, error_service.New(), fakeNotificationRepository{}, nil, repository, walletRepo, nil, nil, refunds, nil, nil)
+}
+
+func newPaymentUseCase(repository *fakeErrandRepository, walletRepo *fakeWalletRepository) UseCase {
+	return NewUseCase(repository, fakeUserRepository{}, error_service.New(), fakeNotificationRepository{}, fakeCategoryRepository{}, repository, walletRepo, nil, fakeFeeUseCase{}, nil, fakePromoUseCase{}, fakeWebhookUseCase{})
+}
+
+func TestCreateErrandCannotOverdraw(t *testing.T) {
+	walletRepo := &fakeWalletRepository{transactions: []wallet.Transaction{
+		wallet.NewCreditTransaction("sender-1", "Wallet top up", "", 10500, wallet.TopUp),
+	}}
+	repository := &fakeErrandRepository{errands: map[string]errand.Errand{}}
+	var drafts []string
+	for n := 0; n < 4; n++ {
+		draft := errand.Errand{Id: entity.NewDatabaseId(), UserId: "sender-1", State: errand.Draft}
+		repository.errands[draft.Id.Hex()] = draft
+		drafts = append(drafts, draft.Id.Hex())
+	}
+	useCase := newPaymentUseCase(repository, walletRepo)
+
+	var created int32
+	var wait sync.WaitGroup
+	for _, draftId := range drafts {
+		wait.Add(1)
+		go func(draftId string) {
+			defer wait.Done()
+			nErrand := &errand.Errand{
+				Budget:   10000,
+				Category: &category.Category{Id: entity.NewDatabaseId()},
+				Duration: &errand.Duration{},
+			}
+			if err := useCase.CreateErrand(&auth.Principal{UserId: "sender-1"}, draftId, nErrand, "", ""); err == nil {
+				atomic.AddInt32(&created, 1)
+			} else if !errors.Is(err, wallet.ErrInsufficientFunds) {
+				t.Errorf("CreateErrand() = %v, want %v", err, wallet.ErrInsufficientFunds)
+			}
+		}(draftId)
+	}
+	wait.Wait()
+
+	if created != 1 {
+		t.Fatalf("%d errands were created from a wallet that pays for one", created)
+	}
+	if cash, _ := walletRepo.balance("sender-1"); cash != 0 {
+		t.Fatalf("sender balance = %d, want 0", cash)
+	}
+	if cash, _ := walletRepo.balance(wallet.PlatformAccountId); cash != 500 {
+		t.Fatalf("platform balance = %d, want the service fee of 500", cash)
+	}
+}
+
+func TestCreateErrandReleasesPromoOnFailedTransfer(t *testing.T) {
+	walletRepo := &fakeWalletRepository{transactions: []wallet.Transaction{
+		wallet.NewCreditTransaction("sender-1", "Wallet top up", "", 10500, wallet.TopUp),
+	}}
+	draft := errand.Errand{Id: entity.NewDatabaseId(), UserId: "sender-1", State: errand.Draft}
+	repository := &fakeErrandRepository{errands: map[string]errand.Errand{draft.Id.Hex(): draft}}
+	promoUseCase := &fakeRedeemingPromoUseCase{}
+	useCase := NewUseCase(repository, fakeUserRepository{}, error_service.New(), fakeNotificationRepository{}, fakeCategoryRepository{}, repository, walletRepo, nil, fakeFeeUseCase{}, nil, promoUseCase, fakeWebhookUseCase{})
+
+	create := func() error {
+		nErrand := &errand.Errand{
+			Budget:   10000,
+			Category: &category.Category{Id: entity.NewDatabaseId()},
+			Duration: &errand.Duration{},
+		}
+		return useCase.CreateErrand(&auth.Principal{UserId: "sender-1"}, draft.Id.Hex(), nErrand, "", "welcome")
+	}
+
+	walletRepo.fail = mongo.CommandError{Labels: []string{"NetworkError"}}
+	if err := create(); err == nil {
+		t.Fatal("CreateErrand() succeeded while the wallet was down")
+	}
+	if promoUseCase.redeemed != 0 {
+		t.Fatalf("%d promo uses claimed by an errand that was not paid for, want 0", promoUseCase.redeemed)
+	}
+
+	walletRepo.fail = nil
+	if err := create(); err != nil {
+		t.Fatal(err)
+	}
+	if promoUseCase.redeemed != 1 {
+		t.Fatalf("%d promo uses claimed, want 1", promoUseCase.redeemed)
+	}
+	// The discount of 300 is paid by the platform on top of the service fee it earns
+	if cash, _ := walletRepo.balance("sender-1"); cash != 300 {
+		t.Fatalf("sender balance = %d, want 300", cash)
+	}
+}
+
+func TestCompleteErrandPaysRunnerOnce(t *testing.T) {
+	walletRepo := &fakeWalletRepository{}
+	nErrand := fundedErrand(walletRepo)
+	repository := &fakeErrandRepository{errands: map[string]errand.Errand{nErrand.Id.Hex(): nErrand}}
+	useCase := newPaymentUseCase(repository, walletRepo)
+
+	if err := useCase.CompleteErrand(&auth.Principal{UserId: "sender-1"}, nErrand.Id.Hex(), "sender"); err != nil {
+		t.Fatal(err)
+	}
+	// A retry of an errand that was already paid only saves the fees again
+	if err := useCase.(*impl).payRunner(&nErrand); err != nil {
+		t.Fatalf("payRunner() retry = %v", err)
+	}
+
+	if transfers := walletRepo.count(); transfers != 1 {
+		t.Fatalf("%d transfers, want the payout and commission in one", transfers)
+	}
+	if cash, _ := walletRepo.balance("runner-1"); cash != 7000 {
+		t.Fatalf("runner balance = %d, want 7000", cash)
+	}
+	if cash, _ := walletRepo.balance(wallet.PlatformAccountId); cash != 1500 {
+		t.Fatalf("platform balance = %d, want the service fee and commission of 1500", cash)
+	}
+	if paid, _ := repository.Get(nErrand.Id.Hex()); paid.PayoutPending || paid.Fees.RunnerPayout != 7000 {
+		t.Fatalf("errand = %+v, want it paid out with a payout of 7000", paid)
+	}
+}
+
+func TestPayPendingRunnersAfterFailedPayout(t *testing.T) {
+	walletRepo := &fakeWalletRepository{}
+	nErrand := fundedErrand(walletRepo)
+	repository := &fakeErrandRepository{errands: map[string]errand.Errand{nErrand.Id.Hex(): nErrand}}
+	useCase := newPaymentUseCase(repository, walletRepo)
+
+	walletRepo.fail = mongo.CommandError{Labels: []string{"NetworkError"}}
+	if err := useCase.CompleteErrand(&auth.Principal{UserId: "sender-1"}, nErrand.Id.Hex(), "sender"); err != nil {
+		t.Fatalf("CompleteErrand() = %v, the errand is complete even when the payout fails", err)
+	}
+	if pending, _ := repository.Get(nErrand.Id.Hex()); pending.State != errand.Completed || !pending.PayoutPending {
+		t.Fatalf("errand = %+v, want it completed with its payout pending", pending)
+	}
+
+	walletRepo.fail = nil
+	useCase.PayPendingRunners()
+	useCase.PayPendingRunners()
+
+	if cash, _ := walletRepo.balance("runner-1"); cash != 7000 {
+		t.Fatalf("runner balance = %d, want 7000", cash)
+	}
+	if paid, _ := repository.Get(nErrand.Id.Hex()); paid.PayoutPending {
+		t.Fatal("the payout is still pending after it was made")
+	}
+}
+
+func TestCancelErrandRefundsOnce(t *testing.T) {
+	walletRepo := &fakeWalletRepository{}
+	nErrand := fundedErrand(walletRepo)
+	repository := &fakeErrandRepository{errands: map[string]errand.Errand{nErrand.Id.Hex(): nErrand}}
+	refundRepo := &fakeRefundRepository{}
+	useCase := newCancelUseCase(repository, walletRepo, refundRepo)
+
+	var cancelled int32
+	var wait sync.WaitGroup
+	for n := 0; n < 8; n++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			if err := useCase.CancelErrand(&auth.Principal{UserId: "sender-1"}, nErrand.Id.Hex(), "changed my mind"); err == nil {
+				atomic.AddInt32(&cancelled, 1)
+			}
+		}()
+	}
+	wait.Wait()
+
+	if cancelled != 1 || refundRepo.created != 1 {
+		t.Fatalf("%d cancellations and %d refunds went through, want 1", cancelled, refundRepo.created)
+	}
+	// The budget less the runner's compensation, the service fee is kept once the runner has started
+	if cash, _ := walletRepo.balance("sender-1"); cash != 8000 {
+		t.Fatalf("sender balance = %d, want 8000", cash)
+	}
+	if cash, _ := walletRepo.balance("runner-1"); cash != 2000 {
+		t.Fatalf("runner balance = %d, want 2000", cash)
+	}
+}
+
+func TestCancelErrandAfterStateChange(t *testing.T) {
+	walletRepo := &fakeWalletRepository{}
+	nErrand := fundedErrand(walletRepo)
+	repository := &fakeErrandRepository{errands: map[string]errand.Errand{nErrand.Id.Hex(): nErrand}}
+	repository.beforeCancel = func() {
+		repository.setState(nErrand.Id.Hex(), errand.Completed)
+	}
+	useCase := newCancelUseCase(repository, walletRepo, &fakeRefundRepository{})
+
+	err := useCase.CancelErrand(&auth.Principal{UserId: "sender-1"}, nErrand.Id.Hex(), "")
+	if !errors.Is(err, error_service.ErrErrandState) {
+		t.Fatalf("CancelErrand() = %v, want %v", err, error_service.ErrErrandState)
+	}
+	if walletRepo.transfers != 0 {
+		t.Fatal("an errand completed while it was being cancelled was refunded")
+	}
+	if state := repository.state(nErrand.Id.Hex()); state != errand.Completed {
+		t.Fatalf("state = %s, want %s", state, errand.Completed)
+	}
+}
+
+func TestCancelErrandRestoresOnFailedRefund(t *testing.T) {
+	walletRepo := &fakeWalletRepository{}
+	nErrand := fundedErrand(walletRepo)
+	repository := &fakeErrandRepository{errands: map[string]errand.Errand{nErrand.Id.Hex(): nErrand}}
+	useCase := newCancelUseCase(repository, walletRepo, &fakeRefundRepository{})
+
+	walletRepo.fail = mongo.CommandError{Labels: []string{"NetworkError"}}
+	err := useCase.CancelErrand(&auth.Principal{UserId: "sender-1"}, nErrand.Id.Hex(), "")
+	if appErr := apperror.From(err); appErr.Code != "refund_failed" {
+		t.Fatalf("CancelErrand() = %v, want refund_failed", err)
+	}
+	if state := repository.state(nErrand.Id.Hex()); state != errand.Active {
+		t.Fatalf("state = %s after the refund failed, want %s", state, errand.Active)
+	}
+	if cash, _ := walletRepo.balance("sender-1"); cash != 0 {
+		t.Fatalf("sender balance = %d after the refund failed, want 0", cash)
+	}
+
+	walletRepo.fail = nil
+	if err = useCase.CancelErrand(&auth.Principal{UserId: "sender-1"}, nErrand.Id.Hex(), ""); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if cash, _ := walletRepo.balance("sender-1"); cash != 8000 {
+		t.Fatalf("sender balance = %d, want 8000", cash)
+	}
+}
@@ -1,27 +1,30 @@
 package errand
 
 import (
+	"DX/src/domain/entity/auth"
 	"DX/src/domain/entity/bid"
 	"DX/src/domain/entity/errand"
 	"DX/src/domain/entity/haggle"
 )
 
 type UseCase interface {
-	GetErrand(string, string) (*errand.Errand, error)
-	GetAllErrands(string) ([]errand.Errand, error)
-	GetErrandsFor(string) ([]errand.Errand, error)
-	UpdateErrand(string, *errand.Errand) error
-	CancelErrand(string, string, string) error
-	CompleteErrand(string, string, string) error
-	CreateErrand(string, string, *errand.Errand) error
-	CreateDraftErrand(string) (*errand.Errand, error)
-	BidForErrand(string, *bid.Bid, *haggle.Haggle) error
-	UpdateErrandBid(string, string, string, *haggle.Haggle) error
-	AcceptBid(string, string, string, string, float64) error
-	RejectBid(string, string, string) error
-	RequestErrandTimelineUpdate(string, string) error
-	UpdateErrandTimeline(string, string, string) error
-	AcceptContract(string, string, string) error
-	RejectContract(string, string, string) error
-	RateUser(string, string, string, int64) error
+	GetErrand(*auth.Principal, string) (*errand.Errand, error)
+	GetAllErrands(*auth.Principal) ([]errand.Errand, error)
+	GetErrandsFor(*auth.Principal) ([]errand.Errand, error)
+	UpdateErrand(*auth.Principal, *errand.Errand) error
+	CancelErrand(*auth.Principal, string, string) error
+	CompleteErrand(*auth.Principal, string, string) error
+	CreateErrand(*auth.Principal, string, *errand.Errand, string, string) error
+	CreateDraftErrand(*auth.Principal) (*errand.Errand, error)
+	BidForErrand(*auth.Principal, *bid.Bid, *haggle.Haggle) error
+	UpdateErrandBid(*auth.Principal, string, string, *haggle.Haggle) error
+	AcceptBid(*auth.Principal, string, string, string, float64) error
+	RejectBid(*auth.Principal, string, string) error
+	RequestErrandTimelineUpdate(*auth.Principal, string) error
+	UpdateErrandTimeline(*auth.Principal, string, string) error
+	AcceptContract(*auth.Principal, string, string) error
+	RejectContract(*auth.Principal, string, string) error
+	RateUser(*auth.Principal, string, string, int64) error
+	TipRunner(*auth.Principal, string, int64, string) error
+	PayPendingRunners()
 }
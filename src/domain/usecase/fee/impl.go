@@ -0,0 +1,67 @@
+package fee
+
+import (
+	"DX/src/domain/entity/fee"
+	"DX/src/pkg/error_service"
+	"go.mongodb.org/mongo-driver/mongo"
+	"time"
+)
+
+type impl struct {
+	fee.Repository
+	error_service.Service
+}
+
+func NewUseCase(repository fee.Repository, service error_service.Service) UseCase {
+	return &impl{
+		Repository: repository,
+		Service:    service,
+	}
+}
+
+// Quote prices a fee kind for an amount using the category override if one exists, then the
+// platform-wide rule, then the built-in default. Active waivers reduce the fee to zero.
+func (i *impl) Quote(kind fee.Kind, categoryId string, amount int64) (fee.Charge, error) {
+	charge := fee.Charge{Kind: kind}
+
+	waivers, err := i.Repository.GetActiveWaivers(kind, time.Now())
+	if err != nil {
+		return charge, i.Service.MongoDbError("fee waiver", err)
+	}
+	for _, waiver := range waivers {
+		if waiver.CategoryId == "" || waiver.CategoryId == categoryId {
+			charge.Waived = true
+			return charge, nil
+		}
+	}
+
+	rule, err := i.rule(kind, categoryId)
+	if err != nil {
+		return charge, err
+	}
+	charge.Amount = rule.Calculate(amount)
+
+	return charge, nil
+}
+
+func (i *impl) rule(kind fee.Kind, categoryId string) (fee.Rule, error) {
+	if categoryId != "" {
+		rule, err := i.Repository.GetRule(kind, categoryId)
+		if err == nil {
+			return *rule, nil
+		}
+		if err != mongo.ErrNoDocuments {
+			return fee.Rule{}, i.Service.MongoDbError("fee rule", err)
+		}
+	}
+
+	rule, err := i.Repository.GetRule(kind, "")
+	if err == nil {
+		return *rule, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return fee.Rule{}, i.Service.MongoDbError("fee rule", err)
+	}
+
+	return fee.DefaultRule(kind), nil
+}
@@ -0,0 +1,7 @@
+package fee
+
+import "DX/src/domain/entity/fee"
+
+type UseCase interface {
+	Quote(fee.Kind, string, int64) (fee.Charge, error)
+}
@@ -5,49 +5,38 @@ import (
 	"DX/src/domain/entity/errand"
 	"DX/src/domain/entity/file"
 	"DX/src/pkg/error_service"
-	"errors"
 )
 
 type impl struct {
-	auth.Manager
 	error_service.Service
 	file.Repository
 	ErrandRepo errand.Repository
 }
 
-func NewUseCase(tokenManager auth.Manager, fileRepo file.Repository, errandRepo errand.Repository, service error_service.Service) UseCase {
+func NewUseCase(fileRepo file.Repository, errandRepo errand.Repository, service error_service.Service) UseCase {
 	return &impl{
-		Manager:    tokenManager,
 		Repository: fileRepo,
 		ErrandRepo: errandRepo,
 		Service:    service,
 	}
 }
 
-func (i *impl) UploadFile(token string, errandId string, payFile *file.File) (string, error) {
-	userId, resp := i.Manager.Get(token)
-	if resp != nil {
-		return "", errors.New(resp.Message)
+func (i *impl) UploadFile(principal *auth.Principal, errandId string, payFile *file.File) (string, error) {
+	if err := i.Create(principal.UserId, errandId, payFile); err != nil {
+		return "", i.StorageError(err)
 	}
-
-	err := i.Create(*userId, errandId, payFile)
-	return "", errors.New(i.HandleGoogleStorageError(err).Message)
+	return payFile.UploadedUrl, nil
 }
 
-func (i *impl) UploadFiles(token string, errandId string, files []*file.File) ([]string, error) {
-	userId, resp := i.Manager.Get(token)
-	if resp != nil {
-		return nil, errors.New(resp.Message)
-	}
-
+func (i *impl) UploadFiles(principal *auth.Principal, errandId string, files []*file.File) ([]string, error) {
 	_, err := i.ErrandRepo.Get(errandId)
 	if err != nil {
-		return nil, errors.New(i.Service.HandleMongoDbError("errand", err).Message)
+		return nil, i.Service.MongoDbError("errand", err)
 	}
 
-	err = i.CreateList(*userId, errandId, files)
+	err = i.CreateList(principal.UserId, errandId, files)
 	if err != nil {
-		return nil, errors.New(i.HandleGoogleStorageError(err).Message)
+		return nil, i.StorageError(err)
 	}
 
 	var urls []string
@@ -1,10 +1,11 @@
 package file
 
 import (
+	"DX/src/domain/entity/auth"
 	"DX/src/domain/entity/file"
 )
 
 type UseCase interface {
-	UploadFile(string, string, *file.File) (string, error)
-	UploadFiles(string, string, []*file.File) ([]string, error)
+	UploadFile(*auth.Principal, string, *file.File) (string, error)
+	UploadFiles(*auth.Principal, string, []*file.File) ([]string, error)
 }
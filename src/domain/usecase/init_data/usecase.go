@@ -4,7 +4,6 @@ import (
 	"DX/src/domain/entity/category"
 	"DX/src/domain/entity/init_data"
 	"DX/src/pkg/error_service"
-	"errors"
 )
 
 type UseCase interface {
@@ -28,7 +27,7 @@ func (i *impl) GetInitData() (*init_data.Data, error) {
 	}
 	categories, err := i.CategoryRepository.GetAll()
 	if err != nil {
-		return nil, errors.New(i.Service.HandleMongoDbError("category", err).Message)
+		return nil, i.Service.MongoDbError("category", err)
 	}
 
 	data.Categories = categories
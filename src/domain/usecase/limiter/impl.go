@@ -0,0 +1,73 @@
+package limiter
+
+import (
+	"DX/src/domain/entity/limiter"
+	"DX/src/utils/logger"
+	"time"
+)
+
+// The limiter fails open, an unavailable store is logged instead of locking every user out.
+type impl struct {
+	limiter.Repository
+}
+
+func NewUseCase(repository limiter.Repository) UseCase {
+	return &impl{
+		Repository: repository,
+	}
+}
+
+// Check rejects an attempt while the key is locked, over its limit or still waiting out the delay
+// from its last failure.
+func (i *impl) Check(rule limiter.Rule, value string) error {
+	usage, err := i.Repository.Get(rule.Key(value), rule.Window)
+	if err != nil {
+		logger.Error("unable to check rate limit", err)
+		return nil
+	}
+
+	cTime := time.Now()
+	if usage.IsLocked() {
+		return limiter.NewLockedError(usage.LockedUntil.Sub(cTime))
+	}
+	if rule.Lock == 0 && usage.Count >= rule.Limit {
+		return limiter.NewLimitError(usage.First.Add(rule.Window).Sub(cTime))
+	}
+	if delay := rule.Delay(usage.Count); delay > 0 && cTime.Before(usage.Last.Add(delay)) {
+		return limiter.NewLimitError(usage.Last.Add(delay).Sub(cTime))
+	}
+	return nil
+}
+
+// Fail counts a failed attempt and reports whether it locked the key. Only one of several concurrent
+// failures that reach the limit takes the lock.
+func (i *impl) Fail(rule limiter.Rule, value string) bool {
+	locked, err := i.Repository.Fail(rule.Key(value), rule)
+	if err != nil {
+		logger.Error("unable to count failed attempt", err)
+		return false
+	}
+	return locked
+}
+
+// Clear forgets the attempts and lifts any lock on the key.
+func (i *impl) Clear(rule limiter.Rule, value string) error {
+	if err := i.Repository.Clear(rule.Key(value)); err != nil {
+		logger.Error("unable to clear rate limit", err)
+		return limiter.ErrStore
+	}
+	return nil
+}
+
+// Hit counts a request and rejects it once the key is over its limit.
+func (i *impl) Hit(rule limiter.Rule, value string) error {
+	usage, err := i.Repository.Hit(rule.Key(value), rule.Window)
+	if err != nil {
+		logger.Error("unable to count request", err)
+		return nil
+	}
+	if usage.Count > rule.Limit {
+		return limiter.NewLimitError(usage.First.Add(rule.Window).Sub(time.Now()))
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+package limiter
+
+import "DX/src/domain/entity/limiter"
+
+type UseCase interface {
+	Check(limiter.Rule, string) error
+	Fail(limiter.Rule, string) bool
+	Clear(limiter.Rule, string) error
+	Hit(limiter.Rule, string) error
+}
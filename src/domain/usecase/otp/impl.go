@@ -0,0 +1,126 @@
+package otp
+
+import (
+	"DX/src/domain/entity/otp"
+	"DX/src/pkg/apperror"
+	"DX/src/pkg/email_service"
+	"DX/src/pkg/error_service"
+	"DX/src/pkg/sms_service"
+	"DX/src/utils/logger"
+	"fmt"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type impl struct {
+	otp.Repository
+	Sms   sms_service.Service
+	Email email_service.Service
+	error_service.Service
+}
+
+func NewUseCase(repository otp.Repository, smsService sms_service.Service, emailService email_service.Service, service error_service.Service) UseCase {
+	return &impl{
+		Repository: repository,
+		Sms:        smsService,
+		Email:      emailService,
+		Service:    service,
+	}
+}
+
+func (i *impl) Send(phone string, purpose otp.Purpose) error {
+	return i.send(phone, purpose, func(body string) error {
+		return i.Sms.Send(sms_service.Message{To: phone, Body: body})
+	})
+}
+
+// SendByEmail delivers the code for the phone number to the email address instead.
+func (i *impl) SendByEmail(phone, email string, purpose otp.Purpose) error {
+	return i.send(phone, purpose, func(body string) error {
+		return i.Email.Send(email_service.Message{To: email, Subject: "Your verification code", Body: body})
+	})
+}
+
+func (i *impl) send(phone string, purpose otp.Purpose, deliver func(string) error) error {
+	record, err := i.Repository.Claim(phone, purpose)
+	if err == mongo.ErrNoDocuments {
+		existing, err := i.Repository.Get(phone, purpose)
+		if err != nil {
+			return i.Service.MongoDbError("verification code", err)
+		}
+		return existing.ClaimError()
+	}
+	if err != nil {
+		return i.Service.MongoDbError("verification code", err)
+	}
+	claimed := *record
+
+	code, err := record.Issue()
+	if err != nil {
+		return err
+	}
+	if err = i.Repository.Save(record); err != nil {
+		return i.Service.MongoDbError("verification code", err)
+	}
+
+	body := fmt.Sprintf("Your verification code is %s. It expires in %d minutes.", code, int(otp.CodeLifetime.Minutes()))
+	if err = deliver(body); err != nil {
+		logger.Error("unable to send verification code", err)
+		// Give the send back so the failed delivery does not count against the cooldown
+		if err = i.Repository.Release(&claimed); err != nil {
+			logger.Error("unable to release verification code", err)
+		}
+		return apperror.New(apperror.Unavailable, "otp_not_sent", "unable to send verification code")
+	}
+	return nil
+}
+
+func (i *impl) Verify(phone string, purpose otp.Purpose, code string) (string, error) {
+	record, err := i.Repository.Attempt(phone, purpose)
+	if err == mongo.ErrNoDocuments {
+		existing, err := i.Repository.Get(phone, purpose)
+		if err == mongo.ErrNoDocuments || (err == nil && existing.CodeHash == "") {
+			return "", otp.ErrNoCode
+		}
+		if err != nil {
+			return "", i.Service.MongoDbError("verification code", err)
+		}
+		return "", otp.ErrTooManyAttempts
+	}
+	if err != nil {
+		return "", i.Service.MongoDbError("verification code", err)
+	}
+
+	ticket, err := record.Verify(code)
+	if err != nil {
+		return "", err
+	}
+	if err = i.Repository.Save(record); err != nil {
+		return "", i.Service.MongoDbError("verification code", err)
+	}
+	return ticket, nil
+}
+
+func (i *impl) CheckTicket(phone string, purpose otp.Purpose, ticket string) error {
+	record, err := i.Repository.Get(phone, purpose)
+	if err == mongo.ErrNoDocuments {
+		return otp.ErrInvalidTicket
+	}
+	if err != nil {
+		return i.Service.MongoDbError("verification code", err)
+	}
+	if !record.HasValidTicket(ticket) {
+		return otp.ErrInvalidTicket
+	}
+	return nil
+}
+
+// Redeem consumes the ticket, it can only be used once.
+func (i *impl) Redeem(phone string, purpose otp.Purpose, ticket string) error {
+	if err := i.Repository.RedeemTicket(phone, purpose, ticket); err != nil {
+		if err == otp.ErrInvalidTicket {
+			return err
+		}
+		return i.Service.MongoDbError("verification code", err)
+	}
+	return nil
+}
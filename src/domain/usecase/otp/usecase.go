@@ -0,0 +1,11 @@
+package otp
+
+import "DX/src/domain/entity/otp"
+
+type UseCase interface {
+	Send(string, otp.Purpose) error
+	SendByEmail(string, string, otp.Purpose) error
+	Verify(string, otp.Purpose, string) (string, error)
+	CheckTicket(string, otp.Purpose, string) error
+	Redeem(string, otp.Purpose, string) error
+}
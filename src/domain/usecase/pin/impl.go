@@ -0,0 +1,225 @@
+package pin
+
+import (
+	"DX/src/domain/entity/auth"
+	"DX/src/domain/entity/limiter"
+	"DX/src/domain/entity/notification"
+	"DX/src/domain/entity/pin"
+	"DX/src/domain/entity/user"
+	limiterUseCase "DX/src/domain/usecase/limiter"
+	"DX/src/pkg/apperror"
+	"DX/src/pkg/error_service"
+	"DX/src/pkg/password_service"
+	"DX/src/utils/logger"
+	"go.mongodb.org/mongo-driver/mongo"
+	"strings"
+)
+
+type impl struct {
+	pin.Repository
+	UserRepo         user.Repository
+	NotificationRepo notification.Repository
+	Password         password_service.Service
+	LimiterUseCase   limiterUseCase.UseCase
+	error_service.Service
+}
+
+func NewUseCase(
+	repository pin.Repository,
+	userRepo user.Repository,
+	notificationRepo notification.Repository,
+	passwordService password_service.Service,
+	limiterUseCase limiterUseCase.UseCase,
+	service error_service.Service,
+) UseCase {
+	return &impl{
+		Repository:       repository,
+		UserRepo:         userRepo,
+		NotificationRepo: notificationRepo,
+		Password:         passwordService,
+		LimiterUseCase:   limiterUseCase,
+		Service:          service,
+	}
+}
+
+func (i *impl) CreatePin(principal *auth.Principal, value string) error {
+	if err := pin.IsValid(value); err != nil {
+		return err
+	}
+
+	if _, err := i.Repository.Get(principal.UserId); err == nil {
+		return pin.ErrPinExists
+	} else if err != mongo.ErrNoDocuments {
+		return i.Service.MongoDbError("transaction pin", err)
+	}
+
+	hash, err := i.Password.Generate(value)
+	if err != nil {
+		logger.Error("CreatePin::", err)
+		return apperror.Wrap(err, apperror.Internal, apperror.Internal.Code(), "unable to create transaction pin")
+	}
+	if err = i.Repository.Save(pin.New(principal.UserId, hash)); err != nil {
+		return i.Service.MongoDbError("transaction pin", err)
+	}
+	if err = i.UserRepo.SetTransactionPin(principal.UserId, true); err != nil {
+		return i.Service.MongoDbError("user", err)
+	}
+
+	i.recordEvent(pin.NewEvent(principal.UserId, pin.Created, ""))
+	return nil
+}
+
+func (i *impl) ChangePin(principal *auth.Principal, oldValue, newValue string) error {
+	if err := pin.IsValid(newValue); err != nil {
+		return err
+	}
+
+	nPin, err := i.verify(principal.UserId, oldValue)
+	if err != nil {
+		return err
+	}
+
+	hash, err := i.Password.Generate(newValue)
+	if err != nil {
+		logger.Error("ChangePin::", err)
+		return apperror.Wrap(err, apperror.Internal, apperror.Internal.Code(), "unable to change transaction pin")
+	}
+	nPin.Reset(hash)
+	if err = i.Repository.Save(nPin); err != nil {
+		return i.Service.MongoDbError("transaction pin", err)
+	}
+
+	i.recordEvent(pin.NewEvent(principal.UserId, pin.Changed, ""))
+	go i.sendNotification(notification.NewTransactionPinNotification(principal.UserId, "Your transaction pin was changed."))
+	return nil
+}
+
+// ResetPin replaces the pin of a user who knows their password. Wrong passwords are limited like sign in
+// attempts, so a stolen session cannot be used to guess the password.
+func (i *impl) ResetPin(principal *auth.Principal, password, newValue string) error {
+	if err := pin.IsValid(newValue); err != nil {
+		return err
+	}
+	if err := i.LimiterUseCase.Check(limiter.PinResetAccount, principal.UserId); err != nil {
+		return err
+	}
+
+	nUser, err := i.UserRepo.GetWithId(principal.UserId)
+	if err != nil {
+		return i.Service.MongoDbError("user", err)
+	}
+	if err = i.Password.Compare(nUser.Password, password); err != nil {
+		i.recordEvent(pin.NewEvent(principal.UserId, pin.Failed, "reset with invalid password"))
+		if i.LimiterUseCase.Fail(limiter.PinResetAccount, principal.UserId) {
+			return limiter.NewLockedError(limiter.PinResetAccount.Lock)
+		}
+		return user.ErrWrongPassword
+	}
+	if err = i.LimiterUseCase.Clear(limiter.PinResetAccount, principal.UserId); err != nil {
+		logger.Error("unable to clear failed pin reset attempts", err)
+	}
+
+	nPin, err := i.Repository.Get(principal.UserId)
+	if err == mongo.ErrNoDocuments {
+		return pin.ErrNoPin
+	}
+	if err != nil {
+		return i.Service.MongoDbError("transaction pin", err)
+	}
+
+	hash, err := i.Password.Generate(newValue)
+	if err != nil {
+		logger.Error("ResetPin::", err)
+		return apperror.Wrap(err, apperror.Internal, apperror.Internal.Code(), "unable to reset transaction pin")
+	}
+	nPin.Reset(hash)
+	if err = i.Repository.Save(nPin); err != nil {
+		return i.Service.MongoDbError("transaction pin", err)
+	}
+
+	i.recordEvent(pin.NewEvent(principal.UserId, pin.Reset, ""))
+	go i.sendNotification(notification.NewTransactionPinNotification(principal.UserId, "Your transaction pin was reset."))
+	return nil
+}
+
+func (i *impl) GetEvents(principal *auth.Principal) ([]pin.Event, error) {
+	events, err := i.Repository.GetEvents(principal.UserId)
+	if err != nil {
+		return nil, i.Service.MongoDbError("transaction pin events", err)
+	}
+
+	return events, nil
+}
+
+func (i *impl) Verify(userId, value string) error {
+	_, err := i.verify(userId, value)
+	return err
+}
+
+// verify counts the attempt before the pin is checked, so parallel guesses cannot get past the lock.
+func (i *impl) verify(userId, value string) (*pin.Pin, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, pin.ErrPinRequired
+	}
+
+	nPin, err := i.Repository.Get(userId)
+	if err == mongo.ErrNoDocuments {
+		return nil, pin.ErrNoPin
+	}
+	if err != nil {
+		return nil, i.Service.MongoDbError("transaction pin", err)
+	}
+	if nPin.IsLocked() {
+		return nil, pin.ErrPinLocked
+	}
+
+	nPin, err = i.Repository.Attempt(userId)
+	if err == mongo.ErrNoDocuments {
+		return nil, pin.ErrPinLocked
+	}
+	if err != nil {
+		return nil, i.Service.MongoDbError("transaction pin", err)
+	}
+	if nPin.FailedAttempts > pin.MaxAttempts {
+		return nil, i.lock(userId)
+	}
+
+	if err = i.Password.Compare(nPin.Hash, value); err != nil {
+		i.recordEvent(pin.NewEvent(userId, pin.Failed, ""))
+		if nPin.IsExhausted() {
+			return nil, i.lock(userId)
+		}
+		return nil, pin.ErrWrongPin
+	}
+
+	nPin.Succeed()
+	if err = i.Repository.Save(nPin); err != nil {
+		logger.Error("VerifyPin::", err)
+	}
+	i.recordEvent(pin.NewEvent(userId, pin.Verified, ""))
+	return nPin, nil
+}
+
+func (i *impl) lock(userId string) error {
+	locked, err := i.Repository.Lock(userId)
+	if err != nil {
+		return i.Service.MongoDbError("transaction pin", err)
+	}
+	if locked {
+		i.recordEvent(pin.NewEvent(userId, pin.Locked, "too many failed attempts"))
+		go i.sendNotification(notification.NewTransactionPinNotification(userId, "Your transaction pin has been locked after too many failed attempts."))
+	}
+	return pin.ErrPinLocked
+}
+
+func (i *impl) recordEvent(event pin.Event) {
+	if err := i.Repository.CreateEvent(event); err != nil {
+		logger.Error("Failed to record transaction pin event", err)
+	}
+}
+
+func (i *impl) sendNotification(notification notification.Notification) {
+	if err := i.NotificationRepo.SendNotification(notification); err != nil {
+		logger.Error("Failed to send notifications", err)
+	}
+}
@@ -0,0 +1,14 @@
+package pin
+
+import (
+	"DX/src/domain/entity/auth"
+	"DX/src/domain/entity/pin"
+)
+
+type UseCase interface {
+	CreatePin(*auth.Principal, string) error
+	ChangePin(*auth.Principal, string, string) error
+	ResetPin(*auth.Principal, string, string) error
+	GetEvents(*auth.Principal) ([]pin.Event, error)
+	Verify(string, string) error
+}
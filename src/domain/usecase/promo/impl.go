@@ -0,0 +1,169 @@
+package promo
+
+import (
+	"DX/src/domain/entity/auth"
+	"DX/src/domain/entity/errand"
+	"DX/src/domain/entity/notification"
+	"DX/src/domain/entity/promo"
+	"DX/src/domain/entity/user"
+	"DX/src/domain/entity/wallet"
+	"DX/src/pkg/error_service"
+	"DX/src/utils/logger"
+	"fmt"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type impl struct {
+	promo.Repository
+	UserRepo         user.Repository
+	ErrandRepo       errand.Repository
+	WalletRepo       wallet.Repository
+	NotificationRepo notification.Repository
+	error_service.Service
+}
+
+func NewUseCase(
+	repository promo.Repository,
+	userRepo user.Repository,
+	errandRepo errand.Repository,
+	walletRepo wallet.Repository,
+	notificationRepo notification.Repository,
+	service error_service.Service,
+) UseCase {
+	return &impl{
+		Repository:       repository,
+		UserRepo:         userRepo,
+		ErrandRepo:       errandRepo,
+		WalletRepo:       walletRepo,
+		NotificationRepo: notificationRepo,
+		Service:          service,
+	}
+}
+
+// Quote validates a promo code for the user's errand and returns the discount it gives on amount.
+func (i *impl) Quote(userId, code, categoryId string, amount int64) (*promo.Promo, int64, error) {
+	nPromo, err := i.Repository.GetPromoByCode(code)
+	if err == mongo.ErrNoDocuments {
+		return nil, 0, promo.ErrInvalidPromo
+	}
+	if err != nil {
+		return nil, 0, i.Service.MongoDbError("promo", err)
+	}
+
+	userUses, err := i.Repository.CountRedemptions(nPromo.Id.Hex(), userId)
+	if err != nil {
+		return nil, 0, i.Service.MongoDbError("promo", err)
+	}
+	var isFirstErrand bool
+	if nPromo.FirstErrandOnly {
+		count, err := i.ErrandRepo.CountForSender(userId)
+		if err != nil {
+			return nil, 0, i.Service.MongoDbError("errand", err)
+		}
+		isFirstErrand = count == 0
+	}
+	if err = nPromo.Validate(categoryId, isFirstErrand, userUses); err != nil {
+		return nil, 0, err
+	}
+
+	return nPromo, nPromo.Discount(amount), nil
+}
+
+func (i *impl) Preview(principal *auth.Principal, code, categoryId string, amount int64) (int64, error) {
+	_, discount, err := i.Quote(principal.UserId, code, categoryId, amount)
+	return discount, err
+}
+
+func (i *impl) Redeem(nPromo *promo.Promo, userId, errandId string, discount int64) error {
+	redemption := promo.NewRedemption(nPromo, userId, errandId, discount)
+	if err := i.Repository.Redeem(nPromo, redemption); err != nil {
+		if err == promo.ErrPromoUsedUp || err == promo.ErrPromoUsed {
+			return err
+		}
+		return i.Service.MongoDbError("promo", err)
+	}
+	return nil
+}
+
+// Release gives back the promo use of a refunded errand, errands without a promo return a nil redemption.
+func (i *impl) Release(errandId string) (*promo.Redemption, error) {
+	redemption, err := i.Repository.GetRedemption(errandId)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, i.Service.MongoDbError("promo", err)
+	}
+	if err = i.Repository.ReleaseRedemption(redemption); err != nil {
+		return nil, i.Service.MongoDbError("promo", err)
+	}
+	return redemption, nil
+}
+
+func (i *impl) CreateReferral(refereeId, referralCode string) error {
+	referrer, err := i.UserRepo.GetWithReferralCode(referralCode)
+	if err == mongo.ErrNoDocuments {
+		return promo.ErrInvalidReferral
+	}
+	if err != nil {
+		return i.Service.MongoDbError("user", err)
+	}
+	if referrer.Id.Hex() == refereeId {
+		return promo.ErrInvalidReferral
+	}
+
+	if err = i.Repository.CreateReferral(promo.NewReferral(referrer.Id.Hex(), refereeId)); err != nil {
+		return i.Service.MongoDbError("referral", err)
+	}
+	return nil
+}
+
+// RewardReferral credits the referrer the first time their invitee completes an errand.
+func (i *impl) RewardReferral(refereeId, errandId string) {
+	referral, err := i.Repository.RewardReferral(refereeId, errandId)
+	if err == mongo.ErrNoDocuments {
+		return
+	}
+	if err != nil {
+		logger.Error("unable to reward referral", err)
+		return
+	}
+
+	txn := wallet.NewPromoCreditTransaction(referral.ReferrerId, "Referral reward", referral.Id.Hex(), referral.Reward, wallet.ReferralReward)
+	if err = i.WalletRepo.CreateTransaction(txn); err != nil {
+		logger.Error("unable to credit referral reward", err)
+		return
+	}
+
+	message := fmt.Sprintf("Someone you invited completed their first errand. %d promo credit has been added to your wallet.", referral.Reward)
+	if err = i.NotificationRepo.SendNotification(notification.NewPromoCreditNotification(referral.ReferrerId, message)); err != nil {
+		logger.Error("Failed to send notifications", err)
+	}
+}
+
+func (i *impl) GetReferrals(principal *auth.Principal) (*ReferralSummary, error) {
+	nUser, err := i.UserRepo.GetWithId(principal.UserId)
+	if err != nil {
+		return nil, i.Service.MongoDbError("user", err)
+	}
+	if nUser.ReferralCode == "" {
+		nUser.ReferralCode = user.NewReferralCode()
+		if err = i.UserRepo.SetReferralCode(principal.UserId, nUser.ReferralCode); err != nil {
+			return nil, i.Service.MongoDbError("user", err)
+		}
+	}
+
+	referrals, err := i.Repository.GetReferrals(principal.UserId)
+	if err != nil {
+		return nil, i.Service.MongoDbError("referral", err)
+	}
+	if referrals == nil {
+		referrals = []promo.Referral{}
+	}
+
+	return &ReferralSummary{
+		Code:      nUser.ReferralCode,
+		Reward:    promo.ReferralReward,
+		Referrals: referrals,
+	}, nil
+}
@@ -0,0 +1,22 @@
+package promo
+
+import (
+	"DX/src/domain/entity/auth"
+	"DX/src/domain/entity/promo"
+)
+
+type ReferralSummary struct {
+	Code      string           `json:"code"`
+	Reward    int64            `json:"reward"`
+	Referrals []promo.Referral `json:"referrals"`
+}
+
+type UseCase interface {
+	Quote(string, string, string, int64) (*promo.Promo, int64, error)
+	Preview(*auth.Principal, string, string, int64) (int64, error)
+	Redeem(*promo.Promo, string, string, int64) error
+	Release(string) (*promo.Redemption, error)
+	CreateReferral(string, string) error
+	RewardReferral(string, string)
+	GetReferrals(*auth.Principal) (*ReferralSummary, error)
+}
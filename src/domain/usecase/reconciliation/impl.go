@@ -0,0 +1,89 @@
+package reconciliation
+
+import (
+	"DX/src/domain/entity/reconciliation"
+	"DX/src/domain/entity/wallet"
+	"DX/src/pkg/error_service"
+	"DX/src/pkg/gateway_service"
+	"time"
+)
+
+// settlementGrace allows for the gateway settling a ledger transaction shortly after the period ends.
+const settlementGrace = 2 * time.Hour
+
+type impl struct {
+	reconciliation.Repository
+	WalletRepo     wallet.Repository
+	GatewayService gateway_service.Service
+	error_service.Service
+}
+
+func NewUseCase(repository reconciliation.Repository, walletRepo wallet.Repository, gatewayService gateway_service.Service, service error_service.Service) UseCase {
+	return &impl{
+		Repository:     repository,
+		WalletRepo:     walletRepo,
+		GatewayService: gatewayService,
+		Service:        service,
+	}
+}
+
+func (i *impl) Reconcile(from, to time.Time) (*reconciliation.Report, error) {
+	if from.IsZero() || to.IsZero() || to.Before(from) {
+		return nil, reconciliation.ErrInvalidPeriod
+	}
+
+	txns, err := i.WalletRepo.GetGatewayTransactions(from, to)
+	if err != nil {
+		return nil, i.MongoDbError("transaction", err)
+	}
+	records, err := i.GatewayService.GetRecords(from, to.Add(settlementGrace))
+	if err != nil {
+		return nil, err
+	}
+
+	ledger := make([]reconciliation.Entry, 0, len(txns))
+	references := make(map[string]bool, len(txns))
+	for _, txn := range txns {
+		amount := txn.Amount
+		if txn.TransactionType == wallet.Debit {
+			amount = -amount
+		}
+		reference := txn.Reference
+		if reference == "" {
+			reference = txn.Id.Hex()
+		}
+		references[reference] = true
+		ledger = append(ledger, reconciliation.Entry{
+			Reference:     reference,
+			Amount:        amount,
+			TransactionId: txn.Id.Hex(),
+		})
+	}
+
+	gateway := make([]reconciliation.Entry, 0, len(records))
+	for _, record := range records {
+		// Records settled in the grace period belong to the next run unless they match this period's ledger.
+		if record.SettledAt.After(to) && !references[record.Reference] {
+			continue
+		}
+		amount := record.Amount
+		if record.Type == gateway_service.Debit {
+			amount = -amount
+		}
+		gateway = append(gateway, reconciliation.Entry{
+			Reference: record.Reference,
+			Amount:    amount,
+		})
+	}
+
+	report := reconciliation.NewReport(from, to)
+	discrepancies := report.Compare(ledger, gateway)
+	if err = i.Repository.SaveDiscrepancies(discrepancies); err != nil {
+		return nil, i.MongoDbError("discrepancy", err)
+	}
+	if err = i.Repository.CreateReport(report); err != nil {
+		return nil, i.MongoDbError("reconciliation report", err)
+	}
+
+	return report, nil
+}
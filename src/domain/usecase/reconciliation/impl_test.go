@@ -0,0 +1,110 @@
+package reconciliation
+
+import (
+	"DX/src/domain/entity/reconciliation"
+	"DX/src/domain/entity/wallet"
+	"DX/src/pkg/error_service"
+	"DX/src/pkg/gateway_service"
+	"testing"
+	"time"
+)
+
+type walletRepository struct {
+	wallet.Repository
+	transactions []wallet.Transaction
+}
+
+func (r *walletRepository) GetGatewayTransactions(from, to time.Time) ([]wallet.Transaction, error) {
+	return r.transactions, nil
+}
+
+type reconciliationRepository struct {
+	reconciliation.Repository
+	reports       []*reconciliation.Report
+	discrepancies []reconciliation.Discrepancy
+}
+
+func (r *reconciliationRepository) CreateReport(report *reconciliation.Report) error {
+	r.reports = append(r.reports, report)
+	return nil
+}
+
+func (r *reconciliationRepository) SaveDiscrepancies(discrepancies []reconciliation.Discrepancy) error {
+	r.discrepancies = append(r.discrepancies, discrepancies...)
+	return nil
+}
+
+func TestReconcile(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	topUp := func(reference string, amount int64) wallet.Transaction {
+		txn := wallet.NewCreditTransaction("user-1", "Credit", "", amount, wallet.TopUp)
+		txn.Reference = reference
+		return txn
+	}
+	settled := func(reference string, amount int64, at time.Time) gateway_service.Record {
+		return gateway_service.Record{Reference: reference, Type: gateway_service.Credit, Amount: amount, SettledAt: at}
+	}
+
+	walletRepo := &walletRepository{transactions: []wallet.Transaction{
+		topUp("matched", 5000),
+		topUp("late", 2000),
+		topUp("unsettled", 3000),
+		topUp("short", 1000),
+	}}
+	gateway := gateway_service.NewFake(
+		settled("matched", 5000, from.Add(time.Hour)),
+		// Settled in the grace period, it belongs to this run because the ledger has it.
+		settled("late", 2000, to.Add(time.Hour)),
+		// Settled in the grace period for a top up of the next run.
+		settled("next-run", 7000, to.Add(time.Hour)),
+		settled("short", 900, from.Add(2*time.Hour)),
+		settled("unknown", 4000, from.Add(3*time.Hour)),
+		// Past the grace period.
+		settled("much-later", 8000, to.Add(3*time.Hour)),
+	)
+	repository := &reconciliationRepository{}
+	useCase := NewUseCase(repository, walletRepo, gateway, error_service.New())
+
+	report, err := useCase.Reconcile(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.LedgerRecords != 4 || report.GatewayRecords != 4 || report.Matched != 2 || report.Discrepancies != 3 {
+		t.Fatalf("unexpected counts %+v", report)
+	}
+	if len(repository.reports) != 1 || repository.reports[0] != report {
+		t.Fatal("report was not saved")
+	}
+
+	kinds := map[string]reconciliation.Kind{}
+	for _, discrepancy := range repository.discrepancies {
+		kinds[discrepancy.Reference] = discrepancy.DiscrepancyKind
+	}
+	expected := map[string]reconciliation.Kind{
+		"unsettled": reconciliation.MissingAtGateway,
+		"short":     reconciliation.AmountMismatch,
+		"unknown":   reconciliation.MissingInLedger,
+	}
+	if len(kinds) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, kinds)
+	}
+	for reference, kind := range expected {
+		if got, ok := kinds[reference]; !ok || got != kind {
+			t.Fatalf("%s: expected %s, got %s", reference, kind.Id(), got.Id())
+		}
+	}
+}
+
+func TestReconcileInvalidPeriod(t *testing.T) {
+	useCase := NewUseCase(&reconciliationRepository{}, &walletRepository{}, gateway_service.NewFake(), error_service.New())
+	cTime := time.Now()
+
+	for _, period := range [][2]time.Time{{{}, cTime}, {cTime, {}}, {cTime, cTime.Add(-time.Hour)}} {
+		if _, err := useCase.Reconcile(period[0], period[1]); err == nil {
+			t.Fatalf("expected %s to %s to be rejected", period[0], period[1])
+		}
+	}
+}
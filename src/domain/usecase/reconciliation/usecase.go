@@ -0,0 +1,10 @@
+package reconciliation
+
+import (
+	"DX/src/domain/entity/reconciliation"
+	"time"
+)
+
+type UseCase interface {
+	Reconcile(time.Time, time.Time) (*reconciliation.Report, error)
+}
@@ -0,0 +1,166 @@
+package refund
+
+import (
+	"DX/src/domain/entity/auth"
+	"DX/src/domain/entity/errand"
+	"DX/src/domain/entity/notification"
+	"DX/src/domain/entity/refund"
+	"DX/src/domain/entity/wallet"
+	promoUseCase "DX/src/domain/usecase/promo"
+	"DX/src/pkg/error_service"
+	"DX/src/utils/logger"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type impl struct {
+	refund.Repository
+	WalletRepo       wallet.Repository
+	NotificationRepo notification.Repository
+	PromoUseCase     promoUseCase.UseCase
+	error_service.Service
+}
+
+func NewUseCase(repository refund.Repository, walletRepo wallet.Repository, notificationRepo notification.Repository, promoUseCase promoUseCase.UseCase, service error_service.Service) UseCase {
+	return &impl{
+		Repository:       repository,
+		WalletRepo:       walletRepo,
+		NotificationRepo: notificationRepo,
+		PromoUseCase:     promoUseCase,
+		Service:          service,
+	}
+}
+
+// RefundCancellation returns the escrowed budget of a cancelled errand according to the cancellation policy.
+// Errands that were never funded return a nil refund. Every leg moves in one transfer, when it fails nothing
+// was refunded.
+func (i *impl) RefundCancellation(oErrand *errand.Errand, cancelledBy string) (*refund.Refund, error) {
+	nRefund, err := refund.ForCancellation(oErrand, cancelledBy)
+	if err != nil || nRefund == nil {
+		return nil, err
+	}
+	errandId := oErrand.Id.Hex()
+
+	var txns []wallet.Transaction
+	if nRefund.Amount > 0 {
+		refundOf, err := i.originalDebit(oErrand.UserId, errandId, wallet.ErrandPayment)
+		if err != nil {
+			return nil, err
+		}
+		txns = append(txns, wallet.NewRefundTransaction(oErrand.UserId, "Errand cancellation refund", errandId, refundOf, nRefund.Amount))
+	}
+	if nRefund.ServiceFee > 0 {
+		refundOf, err := i.originalDebit(oErrand.UserId, errandId, wallet.ServiceFee)
+		if err != nil {
+			return nil, err
+		}
+		txns = append(txns,
+			wallet.NewRefundTransaction(oErrand.UserId, "Errand service fee refund", errandId, refundOf, nRefund.ServiceFee),
+			wallet.NewDebitTransaction(wallet.PlatformAccountId, "Errand service fee refund", errandId, nRefund.ServiceFee, wallet.Refund),
+		)
+	}
+	if nRefund.Compensation > 0 {
+		txns = append(txns, wallet.NewCreditTransaction(nRefund.RunnerId, "Cancelled errand compensation", errandId, nRefund.Compensation, wallet.RunnerCompensation))
+	}
+	if nRefund.Discount > 0 {
+		txns = append(txns,
+			wallet.NewDebitTransaction(oErrand.UserId, "Promo discount reversal", errandId, nRefund.Discount, wallet.PromoDiscount),
+			wallet.NewCreditTransaction(wallet.PlatformAccountId, "Promo discount reversal", errandId, nRefund.Discount, wallet.PromoDiscount),
+		)
+	}
+	if nRefund.PromoCredit > 0 {
+		txns = append(txns,
+			wallet.NewDebitTransaction(oErrand.UserId, "Promo credit returned", errandId, nRefund.PromoCredit, wallet.PromoCredit),
+			wallet.NewPromoCreditTransaction(oErrand.UserId, "Promo credit returned", errandId, nRefund.PromoCredit, wallet.PromoCredit),
+		)
+	}
+	if err = i.transfer(nRefund, txns); err != nil {
+		return nil, err
+	}
+
+	// The money has moved, failures from here on are logged so the refund is never made twice
+	if nRefund.Discount > 0 {
+		if _, err = i.PromoUseCase.Release(errandId); err != nil {
+			logger.Error("unable to release promo redemption", err)
+		}
+	}
+	if err = i.Repository.Create(nRefund); err != nil {
+		logger.Error("unable to save refund", err)
+	}
+	go i.sendNotification(notification.NewRefundNotification(nRefund.UserId, errandId, nRefund.Returned()))
+
+	return nRefund, nil
+}
+
+// IssueManualRefund returns part or all of a debit. The transfer checks that the refunds of the debit never
+// add up to more than it, so concurrent refunds can't go over.
+func (i *impl) IssueManualRefund(principal *auth.Principal, transactionId string, amount int64, reason string) (*refund.Refund, error) {
+	txn, err := i.WalletRepo.GetTransaction(transactionId)
+	if err != nil {
+		return nil, i.Service.MongoDbError("transaction", err)
+	}
+	if txn.TransactionType != wallet.Debit || txn.UserId == wallet.PlatformAccountId {
+		return nil, refund.ErrNotRefundable
+	}
+
+	nRefund, err := refund.NewManualRefund(txn.UserId, txn.ItemId, principal.UserId, reason, amount)
+	if err != nil {
+		return nil, err
+	}
+	txns := []wallet.Transaction{wallet.NewRefundTransaction(txn.UserId, reason, txn.ItemId, transactionId, amount)}
+	if txn.TransactionCategory == wallet.ServiceFee {
+		txns = append(txns, wallet.NewDebitTransaction(wallet.PlatformAccountId, "Service fee refund", txn.ItemId, amount, wallet.Refund))
+	}
+	if err = i.transfer(nRefund, txns); err != nil {
+		return nil, err
+	}
+
+	if err = i.Repository.Create(nRefund); err != nil {
+		logger.Error("unable to save refund", err)
+	}
+	go i.sendNotification(notification.NewRefundNotification(nRefund.UserId, nRefund.ErrandId, amount))
+
+	return nRefund, nil
+}
+
+func (i *impl) GetRefunds(userId, errandId string) ([]refund.Refund, error) {
+	refunds, err := i.Repository.GetRefunds(userId, errandId)
+	if err != nil {
+		return nil, i.Service.MongoDbError("refunds", err)
+	}
+	if refunds == nil {
+		refunds = []refund.Refund{}
+	}
+
+	return refunds, nil
+}
+
+// originalDebit returns the id of the debit being refunded, errands funded before categories were recorded have none.
+func (i *impl) originalDebit(userId, errandId string, category wallet.Category) (string, error) {
+	txn, err := i.WalletRepo.GetItemTransaction(userId, errandId, category)
+	if err == mongo.ErrNoDocuments {
+		return "", nil
+	}
+	if err != nil {
+		return "", i.Service.MongoDbError("transaction", err)
+	}
+	return txn.Id.Hex(), nil
+}
+
+func (i *impl) transfer(nRefund *refund.Refund, txns []wallet.Transaction) error {
+	if len(txns) == 0 {
+		return nil
+	}
+	if err := i.WalletRepo.Transfer(txns...); err != nil {
+		return i.Service.MongoDbError("wallet", err)
+	}
+	for _, txn := range txns {
+		nRefund.TransactionIds = append(nRefund.TransactionIds, txn.Id.Hex())
+	}
+	return nil
+}
+
+func (i *impl) sendNotification(notification notification.Notification) {
+	if err := i.NotificationRepo.SendNotification(notification); err != nil {
+		logger.Error("Failed to send notifications", err)
+	}
+}
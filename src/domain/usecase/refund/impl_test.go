@@ -0,0 +1,92 @@
+package refund
+
+import (
+	"DX/src/domain/entity/auth"
+	"DX/src/domain/entity/notification"
+	"DX/src/domain/entity/refund"
+	"DX/src/domain/entity/wallet"
+	"DX/src/pkg/error_service"
+	"errors"
+	"testing"
+)
+
+type fakeWalletRepository struct {
+	wallet.Repository
+	debit     wallet.Transaction
+	transfers [][]wallet.Transaction
+	fail      error
+}
+
+func (f *fakeWalletRepository) GetTransaction(string) (*wallet.Transaction, error) {
+	return &f.debit, nil
+}
+
+func (f *fakeWalletRepository) Transfer(txns ...wallet.Transaction) error {
+	if f.fail != nil {
+		return f.fail
+	}
+	f.transfers = append(f.transfers, txns)
+	return nil
+}
+
+type fakeRefundRepository struct {
+	refund.Repository
+	refunds []*refund.Refund
+}
+
+func (f *fakeRefundRepository) Create(nRefund *refund.Refund) error {
+	f.refunds = append(f.refunds, nRefund)
+	return nil
+}
+
+type fakeNotificationRepository struct {
+	notification.Repository
+}
+
+func (fakeNotificationRepository) SendNotification(notification.Notification) error {
+	return nil
+}
+
+func TestIssueManualRefund(t *testing.T) {
+	tests := []struct {
+		name     string
+		category wallet.Category
+		legs     int
+		fail     error
+	}{
+		{name: "errand payment", category: wallet.ErrandPayment, legs: 1},
+		{name: "service fee is taken back from the platform", category: wallet.ServiceFee, legs: 2},
+		{name: "more than is left to refund", category: wallet.ErrandPayment, fail: wallet.ErrRefundExceedsDebit},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			walletRepo := &fakeWalletRepository{
+				debit: wallet.NewDebitTransaction("user-1", "Errand", "errand-1", 1000, test.category),
+				fail:  test.fail,
+			}
+			refundRepo := &fakeRefundRepository{}
+			useCase := NewUseCase(refundRepo, walletRepo, fakeNotificationRepository{}, nil, error_service.New())
+
+			nRefund, err := useCase.IssueManualRefund(&auth.Principal{UserId: "admin-1"}, walletRepo.debit.Id.Hex(), 600, "damaged item")
+			if test.fail != nil {
+				if !errors.Is(err, test.fail) || len(refundRepo.refunds) != 0 {
+					t.Fatalf("IssueManualRefund() = %v with %d refunds, want %v and none", err, len(refundRepo.refunds), test.fail)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(walletRepo.transfers) != 1 || len(walletRepo.transfers[0]) != test.legs {
+				t.Fatalf("transfers = %v, want one of %d legs", walletRepo.transfers, test.legs)
+			}
+			if refundOf := walletRepo.transfers[0][0].RefundOf; refundOf != walletRepo.debit.Id.Hex() {
+				t.Fatalf("refund of %q, want the debit", refundOf)
+			}
+			if len(nRefund.TransactionIds) != test.legs || len(refundRepo.refunds) != 1 {
+				t.Fatalf("refund = %+v, want it saved with its transactions", nRefund)
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package refund
+
+import (
+	"DX/src/domain/entity/auth"
+	"DX/src/domain/entity/errand"
+	"DX/src/domain/entity/refund"
+)
+
+type UseCase interface {
+	RefundCancellation(*errand.Errand, string) (*refund.Refund, error)
+	IssueManualRefund(*auth.Principal, string, int64, string) (*refund.Refund, error)
+	GetRefunds(string, string) ([]refund.Refund, error)
+}
@@ -0,0 +1,229 @@
+package reset
+
+import (
+	"DX/src/domain/entity/notification"
+	"DX/src/domain/entity/otp"
+	"DX/src/domain/entity/reset"
+	"DX/src/domain/entity/security"
+	"DX/src/domain/entity/user"
+	otpUseCase "DX/src/domain/usecase/otp"
+	securityUseCase "DX/src/domain/usecase/security"
+	sessionUseCase "DX/src/domain/usecase/session"
+	"DX/src/pkg/apperror"
+	"DX/src/pkg/error_service"
+	"DX/src/pkg/password_service"
+	"DX/src/utils"
+	"DX/src/utils/logger"
+	"fmt"
+	"go.mongodb.org/mongo-driver/mongo"
+	"strings"
+)
+
+type impl struct {
+	reset.Repository
+	UserRepo         user.Repository
+	NotificationRepo notification.Repository
+	Password         password_service.Service
+	OtpUseCase       otpUseCase.UseCase
+	SecurityUseCase  securityUseCase.UseCase
+	SessionUseCase   sessionUseCase.UseCase
+	error_service.Service
+}
+
+func NewUseCase(repository reset.Repository, userRepo user.Repository, notificationRepo notification.Repository, passwordService password_service.Service,
+	otpUseCase otpUseCase.UseCase, securityUseCase securityUseCase.UseCase, sessionUseCase sessionUseCase.UseCase, service error_service.Service) UseCase {
+	return &impl{
+		Repository:       repository,
+		UserRepo:         userRepo,
+		NotificationRepo: notificationRepo,
+		Password:         passwordService,
+		OtpUseCase:       otpUseCase,
+		SecurityUseCase:  securityUseCase,
+		SessionUseCase:   sessionUseCase,
+		Service:          service,
+	}
+}
+
+// Request sends a reset code to the phone number or the verified email of the account. Every valid
+// number gets the same response, failures to send are only logged, so the endpoint cannot be used to
+// find accounts.
+func (i *impl) Request(phone string, channel reset.Channel) error {
+	phone = strings.TrimSpace(phone)
+	if !utils.IsValidPhoneNumber(phone) {
+		return error_service.ErrInvalidPhoneNumber
+	}
+
+	nUser, err := i.UserRepo.GetWithPhone(phone)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return i.Service.MongoDbError("user", err)
+	}
+	if err = i.send(nUser, phone, channel); err != nil {
+		logger.Error(fmt.Sprintf("unable to send password reset code to %s", nUser.Id.Hex()), err)
+	}
+	return nil
+}
+
+func (i *impl) send(nUser *user.User, phone string, channel reset.Channel) error {
+	if channel == reset.Email && (nUser.Email == "" || !nUser.HasVerifiedEmail) {
+		logger.Info(fmt.Sprintf("password reset by email requested for %s without a verified email", nUser.Id.Hex()))
+		return nil
+	}
+
+	record, err := i.Repository.Get(phone)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return err
+	}
+	if record == nil || record.IsExpired() || record.UserId != nUser.Id.Hex() {
+		if err = i.Repository.Delete(phone); err != nil {
+			return err
+		}
+		record = reset.New(nUser.Id.Hex(), phone)
+		if err = i.Repository.Save(record); err != nil {
+			return err
+		}
+	}
+
+	if channel == reset.Email {
+		return i.OtpUseCase.SendByEmail(phone, nUser.Email, otp.PasswordReset)
+	}
+	return i.OtpUseCase.Send(phone, otp.PasswordReset)
+}
+
+// VerifyCode checks the reset code. Accounts with security questions get a ticket and the questions
+// to answer through the security question verification, the others get the reset token straight away.
+func (i *impl) VerifyCode(phone, code string) (*reset.Progress, error) {
+	phone = strings.TrimSpace(phone)
+	record, err := i.get(phone)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, err := i.OtpUseCase.Verify(phone, otp.PasswordReset, code)
+	if err != nil {
+		return nil, err
+	}
+
+	prompts, err := i.SecurityUseCase.GetUserChallenge(record.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if len(prompts) > 0 {
+		return &reset.Progress{Ticket: ticket, SecurityQuestions: prompts}, nil
+	}
+
+	if err = i.OtpUseCase.Redeem(phone, otp.PasswordReset, ticket); err != nil {
+		return nil, err
+	}
+	return i.issueToken(record)
+}
+
+// AnswerQuestion exchanges the code ticket and the ticket from answering the security questions for
+// the reset token.
+func (i *impl) AnswerQuestion(phone, ticket, securityTicket string) (*reset.Progress, error) {
+	phone = strings.TrimSpace(phone)
+	if err := i.OtpUseCase.CheckTicket(phone, otp.PasswordReset, ticket); err != nil {
+		return nil, err
+	}
+
+	record, err := i.attempt(phone)
+	if err != nil {
+		return nil, err
+	}
+	if err = i.SecurityUseCase.RedeemTicket(record.UserId, securityTicket); err != nil {
+		if err == security.ErrInvalidTicket && record.Attempts >= reset.MaxAttempts {
+			return nil, reset.ErrTooManyAttempts
+		}
+		return nil, err
+	}
+
+	if err = i.OtpUseCase.Redeem(phone, otp.PasswordReset, ticket); err != nil {
+		return nil, err
+	}
+	return i.issueToken(record)
+}
+
+// Complete sets the new password and signs the user out everywhere.
+func (i *impl) Complete(phone, token, password string) error {
+	phone = strings.TrimSpace(phone)
+	if password == "" {
+		return reset.ErrNoPassword
+	}
+	if _, err := i.attempt(phone); err != nil {
+		return err
+	}
+
+	record, err := i.Repository.RedeemToken(phone, token)
+	if err == reset.ErrInvalidToken {
+		return err
+	}
+	if err != nil {
+		return i.Service.MongoDbError("password reset", err)
+	}
+
+	nUser, err := i.UserRepo.GetWithId(record.UserId)
+	if err != nil {
+		return i.Service.MongoDbError("user", err)
+	}
+	hash, err := i.Password.Generate(password)
+	if err != nil {
+		logger.Error("unable to hash password", err)
+		return apperror.Wrap(err, apperror.Internal, apperror.Internal.Code(), "error updating password")
+	}
+	nUser.Password = hash
+	if err = i.UserRepo.Update(nUser); err != nil {
+		return i.Service.MongoDbError("user", err)
+	}
+
+	if err = i.SessionUseCase.RevokeAllFor(record.UserId, ""); err != nil {
+		return err
+	}
+	if err = i.NotificationRepo.SendNotification(notification.NewPasswordResetNotification(record.UserId)); err != nil {
+		logger.Error("unable to send password reset notification", err)
+	}
+	return nil
+}
+
+func (i *impl) issueToken(record *reset.Reset) (*reset.Progress, error) {
+	token, err := record.IssueToken()
+	if err != nil {
+		logger.Error("unable to issue reset token", err)
+		return nil, apperror.Wrap(err, apperror.Internal, apperror.Internal.Code(), "unable to reset password")
+	}
+	if err = i.Repository.Save(record); err != nil {
+		return nil, i.Service.MongoDbError("password reset", err)
+	}
+	return &reset.Progress{ResetToken: token}, nil
+}
+
+func (i *impl) get(phone string) (*reset.Reset, error) {
+	record, err := i.Repository.Get(phone)
+	if err == mongo.ErrNoDocuments {
+		return nil, reset.ErrNotStarted
+	}
+	if err != nil {
+		return nil, i.Service.MongoDbError("password reset", err)
+	}
+	if record.IsExpired() {
+		return nil, reset.ErrNotStarted
+	}
+	return record, nil
+}
+
+// attempt counts an attempt against the reset before it is checked, so parallel guesses cannot
+// get past the limit.
+func (i *impl) attempt(phone string) (*reset.Reset, error) {
+	record, err := i.Repository.Attempt(phone)
+	if err == mongo.ErrNoDocuments {
+		if _, err = i.get(phone); err != nil {
+			return nil, err
+		}
+		return nil, reset.ErrTooManyAttempts
+	}
+	if err != nil {
+		return nil, i.Service.MongoDbError("password reset", err)
+	}
+	return record, nil
+}
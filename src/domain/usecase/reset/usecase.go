@@ -0,0 +1,10 @@
+package reset
+
+import "DX/src/domain/entity/reset"
+
+type UseCase interface {
+	Request(string, reset.Channel) error
+	VerifyCode(string, string) (*reset.Progress, error)
+	AnswerQuestion(string, string, string) (*reset.Progress, error)
+	Complete(string, string, string) error
+}
@@ -0,0 +1,81 @@
+package role
+
+import (
+	"DX/src/domain/entity/role"
+	"DX/src/domain/entity/user"
+	"DX/src/pkg/error_service"
+)
+
+type impl struct {
+	role.Repository
+	UserRepo user.Repository
+	error_service.Service
+}
+
+func NewUseCase(repository role.Repository, userRepo user.Repository, service error_service.Service) UseCase {
+	return &impl{
+		Repository: repository,
+		UserRepo:   userRepo,
+		Service:    service,
+	}
+}
+
+// GetPermissions returns every permission granted by the roles.
+func (i *impl) GetPermissions(names []string) ([]role.Permission, error) {
+	if len(names) == 0 {
+		return []role.Permission{}, nil
+	}
+
+	roles, err := i.Repository.GetMany(names)
+	if err != nil {
+		return nil, i.Service.MongoDbError("role", err)
+	}
+
+	permissions := make([]role.Permission, 0)
+	for _, permission := range role.Permissions {
+		for _, nRole := range roles {
+			if nRole.Grants(permission) {
+				permissions = append(permissions, permission)
+				break
+			}
+		}
+	}
+	return permissions, nil
+}
+
+// HasPermissions returns role.ErrForbidden unless the roles grant all the permissions.
+func (i *impl) HasPermissions(names []string, required ...role.Permission) error {
+	permissions, err := i.GetPermissions(names)
+	if err != nil {
+		return err
+	}
+
+	granted := make(map[role.Permission]bool, len(permissions))
+	for _, permission := range permissions {
+		granted[permission] = true
+	}
+	for _, permission := range required {
+		if !granted[permission] {
+			return role.ErrForbidden
+		}
+	}
+	return nil
+}
+
+// Bootstrap makes the user with the phone number a super-admin, so a new deployment has someone to
+// assign roles.
+func (i *impl) Bootstrap(phone string) error {
+	nUser, err := i.UserRepo.GetWithPhone(phone)
+	if err != nil {
+		return i.Service.MongoDbError("user", err)
+	}
+	if nUser.IsSuperAdmin() {
+		return nil
+	}
+
+	roles := append([]string{role.SuperAdmin}, nUser.RoleNames()...)
+	if err = i.UserRepo.SetRoles(nUser.Id.Hex(), nUser.Id.Hex(), roles); err != nil {
+		return i.Service.MongoDbError("user", err)
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+package role
+
+import "DX/src/domain/entity/role"
+
+type UseCase interface {
+	GetPermissions([]string) ([]role.Permission, error)
+	HasPermissions([]string, ...role.Permission) error
+	Bootstrap(string) error
+}
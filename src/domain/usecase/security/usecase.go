@@ -4,98 +4,256 @@ import (
 	"DX/src/domain/entity/auth"
 	"DX/src/domain/entity/security"
 	"DX/src/domain/entity/user"
+	"DX/src/pkg/apperror"
 	"DX/src/pkg/error_service"
+	"DX/src/pkg/password_service"
 	"DX/src/utils"
-	"errors"
+	"DX/src/utils/logger"
+	"go.mongodb.org/mongo-driver/mongo"
+	"strings"
 )
 
 type UseCase interface {
-	GetSecurity(string) (string, error)
-	GetSecurityWithPhone(string) (string, error)
-	SaveSecurity(string, *security.Security) error
-	Validate(string, string) error
+	GetQuestions() ([]security.Question, error)
+	GetSecurity(*auth.Principal) ([]security.Prompt, error)
+	SaveSecurity(*auth.Principal, map[string]string) error
+	GetChallenge(string) ([]security.Prompt, error)
+	GetUserChallenge(string) ([]security.Prompt, error)
+	Verify(string, map[string]string) (string, error)
+	RedeemTicket(string, string) error
 }
 
 type impl struct {
-	auth.Manager
 	security.Repository
 	UserRepo user.Repository
+	Password password_service.Service
 	error_service.Service
 }
 
-func NewUseCase(manager auth.Manager, repository security.Repository, service error_service.Service, UserRepo user.Repository) UseCase {
+func NewUseCase(repository security.Repository, service error_service.Service, UserRepo user.Repository,
+	passwordService password_service.Service) UseCase {
 	return &impl{
-		Manager:    manager,
 		Repository: repository,
 		Service:    service,
 		UserRepo:   UserRepo,
+		Password:   passwordService,
 	}
 }
 
-func (i *impl) GetSecurity(token string) (string, error) {
-	userId, resp := i.Manager.Get(token)
-	if resp != nil {
-		return "", errors.New("unable to get security question")
+func (i *impl) GetQuestions() ([]security.Question, error) {
+	questions, err := i.Repository.GetQuestions(true)
+	if err != nil {
+		return nil, i.Service.MongoDbError("security questions", err)
 	}
+	return questions, nil
+}
 
-	sec, err := i.Repository.Get(*userId)
+func (i *impl) GetSecurity(principal *auth.Principal) ([]security.Prompt, error) {
+	sec, err := i.get(principal.UserId)
 	if err != nil {
-		mongoResp := i.Service.HandleMongoDbError("security question", err)
-		return "", errors.New(mongoResp.Message)
+		return nil, err
+	}
+	return sec.Prompts(), nil
+}
+
+// SaveSecurity replaces the answers of the user, the questions must come from the catalogue.
+func (i *impl) SaveSecurity(principal *auth.Principal, answers map[string]string) error {
+	if len(answers) < security.MinAnswers || len(answers) > security.MaxAnswers {
+		return security.ErrAnswerCount
+	}
+
+	nUser, err := i.UserRepo.GetWithId(principal.UserId)
+	if err != nil {
+		return i.Service.MongoDbError("security question", err)
+	}
+
+	sec, err := i.Repository.Get(principal.UserId)
+	if err == mongo.ErrNoDocuments {
+		sec = &security.Security{}
+	} else if err != nil {
+		return i.Service.MongoDbError("security question", err)
+	}
+	sec.UserId = nUser.Id.Hex()
+	sec.PhoneNumber = nUser.PhoneNumber
+	sec.Answers = nil
+	sec.Challenge = nil
+	sec.LegacyQuestion = ""
+	sec.LegacyAnswer = ""
+
+	for questionId, answer := range answers {
+		question, err := i.Repository.GetQuestion(questionId)
+		if err == mongo.ErrNoDocuments || err == security.ErrUnknownQuestion || (err == nil && !question.Active) {
+			return security.ErrUnknownQuestion
+		}
+		if err != nil {
+			return i.Service.MongoDbError("security question", err)
+		}
+
+		hash, err := i.Password.Generate(security.Normalize(answer))
+		if err != nil {
+			logger.Error("unable to hash security answer", err)
+			return apperror.Wrap(err, apperror.Internal, apperror.Internal.Code(), "unable to save security questions")
+		}
+		sec.Answers = append(sec.Answers, security.Answer{
+			QuestionId: question.Id.Hex(),
+			Question:   question.Text,
+			Hash:       hash,
+		})
 	}
 
-	return sec.Question, nil
+	if err = i.Repository.Save(sec); err != nil {
+		return i.Service.MongoDbError("security question", err)
+	}
+	return nil
 }
 
-func (i *impl) GetSecurityWithPhone(phone string) (string, error) {
-	if !utils.IsValidPhoneNumber(phone) {
-		return "", errors.New("invalid phone number")
+// GetChallenge picks the questions the owner of the phone number has to answer.
+func (i *impl) GetChallenge(phone string) ([]security.Prompt, error) {
+	if !utils.IsValidPhoneNumber(strings.TrimSpace(phone)) {
+		return nil, error_service.ErrInvalidPhoneNumber
 	}
-	sec, err := i.Repository.Get(phone)
+	sec, err := i.get(lookupPhone(phone))
 	if err != nil {
-		mongoResp := i.Service.HandleMongoDbError("security question", err)
-		return "", errors.New(mongoResp.Message)
+		return nil, err
 	}
+	return i.ask(sec)
+}
 
-	return sec.Question, nil
+// GetUserChallenge is GetChallenge for a known user, users without security questions get none.
+func (i *impl) GetUserChallenge(userId string) ([]security.Prompt, error) {
+	sec, err := i.get(userId)
+	if err == security.ErrNotSet {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return i.ask(sec)
 }
 
-func (i *impl) SaveSecurity(token string, sec *security.Security) error {
-	userId, resp := i.Manager.Get(token)
-	if resp != nil {
-		return errors.New("unable to save security questions")
+// Verify checks the answers to the current challenge and returns a ticket proving they were correct. The
+// attempt is counted before the answers are checked, so parallel guesses cannot get past the lock.
+func (i *impl) Verify(phone string, answers map[string]string) (string, error) {
+	if !utils.IsValidPhoneNumber(strings.TrimSpace(phone)) {
+		return "", error_service.ErrInvalidPhoneNumber
+	}
+	sec, err := i.get(lookupPhone(phone))
+	if err != nil {
+		return "", err
+	}
+	if sec.IsLocked() {
+		return "", security.ErrLocked
+	}
+	if !sec.HasChallenge() {
+		return "", security.ErrNoChallenge
 	}
 
-	nUser, err := i.UserRepo.GetWithId(*userId)
+	sec, err = i.Repository.Attempt(sec.UserId)
+	if err == mongo.ErrNoDocuments {
+		return "", security.ErrLocked
+	}
 	if err != nil {
-		mongoResp := i.Service.HandleMongoDbError("security question", err)
-		return errors.New(mongoResp.Message)
+		return "", i.Service.MongoDbError("security question", err)
+	}
+	if sec.FailedAttempts > security.MaxAttempts {
+		return "", i.lock(sec)
+	}
+	if !sec.HasChallenge() {
+		return "", security.ErrNoChallenge
 	}
 
-	sec.UserId = nUser.Id.Hex()
-	sec.PhoneNumber = nUser.PhoneNumber
+	correct := true
+	for _, questionId := range sec.Challenge {
+		answer, _ := sec.Answer(questionId)
+		given, ok := answers[questionId]
+		if !ok || i.Password.Compare(answer.Hash, security.Normalize(given)) != nil {
+			correct = false
+		}
+	}
+
+	if !correct {
+		if sec.IsExhausted() {
+			return "", i.lock(sec)
+		}
+		return "", security.ErrWrongAnswer
+	}
 
-	err = i.Repository.Create(sec)
+	ticket, err := sec.Succeed()
 	if err != nil {
-		mongoResp := i.Service.HandleMongoDbError("security question", err)
-		return errors.New(mongoResp.Message)
+		logger.Error("unable to issue security ticket", err)
+		return "", apperror.Wrap(err, apperror.Internal, apperror.Internal.Code(), "unable to verify security questions")
+	}
+	if err = i.Repository.Save(sec); err != nil {
+		return "", i.Service.MongoDbError("security question", err)
+	}
+	return ticket, nil
+}
+
+func (i *impl) lock(sec *security.Security) error {
+	if err := i.Repository.Lock(sec.UserId); err != nil {
+		return i.Service.MongoDbError("security question", err)
+	}
+	return security.ErrLocked
+}
+
+// RedeemTicket consumes the ticket, it can only be used once.
+func (i *impl) RedeemTicket(userId, ticket string) error {
+	if err := i.Repository.RedeemTicket(userId, ticket); err != nil {
+		if err == security.ErrInvalidTicket {
+			return err
+		}
+		return i.Service.MongoDbError("security question", err)
 	}
 	return nil
 }
 
-func (i *impl) Validate(phone string, answer string) error {
-	if !utils.IsValidPhoneNumber(phone) {
-		return errors.New("invalid phone number")
+func (i *impl) ask(sec *security.Security) ([]security.Prompt, error) {
+	if sec.IsLocked() {
+		return nil, security.ErrLocked
+	}
+	if sec.HasChallenge() {
+		return sec.Ask()
+	}
+
+	prompts, err := sec.Ask()
+	if err != nil {
+		logger.Error("unable to pick security questions", err)
+		return nil, apperror.Wrap(err, apperror.Internal, apperror.Internal.Code(), "unable to get security questions")
+	}
+	if err = i.Repository.Save(sec); err != nil {
+		return nil, i.Service.MongoDbError("security question", err)
+	}
+	return prompts, nil
+}
+
+// get loads the answers of a user, hashing the plain text answer stored by older versions.
+func (i *impl) get(value string) (*security.Security, error) {
+	sec, err := i.Repository.Get(value)
+	if err == mongo.ErrNoDocuments {
+		return nil, security.ErrNotSet
 	}
-	sec, err := i.Repository.Get(phone)
 	if err != nil {
-		mongoResp := i.Service.HandleMongoDbError("security question", err)
-		return errors.New(mongoResp.Message)
+		return nil, i.Service.MongoDbError("security question", err)
 	}
 
-	if sec.Answer != answer {
-		return errors.New("wrong answer to security question")
+	if sec.HasLegacyAnswer() {
+		hash, err := i.Password.Generate(security.Normalize(sec.LegacyAnswer))
+		if err != nil {
+			logger.Error("unable to hash security answer", err)
+			return nil, apperror.Wrap(err, apperror.Internal, apperror.Internal.Code(), "unable to get security questions")
+		}
+		sec.MigrateLegacyAnswer(hash)
+		if err = i.Repository.Save(sec); err != nil {
+			return nil, i.Service.MongoDbError("security question", err)
+		}
 	}
+	if len(sec.Answers) == 0 {
+		return nil, security.ErrNotSet
+	}
+	return sec, nil
+}
 
-	return nil
+// lookupPhone strips the "+" since the repository adds it back, query strings decode it as a space.
+func lookupPhone(phone string) string {
+	return strings.TrimPrefix(strings.TrimSpace(phone), "+")
 }
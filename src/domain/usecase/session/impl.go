@@ -0,0 +1,143 @@
+package session
+
+import (
+	"DX/src/domain/entity/auth"
+	"DX/src/domain/entity/session"
+	"DX/src/pkg/error_service"
+	"errors"
+)
+
+type impl struct {
+	auth.Manager
+	Repository session.Repository
+	error_service.Service
+}
+
+func NewUseCase(manager auth.Manager, repo session.Repository, errorService error_service.Service) UseCase {
+	return &impl{
+		Manager:    manager,
+		Repository: repo,
+		Service:    errorService,
+	}
+}
+
+// Start records a new session for the token, signing in again on a device replaces its previous session.
+func (i *impl) Start(token *auth.Token, info session.Info) error {
+	sessions, err := i.Repository.GetDeviceSessions(token.UserId, token.DeviceId)
+	if err != nil {
+		return i.MongoDbError("session", err)
+	}
+	var ids []string
+	for _, nSession := range sessions {
+		if nSession.Id != token.Family {
+			ids = append(ids, nSession.Id)
+		}
+	}
+	if err = i.revoke(token.UserId, ids...); err != nil {
+		return err
+	}
+
+	if err = i.Repository.Create(session.New(token, info)); err != nil {
+		return i.MongoDbError("session", err)
+	}
+	return nil
+}
+
+func (i *impl) Touch(token *auth.Token, info session.Info) error {
+	if err := i.Repository.Touch(token.Family, info, token.RefreshExpiry()); err != nil {
+		return i.MongoDbError("session", err)
+	}
+	return nil
+}
+
+// End signs out the session of the principal.
+func (i *impl) End(principal *auth.Principal) error {
+	return i.revoke(principal.UserId, principal.SessionId)
+}
+
+func (i *impl) GetSessions(principal *auth.Principal) ([]session.Session, error) {
+	sessions, err := i.Repository.GetSessions(principal.UserId)
+	if err != nil {
+		return nil, i.MongoDbError("session", err)
+	}
+	if sessions == nil {
+		sessions = []session.Session{}
+	}
+	for index := range sessions {
+		sessions[index].Current = sessions[index].Id == principal.SessionId
+	}
+	return sessions, nil
+}
+
+func (i *impl) Revoke(principal *auth.Principal, sessionId string) error {
+	if _, err := i.Repository.Get(principal.UserId, sessionId); err != nil {
+		return i.MongoDbError("session", err)
+	}
+	return i.revoke(principal.UserId, sessionId)
+}
+
+// RevokeAll signs the user out everywhere, optionally keeping the session making the request.
+func (i *impl) RevokeAll(principal *auth.Principal, exceptCurrent bool) error {
+	var except string
+	if exceptCurrent {
+		except = principal.SessionId
+	}
+	return i.RevokeAllFor(principal.UserId, except)
+}
+
+// RevokeAllFor revokes the tokens by user rather than by session, so tokens whose session was never
+// recorded are revoked too.
+func (i *impl) RevokeAllFor(userId, exceptSessionId string) error {
+	if resp := i.Manager.DeleteUserTokens(userId, exceptSessionId); resp != nil {
+		return errors.New(resp.Message)
+	}
+
+	sessions, err := i.Repository.GetSessions(userId)
+	if err != nil {
+		return i.MongoDbError("session", err)
+	}
+	var ids []string
+	for _, nSession := range sessions {
+		if nSession.Id != exceptSessionId {
+			ids = append(ids, nSession.Id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	if err = i.Repository.Delete(userId, ids...); err != nil {
+		return i.MongoDbError("session", err)
+	}
+	return nil
+}
+
+// PassTwoFactor records that the session passed two-factor authentication.
+func (i *impl) PassTwoFactor(userId, sessionId string) error {
+	if err := i.Repository.SetTwoFactor(userId, sessionId); err != nil {
+		return i.MongoDbError("session", err)
+	}
+	return nil
+}
+
+func (i *impl) HasPassedTwoFactor(principal *auth.Principal) (bool, error) {
+	nSession, err := i.Repository.Get(principal.UserId, principal.SessionId)
+	if err != nil {
+		return false, i.MongoDbError("session", err)
+	}
+	return nSession.TwoFactor, nil
+}
+
+func (i *impl) revoke(userId string, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	for _, id := range ids {
+		if resp := i.Manager.DeleteFamily(id); resp != nil {
+			return errors.New(resp.Message)
+		}
+	}
+	if err := i.Repository.Delete(userId, ids...); err != nil {
+		return i.MongoDbError("session", err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package session
+
+import (
+	"DX/src/domain/entity/auth"
+	"DX/src/domain/entity/session"
+	"DX/src/pkg/error_service"
+	"DX/src/pkg/response"
+	"testing"
+)
+
+// fakeManager is the in-memory token store, the sessions use case never refreshes or parses tokens.
+type fakeManager struct {
+	auth.Repository
+}
+
+func (f fakeManager) Refresh(string) (*auth.Token, *response.BaseResponse) {
+	panic("not used")
+}
+
+func (f fakeManager) GetToken(string) (*auth.Token, *response.BaseResponse) {
+	panic("not used")
+}
+
+type fakeRepository struct {
+	session.Repository
+	sessions map[string]session.Session
+}
+
+func (f *fakeRepository) GetSessions(userId string) ([]session.Session, error) {
+	var sessions []session.Session
+	for _, nSession := range f.sessions {
+		if nSession.UserId == userId {
+			sessions = append(sessions, nSession)
+		}
+	}
+	return sessions, nil
+}
+
+func (f *fakeRepository) GetDeviceSessions(userId, deviceId string) ([]session.Session, error) {
+	var sessions []session.Session
+	for _, nSession := range f.sessions {
+		if nSession.UserId == userId && nSession.DeviceId == deviceId {
+			sessions = append(sessions, nSession)
+		}
+	}
+	return sessions, nil
+}
+
+func (f *fakeRepository) Create(nSession *session.Session) error {
+	f.sessions[nSession.Id] = *nSession
+	return nil
+}
+
+func (f *fakeRepository) Delete(userId string, ids ...string) error {
+	for _, id := range ids {
+		if nSession, ok := f.sessions[id]; ok && nSession.UserId == userId {
+			delete(f.sessions, id)
+		}
+	}
+	return nil
+}
+
+func TestRevokeAllFor(t *testing.T) {
+	tests := []struct {
+		name        string
+		keepCurrent bool
+	}{
+		{name: "everywhere"},
+		{name: "except the current session", keepCurrent: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := auth.NewInMemoryRepository()
+			repository := &fakeRepository{sessions: map[string]session.Session{}}
+			useCase := NewUseCase(fakeManager{store}, repository, error_service.New())
+
+			current := startSession(t, store, useCase, "user-1", "device-1")
+			other := startSession(t, store, useCase, "user-1", "device-2")
+			otherUser := startSession(t, store, useCase, "user-2", "device-1")
+			// A token whose session was never recorded, like one from a sign in that failed half way
+			unrecorded := auth.NewUserToken("device-3")
+			if resp := store.CreateToken(unrecorded, "user-1"); resp != nil {
+				t.Fatal(resp.Message)
+			}
+
+			var except string
+			if test.keepCurrent {
+				except = current.Family
+			}
+			if err := useCase.RevokeAllFor("user-1", except); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, token := range []*auth.Token{other, unrecorded} {
+				if _, resp := store.Get(token.AccessUuid); resp == nil {
+					t.Fatalf("token of %s survived", token.DeviceId)
+				}
+			}
+			if _, ok := repository.sessions[other.Family]; ok {
+				t.Fatal("session survived")
+			}
+			if _, resp := store.Get(otherUser.AccessUuid); resp != nil {
+				t.Fatal("token of another user was revoked")
+			}
+			_, resp := store.Get(current.AccessUuid)
+			_, listed := repository.sessions[current.Family]
+			if kept := resp == nil && listed; kept != test.keepCurrent {
+				t.Fatalf("current session kept = %v, want %v", kept, test.keepCurrent)
+			}
+		})
+	}
+}
+
+func startSession(t *testing.T, store auth.Repository, useCase UseCase, userId, deviceId string) *auth.Token {
+	token := auth.NewUserToken(deviceId)
+	if resp := store.CreateToken(token, userId); resp != nil {
+		t.Fatal(resp.Message)
+	}
+	if err := useCase.Start(token, session.Info{}); err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
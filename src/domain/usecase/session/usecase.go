@@ -0,0 +1,18 @@
+package session
+
+import (
+	"DX/src/domain/entity/auth"
+	"DX/src/domain/entity/session"
+)
+
+type UseCase interface {
+	Start(*auth.Token, session.Info) error
+	Touch(*auth.Token, session.Info) error
+	End(*auth.Principal) error
+	GetSessions(*auth.Principal) ([]session.Session, error)
+	Revoke(*auth.Principal, string) error
+	RevokeAll(*auth.Principal, bool) error
+	RevokeAllFor(string, string) error
+	PassTwoFactor(string, string) error
+	HasPassedTwoFactor(*auth.Principal) (bool, error)
+}
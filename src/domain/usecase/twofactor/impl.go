@@ -0,0 +1,233 @@
+package twofactor
+
+import (
+	"DX/src/domain/entity/auth"
+	"DX/src/domain/entity/otp"
+	"DX/src/domain/entity/twofactor"
+	"DX/src/domain/entity/user"
+	sessionUseCase "DX/src/domain/usecase/session"
+	"DX/src/pkg/apperror"
+	"DX/src/pkg/error_service"
+	"DX/src/pkg/totp_service"
+	"DX/src/utils/logger"
+	"go.mongodb.org/mongo-driver/mongo"
+	"strings"
+	"time"
+)
+
+type impl struct {
+	twofactor.Repository
+	UserRepo       user.Repository
+	Totp           totp_service.Service
+	SessionUseCase sessionUseCase.UseCase
+	error_service.Service
+}
+
+func NewUseCase(repository twofactor.Repository, userRepo user.Repository, totpService totp_service.Service,
+	sessionUseCase sessionUseCase.UseCase, service error_service.Service) UseCase {
+	return &impl{
+		Repository:     repository,
+		UserRepo:       userRepo,
+		Totp:           totpService,
+		SessionUseCase: sessionUseCase,
+		Service:        service,
+	}
+}
+
+// Enroll starts setting up an authenticator app, it is only enabled once a code from it is confirmed.
+func (i *impl) Enroll(principal *auth.Principal) (*twofactor.Enrollment, error) {
+	nUser, err := i.UserRepo.GetWithId(principal.UserId)
+	if err != nil {
+		return nil, i.Service.MongoDbError("user", err)
+	}
+	if existing, err := i.get(principal.UserId); err == nil && existing.Enabled {
+		return nil, twofactor.ErrAlreadyEnabled
+	}
+
+	secret, err := i.Totp.GenerateSecret()
+	if err != nil {
+		logger.Error("unable to generate two-factor secret", err)
+		return nil, apperror.Wrap(err, apperror.Internal, apperror.Internal.Code(), "unable to set up two-factor authentication")
+	}
+	codes, hashes, err := twofactor.NewRecoveryCodes()
+	if err != nil {
+		logger.Error("unable to generate recovery codes", err)
+		return nil, apperror.Wrap(err, apperror.Internal, apperror.Internal.Code(), "unable to set up two-factor authentication")
+	}
+
+	nTwoFactor := twofactor.New(principal.UserId, secret)
+	nTwoFactor.RecoveryCodes = hashes
+	if err = i.Repository.Save(nTwoFactor); err != nil {
+		return nil, i.Service.MongoDbError("two-factor authentication", err)
+	}
+
+	return &twofactor.Enrollment{
+		Secret:          secret,
+		ProvisioningUri: i.Totp.ProvisioningUri(secret, nUser.PhoneNumber),
+		RecoveryCodes:   codes,
+	}, nil
+}
+
+// Confirm enables two-factor authentication with a code from the enrolled app. The session making the
+// request counts as having passed it.
+func (i *impl) Confirm(principal *auth.Principal, code string) error {
+	nTwoFactor, err := i.get(principal.UserId)
+	if err != nil {
+		return err
+	}
+	if nTwoFactor.Enabled {
+		return twofactor.ErrAlreadyEnabled
+	}
+	if err = i.checkCode(nTwoFactor, code); err != nil {
+		return err
+	}
+
+	nTwoFactor.Enable()
+	if err = i.Repository.Save(nTwoFactor); err != nil {
+		return i.Service.MongoDbError("two-factor authentication", err)
+	}
+	return i.SessionUseCase.PassTwoFactor(principal.UserId, principal.SessionId)
+}
+
+func (i *impl) Disable(principal *auth.Principal, code string) error {
+	if principal.IsAdmin() {
+		return twofactor.ErrMandatory
+	}
+
+	nTwoFactor, err := i.enabled(principal.UserId)
+	if err != nil {
+		return err
+	}
+	if err = i.verify(nTwoFactor, code); err != nil {
+		return err
+	}
+	if err = i.Repository.Delete(principal.UserId); err != nil {
+		return i.Service.MongoDbError("two-factor authentication", err)
+	}
+	return nil
+}
+
+func (i *impl) RegenerateRecoveryCodes(principal *auth.Principal, code string) ([]string, error) {
+	nTwoFactor, err := i.enabled(principal.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if err = i.checkCode(nTwoFactor, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := twofactor.NewRecoveryCodes()
+	if err != nil {
+		logger.Error("unable to generate recovery codes", err)
+		return nil, apperror.Wrap(err, apperror.Internal, apperror.Internal.Code(), "unable to generate recovery codes")
+	}
+	nTwoFactor.RecoveryCodes = hashes
+	if err = i.Repository.Save(nTwoFactor); err != nil {
+		return nil, i.Service.MongoDbError("two-factor authentication", err)
+	}
+	return codes, nil
+}
+
+func (i *impl) IsEnabled(userId string) (bool, error) {
+	nTwoFactor, err := i.get(userId)
+	if err == twofactor.ErrNotEnrolled {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return nTwoFactor.Enabled, nil
+}
+
+// Challenge holds a sign in until the second factor is verified and returns the value identifying it.
+func (i *impl) Challenge(nUser *user.User) (string, error) {
+	challenge, value, err := twofactor.NewChallenge(nUser.Id.Hex(), nUser.DeviceId, string(nUser.Client))
+	if err != nil {
+		logger.Error("unable to create sign in challenge", err)
+		return "", apperror.Wrap(err, apperror.Internal, apperror.Internal.Code(), "unable to sign in")
+	}
+	if err = i.Repository.CreateChallenge(challenge); err != nil {
+		return "", i.Service.MongoDbError("sign in challenge", err)
+	}
+	return value, nil
+}
+
+// VerifyChallenge accepts a code from the authenticator app or a recovery code and consumes the challenge.
+func (i *impl) VerifyChallenge(value, code string) (*twofactor.Challenge, error) {
+	challenge, err := i.Repository.AttemptChallenge(otp.Hash(value))
+	if err == twofactor.ErrInvalidChallenge {
+		return nil, err
+	}
+	if err != nil {
+		return nil, i.Service.MongoDbError("sign in challenge", err)
+	}
+
+	nTwoFactor, err := i.enabled(challenge.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if err = i.verify(nTwoFactor, code); err != nil {
+		if challenge.Attempts >= twofactor.MaxAttempts {
+			return nil, twofactor.ErrInvalidChallenge
+		}
+		return nil, err
+	}
+
+	if err = i.Repository.DeleteChallenge(challenge); err != nil {
+		logger.Error("unable to delete sign in challenge", err)
+	}
+	return challenge, nil
+}
+
+// verify accepts either a code from the app or one of the recovery codes.
+func (i *impl) verify(nTwoFactor *twofactor.TwoFactor, code string) error {
+	code = strings.TrimSpace(code)
+	if err := i.checkCode(nTwoFactor, code); err != twofactor.ErrWrongCode {
+		return err
+	}
+
+	err := i.Repository.UseRecoveryCode(nTwoFactor.UserId, twofactor.HashRecoveryCode(code))
+	if err == nil || err == twofactor.ErrWrongCode {
+		return err
+	}
+	return i.Service.MongoDbError("two-factor authentication", err)
+}
+
+func (i *impl) checkCode(nTwoFactor *twofactor.TwoFactor, code string) error {
+	step, ok := i.Totp.Validate(nTwoFactor.Secret, strings.TrimSpace(code), nTwoFactor.LastStep, time.Now())
+	if !ok {
+		return twofactor.ErrWrongCode
+	}
+
+	err := i.Repository.UseStep(nTwoFactor.UserId, step)
+	if err == twofactor.ErrWrongCode {
+		return err
+	}
+	if err != nil {
+		return i.Service.MongoDbError("two-factor authentication", err)
+	}
+	nTwoFactor.LastStep = step
+	return nil
+}
+
+func (i *impl) enabled(userId string) (*twofactor.TwoFactor, error) {
+	nTwoFactor, err := i.get(userId)
+	if err != nil {
+		return nil, err
+	}
+	if !nTwoFactor.Enabled {
+		return nil, twofactor.ErrNotEnrolled
+	}
+	return nTwoFactor, nil
+}
+
+func (i *impl) get(userId string) (*twofactor.TwoFactor, error) {
+	nTwoFactor, err := i.Repository.Get(userId)
+	if err == mongo.ErrNoDocuments {
+		return nil, twofactor.ErrNotEnrolled
+	}
+	if err != nil {
+		return nil, i.Service.MongoDbError("two-factor authentication", err)
+	}
+	return nTwoFactor, nil
+}
@@ -0,0 +1,17 @@
+package twofactor
+
+import (
+	"DX/src/domain/entity/auth"
+	"DX/src/domain/entity/twofactor"
+	"DX/src/domain/entity/user"
+)
+
+type UseCase interface {
+	Enroll(*auth.Principal) (*twofactor.Enrollment, error)
+	Confirm(*auth.Principal, string) error
+	Disable(*auth.Principal, string) error
+	RegenerateRecoveryCodes(*auth.Principal, string) ([]string, error)
+	IsEnabled(string) (bool, error)
+	Challenge(*user.User) (string, error)
+	VerifyChallenge(string, string) (*twofactor.Challenge, error)
+}
@@ -2,57 +2,99 @@ package wallet
 
 import (
 	"DX/src/domain/entity/auth"
+	"DX/src/domain/entity/errand"
 	"DX/src/domain/entity/wallet"
+	pinUseCase "DX/src/domain/usecase/pin"
 	"DX/src/pkg/error_service"
-	"errors"
+	"time"
 )
 
+const (
+	maxStatementPeriod = 366 * 24 * time.Hour
+	topCategoriesLimit = int64(5)
+)
+
+type EarningsDashboard struct {
+	Period        string                    `json:"period"`
+	From          time.Time                 `json:"from"`
+	To            time.Time                 `json:"to"`
+	Available     int64                     `json:"available"` // Withdrawable balance
+	Pending       *errand.PendingEarnings   `json:"pending"`
+	Lifetime      *wallet.Earnings          `json:"lifetime"`
+	Summary       wallet.Earnings           `json:"summary"` // Totals for the selected range
+	Series        []wallet.EarningsPeriod   `json:"series"`
+	TopCategories []errand.CategoryEarnings `json:"top_categories"`
+}
+
 type UseCase interface {
-	AddMoney(string, int64) error
-	RemoveMoney(string, int64) error
-	GetWalletFor(string) (*wallet.Wallet, error)
+	AddMoney(*auth.Principal, int64, string) error
+	RemoveMoney(*auth.Principal, int64, string) error
+	Withdraw(*auth.Principal, int64, string) error
+	GetWalletFor(*auth.Principal) (*wallet.Wallet, error)
 	GetBalance() (int64, error)
+	GetTransactions(*auth.Principal, wallet.Filter) (*wallet.Page, error)
+	GetStatement(*auth.Principal, time.Time, time.Time) (*wallet.Statement, error)
+	GetUserTransactions(string, wallet.Filter) (*wallet.Page, error)
+	GetUserStatement(string, time.Time, time.Time) (*wallet.Statement, error)
+	GetEarnings(*auth.Principal, wallet.Period, time.Time, time.Time) (*EarningsDashboard, error)
 }
 
 type impl struct {
-	auth.Manager
 	wallet.Repository
 	error_service.Service
+	PinUseCase pinUseCase.UseCase
+	ErrandRepo errand.Repository
 }
 
-func NewUseCase(repo wallet.Repository, service error_service.Service, authManager auth.Manager) UseCase {
+func NewUseCase(repo wallet.Repository, service error_service.Service, pinUseCase pinUseCase.UseCase,
+	errandRepo errand.Repository) UseCase {
 	return &impl{
 		Repository: repo,
 		Service:    service,
-		Manager:    authManager,
+		PinUseCase: pinUseCase,
+		ErrandRepo: errandRepo,
 	}
 }
 
-func (i *impl) AddMoney(token string, amount int64) error {
-	userId, resp := i.Manager.Get(token)
-	if resp != nil {
-		return errors.New(resp.Message)
+func (i *impl) AddMoney(principal *auth.Principal, amount int64, reference string) error {
+	txn := wallet.NewCreditTransaction(principal.UserId, "Credit", "", amount, wallet.TopUp)
+	if reference != "" {
+		txn.Reference = reference
 	}
-
-	txn := wallet.NewCreditTransaction(*userId, "Credit", "", amount)
 	err := i.Repository.CreateTransaction(txn)
 	if err != nil {
-		return errors.New(i.Service.HandleMongoDbError("transaction", err).Message)
+		return i.Service.MongoDbError("transaction", err)
 	}
 
 	return nil
 }
 
-func (i *impl) RemoveMoney(token string, amount int64) error {
-	userId, resp := i.Manager.Get(token)
-	if resp != nil {
-		return errors.New(resp.Message)
+func (i *impl) RemoveMoney(principal *auth.Principal, amount int64, transactionPin string) error {
+	if err := i.PinUseCase.Verify(principal.UserId, transactionPin); err != nil {
+		return err
 	}
 
-	txn := wallet.NewDebitTransaction(*userId, "Debit", "", amount)
-	err := i.Repository.CreateTransaction(txn)
-	if err != nil {
-		return errors.New(i.Service.HandleMongoDbError("transaction", err).Message)
+	txn := wallet.NewDebitTransaction(principal.UserId, "Debit", "", amount, wallet.Withdrawal)
+	if err := i.Repository.Transfer(txn); err != nil {
+		return i.Service.MongoDbError("transaction", err)
+	}
+
+	return nil
+}
+
+// Withdraw debits the wallet, the repository checks the balance in the same transaction as the debit so
+// concurrent withdrawals cannot overdraw it.
+func (i *impl) Withdraw(principal *auth.Principal, amount int64, transactionPin string) error {
+	if amount <= 0 {
+		return wallet.ErrInvalidWithdrawal
+	}
+	if err := i.PinUseCase.Verify(principal.UserId, transactionPin); err != nil {
+		return err
+	}
+
+	txn := wallet.NewDebitTransaction(principal.UserId, "Withdrawal", "", amount, wallet.Withdrawal)
+	if err := i.Repository.Transfer(txn); err != nil {
+		return i.Service.MongoDbError("transaction", err)
 	}
 
 	return nil
@@ -66,25 +108,130 @@ func (i *impl) GetBalance() (int64, error) {
 	}
 }
 
-func (i *impl) GetWalletFor(token string) (*wallet.Wallet, error) {
-	userId, resp := i.Manager.Get(token)
-	if resp != nil {
-		return nil, errors.New(resp.Message)
+func (i *impl) GetWalletFor(principal *auth.Principal) (*wallet.Wallet, error) {
+	txns, total, err := i.Repository.GetTransactions(wallet.NewFilter(principal.UserId, 1, wallet.DefaultPageLimit))
+	if err != nil {
+		return nil, i.MongoDbError("transaction", err)
 	}
 
-	txns, err := i.GetTransactionsFor(*userId)
+	balance, err := i.Repository.GetBalance(principal.UserId)
 	if err != nil {
-		return nil, errors.New(i.HandleMongoDbError("transaction", err).Message)
+		return nil, i.MongoDbError("balance", err)
 	}
-
-	balance, err := i.Repository.GetBalance(*userId)
+	promoBalance, err := i.Repository.GetPromoBalance(principal.UserId)
 	if err != nil {
-		return nil, errors.New(i.HandleMongoDbError("balance", err).Message)
+		return nil, i.MongoDbError("balance", err)
 	}
 
 	return &wallet.Wallet{
+		Transactions:      txns,
+		TotalTransactions: total,
+		Balance:           balance,
+		PromoBalance:      promoBalance,
+		Escrow:            0,
+	}, nil
+}
+
+func (i *impl) GetTransactions(principal *auth.Principal, filter wallet.Filter) (*wallet.Page, error) {
+	return i.GetUserTransactions(principal.UserId, filter)
+}
+
+func (i *impl) GetUserTransactions(userId string, filter wallet.Filter) (*wallet.Page, error) {
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return nil, wallet.ErrInvalidDateRange
+	}
+	filter.UserId = userId
+
+	txns, total, err := i.Repository.GetTransactions(filter)
+	if err != nil {
+		return nil, i.MongoDbError("transaction", err)
+	}
+	if txns == nil {
+		txns = []wallet.Transaction{}
+	}
+
+	return &wallet.Page{
 		Transactions: txns,
-		Balance:      balance,
-		Escrow:       0,
+		Total:        total,
+		Page:         filter.Page,
+		Limit:        filter.Limit,
 	}, nil
 }
+
+func (i *impl) GetStatement(principal *auth.Principal, from, to time.Time) (*wallet.Statement, error) {
+	return i.GetUserStatement(principal.UserId, from, to)
+}
+
+func (i *impl) GetUserStatement(userId string, from, to time.Time) (*wallet.Statement, error) {
+	if from.IsZero() || to.IsZero() || to.Before(from) {
+		return nil, wallet.ErrInvalidStatementPeriod
+	}
+	if to.Sub(from) > maxStatementPeriod {
+		return nil, wallet.ErrStatementTooLong
+	}
+
+	openingBalance, err := i.Repository.GetBalanceBefore(userId, from)
+	if err != nil {
+		return nil, i.MongoDbError("balance", err)
+	}
+	txns, err := i.Repository.GetStatementTransactions(userId, from, to)
+	if err != nil {
+		return nil, i.MongoDbError("transaction", err)
+	}
+
+	return wallet.NewStatement(userId, from, to, openingBalance, txns), nil
+}
+
+func (i *impl) GetEarnings(principal *auth.Principal, period wallet.Period, from, to time.Time) (*EarningsDashboard, error) {
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = period.Span(to)
+	}
+	if to.Before(from) {
+		return nil, wallet.ErrInvalidDateRange
+	}
+	if to.Sub(from) > maxStatementPeriod {
+		return nil, wallet.ErrEarningsTooLong
+	}
+
+	dashboard := &EarningsDashboard{
+		Period: period.Id(),
+		From:   from,
+		To:     to,
+	}
+	var err error
+	if dashboard.Available, err = i.Repository.GetBalance(principal.UserId); err != nil {
+		return nil, i.MongoDbError("balance", err)
+	}
+	if dashboard.Lifetime, err = i.Repository.GetEarnings(principal.UserId); err != nil {
+		return nil, i.MongoDbError("earnings", err)
+	}
+	if dashboard.Series, err = i.Repository.GetEarningsSeries(principal.UserId, period, from, to); err != nil {
+		return nil, i.MongoDbError("earnings", err)
+	}
+	if dashboard.Pending, err = i.ErrandRepo.GetPendingEarnings(principal.UserId); err != nil {
+		return nil, i.MongoDbError("errand", err)
+	}
+	if dashboard.TopCategories, err = i.ErrandRepo.GetTopCategories(principal.UserId, from, to, topCategoriesLimit); err != nil {
+		return nil, i.MongoDbError("errand", err)
+	}
+	if dashboard.Series == nil {
+		dashboard.Series = []wallet.EarningsPeriod{}
+	}
+	if dashboard.TopCategories == nil {
+		dashboard.TopCategories = []errand.CategoryEarnings{}
+	}
+	for _, earnings := range dashboard.Series {
+		dashboard.Summary.Gross += earnings.Gross
+		dashboard.Summary.Commission += earnings.Commission
+		dashboard.Summary.Payouts += earnings.Payouts
+		dashboard.Summary.Tips += earnings.Tips
+		dashboard.Summary.Compensation += earnings.Compensation
+		dashboard.Summary.Total += earnings.Total
+		dashboard.Summary.Errands += earnings.Errands
+	}
+
+	return dashboard, nil
+}
@@ -0,0 +1,251 @@
+package webhook
+
+import (
+	"DX/src/domain/entity"
+	"DX/src/domain/entity/auth"
+	"DX/src/domain/entity/notification"
+	"DX/src/domain/entity/webhook"
+	"DX/src/pkg/error_service"
+	"DX/src/pkg/webhook_service"
+	"DX/src/utils/logger"
+	"encoding/json"
+	"go.mongodb.org/mongo-driver/mongo"
+	"time"
+)
+
+const (
+	deliveryBatch = 100         // deliveries sent per run of the worker
+	deliveryLease = time.Minute // a claimed delivery is retried after this if the worker stops
+	deliveryLimit = 50          // deliveries listed per webhook
+)
+
+type impl struct {
+	webhook.Repository
+	Sender           webhook_service.Service
+	NotificationRepo notification.Repository
+	error_service.Service
+}
+
+func NewUseCase(repository webhook.Repository, sender webhook_service.Service, notificationRepo notification.Repository,
+	service error_service.Service) UseCase {
+	return &impl{
+		Repository:       repository,
+		Sender:           sender,
+		NotificationRepo: notificationRepo,
+		Service:          service,
+	}
+}
+
+// CreateSubscription registers an endpoint, the returned secret signs its payloads and is not shown again.
+func (i *impl) CreateSubscription(principal *auth.Principal, request webhook.Request) (*webhook.Created, error) {
+	count, err := i.Repository.CountSubscriptions(principal.UserId)
+	if err != nil {
+		return nil, i.Service.MongoDbError("webhook", err)
+	}
+	if count >= webhook.MaxSubscriptions {
+		return nil, webhook.ErrTooManySubscriptions
+	}
+
+	created, err := webhook.NewSubscription(principal.UserId, request)
+	if err != nil {
+		return nil, err
+	}
+	if err = i.Repository.CreateSubscription(created.Subscription); err != nil {
+		return nil, i.Service.MongoDbError("webhook", err)
+	}
+	return created, nil
+}
+
+func (i *impl) GetSubscriptions(principal *auth.Principal) ([]webhook.Subscription, error) {
+	subscriptions, err := i.Repository.GetSubscriptions(principal.UserId)
+	if err != nil {
+		return nil, i.Service.MongoDbError("webhook", err)
+	}
+	if subscriptions == nil {
+		subscriptions = []webhook.Subscription{}
+	}
+	return subscriptions, nil
+}
+
+func (i *impl) UpdateSubscription(principal *auth.Principal, id string, update webhook.Update) (*webhook.Subscription, error) {
+	subscription, err := i.getSubscription(principal.UserId, id)
+	if err != nil {
+		return nil, err
+	}
+	if err = subscription.Set(update); err != nil {
+		return nil, err
+	}
+	if err = i.Repository.UpdateSubscription(subscription); err != nil {
+		return nil, i.Service.MongoDbError("webhook", err)
+	}
+	return subscription, nil
+}
+
+// DeleteSubscription removes the endpoint, deliveries still pending for it are abandoned by the worker.
+func (i *impl) DeleteSubscription(principal *auth.Principal, id string) error {
+	err := i.Repository.DeleteSubscription(principal.UserId, id)
+	if err == webhook.ErrUnknownSubscription {
+		return err
+	}
+	if err != nil {
+		return i.Service.MongoDbError("webhook", err)
+	}
+	return nil
+}
+
+func (i *impl) GetDeliveries(principal *auth.Principal, subscriptionId string) ([]webhook.Delivery, error) {
+	if _, err := i.getSubscription(principal.UserId, subscriptionId); err != nil {
+		return nil, err
+	}
+	deliveries, err := i.Repository.GetDeliveries(principal.UserId, subscriptionId, deliveryLimit)
+	if err != nil {
+		return nil, i.Service.MongoDbError("webhook delivery", err)
+	}
+	if deliveries == nil {
+		deliveries = []webhook.Delivery{}
+	}
+	return deliveries, nil
+}
+
+// Redeliver queues the event of a past delivery to be sent again.
+func (i *impl) Redeliver(principal *auth.Principal, subscriptionId, deliveryId string) (*webhook.Delivery, error) {
+	subscription, err := i.getSubscription(principal.UserId, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+	if !subscription.Active {
+		return nil, webhook.ErrDisabled
+	}
+	delivery, err := i.Repository.GetDelivery(principal.UserId, deliveryId)
+	if err == webhook.ErrUnknownDelivery {
+		return nil, err
+	}
+	if err != nil {
+		return nil, i.Service.MongoDbError("webhook delivery", err)
+	}
+	if delivery.SubscriptionId != subscriptionId {
+		return nil, webhook.ErrUnknownDelivery
+	}
+
+	nDelivery := delivery.Redeliver(subscription)
+	if err = i.Repository.CreateDeliveries(nDelivery); err != nil {
+		return nil, i.Service.MongoDbError("webhook delivery", err)
+	}
+	return nDelivery, nil
+}
+
+// Publish queues the event for every endpoint of the user subscribed to it.
+func (i *impl) Publish(userId string, event webhook.Event, data interface{}) error {
+	subscriptions, err := i.Repository.GetSubscribers(userId, event)
+	if err != nil {
+		return i.Service.MongoDbError("webhook", err)
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	eventId := entity.NewDefaultId().String()
+	payload, err := json.Marshal(webhook.Payload{Id: eventId, Type: event, CreatedAt: time.Now(), Data: data})
+	if err != nil {
+		return err
+	}
+	deliveries := make([]*webhook.Delivery, 0, len(subscriptions))
+	for index := range subscriptions {
+		deliveries = append(deliveries, webhook.NewDelivery(&subscriptions[index], eventId, event, string(payload)))
+	}
+	if err = i.Repository.CreateDeliveries(deliveries...); err != nil {
+		return i.Service.MongoDbError("webhook delivery", err)
+	}
+	return nil
+}
+
+// DeliverDue sends the deliveries whose next attempt is due, it is run by the webhook worker.
+func (i *impl) DeliverDue() {
+	for count := 0; count < deliveryBatch; count++ {
+		delivery, err := i.Repository.ClaimDelivery(time.Now(), deliveryLease)
+		if err == mongo.ErrNoDocuments {
+			return
+		}
+		if err != nil {
+			logger.Error("unable to claim webhook delivery", err)
+			return
+		}
+		i.deliver(delivery)
+	}
+}
+
+func (i *impl) deliver(delivery *webhook.Delivery) {
+	subscription, err := i.Repository.GetSubscription(delivery.UserId, delivery.SubscriptionId)
+	switch {
+	case err == webhook.ErrUnknownSubscription:
+		delivery.Abandon("webhook was deleted")
+	case err != nil:
+		logger.Error("unable to get webhook", err)
+		return
+	case !subscription.Active:
+		delivery.Abandon(webhook.ErrDisabled.Error())
+	default:
+		attempt := i.send(subscription, delivery)
+		delivery.Record(attempt)
+		i.recordResult(subscription, attempt.Succeeded())
+	}
+
+	if err = i.Repository.SaveDelivery(delivery); err != nil {
+		logger.Error("unable to save webhook delivery", err)
+	}
+}
+
+func (i *impl) send(subscription *webhook.Subscription, delivery *webhook.Delivery) webhook.Attempt {
+	cTime := time.Now()
+	body := []byte(delivery.Payload)
+	resp, err := i.Sender.Post(webhook_service.Request{
+		Url: subscription.Url,
+		Headers: map[string]string{
+			webhook.SignatureHeader: subscription.Sign(cTime, body),
+			webhook.EventHeader:     string(delivery.Event),
+			webhook.DeliveryHeader:  delivery.Id.Hex(),
+		},
+		Body: body,
+	})
+
+	attempt := webhook.Attempt{At: cTime, Duration: time.Since(cTime).Milliseconds()}
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	attempt.ResponseCode = resp.Code
+	attempt.ResponseBody = resp.Body
+	return attempt
+}
+
+// recordResult keeps the count of failures in a row and lets the user know when it disabled the endpoint.
+func (i *impl) recordResult(subscription *webhook.Subscription, succeeded bool) {
+	if succeeded {
+		if err := i.Repository.Succeed(subscription.Id); err != nil {
+			logger.Error("unable to reset webhook failures", err)
+		}
+		return
+	}
+
+	disabled, err := i.Repository.Fail(subscription.Id)
+	if err != nil {
+		logger.Error("unable to count webhook failure", err)
+		return
+	}
+	if disabled {
+		if err = i.NotificationRepo.SendNotification(notification.NewWebhookDisabledNotification(subscription.UserId, subscription.Url)); err != nil {
+			logger.Error("Failed to send notifications", err)
+		}
+	}
+}
+
+func (i *impl) getSubscription(userId, id string) (*webhook.Subscription, error) {
+	subscription, err := i.Repository.GetSubscription(userId, id)
+	if err == webhook.ErrUnknownSubscription {
+		return nil, err
+	}
+	if err != nil {
+		return nil, i.Service.MongoDbError("webhook", err)
+	}
+	return subscription, nil
+}
@@ -0,0 +1,17 @@
+package webhook
+
+import (
+	"DX/src/domain/entity/auth"
+	"DX/src/domain/entity/webhook"
+)
+
+type UseCase interface {
+	CreateSubscription(*auth.Principal, webhook.Request) (*webhook.Created, error)
+	GetSubscriptions(*auth.Principal) ([]webhook.Subscription, error)
+	UpdateSubscription(*auth.Principal, string, webhook.Update) (*webhook.Subscription, error)
+	DeleteSubscription(*auth.Principal, string) error
+	GetDeliveries(*auth.Principal, string) ([]webhook.Delivery, error)
+	Redeliver(*auth.Principal, string, string) (*webhook.Delivery, error)
+	Publish(string, webhook.Event, interface{}) error
+	DeliverDue()
+}
@@ -0,0 +1,137 @@
+package apperror
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Kind is the class of an error, it decides the HTTP status.
+type Kind int
+
+const (
+	Invalid Kind = iota
+	Unauthorized
+	Forbidden
+	NotFound
+	Conflict
+	RateLimited
+	Internal
+	Unavailable
+)
+
+func (k Kind) Status() int {
+	switch k {
+	case Unauthorized:
+		return http.StatusUnauthorized
+	case Forbidden:
+		return http.StatusForbidden
+	case NotFound:
+		return http.StatusNotFound
+	case Conflict:
+		return http.StatusConflict
+	case RateLimited:
+		return http.StatusTooManyRequests
+	case Internal:
+		return http.StatusInternalServerError
+	case Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// Code is the code of errors of the kind that do not have their own.
+func (k Kind) Code() string {
+	switch k {
+	case Unauthorized:
+		return "unauthorized"
+	case Forbidden:
+		return "forbidden"
+	case NotFound:
+		return "not_found"
+	case Conflict:
+		return "conflict"
+	case RateLimited:
+		return "rate_limited"
+	case Internal:
+		return "internal_error"
+	case Unavailable:
+		return "unavailable"
+	default:
+		return "invalid_request"
+	}
+}
+
+const internalMessage = "something went wrong, please try again"
+
+// Error is an error clients can act on. Code is stable and meant for programs, Message is safe to show to
+// users and Err holds the internal detail, which is logged but never sent.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Err     error
+}
+
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+// Wrap keeps err as the internal cause of the error.
+func Wrap(err error, kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message, Err: err}
+}
+
+// Unexpected hides err behind a generic message.
+func Unexpected(err error) *Error {
+	return Wrap(err, Internal, Internal.Code(), internalMessage)
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches errors by code, so a sentinel still matches after a cause is added to it.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithCause is a copy of the error with err as its internal cause.
+func (e *Error) WithCause(err error) *Error {
+	nErr := *e
+	nErr.Err = err
+	return &nErr
+}
+
+// WithDetail is a copy of the error whose message ends with detail, like the value that was rejected.
+func (e *Error) WithDetail(detail string) *Error {
+	nErr := *e
+	nErr.Message = e.Message + ": " + detail
+	return &nErr
+}
+
+func (e *Error) Status() int {
+	return e.Kind.Status()
+}
+
+// From finds the Error in the chain of err. Timeouts are reported as unavailable and any other error is
+// unexpected, its message is never sent since nothing says it is safe to show.
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return Wrap(err, Unavailable, Unavailable.Code(), "the service is busy, please try again")
+	}
+	return Unexpected(err)
+}
@@ -0,0 +1,129 @@
+package apperror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+var errSample = New(Conflict, "sample_conflict", "sample conflict")
+
+func TestFrom(t *testing.T) {
+	cause := errors.New("connection reset by peer")
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		code    string
+		message string
+	}{
+		{
+			name:    "application error",
+			err:     errSample,
+			status:  http.StatusConflict,
+			code:    "sample_conflict",
+			message: "sample conflict",
+		},
+		{
+			name:    "wrapped application error",
+			err:     fmt.Errorf("saving errand: %w", errSample.WithCause(cause)),
+			status:  http.StatusConflict,
+			code:    "sample_conflict",
+			message: "sample conflict",
+		},
+		{
+			name:    "deadline",
+			err:     fmt.Errorf("finding errand: %w", context.DeadlineExceeded),
+			status:  http.StatusServiceUnavailable,
+			code:    "unavailable",
+			message: "the service is busy, please try again",
+		},
+		{
+			name:    "unknown error",
+			err:     cause,
+			status:  http.StatusInternalServerError,
+			code:    "internal_error",
+			message: internalMessage,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			appErr := From(test.err)
+			if appErr.Status() != test.status || appErr.Code != test.code || appErr.Message != test.message {
+				t.Fatalf("From() = %d %q %q, want %d %q %q", appErr.Status(), appErr.Code, appErr.Message, test.status, test.code, test.message)
+			}
+		})
+	}
+}
+
+func TestFromKeepsCause(t *testing.T) {
+	cause := errors.New("dial tcp: connection refused")
+	appErr := From(cause)
+	if !errors.Is(appErr, cause) {
+		t.Fatalf("From() lost the cause %v", cause)
+	}
+}
+
+func TestIs(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		target error
+		is     bool
+	}{
+		{name: "same error", err: errSample, target: errSample, is: true},
+		{name: "with cause", err: errSample.WithCause(errors.New("write conflict")), target: errSample, is: true},
+		{name: "with detail", err: errSample.WithDetail("errand-1"), target: errSample, is: true},
+		{name: "wrapped", err: fmt.Errorf("tip: %w", errSample), target: errSample, is: true},
+		{name: "same code", err: New(Invalid, "sample_conflict", "another message"), target: errSample, is: true},
+		{name: "other code", err: New(Conflict, "other_conflict", "sample conflict"), target: errSample},
+		{name: "plain error", err: errors.New("sample conflict"), target: errSample},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if is := errors.Is(test.err, test.target); is != test.is {
+				t.Fatalf("errors.Is() = %v, want %v", is, test.is)
+			}
+		})
+	}
+}
+
+func TestWithCause(t *testing.T) {
+	cause := errors.New("write conflict")
+	nErr := errSample.WithCause(cause)
+	if errSample.Err != nil {
+		t.Fatal("WithCause changed the sentinel")
+	}
+	if nErr.Error() != "sample conflict: write conflict" {
+		t.Fatalf("Error() = %q", nErr.Error())
+	}
+	if !errors.Is(nErr, cause) {
+		t.Fatal("cause is not in the chain")
+	}
+}
+
+func TestStatus(t *testing.T) {
+	tests := []struct {
+		kind   Kind
+		status int
+	}{
+		{Invalid, http.StatusBadRequest},
+		{Unauthorized, http.StatusUnauthorized},
+		{Forbidden, http.StatusForbidden},
+		{NotFound, http.StatusNotFound},
+		{Conflict, http.StatusConflict},
+		{RateLimited, http.StatusTooManyRequests},
+		{Internal, http.StatusInternalServerError},
+		{Unavailable, http.StatusServiceUnavailable},
+	}
+
+	for _, test := range tests {
+		if status := test.kind.Status(); status != test.status {
+			t.Fatalf("%s.Status() = %d, want %d", test.kind.Code(), status, test.status)
+		}
+	}
+}
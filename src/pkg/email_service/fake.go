@@ -0,0 +1,39 @@
+package email_service
+
+import (
+	"DX/src/utils/logger"
+	"fmt"
+	"sync"
+)
+
+// Fake logs emails instead of sending them, it is used locally and in tests.
+type Fake struct {
+	sync.Mutex
+	messages []Message
+}
+
+func NewFake() *Fake {
+	return &Fake{}
+}
+
+func (f *Fake) Send(message Message) error {
+	f.Lock()
+	defer f.Unlock()
+
+	f.messages = append(f.messages, message)
+	logger.Info(fmt.Sprintf("email to %s: %s", message.To, message.Subject))
+	return nil
+}
+
+// Last returns the most recent email sent to the address.
+func (f *Fake) Last(to string) (Message, bool) {
+	f.Lock()
+	defer f.Unlock()
+
+	for index := len(f.messages) - 1; index >= 0; index-- {
+		if f.messages[index].To == to {
+			return f.messages[index], true
+		}
+	}
+	return Message{}, false
+}
@@ -0,0 +1,11 @@
+package email_service
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Service interface {
+	Send(Message) error
+}
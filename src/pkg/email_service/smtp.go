@@ -0,0 +1,155 @@
+package email_service
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	smtpHost     = "SMTP_HOST"
+	smtpPort     = "SMTP_PORT"
+	smtpUsername = "SMTP_USERNAME"
+	smtpPassword = "SMTP_PASSWORD"
+	emailFrom    = "EMAIL_FROM"
+)
+
+var ErrHeaderInjection = errors.New("email: address or subject contains a line break")
+
+type Config struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	Timeout  time.Duration
+}
+
+// ConfigFromEnv reads the SMTP settings, the host is empty when no server is configured.
+func ConfigFromEnv() (Config, error) {
+	config := Config{
+		Host:     os.Getenv(smtpHost),
+		Port:     os.Getenv(smtpPort),
+		Username: os.Getenv(smtpUsername),
+		Password: os.Getenv(smtpPassword),
+		From:     os.Getenv(emailFrom),
+		Timeout:  30 * time.Second,
+	}
+	if config.Host == "" {
+		return config, nil
+	}
+	if config.Port == "" {
+		config.Port = "587"
+	}
+	if config.From == "" {
+		return config, fmt.Errorf("%s is required with %s", emailFrom, smtpHost)
+	}
+	return config, nil
+}
+
+func (c Config) Configured() bool {
+	return c.Host != ""
+}
+
+type smtpService struct {
+	config Config
+}
+
+// NewSmtp sends through an SMTP server, with TLS from the start on port 465 and STARTTLS otherwise.
+// Credentials are only sent over TLS.
+func NewSmtp(config Config) Service {
+	return &smtpService{
+		config: config,
+	}
+}
+
+func (s *smtpService) Send(message Message) error {
+	if strings.ContainsAny(message.To+message.Subject, "\r\n") {
+		return ErrHeaderInjection
+	}
+	content, err := s.content(message)
+	if err != nil {
+		return err
+	}
+
+	address := net.JoinHostPort(s.config.Host, s.config.Port)
+	tlsConfig := &tls.Config{ServerName: s.config.Host}
+	dialer := &net.Dialer{Timeout: s.config.Timeout}
+	var conn net.Conn
+	if s.config.Port == "465" {
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", address)
+	}
+	if err != nil {
+		return err
+	}
+	if err = conn.SetDeadline(time.Now().Add(s.config.Timeout)); err != nil {
+		conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, s.config.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && s.config.Port != "465" {
+		if err = client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if s.config.Username != "" {
+		// PlainAuth refuses to send the password over a connection without TLS
+		if err = client.Auth(smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)); err != nil {
+			return err
+		}
+	}
+	if err = client.Mail(s.config.From); err != nil {
+		return err
+	}
+	if err = client.Rcpt(message.To); err != nil {
+		return err
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = writer.Write(content); err != nil {
+		return err
+	}
+	if err = writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func (s *smtpService) content(message Message) ([]byte, error) {
+	var content bytes.Buffer
+	fmt.Fprintf(&content, "From: %s\r\n", s.config.From)
+	fmt.Fprintf(&content, "To: %s\r\n", message.To)
+	fmt.Fprintf(&content, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&content, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	content.WriteString("MIME-Version: 1.0\r\n")
+	content.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	content.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	writer := quotedprintable.NewWriter(&content)
+	if _, err := writer.Write([]byte(message.Body)); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return content.Bytes(), nil
+}
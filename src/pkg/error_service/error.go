@@ -1,11 +1,15 @@
 package error_service
 
 import (
+	"DX/src/pkg/apperror"
 	"DX/src/pkg/response"
 	"DX/src/utils/logger"
+	"context"
+	"errors"
 	"fmt"
 	"github.com/go-redis/redis/v8"
 	"go.mongodb.org/mongo-driver/mongo"
+	"strings"
 )
 
 type errandError struct{}
@@ -14,49 +18,52 @@ func New() Service {
 	return &errandError{}
 }
 
-func (errandErr *errandError) HandleMongoDbError(from string, err error) *response.BaseResponse {
-	logger.Error("mongo error:", err)
-	if writeException, ok := err.(mongo.WriteException); ok {
-		for _, exception := range writeException.WriteErrors {
-			switch exception.Code {
-			case 11000:
-				return response.NewBadRequestError(fmt.Sprintf("%s already exist", from))
-			}
-		}
-		return response.NewBadRequestError(fmt.Sprintf("%s already exist", from))
+// MongoDbError turns a database error about from into an application error, the driver error is kept as
+// its cause and never shown to clients.
+func (errandErr *errandError) MongoDbError(from string, err error) error {
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		return err
 	}
-	switch err {
-	case mongo.ErrNoDocuments:
-		return response.NewNotFoundError(fmt.Sprintf("%s doesn't exist", from))
-	case mongo.ErrClientDisconnected:
-		return response.NewInternalServerError("internal server error")
-	case mongo.ErrNilDocument:
-		return response.NewBadRequestError("internal server error")
-	case ErrDuplicatePhoneNumber:
-		return response.NewBadRequestError("phone number already exist")
-	case ErrNoUser:
-		return response.NewBadRequestError("user doesn't exist")
+	logger.Error("mongo error:", err)
+	code := strings.ReplaceAll(from, " ", "_")
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return apperror.Wrap(err, apperror.NotFound, code+"_not_found", fmt.Sprintf("%s doesn't exist", from))
+	case mongo.IsDuplicateKeyError(err):
+		return apperror.Wrap(err, apperror.Conflict, code+"_already_exists", fmt.Sprintf("%s already exist", from))
+	case errors.Is(err, context.DeadlineExceeded), mongo.IsTimeout(err), mongo.IsNetworkError(err),
+		errors.Is(err, mongo.ErrClientDisconnected):
+		return apperror.Wrap(err, apperror.Unavailable, apperror.Unavailable.Code(), "the service is busy, please try again")
 	default:
-		return response.NewInternalServerError(err.Error())
+		return apperror.Unexpected(err)
 	}
 }
 
+func (errandErr *errandError) StorageError(err error) error {
+	logger.Error("google storage error", err)
+	return apperror.Wrap(err, apperror.Unavailable, "storage_unavailable", "unable to store the file, please try again")
+}
+
+func (errandErr *errandError) HandleMongoDbError(from string, err error) *response.BaseResponse {
+	return response.NewErrorResponse(apperror.From(errandErr.MongoDbError(from, err)))
+}
+
 func (errandErr *errandError) HandleRedisDbError(err error) *response.BaseResponse {
 	logger.Error("redis error", err)
 	switch err {
 	case redis.Nil:
 		return response.NewUnAuthorizedError()
 	default:
-		return response.NewInternalServerError(err.Error())
+		return response.NewErrorResponse(apperror.Unexpected(err))
 	}
 }
 
 func (errandErr *errandError) HandleGoogleStorageError(err error) *response.BaseResponse {
-	logger.Error("google storage error", err)
-	return response.NewInternalServerError(fmt.Sprintf("an error occurred: %s", err.Error()))
+	return response.NewErrorResponse(apperror.From(errandErr.StorageError(err)))
 }
 
 func (errandErr *errandError) HandleUtilityError(err error) *response.BaseResponse {
 	logger.Error("utility error", err)
-	return response.NewInternalServerError(fmt.Sprintf("an error occurred: %s", err.Error()))
+	return response.NewErrorResponse(apperror.Unexpected(err))
 }
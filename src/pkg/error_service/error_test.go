@@ -0,0 +1,59 @@
+package error_service
+
+import (
+	"DX/src/pkg/apperror"
+	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+	"testing"
+)
+
+func TestMongoDbError(t *testing.T) {
+	duplicate := mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000, Message: "E11000 duplicate key error"}}}
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{name: "no documents", err: mongo.ErrNoDocuments, status: http.StatusNotFound, code: "api_key_not_found"},
+		{name: "duplicate key", err: duplicate, status: http.StatusConflict, code: "api_key_already_exists"},
+		{name: "deadline", err: fmt.Errorf("find: %w", context.DeadlineExceeded), status: http.StatusServiceUnavailable, code: "unavailable"},
+		{name: "network", err: mongo.CommandError{Labels: []string{"NetworkError"}}, status: http.StatusServiceUnavailable, code: "unavailable"},
+		{name: "disconnected", err: mongo.ErrClientDisconnected, status: http.StatusServiceUnavailable, code: "unavailable"},
+		{name: "unexpected", err: errors.New("(BadValue) unknown operator: $foo"), status: http.StatusInternalServerError, code: "internal_error"},
+	}
+
+	service := New()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := service.MongoDbError("api key", test.err)
+			var appErr *apperror.Error
+			if !errors.As(err, &appErr) {
+				t.Fatalf("MongoDbError() = %v, want an application error", err)
+			}
+			if appErr.Status() != test.status || appErr.Code != test.code {
+				t.Fatalf("MongoDbError() = %d %q, want %d %q", appErr.Status(), appErr.Code, test.status, test.code)
+			}
+			if cause := errors.Unwrap(err); cause == nil || cause.Error() != test.err.Error() {
+				t.Fatalf("MongoDbError() lost the cause %v", test.err)
+			}
+		})
+	}
+}
+
+func TestMongoDbErrorPassesThrough(t *testing.T) {
+	err := fmt.Errorf("accept bid: %w", ErrBidAcceptance)
+	if nErr := New().MongoDbError("errand", err); nErr != err {
+		t.Fatalf("MongoDbError() = %v, want %v", nErr, err)
+	}
+}
+
+func TestMongoDbErrorHidesCause(t *testing.T) {
+	err := New().MongoDbError("errand", errors.New("connection to 10.0.0.4:27017 closed"))
+	if message := apperror.From(err).Message; message != "something went wrong, please try again" {
+		t.Fatalf("message = %q, the driver error must not reach clients", message)
+	}
+}
@@ -3,6 +3,8 @@ package error_service
 import "DX/src/pkg/response"
 
 type Service interface {
+	MongoDbError(string, error) error
+	StorageError(error) error
 	HandleMongoDbError(string, error) *response.BaseResponse
 	HandleRedisDbError(error) *response.BaseResponse
 	HandleGoogleStorageError(error) *response.BaseResponse
@@ -1,8 +1,10 @@
 package error_service
 
-import "errors"
+import "DX/src/pkg/apperror"
 
-var ErrNoPhoneNumber = errors.New("phone number doesn't exist")
-var ErrNoUser = errors.New("user does not exist")
-var ErrDuplicatePhoneNumber = errors.New("phone number already in use")
-var ErrBidAcceptance = errors.New("bid could not be accepted")
+var ErrNoPhoneNumber = apperror.New(apperror.NotFound, "phone_number_not_found", "phone number doesn't exist")
+var ErrNoUser = apperror.New(apperror.NotFound, "user_not_found", "user does not exist")
+var ErrDuplicatePhoneNumber = apperror.New(apperror.Conflict, "phone_number_in_use", "phone number already in use")
+var ErrBidAcceptance = apperror.New(apperror.Conflict, "bid_not_accepted", "bid could not be accepted")
+var ErrErrandState = apperror.New(apperror.Conflict, "errand_state_changed", "errand is not in a state that allows this")
+var ErrInvalidPhoneNumber = apperror.New(apperror.Invalid, "invalid_phone_number", "invalid phone number")
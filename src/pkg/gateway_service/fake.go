@@ -0,0 +1,39 @@
+package gateway_service
+
+import (
+	"sync"
+	"time"
+)
+
+// Fake is an in-memory gateway used locally and in tests.
+type Fake struct {
+	sync.Mutex
+	records []Record
+}
+
+func NewFake(records ...Record) *Fake {
+	return &Fake{
+		records: records,
+	}
+}
+
+func (f *Fake) Add(records ...Record) {
+	f.Lock()
+	defer f.Unlock()
+
+	f.records = append(f.records, records...)
+}
+
+func (f *Fake) GetRecords(from, to time.Time) ([]Record, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	var records []Record
+	for _, record := range f.records {
+		if record.SettledAt.Before(from) || record.SettledAt.After(to) {
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
@@ -0,0 +1,20 @@
+package gateway_service
+
+import "time"
+
+const (
+	Credit = "credit"
+	Debit  = "debit"
+)
+
+// Record is a settled movement of money as reported by the payment gateway.
+type Record struct {
+	Reference string    `json:"reference"`
+	Type      string    `json:"type"`
+	Amount    int64     `json:"amount"`
+	SettledAt time.Time `json:"settled_at"`
+}
+
+type Service interface {
+	GetRecords(from, to time.Time) ([]Record, error)
+}
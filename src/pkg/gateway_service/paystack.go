@@ -0,0 +1,106 @@
+package gateway_service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	paystackBaseUrl = "https://api.paystack.co"
+	paystackPerPage = 100
+)
+
+type paystack struct {
+	secretKey string
+	client    *http.Client
+}
+
+type paystackPage struct {
+	Status  bool   `json:"status"`
+	Message string `json:"message"`
+	Data    []struct {
+		Reference string    `json:"reference"`
+		Amount    int64     `json:"amount"`
+		Status    string    `json:"status"`
+		PaidAt    time.Time `json:"paid_at"`
+		UpdatedAt time.Time `json:"updatedAt"`
+	} `json:"data"`
+	Meta struct {
+		PageCount int `json:"pageCount"`
+	} `json:"meta"`
+}
+
+func NewPaystack(secretKey string) Service {
+	return &paystack{
+		secretKey: secretKey,
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// GetRecords returns successful collections as credits. Withdrawals are not paid out through Paystack, so
+// transfers made from the dashboard are left out rather than reported as missing from the ledger.
+func (p *paystack) GetRecords(from, to time.Time) ([]Record, error) {
+	return p.list("/transaction", Credit, from, to)
+}
+
+func (p *paystack) list(path, recordType string, from, to time.Time) ([]Record, error) {
+	var records []Record
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("from", from.Format(time.RFC3339))
+		query.Set("to", to.Format(time.RFC3339))
+		query.Set("perPage", strconv.Itoa(paystackPerPage))
+		query.Set("page", strconv.Itoa(page))
+
+		result, err := p.get(fmt.Sprintf("%s%s?%s", paystackBaseUrl, path, query.Encode()))
+		if err != nil {
+			return nil, err
+		}
+		for _, data := range result.Data {
+			if data.Status != "success" {
+				continue
+			}
+			settledAt := data.PaidAt
+			if settledAt.IsZero() {
+				settledAt = data.UpdatedAt
+			}
+			records = append(records, Record{
+				Reference: data.Reference,
+				Type:      recordType,
+				Amount:    data.Amount,
+				SettledAt: settledAt,
+			})
+		}
+		if page >= result.Meta.PageCount {
+			return records, nil
+		}
+	}
+}
+
+func (p *paystack) get(endpoint string) (*paystackPage, error) {
+	request, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Authorization", "Bearer "+p.secretKey)
+
+	resp, err := p.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result paystackPage
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK || !result.Status {
+		return nil, errors.New(fmt.Sprintf("paystack: %s", result.Message))
+	}
+	return &result, nil
+}
@@ -0,0 +1,387 @@
+package openapi
+
+import (
+	"DX/src/pkg/response"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const Version = "3.0.3"
+
+// Security schemes of the API. Partner API keys are sent as bearer tokens like access tokens.
+const (
+	BearerAuth = "bearerAuth"
+	ApiKeyAuth = "apiKeyAuth"
+)
+
+// Auth is how a route authenticates its requests.
+type Auth int
+
+const (
+	Public Auth = iota
+	User        // access token of a signed in user
+)
+
+// Route describes one route of the router. Request is decoded from the JSON body and Data is sent in the
+// data field of the response envelope, unless Raw is set and it is the whole response.
+type Route struct {
+	Method      string
+	Path        string
+	Id          string
+	Summary     string
+	Tag         string
+	Auth        Auth
+	Scopes      []string // scopes of the API keys that are accepted besides access tokens
+	Permissions []string // role permissions the user needs
+	RateLimited bool
+	Idempotent  bool
+	Query       []Parameter
+	Request     interface{}
+	Form        []FormField // multipart form body, for uploads
+	Status      int
+	Data        interface{}
+	Raw         bool
+	ContentType string   // of raw responses, JSON when empty
+	Downloads   []string // other content types the response can be rendered in
+}
+
+type Document struct {
+	OpenApi    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Tags       []Tag                `json:"tags,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Server struct {
+	Url         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+type Tag struct {
+	Name string `json:"name"`
+}
+
+type PathItem struct {
+	Get    *Operation `json:"get,omitempty"`
+	Put    *Operation `json:"put,omitempty"`
+	Post   *Operation `json:"post,omitempty"`
+	Delete *Operation `json:"delete,omitempty"`
+	Patch  *Operation `json:"patch,omitempty"`
+}
+
+type Operation struct {
+	OperationId string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Scopes      []string              `json:"x-api-key-scopes,omitempty"`
+	Permissions []string              `json:"x-permissions,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type FormField struct {
+	Name        string
+	Description string
+	Files       bool
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// Query is an optional query parameter, limited to the values when there are any.
+func Query(name, description string, values ...string) Parameter {
+	schema := &Schema{Type: "string"}
+	for _, value := range values {
+		schema.Enum = append(schema.Enum, value)
+	}
+	return Parameter{Name: name, Description: description, Schema: schema}
+}
+
+// QueryOf is an optional query parameter of another type, like integer or boolean.
+func QueryOf(name, description, kind string) Parameter {
+	return Parameter{Name: name, Description: description, Schema: &Schema{Type: kind}}
+}
+
+var pathParam = regexp.MustCompile(`:([A-Za-z_]+)`)
+
+// New describes the routes. It panics when two routes share a method and path or an id, which are bugs
+// in the route list.
+func New(info Info, routes ...Route) *Document {
+	document := &Document{
+		OpenApi: Version,
+		Info:    info,
+		Paths:   map[string]*PathItem{},
+		Components: Components{
+			Schemas: map[string]*Schema{},
+			SecuritySchemes: map[string]*SecurityScheme{
+				BearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "JWT", Description: "Access token of a signed in user."},
+				ApiKeyAuth: {Type: "http", Scheme: "bearer", Description: "Partner API key, starting with dxk_. " +
+					"Only routes listing x-api-key-scopes accept it and the key needs every listed scope."},
+			},
+		},
+	}
+	generator := newGenerator(document.Components.Schemas)
+	document.Components.Schemas["Error"] = generator.structSchema(reflect.TypeOf(errorEnvelope{}))
+	document.Components.Schemas["ValidationError"] = generator.structSchema(reflect.TypeOf(validationEnvelope{}))
+
+	ids := map[string]bool{}
+	tags := map[string]bool{}
+	for _, route := range routes {
+		if ids[route.Id] {
+			panic("openapi: duplicate operation id " + route.Id)
+		}
+		ids[route.Id] = true
+		if route.Tag != "" && !tags[route.Tag] {
+			tags[route.Tag] = true
+			document.Tags = append(document.Tags, Tag{Name: route.Tag})
+		}
+
+		path := Path(route.Path)
+		item, ok := document.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			document.Paths[path] = item
+		}
+		slot := item.slot(route.Method)
+		if slot == nil {
+			panic("openapi: unsupported method " + route.Method)
+		}
+		if *slot != nil {
+			panic("openapi: duplicate route " + route.Method + " " + route.Path)
+		}
+		*slot = generator.operation(route)
+	}
+	sort.Slice(document.Tags, func(i, j int) bool {
+		return document.Tags[i].Name < document.Tags[j].Name
+	})
+	return document
+}
+
+// Path is the OpenAPI template of a gin path, /user/:id becomes /user/{id}.
+func Path(ginPath string) string {
+	return pathParam.ReplaceAllString(ginPath, "{$1}")
+}
+
+// Operations lists the method and path of every operation, like "GET /v1/user/{id}".
+func (d *Document) Operations() []string {
+	var operations []string
+	for path, item := range d.Paths {
+		for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete, http.MethodPatch} {
+			if slot := item.slot(method); *slot != nil {
+				operations = append(operations, method+" "+path)
+			}
+		}
+	}
+	sort.Strings(operations)
+	return operations
+}
+
+func (p *PathItem) slot(method string) **Operation {
+	switch method {
+	case http.MethodGet:
+		return &p.Get
+	case http.MethodPut:
+		return &p.Put
+	case http.MethodPost:
+		return &p.Post
+	case http.MethodDelete:
+		return &p.Delete
+	case http.MethodPatch:
+		return &p.Patch
+	}
+	return nil
+}
+
+func (g *generator) operation(route Route) *Operation {
+	operation := &Operation{
+		OperationId: route.Id,
+		Summary:     route.Summary,
+		Responses:   map[string]*Response{},
+		Scopes:      route.Scopes,
+		Permissions: route.Permissions,
+	}
+	if route.Tag != "" {
+		operation.Tags = []string{route.Tag}
+	}
+
+	for _, match := range pathParam.FindAllStringSubmatch(route.Path, -1) {
+		operation.Parameters = append(operation.Parameters, Parameter{Name: match[1], In: "path", Required: true, Schema: &Schema{Type: "string"}})
+	}
+	if route.Idempotent {
+		operation.Parameters = append(operation.Parameters, Parameter{Name: "Idempotency-Key", In: "header",
+			Description: "Replays the stored response when the request is retried with the same key.", Schema: &Schema{Type: "string"}})
+	}
+	for _, parameter := range route.Query {
+		parameter.In = "query"
+		if parameter.Schema == nil {
+			parameter.Schema = &Schema{Type: "string"}
+		}
+		operation.Parameters = append(operation.Parameters, parameter)
+	}
+
+	switch {
+	case route.Request != nil:
+		operation.RequestBody = &RequestBody{Required: true, Content: map[string]*MediaType{
+			"application/json": {Schema: g.schema(route.Request)},
+		}}
+	case len(route.Form) > 0:
+		form := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		for _, field := range route.Form {
+			property := &Schema{Type: "string", Description: field.Description}
+			if field.Files {
+				property = &Schema{Type: "array", Description: field.Description, Items: &Schema{Type: "string", Format: "binary"}}
+			}
+			form.Properties[field.Name] = property
+			form.Required = append(form.Required, field.Name)
+		}
+		operation.RequestBody = &RequestBody{Required: true, Content: map[string]*MediaType{
+			"multipart/form-data": {Schema: form},
+		}}
+	}
+
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	operation.Responses[strconv.Itoa(status)] = g.response(route)
+
+	if route.Request != nil || len(route.Query) > 0 || len(route.Form) > 0 {
+		operation.Responses["400"] = errorResponse("ValidationError", "The request is invalid.")
+	}
+	if route.Auth == User {
+		operation.Security = []map[string][]string{{BearerAuth: {}}}
+		if len(route.Scopes) > 0 {
+			operation.Security = append(operation.Security, map[string][]string{ApiKeyAuth: {}})
+		}
+		operation.Responses["401"] = errorResponse("Error", "The access token or API key is missing or invalid.")
+	}
+	if len(route.Permissions) > 0 || len(route.Scopes) > 0 {
+		operation.Responses["403"] = errorResponse("Error", "The user or API key may not do this.")
+	}
+	if route.Idempotent {
+		operation.Responses["409"] = errorResponse("Error", "A request with the idempotency key is in progress.")
+		operation.Responses["422"] = errorResponse("Error", "The idempotency key was used for a different request.")
+	}
+	if route.RateLimited || len(route.Scopes) > 0 {
+		operation.Responses["429"] = errorResponse("Error", "Too many requests, retry after the Retry-After header.")
+	}
+	operation.Responses["default"] = errorResponse("Error", "The request failed, code tells why.")
+	return operation
+}
+
+func (g *generator) response(route Route) *Response {
+	resp := &Response{Description: http.StatusText(route.Status), Content: map[string]*MediaType{}}
+	if resp.Description == "" {
+		resp.Description = http.StatusText(http.StatusOK)
+	}
+
+	var schema *Schema
+	switch {
+	case route.Raw && route.Data == nil:
+		schema = &Schema{Type: "string"}
+	case route.Raw:
+		schema = g.schema(route.Data)
+	default:
+		schema = g.envelope(route.Data)
+	}
+	contentType := route.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	resp.Content[contentType] = &MediaType{Schema: schema}
+	for _, download := range route.Downloads {
+		resp.Content[download] = &MediaType{Schema: &Schema{Type: "string", Format: "binary"}}
+	}
+	return resp
+}
+
+// envelope is the schema of the response every JSON route sends, with data in the data field.
+func (g *generator) envelope(data interface{}) *Schema {
+	schema := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"success": {Type: "boolean"},
+			"message": {Type: "string"},
+		},
+		Required: []string{"success", "message"},
+	}
+	if data != nil {
+		schema.Properties["data"] = g.schema(data)
+	}
+	return schema
+}
+
+func errorResponse(schema, description string) *Response {
+	return &Response{Description: description, Content: map[string]*MediaType{
+		"application/json": {Schema: &Schema{Ref: "#/components/schemas/" + schema}},
+	}}
+}
+
+// errorEnvelope and validationEnvelope are the bodies of failed requests, see response.BaseResponse.
+type errorEnvelope struct {
+	Success bool   `json:"success"`
+	Message string `json:"message" binding:"required"`
+	Code    string `json:"code" binding:"required"`
+}
+
+type validationEnvelope struct {
+	Success bool   `json:"success"`
+	Message string `json:"message" binding:"required"`
+	Code    string `json:"code" binding:"required"`
+	Data    struct {
+		Errors []response.FieldError `json:"errors"`
+	} `json:"data"`
+}
+
+func title(value string) string {
+	var parts []string
+	for _, part := range strings.FieldsFunc(value, func(r rune) bool { return r == '_' || r == '-' }) {
+		parts = append(parts, strings.ToUpper(part[:1])+part[1:])
+	}
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,305 @@
+package openapi
+
+import (
+	"encoding/json"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     bool               `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     bool               `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	UniqueItems          bool               `json:"uniqueItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+}
+
+// Alternatives are values of different types a route can respond with.
+type Alternatives []interface{}
+
+func OneOf(values ...interface{}) Alternatives {
+	return values
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	objectIdType = reflect.TypeOf(primitive.ObjectID{})
+	rawType      = reflect.TypeOf(json.RawMessage{})
+)
+
+// generator turns Go types into schemas the way encoding/json writes them and gin validates them. Named
+// structs become components referenced by name.
+type generator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newGenerator(schemas map[string]*Schema) *generator {
+	return &generator{schemas: schemas, names: map[reflect.Type]string{}}
+}
+
+func (g *generator) schema(value interface{}) *Schema {
+	if alternatives, ok := value.(Alternatives); ok {
+		schema := &Schema{}
+		for _, alternative := range alternatives {
+			schema.OneOf = append(schema.OneOf, g.schema(alternative))
+		}
+		return schema
+	}
+	return g.typeSchema(reflect.TypeOf(value))
+}
+
+func (g *generator) typeSchema(kind reflect.Type) *Schema {
+	switch kind {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case objectIdType:
+		return &Schema{Type: "string", Pattern: "^[0-9a-f]{24}$"}
+	case rawType:
+		return &Schema{}
+	}
+
+	switch kind.Kind() {
+	case reflect.Ptr:
+		schema := g.typeSchema(kind.Elem())
+		if schema.Ref == "" {
+			schema.Nullable = true
+		}
+		return schema
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if kind.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.typeSchema(kind.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.typeSchema(kind.Elem())}
+	case reflect.Struct:
+		if kind.Name() == "" {
+			return g.structSchema(kind)
+		}
+		return g.component(kind)
+	}
+	return &Schema{}
+}
+
+// component registers the struct once under its name and references it, so recursive types end.
+func (g *generator) component(kind reflect.Type) *Schema {
+	name, ok := g.names[kind]
+	if !ok {
+		name = componentName(kind)
+		if _, taken := g.schemas[name]; taken {
+			panic("openapi: two types are named " + name + ", one of them is " + kind.String())
+		}
+		g.names[kind] = name
+		g.schemas[name] = &Schema{}
+		*g.schemas[name] = *g.structSchema(kind)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// componentName is the type name, prefixed with its package unless it already starts with it. Request
+// types of the handlers are named after the type alone.
+func componentName(kind reflect.Type) string {
+	name := title(kind.Name())
+	pkg := path.Base(kind.PkgPath())
+	switch {
+	case pkg == "handler" || pkg == "openapi":
+		return name
+	case strings.HasPrefix(strings.ToLower(name), strings.ReplaceAll(pkg, "_", "")):
+		return name
+	case strings.HasSuffix(title(pkg), name):
+		return title(pkg)
+	}
+	return title(pkg) + name
+}
+
+func (g *generator) structSchema(kind reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	g.addFields(schema, kind)
+	return schema
+}
+
+func (g *generator) addFields(schema *Schema, kind reflect.Type) {
+	for index := 0; index < kind.NumField(); index++ {
+		field := kind.Field(index)
+		name, omitted := jsonName(field)
+		if omitted {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.addFields(schema, embedded)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := g.typeSchema(field.Type)
+		rules := field.Tag.Get("binding")
+		required := false
+		if property.Ref != "" {
+			required = hasRule(rules, "required")
+		} else if rules != "" {
+			required = applyRules(property, rules)
+		}
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = property
+	}
+}
+
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	if !field.IsExported() && !field.Anonymous {
+		return "", true
+	}
+	return strings.SplitN(tag, ",", 2)[0], false
+}
+
+func hasRule(rules, rule string) bool {
+	for _, value := range strings.Split(rules, ",") {
+		if value == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// applyRules describes the validator rules of the binding tag in the schema and reports whether the field
+// is required. Rules after dive apply to the items of the list.
+func applyRules(schema *Schema, rules string) bool {
+	required := false
+	target := schema
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required", "notblank":
+			if target == schema {
+				required = true
+			} else if target.Type == "string" {
+				target.MinLength = intPointer(1)
+			}
+		case "dive":
+			if target.Items == nil {
+				return required
+			}
+			target = target.Items
+		case "min", "max", "len", "gt", "gte", "lt", "lte":
+			applyBound(target, name, param)
+		case "oneof":
+			for _, value := range strings.Fields(param) {
+				if number, err := strconv.ParseFloat(value, 64); err == nil && target.Type != "string" {
+					target.Enum = append(target.Enum, number)
+				} else {
+					target.Enum = append(target.Enum, value)
+				}
+			}
+		case "email":
+			target.Format = "email"
+		case "url", "https_url":
+			target.Format = "uri"
+		case "datetime":
+			target.Format = "date-time"
+		case "objectid":
+			target.Pattern = "^[0-9a-f]{24}$"
+		case "numeric":
+			target.Pattern = "^[0-9]+$"
+		case "latitude":
+			target.Minimum, target.Maximum = floatPointer(-90), floatPointer(90)
+		case "longitude":
+			target.Minimum, target.Maximum = floatPointer(-180), floatPointer(180)
+		case "unique":
+			target.UniqueItems = true
+		}
+	}
+	return required
+}
+
+func applyBound(schema *Schema, rule, param string) {
+	value, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	switch schema.Type {
+	case "string":
+		switch rule {
+		case "min":
+			schema.MinLength = intPointer(int(value))
+		case "max":
+			schema.MaxLength = intPointer(int(value))
+		case "len":
+			schema.MinLength, schema.MaxLength = intPointer(int(value)), intPointer(int(value))
+		}
+	case "array":
+		switch rule {
+		case "min":
+			schema.MinItems = intPointer(int(value))
+		case "max":
+			schema.MaxItems = intPointer(int(value))
+		case "len":
+			schema.MinItems, schema.MaxItems = intPointer(int(value)), intPointer(int(value))
+		}
+	case "integer", "number":
+		switch rule {
+		case "min", "gte":
+			schema.Minimum = floatPointer(value)
+		case "max", "lte":
+			schema.Maximum = floatPointer(value)
+		case "gt":
+			schema.Minimum, schema.ExclusiveMinimum = floatPointer(value), true
+		case "lt":
+			schema.Maximum, schema.ExclusiveMaximum = floatPointer(value), true
+		}
+	}
+}
+
+func intPointer(value int) *int {
+	return &value
+}
+
+func floatPointer(value float64) *float64 {
+	return &value
+}
@@ -0,0 +1,328 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	identifier    = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+	templateParam = regexp.MustCompile(`{([A-Za-z_]+)}`)
+)
+
+// TypeScript is a client for the document, an interface for every component and a method named after the
+// id of every operation. It needs nothing but fetch.
+func TypeScript(document *Document) []byte {
+	var out bytes.Buffer
+	out.WriteString("// Code generated by DX/src/cmd/openapi from the OpenAPI document. DO NOT EDIT.\n\n")
+
+	var names []string
+	for name := range document.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		schema := document.Components.Schemas[name]
+		if schema.Type == "object" && schema.AdditionalProperties == nil {
+			fmt.Fprintf(&out, "export interface %s %s\n\n", name, tsObject(schema, ""))
+		} else {
+			fmt.Fprintf(&out, "export type %s = %s;\n\n", name, tsType(schema, ""))
+		}
+	}
+	out.WriteString(tsRuntime)
+
+	var paths []string
+	for path := range document.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+			if operation := *document.Paths[path].slot(method); operation != nil {
+				writeTsMethod(&out, method, path, operation)
+			}
+		}
+	}
+	out.WriteString("}\n")
+	return out.Bytes()
+}
+
+func writeTsMethod(out *bytes.Buffer, method, path string, operation *Operation) {
+	var fields, query, headers []string
+	optionalOnly := operation.RequestBody == nil
+	for _, parameter := range operation.Parameters {
+		name := parameter.Name
+		optional := "?"
+		if parameter.Required {
+			optional = ""
+			optionalOnly = false
+		}
+		switch parameter.In {
+		case "header":
+			name = lowerFirst(strings.ReplaceAll(parameter.Name, "-", ""))
+			headers = append(headers, fmt.Sprintf("%q: request.%s", parameter.Name, name))
+		case "query":
+			query = append(query, fmt.Sprintf("%s: request%s", tsKey(name), tsAccess(name)))
+		}
+		fields = append(fields, fmt.Sprintf("%s%s: %s", tsKey(name), optional, tsType(parameter.Schema, "  ")))
+	}
+
+	body := "undefined"
+	if operation.RequestBody != nil {
+		if content, ok := operation.RequestBody.Content["application/json"]; ok {
+			fields = append(fields, "body: "+tsType(content.Schema, "  "))
+			body = "request.body"
+		} else {
+			fields = append(fields, "body: FormData")
+			body = "request.body"
+		}
+	}
+
+	url := "`" + templateParam.ReplaceAllString(path, "$${encodeURIComponent(request.$1)}") + "`"
+
+	fmt.Fprintf(out, "\n  /** %s */\n", operation.Summary)
+	argument := "request: " + tsInline(fields, "; ")
+	if optionalOnly {
+		argument += " = {}"
+	}
+	fmt.Fprintf(out, "  %s(%s): Promise<%s> {\n", operation.OperationId, argument, tsResult(operation))
+	fmt.Fprintf(out, "    return this.request(%q, %s, { query: %s, headers: %s, body: %s, auth: %t });\n",
+		method, url, tsInline(query, ", "), tsInline(headers, ", "), body, operation.Security != nil)
+	out.WriteString("  }\n")
+}
+
+// tsResult is the type of the successful response. Downloads in other content types come back as text or
+// a blob.
+func tsResult(operation *Operation) string {
+	for _, status := range []int{http.StatusOK, http.StatusCreated, http.StatusAccepted} {
+		response, ok := operation.Responses[fmt.Sprint(status)]
+		if !ok {
+			continue
+		}
+		var types []string
+		for _, contentType := range sortedKeys(response.Content) {
+			switch {
+			case contentType == "application/json":
+				types = append([]string{tsEnvelope(response.Content[contentType].Schema)}, types...)
+			case strings.HasPrefix(contentType, "text/") && !contains(types, "string"):
+				types = append(types, "string")
+			case !strings.HasPrefix(contentType, "text/") && !contains(types, "Blob"):
+				types = append(types, "Blob")
+			}
+		}
+		return strings.Join(types, " | ")
+	}
+	return "unknown"
+}
+
+// tsEnvelope names the response envelope after its data instead of spelling it out in every method.
+func tsEnvelope(schema *Schema) string {
+	if schema.Ref != "" || schema.Type != "object" || schema.Properties["success"] == nil || schema.Properties["message"] == nil {
+		return tsType(schema, "  ")
+	}
+	if data, ok := schema.Properties["data"]; ok {
+		return "Envelope<" + tsType(data, "  ") + ">"
+	}
+	return "Envelope"
+}
+
+func sortedKeys(content map[string]*MediaType) []string {
+	var keys []string
+	for key := range content {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func tsType(schema *Schema, indent string) string {
+	value := tsBareType(schema, indent)
+	if schema.Nullable {
+		value += " | null"
+	}
+	return value
+}
+
+func tsBareType(schema *Schema, indent string) string {
+	switch {
+	case schema == nil:
+		return "unknown"
+	case schema.Ref != "":
+		return schema.Ref[strings.LastIndex(schema.Ref, "/")+1:]
+	case len(schema.OneOf) > 0:
+		var types []string
+		for _, alternative := range schema.OneOf {
+			types = append(types, tsType(alternative, indent))
+		}
+		return strings.Join(types, " | ")
+	case len(schema.Enum) > 0:
+		var values []string
+		for _, value := range schema.Enum {
+			if text, ok := value.(string); ok {
+				values = append(values, fmt.Sprintf("%q", text))
+			} else {
+				values = append(values, fmt.Sprint(value))
+			}
+		}
+		return strings.Join(values, " | ")
+	}
+
+	switch schema.Type {
+	case "string":
+		if schema.Format == "binary" {
+			return "Blob"
+		}
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "array":
+		item := tsType(schema.Items, indent)
+		if strings.Contains(item, " ") && !strings.HasPrefix(item, "{") {
+			return "Array<" + item + ">"
+		}
+		return item + "[]"
+	case "object":
+		if schema.AdditionalProperties != nil {
+			return "Record<string, " + tsType(schema.AdditionalProperties, indent) + ">"
+		}
+		if len(schema.Properties) == 0 {
+			return "Record<string, unknown>"
+		}
+		return tsObject(schema, indent)
+	}
+	return "unknown"
+}
+
+func tsObject(schema *Schema, indent string) string {
+	required := map[string]bool{}
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+	var names []string
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var out strings.Builder
+	out.WriteString("{\n")
+	for _, name := range names {
+		optional := "?"
+		if required[name] {
+			optional = ""
+		}
+		fmt.Fprintf(&out, "%s  %s%s: %s;\n", indent, tsKey(name), optional, tsType(schema.Properties[name], indent+"  "))
+	}
+	out.WriteString(indent + "}")
+	return out.String()
+}
+
+func tsInline(members []string, separator string) string {
+	if len(members) == 0 {
+		return "{}"
+	}
+	return "{ " + strings.Join(members, separator) + " }"
+}
+
+func tsKey(name string) string {
+	if identifier.MatchString(name) {
+		return name
+	}
+	return fmt.Sprintf("%q", name)
+}
+
+func tsAccess(name string) string {
+	if identifier.MatchString(name) {
+		return "." + name
+	}
+	return fmt.Sprintf("[%q]", name)
+}
+
+func lowerFirst(value string) string {
+	if value == "" {
+		return value
+	}
+	return strings.ToLower(value[:1]) + value[1:]
+}
+
+const tsRuntime = `export class ApiError extends Error {
+  constructor(readonly status: number, readonly code: string, readonly body: unknown) {
+    super(code);
+  }
+}
+
+export interface Envelope<T = never> {
+  success: boolean;
+  message: string;
+  data?: T;
+}
+
+export interface ClientOptions {
+  baseUrl: string;
+  // token is an access token or a partner API key, sent as a bearer token.
+  token?: string | (() => string | Promise<string>);
+  fetch?: typeof fetch;
+}
+
+type Call = {
+  query: Record<string, unknown>;
+  headers: Record<string, string | undefined>;
+  body: unknown;
+  auth: boolean;
+};
+
+export class Client {
+  constructor(private readonly options: ClientOptions) {}
+
+  private async request<T>(method: string, path: string, call: Call): Promise<T> {
+    const url = new URL(this.options.baseUrl.replace(/\/$/, "") + path);
+    for (const [name, value] of Object.entries(call.query)) {
+      if (value !== undefined && value !== null) url.searchParams.set(name, String(value));
+    }
+    const headers: Record<string, string> = {};
+    for (const [name, value] of Object.entries(call.headers)) {
+      if (value !== undefined) headers[name] = value;
+    }
+    if (call.auth && this.options.token) {
+      const token = typeof this.options.token === "function" ? await this.options.token() : this.options.token;
+      headers["Authorization"] = "Bearer " + token;
+    }
+    let body: BodyInit | undefined;
+    if (call.body instanceof FormData) {
+      body = call.body;
+    } else if (call.body !== undefined) {
+      headers["Content-Type"] = "application/json";
+      body = JSON.stringify(call.body);
+    }
+
+    const response = await (this.options.fetch ?? fetch)(url.toString(), { method, headers, body });
+    const type = response.headers.get("Content-Type") ?? "";
+    let value: any;
+    if (type.includes("json")) {
+      value = await response.json();
+    } else if (type.startsWith("text/")) {
+      value = await response.text();
+    } else {
+      value = await response.blob();
+    }
+    if (!response.ok) {
+      throw new ApiError(response.status, value?.code ?? "http_" + response.status, value);
+    }
+    return value as T;
+  }
+`
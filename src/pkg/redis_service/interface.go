@@ -0,0 +1,12 @@
+package redis_service
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+)
+
+type Service interface {
+	Client() *redis.Client
+	Health(context.Context) error
+	Close() error
+}
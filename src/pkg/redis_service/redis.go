@@ -0,0 +1,134 @@
+package redis_service
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	redisHost         = "REDIS_HOST"
+	redisPort         = "REDIS_PORT"
+	redisUsername     = "REDIS_USERNAME"
+	redisPassword     = "REDIS_PASSWORD"
+	redisDatabase     = "REDIS_DB"
+	redisPoolSize     = "REDIS_POOL_SIZE"
+	redisTls          = "REDIS_TLS"
+	redisDialTimeout  = "REDIS_DIAL_TIMEOUT"
+	redisReadTimeout  = "REDIS_READ_TIMEOUT"
+	redisWriteTimeout = "REDIS_WRITE_TIMEOUT"
+)
+
+type Config struct {
+	Host         string
+	Port         string
+	Username     string
+	Password     string
+	Database     int
+	PoolSize     int
+	Tls          bool
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+// ConfigFromEnv reads the connection settings, anything not set falls back to a local instance.
+func ConfigFromEnv() (Config, error) {
+	var err error
+	config := Config{
+		Host:         os.Getenv(redisHost),
+		Port:         os.Getenv(redisPort),
+		Username:     os.Getenv(redisUsername),
+		Password:     os.Getenv(redisPassword),
+		PoolSize:     10,
+		DialTimeout:  5 * time.Second,
+		ReadTimeout:  3 * time.Second,
+		WriteTimeout: 3 * time.Second,
+	}
+	if config.Host == "" {
+		config.Host = "localhost"
+	}
+	if config.Port == "" {
+		config.Port = "6379"
+	}
+	if value := os.Getenv(redisDatabase); value != "" {
+		if config.Database, err = strconv.Atoi(value); err != nil {
+			return config, fmt.Errorf("invalid %s: %v", redisDatabase, err)
+		}
+	}
+	if value := os.Getenv(redisPoolSize); value != "" {
+		if config.PoolSize, err = strconv.Atoi(value); err != nil {
+			return config, fmt.Errorf("invalid %s: %v", redisPoolSize, err)
+		}
+	}
+	if value := os.Getenv(redisTls); value != "" {
+		if config.Tls, err = strconv.ParseBool(value); err != nil {
+			return config, fmt.Errorf("invalid %s: %v", redisTls, err)
+		}
+	}
+	timeouts := map[string]*time.Duration{
+		redisDialTimeout:  &config.DialTimeout,
+		redisReadTimeout:  &config.ReadTimeout,
+		redisWriteTimeout: &config.WriteTimeout,
+	}
+	for name, timeout := range timeouts {
+		if value := os.Getenv(name); value != "" {
+			if *timeout, err = time.ParseDuration(value); err != nil {
+				return config, fmt.Errorf("invalid %s: %v", name, err)
+			}
+		}
+	}
+
+	return config, nil
+}
+
+func (c Config) Address() string {
+	return c.Host + ":" + c.Port
+}
+
+type redisService struct {
+	client *redis.Client
+}
+
+// New connects to redis and fails if the server cannot be reached.
+func New(config Config) (Service, error) {
+	redisOptions := &redis.Options{
+		Addr:         config.Address(),
+		Username:     config.Username,
+		Password:     config.Password,
+		DB:           config.Database,
+		PoolSize:     config.PoolSize,
+		DialTimeout:  config.DialTimeout,
+		ReadTimeout:  config.ReadTimeout,
+		WriteTimeout: config.WriteTimeout,
+	}
+	if config.Tls {
+		redisOptions.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12, ServerName: config.Host}
+	}
+
+	service := &redisService{client: redis.NewClient(redisOptions)}
+	ctx, cancel := context.WithTimeout(context.Background(), config.DialTimeout)
+	defer cancel()
+	if err := service.Health(ctx); err != nil {
+		_ = service.client.Close()
+		return nil, fmt.Errorf("unable to connect to redis at %s: %v", config.Address(), err)
+	}
+
+	return service, nil
+}
+
+func (r *redisService) Client() *redis.Client {
+	return r.client
+}
+
+func (r *redisService) Health(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
+func (r *redisService) Close() error {
+	return r.client.Close()
+}
@@ -1,12 +1,17 @@
 package response
 
-import "net/http"
+import (
+	"DX/src/pkg/apperror"
+	"net/http"
+	"time"
+)
 
 type BaseResponse struct {
-	Success bool        `json:"success"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
-	Code    int         `json:"-"`
+	Success   bool        `json:"success"`
+	Message   string      `json:"message"`
+	ErrorCode string      `json:"code,omitempty"` // stable code of failed requests
+	Data      interface{} `json:"data,omitempty"`
+	Code      int         `json:"-"`
 }
 
 func NewCreatedResponse(message string, data interface{}) *BaseResponse {
@@ -29,36 +34,106 @@ func NewOkResponse(message string, data interface{}) *BaseResponse {
 
 func NewNotFoundError(message string) *BaseResponse {
 	return &BaseResponse{
-		Success: false,
-		Message: message,
-		Code:    http.StatusNotFound,
-		Data:    nil,
+		Success:   false,
+		ErrorCode: apperror.NotFound.Code(),
+		Message:   message,
+		Code:      http.StatusNotFound,
+		Data:      nil,
 	}
 }
 
 func NewBadRequestError(message string) *BaseResponse {
 	return &BaseResponse{
-		Success: false,
-		Code:    http.StatusBadRequest,
-		Message: message,
-		Data:    nil,
+		Success:   false,
+		ErrorCode: apperror.Invalid.Code(),
+		Code:      http.StatusBadRequest,
+		Message:   message,
+		Data:      nil,
 	}
 }
 
 func NewInternalServerError(message string) *BaseResponse {
 	return &BaseResponse{
-		Success: false,
-		Code:    http.StatusInternalServerError,
-		Message: message,
-		Data:    nil,
+		Success:   false,
+		ErrorCode: apperror.Internal.Code(),
+		Code:      http.StatusInternalServerError,
+		Message:   message,
+		Data:      nil,
 	}
 }
 
 func NewUnAuthorizedError() *BaseResponse {
 	return &BaseResponse{
-		Success: false,
-		Code:    http.StatusUnauthorized,
-		Message: Unauthorized,
-		Data:    nil,
+		Success:   false,
+		ErrorCode: apperror.Unauthorized.Code(),
+		Code:      http.StatusUnauthorized,
+		Message:   Unauthorized,
+		Data:      nil,
+	}
+}
+
+func NewForbiddenError(message string) *BaseResponse {
+	return &BaseResponse{
+		Success:   false,
+		ErrorCode: apperror.Forbidden.Code(),
+		Code:      http.StatusForbidden,
+		Message:   message,
+		Data:      nil,
+	}
+}
+
+func NewConflictError(message string) *BaseResponse {
+	return &BaseResponse{
+		Success:   false,
+		ErrorCode: apperror.Conflict.Code(),
+		Code:      http.StatusConflict,
+		Message:   message,
+		Data:      nil,
+	}
+}
+
+func NewUnprocessableEntityError(message string) *BaseResponse {
+	return &BaseResponse{
+		Success:   false,
+		ErrorCode: "unprocessable_entity",
+		Code:      http.StatusUnprocessableEntity,
+		Message:   message,
+		Data:      nil,
+	}
+}
+
+func NewTooManyRequestsError(message string, retryAfter time.Duration) *BaseResponse {
+	return &BaseResponse{
+		Success:   false,
+		ErrorCode: apperror.RateLimited.Code(),
+		Code:      http.StatusTooManyRequests,
+		Message:   message,
+		Data:      map[string]int64{"retry_after": int64(retryAfter.Seconds()) + 1},
+	}
+}
+
+// FieldError is an invalid field of a request body.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func NewValidationError(errors []FieldError) *BaseResponse {
+	return &BaseResponse{
+		Success:   false,
+		ErrorCode: "validation_failed",
+		Code:      http.StatusBadRequest,
+		Message:   "invalid request data",
+		Data:      map[string][]FieldError{"errors": errors},
+	}
+}
+
+// NewErrorResponse reports the error with its status, code and public message, never its cause.
+func NewErrorResponse(err *apperror.Error) *BaseResponse {
+	return &BaseResponse{
+		Success:   false,
+		ErrorCode: err.Code,
+		Code:      err.Status(),
+		Message:   err.Message,
 	}
 }
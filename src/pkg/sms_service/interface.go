@@ -0,0 +1,10 @@
+package sms_service
+
+type Message struct {
+	To   string
+	Body string
+}
+
+type Service interface {
+	Send(Message) error
+}
@@ -0,0 +1,49 @@
+package sms_service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const termiiBaseUrl = "https://api.ng.termii.com/api"
+
+type termii struct {
+	apiKey   string
+	senderId string
+	client   *http.Client
+}
+
+func NewTermii(apiKey, senderId string) Service {
+	return &termii{
+		apiKey:   apiKey,
+		senderId: senderId,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (t *termii) Send(message Message) error {
+	body, err := json.Marshal(map[string]string{
+		"api_key": t.apiKey,
+		"to":      message.To,
+		"from":    t.senderId,
+		"sms":     message.Body,
+		"type":    "plain",
+		"channel": "dnd",
+	})
+	if err != nil {
+		return err
+	}
+
+	res, err := t.client.Post(termiiBaseUrl+"/sms/send", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("termii: unexpected status %d", res.StatusCode)
+	}
+	return nil
+}
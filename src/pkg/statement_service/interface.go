@@ -0,0 +1,8 @@
+package statement_service
+
+import "DX/src/domain/entity/wallet"
+
+type Service interface {
+	CSV(*wallet.Statement) ([]byte, error)
+	PDF(*wallet.Statement) ([]byte, error)
+}
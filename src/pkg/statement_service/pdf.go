@@ -0,0 +1,91 @@
+package statement_service
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	linesPerPage = 60
+	fontSize     = 8
+	lineHeight   = 12
+	pageWidth    = 612
+	pageHeight   = 792
+	margin       = 40
+)
+
+// document is a minimal single-font PDF writer, enough for plain text statements.
+type document struct {
+	pages [][]string
+}
+
+func newDocument(lines []string) *document {
+	doc := &document{}
+	for start := 0; start < len(lines); start += linesPerPage {
+		end := start + linesPerPage
+		if end > len(lines) {
+			end = len(lines)
+		}
+		doc.pages = append(doc.pages, lines[start:end])
+	}
+	if len(doc.pages) == 0 {
+		doc.pages = append(doc.pages, []string{})
+	}
+	return doc
+}
+
+func (d *document) render() []byte {
+	// Objects: 1 catalog, 2 page tree, 3 font, then a page and content stream pair per page.
+	var objects []string
+	kids := make([]string, len(d.pages))
+	for index := range d.pages {
+		kids[index] = fmt.Sprintf("%d 0 R", 4+index*2)
+	}
+
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier >>",
+	)
+	for index, lines := range d.pages {
+		content := d.content(lines)
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+				pageWidth, pageHeight, 5+index*2),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		)
+	}
+
+	buffer := &bytes.Buffer{}
+	buffer.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for index, object := range objects {
+		offsets[index] = buffer.Len()
+		fmt.Fprintf(buffer, "%d 0 obj\n%s\nendobj\n", index+1, object)
+	}
+
+	xref := buffer.Len()
+	fmt.Fprintf(buffer, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(buffer, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(buffer, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return buffer.Bytes()
+}
+
+func (d *document) content(lines []string) string {
+	builder := &strings.Builder{}
+	fmt.Fprintf(builder, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", fontSize, lineHeight, margin, pageHeight-margin)
+	for _, line := range lines {
+		fmt.Fprintf(builder, "(%s) '\n", escape(line))
+	}
+	builder.WriteString("ET")
+	return builder.String()
+}
+
+func escape(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`, "\r", " ", "\n", " ")
+	return replacer.Replace(value)
+}
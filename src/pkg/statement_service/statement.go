@@ -0,0 +1,111 @@
+package statement_service
+
+import (
+	"DX/src/domain/entity/wallet"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+type statement struct{}
+
+func New() Service {
+	return &statement{}
+}
+
+func (s *statement) CSV(nStatement *wallet.Statement) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	writer := csv.NewWriter(buffer)
+
+	rows := [][]string{
+		{"Statement for", nStatement.UserId},
+		{"Period", nStatement.From.Format(dateLayout), nStatement.To.Format(dateLayout)},
+		{"Opening balance", strconv.FormatInt(nStatement.OpeningBalance, 10)},
+		{},
+		{"Date", "Reference", "Description", "Category", "Item", "Type", "Amount", "Balance"},
+	}
+	balance := nStatement.OpeningBalance
+	for _, txn := range nStatement.Transactions {
+		balance = runningBalance(balance, txn)
+		rows = append(rows, []string{
+			txn.CreatedAt.Format(time.RFC3339),
+			txn.Id.Hex(),
+			txn.Description,
+			txn.Category,
+			txn.ItemId,
+			txn.Type,
+			strconv.FormatInt(txn.Amount, 10),
+			strconv.FormatInt(balance, 10),
+		})
+	}
+	rows = append(rows,
+		[]string{},
+		[]string{"Promo credits", strconv.FormatInt(nStatement.PromoCredits, 10)},
+		[]string{"Promo debits", strconv.FormatInt(nStatement.PromoDebits, 10)},
+		[]string{"Total credits", strconv.FormatInt(nStatement.TotalCredits, 10)},
+		[]string{"Total debits", strconv.FormatInt(nStatement.TotalDebits, 10)},
+		[]string{"Closing balance", strconv.FormatInt(nStatement.ClosingBalance, 10)},
+	)
+
+	if err := writer.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func (s *statement) PDF(nStatement *wallet.Statement) ([]byte, error) {
+	lines := []string{
+		"Wallet Statement",
+		fmt.Sprintf("Account: %s", nStatement.UserId),
+		fmt.Sprintf("Period: %s to %s", nStatement.From.Format(dateLayout), nStatement.To.Format(dateLayout)),
+		fmt.Sprintf("Generated: %s", nStatement.GeneratedAt.Format(time.RFC1123)),
+		"",
+		fmt.Sprintf("Opening balance: %d", nStatement.OpeningBalance),
+		"",
+		fmt.Sprintf("%-11s %-14s %-28s %-7s %10s %12s", "Date", "Category", "Description", "Type", "Amount", "Balance"),
+	}
+	balance := nStatement.OpeningBalance
+	for _, txn := range nStatement.Transactions {
+		balance = runningBalance(balance, txn)
+		lines = append(lines, fmt.Sprintf("%-11s %-14s %-28s %-7s %10d %12d",
+			txn.CreatedAt.Format(dateLayout),
+			truncate(txn.Category, 14),
+			truncate(txn.Description, 28),
+			txn.Type,
+			txn.Amount,
+			balance,
+		))
+	}
+	lines = append(lines,
+		"",
+		fmt.Sprintf("Promo credits: %d", nStatement.PromoCredits),
+		fmt.Sprintf("Promo debits: %d", nStatement.PromoDebits),
+		fmt.Sprintf("Total credits: %d", nStatement.TotalCredits),
+		fmt.Sprintf("Total debits: %d", nStatement.TotalDebits),
+		fmt.Sprintf("Closing balance: %d", nStatement.ClosingBalance),
+	)
+
+	return newDocument(lines).render(), nil
+}
+
+// runningBalance is the cash balance after the transaction, promotional credit does not change it.
+func runningBalance(balance int64, txn wallet.Transaction) int64 {
+	if txn.Promotional {
+		return balance
+	}
+	if txn.TransactionType == wallet.Credit {
+		return balance + txn.Amount
+	}
+	return balance - txn.Amount
+}
+
+func truncate(value string, length int) string {
+	if len(value) <= length {
+		return value
+	}
+	return value[:length-1] + "~"
+}
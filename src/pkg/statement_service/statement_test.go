@@ -0,0 +1,204 @@
+package statement_service
+
+import (
+	"DX/src/domain/entity/wallet"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newStatement(transactions ...wallet.Transaction) *wallet.Statement {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+	for index := range transactions {
+		transactions[index].CreatedAt = from.Add(time.Duration(index) * time.Hour)
+	}
+	return wallet.NewStatement("user-1", from, to, 1000, transactions)
+}
+
+func TestCSV(t *testing.T) {
+	nStatement := newStatement(
+		wallet.NewCreditTransaction("user-1", "Wallet top up", "", 5000, wallet.TopUp),
+		wallet.NewDebitTransaction("user-1", `Errand "groceries", two stops`, "errand-1", 1500, wallet.ErrandPayment),
+		wallet.NewDebitTransaction("user-1", "Line one\nline two", "errand-2", 500, wallet.ErrandPayment),
+	)
+
+	content, err := New().CSV(nStatement)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("statement is not valid CSV: %v", err)
+	}
+
+	want := [][]string{
+		{"Statement for", "user-1"},
+		{"Period", "2026-01-01", "2026-01-31"},
+		{"Opening balance", "1000"},
+	}
+	for index, row := range want {
+		if strings.Join(rows[index], ",") != strings.Join(row, ",") {
+			t.Fatalf("row %d = %q, want %q", index, rows[index], row)
+		}
+	}
+
+	// The reader skips the blank line before the header
+	if rows[3][0] != "Date" || len(rows[3]) != 8 {
+		t.Fatalf("header = %q", rows[3])
+	}
+	transactions := rows[4:7]
+	balances := []string{"6000", "4500", "4000"}
+	for index, row := range transactions {
+		txn := nStatement.Transactions[index]
+		if row[1] != txn.Id.Hex() || row[2] != txn.Description || row[4] != txn.ItemId || row[6] != strconv.FormatInt(txn.Amount, 10) {
+			t.Fatalf("transaction row %d = %q", index, row)
+		}
+		if row[7] != balances[index] {
+			t.Fatalf("balance after transaction %d = %s, want %s", index, row[7], balances[index])
+		}
+	}
+
+	totals := rows[len(rows)-3:]
+	for index, row := range [][]string{{"Total credits", "5000"}, {"Total debits", "2000"}, {"Closing balance", "4000"}} {
+		if strings.Join(totals[index], ",") != strings.Join(row, ",") {
+			t.Fatalf("total %d = %q, want %q", index, totals[index], row)
+		}
+	}
+}
+
+func TestCSVKeepsPromoCreditOutOfBalance(t *testing.T) {
+	nStatement := newStatement(
+		wallet.NewPromoCreditTransaction("user-1", "Referral reward", "referral-1", 700, wallet.ReferralReward),
+		wallet.NewDebitTransaction("user-1", "Errand creation", "errand-1", 1500, wallet.ErrandPayment),
+		wallet.NewPromoDebitTransaction("user-1", "Promo credit applied", "errand-1", 700, wallet.PromoCredit),
+		wallet.NewCreditTransaction("user-1", "Promo credit applied", "errand-1", 700, wallet.PromoCredit),
+	)
+
+	content, err := New().CSV(nStatement)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for index, balance := range []string{"1000", "-500", "-500", "200"} {
+		if row := rows[4+index]; row[7] != balance {
+			t.Fatalf("balance after transaction %d = %s, want %s", index, row[7], balance)
+		}
+	}
+	totals := rows[len(rows)-5:]
+	for index, row := range [][]string{{"Promo credits", "700"}, {"Promo debits", "700"}, {"Total credits", "700"}, {"Total debits", "1500"}, {"Closing balance", "200"}} {
+		if strings.Join(totals[index], ",") != strings.Join(row, ",") {
+			t.Fatalf("total %d = %q, want %q", index, totals[index], row)
+		}
+	}
+}
+
+func TestCSVWithoutTransactions(t *testing.T) {
+	content, err := New().CSV(newStatement())
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if closing := rows[len(rows)-1]; closing[0] != "Closing balance" || closing[1] != "1000" {
+		t.Fatalf("closing balance = %q, want the opening balance", closing)
+	}
+}
+
+func TestPDF(t *testing.T) {
+	tests := []struct {
+		name         string
+		transactions int
+		pages        int
+	}{
+		{name: "no transactions", transactions: 0, pages: 1},
+		{name: "one page", transactions: 40, pages: 1},
+		{name: "several pages", transactions: 150, pages: 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var transactions []wallet.Transaction
+			for n := 0; n < test.transactions; n++ {
+				transactions = append(transactions, wallet.NewDebitTransaction("user-1", fmt.Sprintf("Errand %d", n), "", 100, wallet.ErrandPayment))
+			}
+			content, err := New().PDF(newStatement(transactions...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkPdf(t, content)
+
+			count := regexp.MustCompile(`/Type /Pages /Kids \[[^\]]*\] /Count (\d+)`).FindSubmatch(content)
+			if count == nil || string(count[1]) != strconv.Itoa(test.pages) {
+				t.Fatalf("page count = %s, want %d", count, test.pages)
+			}
+			if pages := bytes.Count(content, []byte("/Type /Page /Parent")); pages != test.pages {
+				t.Fatalf("%d page objects, want %d", pages, test.pages)
+			}
+		})
+	}
+}
+
+func TestPDFEscapesText(t *testing.T) {
+	content, err := New().PDF(newStatement(
+		wallet.NewDebitTransaction("user-1", `Refund (partial) \ fee`, "", 100, wallet.Refund),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkPdf(t, content)
+	if !bytes.Contains(content, []byte(`Refund \(partial\) \\ fee`)) {
+		t.Fatal("description is not escaped")
+	}
+}
+
+// checkPdf checks the structure a reader relies on: the header, that every object sits at the offset the
+// cross reference table gives and that stream lengths are right.
+func checkPdf(t *testing.T, content []byte) {
+	t.Helper()
+	if !bytes.HasPrefix(content, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(content, []byte("%%EOF\n")) {
+		t.Fatal("missing PDF header or trailer")
+	}
+
+	startXref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(content)
+	if startXref == nil {
+		t.Fatal("missing startxref")
+	}
+	xref, _ := strconv.Atoi(string(startXref[1]))
+	if !bytes.HasPrefix(content[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+	offsets := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(content[xref:], -1)
+	if len(offsets) == 0 {
+		t.Fatal("empty xref table")
+	}
+	for index, match := range offsets {
+		offset, _ := strconv.Atoi(string(match[1]))
+		if object := fmt.Sprintf("%d 0 obj\n", index+1); !bytes.HasPrefix(content[offset:], []byte(object)) {
+			t.Fatalf("xref entry %d points at %q", index+1, content[offset:offset+10])
+		}
+	}
+
+	for _, stream := range regexp.MustCompile(`(?s)<< /Length (\d+) >>\nstream\n(.*?)\nendstream`).FindAllSubmatch(content, -1) {
+		if length, _ := strconv.Atoi(string(stream[1])); length != len(stream[2]) {
+			t.Fatalf("stream length %d, want %d", length, len(stream[2]))
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package token_service
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"github.com/dgrijalva/jwt-go"
+	"testing"
+)
+
+func TestSigningMethodEdDSA(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPublic, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if method := jwt.GetSigningMethod("EdDSA"); method != SigningMethodEdDSA {
+		t.Fatalf("EdDSA is not registered with jwt-go, got %v", method)
+	}
+
+	signingString := "eyJhbGciOiJFZERTQSJ9.eyJzdWIiOiJ0ZXN0In0"
+	signature, err := SigningMethodEdDSA.Sign(signingString, private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = SigningMethodEdDSA.Verify(signingString, signature, public); err != nil {
+		t.Fatalf("valid signature refused: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		signingString string
+		signature     string
+		key           interface{}
+	}{
+		{name: "other key", signingString: signingString, signature: signature, key: otherPublic},
+		{name: "changed payload", signingString: signingString + "x", signature: signature, key: public},
+		{name: "changed signature", signingString: signingString, signature: "A" + signature[1:], key: public},
+		{name: "malformed signature", signingString: signingString, signature: "not base64!", key: public},
+		{name: "private key", signingString: signingString, signature: signature, key: private},
+		{name: "rsa key", signingString: signingString, signature: signature, key: &rsaKey.PublicKey},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := SigningMethodEdDSA.Verify(test.signingString, test.signature, test.key); err == nil {
+				t.Fatal("expected the signature to be refused")
+			}
+		})
+	}
+
+	if _, err = SigningMethodEdDSA.Sign(signingString, public); err != jwt.ErrInvalidKeyType {
+		t.Fatalf("signing with a public key: expected ErrInvalidKeyType, got %v", err)
+	}
+}
@@ -10,4 +10,5 @@ type Service interface {
 	VerifyAndExtract(string, bool) (*entity.ErrandJwtToken, *response.BaseResponse)
 	CheckTokenValidity(string, bool) *response.BaseResponse
 	GetTokenMetaData(string, bool) (string, *response.BaseResponse)
+	Jwks() Jwks
 }
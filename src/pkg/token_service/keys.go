@@ -0,0 +1,243 @@
+package token_service
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/dgrijalva/jwt-go"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	keysFile           = "JWT_KEYS_FILE"
+	DefaultGracePeriod = 30 * 24 * time.Hour // outlives a refresh token signed just before a rotation
+	minRsaKeyBits      = 2048
+)
+
+var ErrNoKeys = errors.New("no JWT signing key is configured, point " + keysFile + " at a key file")
+
+// Key is a signing key. It signs tokens from ActiveFrom until a newer key becomes active, and keeps
+// verifying them for the grace period after that.
+type Key struct {
+	Id         string
+	Algorithm  string
+	ActiveFrom time.Time
+	private    crypto.Signer
+	public     crypto.PublicKey
+}
+
+func (k *Key) method() jwt.SigningMethod {
+	if k.Algorithm == SigningMethodEdDSA.Alg() {
+		return SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+// KeySet holds every configured key ordered by activation. A rotation is scheduled by adding a key with
+// a later active_from, it is published in the JWKS straight away and starts signing at that time.
+type KeySet struct {
+	keys        []Key
+	gracePeriod time.Duration
+}
+
+// JsonWebKey is the public half of a key as served from the JWKS endpoint.
+type JsonWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyId     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Modulus   string `json:"n,omitempty"`
+	Exponent  string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+type Jwks struct {
+	Keys []JsonWebKey `json:"keys"`
+}
+
+type keyConfig struct {
+	Id             string    `json:"kid"`
+	Algorithm      string    `json:"algorithm"`
+	PrivateKeyFile string    `json:"private_key_file"`
+	ActiveFrom     time.Time `json:"active_from"`
+}
+
+type keysConfig struct {
+	GracePeriod string      `json:"grace_period"`
+	Keys        []keyConfig `json:"keys"`
+}
+
+// LoadKeys reads the key set from the file JWT_KEYS_FILE points at, for example
+//
+//	{"grace_period": "720h", "keys": [{"kid": "2024-06", "algorithm": "RS256", "private_key_file": "2024-06.pem", "active_from": "2024-06-01T00:00:00Z"}]}
+//
+// Keys are PKCS#8 or PKCS#1 PEM files, relative paths are resolved against the directory of the file.
+func LoadKeys() (*KeySet, error) {
+	path := strings.TrimSpace(os.Getenv(keysFile))
+	if path == "" {
+		return nil, ErrNoKeys
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", keysFile, err)
+	}
+	var config keysConfig
+	if err = json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", keysFile, err)
+	}
+
+	gracePeriod := DefaultGracePeriod
+	if config.GracePeriod != "" {
+		if gracePeriod, err = time.ParseDuration(config.GracePeriod); err != nil {
+			return nil, fmt.Errorf("invalid grace period: %w", err)
+		}
+	}
+
+	var keys []Key
+	for _, kConfig := range config.Keys {
+		keyPath := kConfig.PrivateKeyFile
+		if keyPath != "" && !filepath.IsAbs(keyPath) {
+			keyPath = filepath.Join(filepath.Dir(path), keyPath)
+		}
+		pemData, err := os.ReadFile(keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read key %s: %w", kConfig.Id, err)
+		}
+		key, err := NewKey(kConfig.Id, kConfig.Algorithm, pemData, kConfig.ActiveFrom)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+	return NewKeySet(keys, gracePeriod)
+}
+
+func NewKey(id, algorithm string, pemData []byte, activeFrom time.Time) (*Key, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil, errors.New("every key needs a kid")
+	}
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, fmt.Errorf("key %s is not PEM encoded", id)
+	}
+	privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		if privateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+			return nil, fmt.Errorf("key %s is not a PKCS#8 or PKCS#1 private key", id)
+		}
+	}
+
+	key := &Key{Id: id, Algorithm: algorithm, ActiveFrom: activeFrom}
+	switch privateKey := privateKey.(type) {
+	case *rsa.PrivateKey:
+		if algorithm != jwt.SigningMethodRS256.Alg() {
+			return nil, fmt.Errorf("key %s is an RSA key, its algorithm must be RS256", id)
+		}
+		if privateKey.N.BitLen() < minRsaKeyBits {
+			return nil, fmt.Errorf("key %s must be at least %d bits", id, minRsaKeyBits)
+		}
+		key.private, key.public = privateKey, privateKey.Public()
+	case ed25519.PrivateKey:
+		if algorithm != SigningMethodEdDSA.Alg() {
+			return nil, fmt.Errorf("key %s is an Ed25519 key, its algorithm must be EdDSA", id)
+		}
+		key.private, key.public = privateKey, privateKey.Public()
+	default:
+		return nil, fmt.Errorf("key %s must be an RSA or Ed25519 key", id)
+	}
+	return key, nil
+}
+
+// NewKeySet fails unless there are keys with unique ids and one of them can sign now.
+func NewKeySet(keys []Key, gracePeriod time.Duration) (*KeySet, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoKeys
+	}
+	ids := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if ids[key.Id] {
+			return nil, fmt.Errorf("kid %s is used by more than one key", key.Id)
+		}
+		ids[key.Id] = true
+	}
+
+	sorted := append([]Key{}, keys...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ActiveFrom.Before(sorted[j].ActiveFrom)
+	})
+	keySet := &KeySet{keys: sorted, gracePeriod: gracePeriod}
+	if _, err := keySet.signing(time.Now()); err != nil {
+		return nil, err
+	}
+	return keySet, nil
+}
+
+// signing is the most recently activated key.
+func (s *KeySet) signing(now time.Time) (*Key, error) {
+	for index := len(s.keys) - 1; index >= 0; index-- {
+		if !s.keys[index].ActiveFrom.After(now) {
+			return &s.keys[index], nil
+		}
+	}
+	return nil, errors.New("none of the JWT signing keys is active yet")
+}
+
+// verifying returns the key with the id unless it is not active yet or its grace period ran out.
+func (s *KeySet) verifying(id string, now time.Time) (*Key, bool) {
+	for index := range s.keys {
+		if s.keys[index].Id != id {
+			continue
+		}
+		if s.keys[index].ActiveFrom.After(now) || s.retired(index, now) {
+			return nil, false
+		}
+		return &s.keys[index], true
+	}
+	return nil, false
+}
+
+// retired reports whether a newer key took over from the key more than a grace period ago.
+func (s *KeySet) retired(index int, now time.Time) bool {
+	for next := index + 1; next < len(s.keys); next++ {
+		if !s.keys[next].ActiveFrom.After(now) {
+			return now.After(s.keys[next].ActiveFrom.Add(s.gracePeriod))
+		}
+	}
+	return false
+}
+
+// Jwks publishes the public keys that are scheduled, signing or still in their grace period.
+func (s *KeySet) Jwks(now time.Time) Jwks {
+	jwks := Jwks{Keys: []JsonWebKey{}}
+	for index, key := range s.keys {
+		if s.retired(index, now) {
+			continue
+		}
+		jwk := JsonWebKey{KeyId: key.Id, Use: "sig", Algorithm: key.Algorithm}
+		switch public := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.Modulus = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.Exponent = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
@@ -0,0 +1,227 @@
+package token_service
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var rsaKey, smallRsaKey *rsa.PrivateKey
+
+func TestMain(m *testing.M) {
+	var err error
+	if rsaKey, err = rsa.GenerateKey(rand.Reader, minRsaKeyBits); err != nil {
+		panic(err)
+	}
+	if smallRsaKey, err = rsa.GenerateKey(rand.Reader, 1024); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func encodeKey(t *testing.T, privateKey interface{}) []byte {
+	data, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: data})
+}
+
+func newEd25519Key(t *testing.T, id string, activeFrom time.Time) Key {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := NewKey(id, SigningMethodEdDSA.Alg(), encodeKey(t, privateKey), activeFrom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *key
+}
+
+func newRsaKey(t *testing.T, id string, activeFrom time.Time) Key {
+	key, err := NewKey(id, "RS256", encodeKey(t, rsaKey), activeFrom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *key
+}
+
+func TestNewKey(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs1 := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})
+
+	tests := []struct {
+		name      string
+		id        string
+		algorithm string
+		pem       []byte
+		ok        bool
+	}{
+		{name: "rsa pkcs8", id: "rsa", algorithm: "RS256", pem: encodeKey(t, rsaKey), ok: true},
+		{name: "rsa pkcs1", id: "rsa", algorithm: "RS256", pem: pkcs1, ok: true},
+		{name: "ed25519", id: "ed", algorithm: "EdDSA", pem: encodeKey(t, edKey), ok: true},
+		{name: "missing kid", id: " ", algorithm: "RS256", pem: encodeKey(t, rsaKey)},
+		{name: "rsa key for EdDSA", id: "rsa", algorithm: "EdDSA", pem: encodeKey(t, rsaKey)},
+		{name: "ed25519 key for RS256", id: "ed", algorithm: "RS256", pem: encodeKey(t, edKey)},
+		{name: "short rsa key", id: "rsa", algorithm: "RS256", pem: encodeKey(t, smallRsaKey)},
+		{name: "not pem", id: "rsa", algorithm: "RS256", pem: []byte("not a key")},
+		{name: "not a private key", id: "rsa", algorithm: "RS256", pem: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("junk")})},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			key, err := NewKey(test.id, test.algorithm, test.pem, time.Now())
+			if test.ok != (err == nil) {
+				t.Fatalf("expected ok %t, got %v", test.ok, err)
+			}
+			if test.ok && (key.private == nil || key.public == nil || key.Id != test.id) {
+				t.Fatalf("incomplete key %+v", key)
+			}
+		})
+	}
+}
+
+func TestNewKeySet(t *testing.T) {
+	cTime := time.Now()
+
+	if _, err := NewKeySet(nil, time.Hour); err != ErrNoKeys {
+		t.Fatalf("expected ErrNoKeys, got %v", err)
+	}
+	if _, err := NewKeySet([]Key{newEd25519Key(t, "a", cTime.Add(-time.Hour)), newEd25519Key(t, "a", cTime)}, time.Hour); err == nil {
+		t.Fatal("duplicate kids were accepted")
+	}
+	if _, err := NewKeySet([]Key{newEd25519Key(t, "a", cTime.Add(time.Hour))}, time.Hour); err == nil {
+		t.Fatal("a key set that cannot sign yet was accepted")
+	}
+}
+
+func TestKeySetRotation(t *testing.T) {
+	cTime := time.Now()
+	old := newRsaKey(t, "old", cTime.Add(-48*time.Hour))
+	current := newEd25519Key(t, "current", cTime.Add(-time.Hour))
+	next := newEd25519Key(t, "next", cTime.Add(time.Hour))
+
+	// Keys are given out of order, the set orders them by activation.
+	keys, err := NewKeySet([]Key{next, old, current}, 2*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		now       time.Time
+		signing   string
+		verifying []string
+		refused   []string
+	}{
+		{name: "before the rotation", now: cTime.Add(-2 * time.Hour), signing: "old", verifying: []string{"old"}, refused: []string{"current", "next"}},
+		{name: "within the grace period", now: cTime, signing: "current", verifying: []string{"old", "current"}, refused: []string{"next", "unknown"}},
+		{name: "after the grace period", now: cTime.Add(90 * time.Minute), signing: "next", verifying: []string{"current", "next"}, refused: []string{"old"}},
+		{name: "after the next grace period", now: cTime.Add(4 * time.Hour), signing: "next", verifying: []string{"next"}, refused: []string{"old", "current"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			key, err := keys.signing(test.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if key.Id != test.signing {
+				t.Fatalf("expected %s to sign, got %s", test.signing, key.Id)
+			}
+			for _, id := range test.verifying {
+				if _, ok := keys.verifying(id, test.now); !ok {
+					t.Fatalf("%s does not verify", id)
+				}
+			}
+			for _, id := range test.refused {
+				if _, ok := keys.verifying(id, test.now); ok {
+					t.Fatalf("%s still verifies", id)
+				}
+			}
+		})
+	}
+}
+
+func TestKeySetJwks(t *testing.T) {
+	cTime := time.Now()
+	retired := newEd25519Key(t, "retired", cTime.Add(-72*time.Hour))
+	old := newRsaKey(t, "old", cTime.Add(-48*time.Hour))
+	current := newEd25519Key(t, "current", cTime.Add(-time.Hour))
+	next := newEd25519Key(t, "next", cTime.Add(time.Hour))
+	keys, err := NewKeySet([]Key{retired, old, current, next}, 2*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwks := keys.Jwks(cTime)
+	published := map[string]JsonWebKey{}
+	for _, jwk := range jwks.Keys {
+		published[jwk.KeyId] = jwk
+	}
+	if len(published) != 3 {
+		t.Fatalf("expected old, current and next, got %+v", jwks.Keys)
+	}
+	if _, ok := published["retired"]; ok {
+		t.Fatal("retired key is published")
+	}
+
+	rsaJwk := published["old"]
+	if rsaJwk.KeyType != "RSA" || rsaJwk.Algorithm != "RS256" || rsaJwk.Use != "sig" || rsaJwk.Exponent != "AQAB" || rsaJwk.X != "" {
+		t.Fatalf("unexpected RSA key %+v", rsaJwk)
+	}
+	if modulus, err := base64.RawURLEncoding.DecodeString(rsaJwk.Modulus); err != nil || string(modulus) != string(rsaKey.N.Bytes()) {
+		t.Fatal("RSA modulus does not match the key")
+	}
+
+	for _, key := range []Key{current, next} {
+		jwk := published[key.Id]
+		if jwk.KeyType != "OKP" || jwk.Curve != "Ed25519" || jwk.Algorithm != "EdDSA" || jwk.Modulus != "" {
+			t.Fatalf("unexpected Ed25519 key %+v", jwk)
+		}
+		if x, err := base64.RawURLEncoding.DecodeString(jwk.X); err != nil || string(x) != string(key.public.(ed25519.PublicKey)) {
+			t.Fatalf("%s public key does not match", key.Id)
+		}
+	}
+
+	if empty := (&KeySet{}).Jwks(cTime); empty.Keys == nil {
+		t.Fatal("an empty key set must publish an empty list, not null")
+	}
+}
+
+func TestLoadKeys(t *testing.T) {
+	directory := t.TempDir()
+	if err := os.WriteFile(filepath.Join(directory, "current.pem"), encodeKey(t, rsaKey), 0600); err != nil {
+		t.Fatal(err)
+	}
+	config := fmt.Sprintf(`{"grace_period": "1h", "keys": [{"kid": "current", "algorithm": "RS256", "private_key_file": "current.pem", "active_from": %q}]}`,
+		time.Now().Add(-time.Hour).Format(time.RFC3339))
+	path := filepath.Join(directory, "keys.json")
+	if err := os.WriteFile(path, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(keysFile, "")
+	if _, err := LoadKeys(); err != ErrNoKeys {
+		t.Fatalf("expected ErrNoKeys, got %v", err)
+	}
+
+	t.Setenv(keysFile, path)
+	keys, err := LoadKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys.gracePeriod != time.Hour || len(keys.keys) != 1 || keys.keys[0].Id != "current" {
+		t.Fatalf("unexpected key set %+v", keys)
+	}
+}
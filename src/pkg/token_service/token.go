@@ -8,43 +8,58 @@ import (
 	"fmt"
 	"github.com/dgrijalva/jwt-go"
 	"os"
+	"time"
 )
 
 const (
-	jwtSecret         = "JWT_SECRET"
-	jwtRefreshSecret  = "JWT_SECRET_REFRESH"
-	accessUuidClaim   = "access_uuid"
-	userIdClaim       = "user_id"
-	accessTokenClaim  = "token_expiry"
-	refreshUuidClaim  = "refresh_uuid"
-	refreshTokenClaim = "access_uuid"
+	jwtSecret        = "JWT_SECRET"
+	jwtRefreshSecret = "JWT_SECRET_REFRESH"
+	accessUuidClaim  = "access_uuid"
+	userIdClaim      = "user_id"
+	refreshUuidClaim = "refresh_uuid"
+	expiresClaim     = "exp"
+	tokenTypeClaim   = "token_type"
+	keyIdHeader      = "kid"
+	accessTokenType  = "access"
+	refreshTokenType = "refresh"
 )
 
-type tokenService struct{}
+type tokenService struct {
+	keys *KeySet
+}
 
-func New() Service {
-	return &tokenService{}
+func New(keys *KeySet) Service {
+	return &tokenService{
+		keys: keys,
+	}
 }
 
 func (token *tokenService) Create(accessTokenExpires, refreshTokenExpires int64, accessUuid, refreshUuid, userId string) (string, string, *response.BaseResponse) {
-	accessTokenSecret := os.Getenv(jwtSecret)
-	refreshTokenSecret := os.Getenv(jwtRefreshSecret)
+	key, err := token.keys.signing(time.Now())
+	if err != nil {
+		logger.Error("no signing key", err)
+		return "", "", response.NewInternalServerError(err.Error())
+	}
 
 	accessTokenClaims := jwt.MapClaims{}
 	accessTokenClaims[accessUuidClaim] = accessUuid
 	accessTokenClaims[userIdClaim] = userId
-	accessTokenClaims[accessTokenClaim] = accessTokenExpires
+	accessTokenClaims[expiresClaim] = accessTokenExpires
+	accessTokenClaims[tokenTypeClaim] = accessTokenType
 
 	refreshTokenClaims := jwt.MapClaims{}
 	refreshTokenClaims[refreshUuidClaim] = refreshUuid
 	refreshTokenClaims[userIdClaim] = userId
-	refreshTokenClaims[refreshTokenClaim] = refreshTokenExpires
+	refreshTokenClaims[expiresClaim] = refreshTokenExpires
+	refreshTokenClaims[tokenTypeClaim] = refreshTokenType
 
-	accessTokenWithClaims := jwt.NewWithClaims(jwt.SigningMethodHS256, accessTokenClaims)
-	accessToken, accessTokenError := accessTokenWithClaims.SignedString([]byte(accessTokenSecret))
+	accessTokenWithClaims := jwt.NewWithClaims(key.method(), accessTokenClaims)
+	accessTokenWithClaims.Header[keyIdHeader] = key.Id
+	accessToken, accessTokenError := accessTokenWithClaims.SignedString(key.private)
 
-	refreshTokenWithClaims := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshTokenClaims)
-	refreshToken, refreshTokenError := refreshTokenWithClaims.SignedString([]byte(refreshTokenSecret))
+	refreshTokenWithClaims := jwt.NewWithClaims(key.method(), refreshTokenClaims)
+	refreshTokenWithClaims.Header[keyIdHeader] = key.Id
+	refreshToken, refreshTokenError := refreshTokenWithClaims.SignedString(key.private)
 
 	if accessTokenError != nil {
 		logger.Error("error creating access token", accessTokenError)
@@ -59,27 +74,62 @@ func (token *tokenService) Create(accessTokenExpires, refreshTokenExpires int64,
 }
 
 func (token *tokenService) VerifyAndExtract(userToken string, isAccessToken bool) (*entity.ErrandJwtToken, *response.BaseResponse) {
-	var tokenSecret string
-	if isAccessToken {
-		tokenSecret = os.Getenv(jwtSecret)
-	} else {
-		tokenSecret = os.Getenv(jwtRefreshSecret)
-	}
-
 	parsedToken, tokenValidationError := jwt.Parse(userToken, func(jwtToken *jwt.Token) (interface{}, error) {
-		if _, ok := jwtToken.Method.(*jwt.SigningMethodHMAC); !ok {
+		keyId, ok := jwtToken.Header[keyIdHeader].(string)
+		if !ok {
+			return legacySecret(jwtToken, isAccessToken)
+		}
+		key, ok := token.keys.verifying(keyId, time.Now())
+		if !ok {
+			return nil, fmt.Errorf("unknown or retired key: %s", keyId)
+		}
+		if jwtToken.Method.Alg() != key.Algorithm {
 			errorMessage := fmt.Sprintf("unexpected signing method: %v", jwtToken.Header["alg"])
 			logger.Error("signing method error", errors.New(errorMessage))
 			return nil, errors.New(errorMessage)
 		}
-		return []byte(tokenSecret), nil
+		return key.public, nil
 	})
 	if tokenValidationError != nil {
 		return nil, response.NewUnAuthorizedError()
 	}
+
+	// Both token types are signed with the same key, so the claim is what keeps a refresh token out
+	// of places that take an access token
+	if _, ok := parsedToken.Header[keyIdHeader]; ok {
+		tokenType := accessTokenType
+		if !isAccessToken {
+			tokenType = refreshTokenType
+		}
+		if claims, ok := parsedToken.Claims.(jwt.MapClaims); !ok || claims[tokenTypeClaim] != tokenType {
+			return nil, response.NewUnAuthorizedError()
+		}
+	}
 	return parsedToken, nil
 }
 
+// legacySecret verifies tokens issued before signing keys had ids, while JWT_SECRET and
+// JWT_SECRET_REFRESH are still set. Unset them once those tokens expired.
+func legacySecret(jwtToken *jwt.Token, isAccessToken bool) (interface{}, error) {
+	if _, ok := jwtToken.Method.(*jwt.SigningMethodHMAC); !ok {
+		errorMessage := fmt.Sprintf("unexpected signing method: %v", jwtToken.Header["alg"])
+		logger.Error("signing method error", errors.New(errorMessage))
+		return nil, errors.New(errorMessage)
+	}
+	tokenSecret := os.Getenv(jwtSecret)
+	if !isAccessToken {
+		tokenSecret = os.Getenv(jwtRefreshSecret)
+	}
+	if tokenSecret == "" {
+		return nil, errors.New("token has no key id")
+	}
+	return []byte(tokenSecret), nil
+}
+
+func (token *tokenService) Jwks() Jwks {
+	return token.keys.Jwks(time.Now())
+}
+
 func (token *tokenService) CheckTokenValidity(userToken string, isAccessToken bool) *response.BaseResponse {
 	parsedToken, parseError := token.VerifyAndExtract(userToken, isAccessToken)
 	if parseError != nil {
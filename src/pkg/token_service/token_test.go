@@ -0,0 +1,143 @@
+package token_service
+
+import (
+	"github.com/dgrijalva/jwt-go"
+	"testing"
+	"time"
+)
+
+func createTokens(t *testing.T, service Service) (string, string) {
+	expires := time.Now().Add(time.Hour).Unix()
+	access, refresh, resp := service.Create(expires, expires, "access-1", "refresh-1", "user-1")
+	if resp != nil {
+		t.Fatalf("create: %s", resp.Message)
+	}
+	return access, refresh
+}
+
+func TestRotationRoundTrip(t *testing.T) {
+	cTime := time.Now()
+	old := newRsaKey(t, "old", cTime.Add(-48*time.Hour))
+	current := newEd25519Key(t, "current", cTime.Add(-time.Minute))
+
+	before, err := NewKeySet([]Key{old}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotated, err := NewKeySet([]Key{old, current}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, err := NewKeySet([]Key{old, newEd25519Key(t, "current", cTime.Add(-2*time.Hour))}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oldAccess, oldRefresh := createTokens(t, New(before))
+	newAccess, newRefresh := createTokens(t, New(rotated))
+
+	tests := []struct {
+		name    string
+		service Service
+		token   string
+		access  bool
+		uuid    string
+	}{
+		{name: "old access token before the rotation", service: New(before), token: oldAccess, access: true, uuid: "access-1"},
+		{name: "old access token in the grace period", service: New(rotated), token: oldAccess, access: true, uuid: "access-1"},
+		{name: "old refresh token in the grace period", service: New(rotated), token: oldRefresh, uuid: "refresh-1"},
+		{name: "new access token", service: New(rotated), token: newAccess, access: true, uuid: "access-1"},
+		{name: "new refresh token", service: New(rotated), token: newRefresh, uuid: "refresh-1"},
+		{name: "new token before the rotation", service: New(before), token: newAccess, access: true},
+		{name: "old token after the grace period", service: New(expired), token: oldAccess, access: true},
+		{name: "refresh token as an access token", service: New(rotated), token: newRefresh, access: true},
+		{name: "access token as a refresh token", service: New(rotated), token: newAccess},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			uuid, resp := test.service.GetTokenMetaData(test.token, test.access)
+			if test.uuid == "" {
+				if resp == nil {
+					t.Fatalf("expected the token to be refused, got %s", uuid)
+				}
+				return
+			}
+			if resp != nil {
+				t.Fatalf("token refused: %s", resp.Message)
+			}
+			if uuid != test.uuid {
+				t.Fatalf("expected %s, got %s", test.uuid, uuid)
+			}
+		})
+	}
+
+	header, err := parseHeader(newAccess)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header["kid"] != "current" || header["alg"] != "EdDSA" {
+		t.Fatalf("token is not signed with the active key: %v", header)
+	}
+}
+
+func TestUnknownKeyId(t *testing.T) {
+	cTime := time.Now()
+	keys, err := NewKeySet([]Key{newEd25519Key(t, "current", cTime.Add(-time.Hour))}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := New(keys)
+
+	// Signed with a key the set does not hold, under an id it does not know and under the id it does.
+	stranger := newEd25519Key(t, "stranger", cTime.Add(-time.Hour))
+	for _, kid := range []string{"stranger", "current"} {
+		token := jwt.NewWithClaims(SigningMethodEdDSA, jwt.MapClaims{
+			accessUuidClaim: "access-1",
+			userIdClaim:     "user-1",
+			expiresClaim:    cTime.Add(time.Hour).Unix(),
+			tokenTypeClaim:  accessTokenType,
+		})
+		token.Header[keyIdHeader] = kid
+		signed, err := token.SignedString(stranger.private)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, resp := service.GetTokenMetaData(signed, true); resp == nil {
+			t.Fatalf("token with kid %s signed by an unknown key was accepted", kid)
+		}
+	}
+
+	// Without a kid only the legacy HMAC secrets apply, and they are not set.
+	t.Setenv(jwtSecret, "")
+	legacy := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		accessUuidClaim: "access-1",
+		userIdClaim:     "user-1",
+		expiresClaim:    cTime.Add(time.Hour).Unix(),
+	})
+	signed, err := legacy.SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, resp := service.GetTokenMetaData(signed, true); resp == nil {
+		t.Fatal("token without a kid was accepted")
+	}
+}
+
+func TestJwks(t *testing.T) {
+	keys, err := NewKeySet([]Key{newEd25519Key(t, "current", time.Now().Add(-time.Hour))}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks := New(keys).Jwks()
+	if len(jwks.Keys) != 1 || jwks.Keys[0].KeyId != "current" {
+		t.Fatalf("unexpected jwks %+v", jwks)
+	}
+}
+
+func parseHeader(token string) (map[string]interface{}, error) {
+	parsed, _, err := new(jwt.Parser).ParseUnverified(token, jwt.MapClaims{})
+	if err != nil {
+		return nil, err
+	}
+	return parsed.Header, nil
+}
@@ -0,0 +1,9 @@
+package totp_service
+
+import "time"
+
+type Service interface {
+	GenerateSecret() (string, error)
+	ProvisioningUri(string, string) string
+	Validate(string, string, int64, time.Time) (int64, bool)
+}
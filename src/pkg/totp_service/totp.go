@@ -0,0 +1,92 @@
+package totp_service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Codes follow RFC 6238 with the defaults authenticator apps expect: SHA-1, 6 digits and 30 second steps.
+const (
+	digits     = 6
+	step       = 30
+	skew       = 1 // steps accepted either side of the current one
+	secretSize = 20
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type totp struct {
+	issuer string
+}
+
+func New(issuer string) Service {
+	return &totp{
+		issuer: issuer,
+	}
+}
+
+func (t *totp) GenerateSecret() (string, error) {
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(secret), nil
+}
+
+// ProvisioningUri is the otpauth:// URI authenticator apps read from a QR code.
+func (t *totp) ProvisioningUri(secret, account string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", t.issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(digits))
+	query.Set("period", fmt.Sprint(step))
+
+	label := url.PathEscape(t.issuer + ":" + account)
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, query.Encode())
+}
+
+// Validate checks the code against the steps around now and returns the matching step. Steps up to
+// lastStep are refused so a code cannot be replayed.
+func (t *totp) Validate(secret, code string, lastStep int64, now time.Time) (int64, bool) {
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != digits {
+		return 0, false
+	}
+
+	current := now.Unix() / step
+	for counter := current - skew; counter <= current+skew; counter++ {
+		if counter <= lastStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(generate(key, counter)), []byte(code)) == 1 {
+			return counter, true
+		}
+	}
+	return 0, false
+}
+
+func generate(key []byte, counter int64) string {
+	message := make([]byte, 8)
+	binary.BigEndian.PutUint64(message, uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	modulo := uint32(1)
+	for index := 0; index < digits; index++ {
+		modulo *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, value%modulo)
+}
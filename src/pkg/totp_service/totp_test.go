@@ -0,0 +1,103 @@
+package totp_service
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The SHA-1 vectors of RFC 6238 Appendix B, cut to the last six of their eight digits.
+var rfc6238 = []struct {
+	time int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+var rfc6238Secret = encoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestGenerate(t *testing.T) {
+	for _, vector := range rfc6238 {
+		if code := generate([]byte("12345678901234567890"), vector.time/step); code != vector.code {
+			t.Errorf("at %d: expected %s, got %s", vector.time, vector.code, code)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	service := New("Errand App")
+
+	for _, vector := range rfc6238 {
+		now := time.Unix(vector.time, 0)
+		counter, ok := service.Validate(rfc6238Secret, vector.code, 0, now)
+		if !ok || counter != vector.time/step {
+			t.Errorf("at %d: expected step %d, got %d %t", vector.time, vector.time/step, counter, ok)
+		}
+		// Lower case secrets are accepted, authenticator apps are not consistent about the case.
+		if _, ok = service.Validate(strings.ToLower(rfc6238Secret), vector.code, 0, now); !ok {
+			t.Errorf("at %d: lower case secret refused", vector.time)
+		}
+	}
+
+	now := time.Unix(1111111111, 0)
+	code := "050471"
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		lastStep int64
+		now      time.Time
+		ok       bool
+	}{
+		{name: "one step early", secret: rfc6238Secret, code: code, now: now.Add(-step * time.Second), ok: true},
+		{name: "one step late", secret: rfc6238Secret, code: code, now: now.Add(step * time.Second), ok: true},
+		{name: "two steps late", secret: rfc6238Secret, code: code, now: now.Add(2 * step * time.Second)},
+		{name: "replayed", secret: rfc6238Secret, code: code, lastStep: now.Unix() / step, now: now},
+		{name: "wrong code", secret: rfc6238Secret, code: "050472", now: now},
+		{name: "short code", secret: rfc6238Secret, code: "50471", now: now},
+		{name: "invalid secret", secret: "not base32!", code: code, now: now},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, ok := service.Validate(test.secret, test.code, test.lastStep, test.now); ok != test.ok {
+				t.Fatalf("expected %t, got %t", test.ok, ok)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	service := New("Errand App")
+
+	secret, err := service.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := encoding.DecodeString(secret)
+	if err != nil || len(key) != secretSize {
+		t.Fatalf("expected a %d byte base32 secret, got %q", secretSize, secret)
+	}
+	if other, _ := service.GenerateSecret(); other == secret {
+		t.Fatal("secrets repeat")
+	}
+}
+
+func TestProvisioningUri(t *testing.T) {
+	uri, err := url.Parse(New("Errand App").ProvisioningUri(rfc6238Secret, "+2348000000000"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/Errand App:+2348000000000" {
+		t.Fatalf("unexpected uri %s", uri)
+	}
+	query := uri.Query()
+	if query.Get("secret") != rfc6238Secret || query.Get("issuer") != "Errand App" || query.Get("digits") != "6" || query.Get("period") != "30" {
+		t.Fatalf("unexpected parameters %s", uri.RawQuery)
+	}
+}
@@ -0,0 +1,80 @@
+package webhook_service
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+const maxResponseBody = 4096
+
+type httpService struct {
+	client *http.Client
+}
+
+// NewHttp posts webhooks to partner endpoints. It refuses to connect to private, loopback and link-local
+// addresses so a webhook url cannot be used to reach internal services.
+func NewHttp(timeout time.Duration) Service {
+	return newHttp(timeout, isPublic)
+}
+
+// newHttp connects only to the addresses allowed accepts.
+func newHttp(timeout time.Duration, allowed func(net.IP) bool) *httpService {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !allowed(ip) {
+				return ErrForbiddenAddress
+			}
+			return nil
+		},
+	}
+	transport := &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: timeout,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+	}
+	return &httpService{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+func (h *httpService) Post(request Request) (*Response, error) {
+	req, err := http.NewRequest(http.MethodPost, request.Url, bytes.NewReader(request.Body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "DX-Webhooks/1.0")
+	for key, value := range request.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	return &Response{Code: resp.StatusCode, Body: string(body)}, nil
+}
+
+func isPublic(ip net.IP) bool {
+	return !(ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsUnspecified() || ip.IsMulticast() || ip.IsInterfaceLocalMulticast())
+}
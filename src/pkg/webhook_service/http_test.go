@@ -0,0 +1,91 @@
+package webhook_service
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestIsPublic(t *testing.T) {
+	tests := []struct {
+		ip     string
+		public bool
+	}{
+		{ip: "8.8.8.8", public: true},
+		{ip: "2001:4860:4860::8888", public: true},
+		{ip: "10.0.0.4"},
+		{ip: "172.16.0.1"},
+		{ip: "192.168.1.1"},
+		{ip: "127.0.0.1"},
+		{ip: "::1"},
+		{ip: "::ffff:127.0.0.1"},
+		{ip: "169.254.169.254"},
+		{ip: "fe80::1"},
+		{ip: "fc00::1"},
+		{ip: "0.0.0.0"},
+		{ip: "::"},
+		{ip: "224.0.0.1"},
+	}
+
+	for _, test := range tests {
+		if public := isPublic(net.ParseIP(test.ip)); public != test.public {
+			t.Fatalf("isPublic(%s) = %v, want %v", test.ip, public, test.public)
+		}
+	}
+}
+
+func TestPostRefusesInternalAddresses(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		calls++
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{name: "loopback", url: server.URL},
+		{name: "localhost", url: "http://localhost:" + strconv.Itoa(server.Listener.Addr().(*net.TCPAddr).Port)},
+		{name: "private", url: "http://10.0.0.4:8080"},
+		{name: "metadata", url: "http://169.254.169.254/latest/meta-data"},
+	}
+
+	service := NewHttp(time.Second)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := service.Post(Request{Url: test.url, Body: []byte(`{}`)}); !errors.Is(err, ErrForbiddenAddress) {
+				t.Fatalf("Post() error = %v, want %v", err, ErrForbiddenAddress)
+			}
+		})
+	}
+	if calls != 0 {
+		t.Fatalf("%d requests reached the server", calls)
+	}
+}
+
+func TestPostDoesNotFollowRedirects(t *testing.T) {
+	var followed bool
+	target := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		followed = true
+	}))
+	defer target.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	// Loopback is allowed here so the test servers can be reached, the redirect must still not be followed
+	service := newHttp(time.Second, func(net.IP) bool { return true })
+	resp, err := service.Post(Request{Url: server.URL, Body: []byte(`{}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Code != http.StatusTemporaryRedirect || followed {
+		t.Fatalf("status = %d, followed = %v, want the redirect itself", resp.Code, followed)
+	}
+}
@@ -0,0 +1,21 @@
+package webhook_service
+
+import "errors"
+
+var ErrForbiddenAddress = errors.New("webhook url resolves to a private address")
+
+type Request struct {
+	Url     string
+	Headers map[string]string
+	Body    []byte
+}
+
+// Response is what the endpoint answered, the body is cut short.
+type Response struct {
+	Code int
+	Body string
+}
+
+type Service interface {
+	Post(Request) (*Response, error)
+}